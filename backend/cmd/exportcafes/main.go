@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/domains/cafes"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	var (
+		format       = flag.String("format", "geojson", "Output format: geojson|csv|json.")
+		out          = flag.String("out", "", "Output file (default stdout).")
+		bbox         = flag.String("bbox", "", "Only cafes inside min_lng,min_lat,max_lng,max_lat.")
+		amenities    = flag.String("amenities", "", "Only cafes with all of these amenities (comma separated).")
+		updatedSince = flag.String("updated-since", "", "Only cafes changed since this RFC3339 time or YYYY-MM-DD date.")
+		timeout      = flag.Duration("timeout", 30*time.Minute, "Export timeout.")
+	)
+	flag.Parse()
+
+	// Logs go to stderr: stdout may carry the export itself.
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stderr, nil)))
+
+	_ = godotenv.Load()
+
+	exportFormat, err := cafes.NormalizeCafeExportFormat(*format)
+	if err != nil {
+		slog.Error("invalid format", "error", err)
+		os.Exit(1)
+	}
+	filter, err := cafes.ParseCafeExportFilter(*bbox, *amenities, *updatedSince)
+	if err != nil {
+		slog.Error("invalid filter", "error", err)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("config load failed", "error", err)
+		os.Exit(1)
+	}
+
+	dbURL, err := resolveDatabaseURL()
+	if err != nil {
+		slog.Error("fatal error", "error", err)
+		os.Exit(1)
+	}
+	pool, err := connectDB(dbURL)
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	target := os.Stdout
+	if path := strings.TrimSpace(*out); path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			slog.Error("create output failed", "error", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		target = file
+	}
+	writer := bufio.NewWriterSize(target, 64*1024)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	service := cafes.NewService(cafes.NewRepository(pool), cfg)
+	count, err := service.ExportCafes(ctx, filter, exportFormat, writer)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err != nil {
+		slog.Error("export failed", "written", count, "error", err)
+		os.Exit(1)
+	}
+	slog.Info("export completed", "format", exportFormat, "cafes", count, "out", *out)
+}
+
+func resolveDatabaseURL() (string, error) {
+	for _, key := range []string{"DATABASE_URL", "DATABASE_URL_2", "DATABASE_URL_3"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("DATABASE_URL or DATABASE_URL_2 or DATABASE_URL_3 is required")
+}
+
+func connectDB(dbURL string) (*pgxpool.Pool, error) {
+	cfgPool, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	cfgPool.MinConns = 1
+	cfgPool.MaxConns = 2
+	cfgPool.ConnConfig.ConnectTimeout = 15 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	return pgxpool.NewWithConfig(ctx, cfgPool)
+}
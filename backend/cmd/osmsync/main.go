@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/domains/osmsync"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	var (
+		fromFile = flag.String("from-file", "", "Replay a recorded Overpass JSON response instead of querying Overpass.")
+		record   = flag.String("record", "", "Save the raw Overpass response to this file.")
+		areaID   = flag.Int64("area", 0, "Overpass area id (default OSM_SYNC_AREA_ID).")
+		dryRun   = flag.Bool("dry-run", false, "Compute the diff and print it without storing links or submissions.")
+	)
+	flag.Parse()
+
+	_ = godotenv.Load()
+
+	cfg, err := config.Load()
+	if err != nil {
+		slog.Error("config load failed", "error", err)
+		os.Exit(1)
+	}
+	if *areaID > 0 {
+		cfg.OSMSync.AreaID = *areaID
+	}
+
+	dbURL, err := resolveDatabaseURL()
+	if err != nil {
+		slog.Error("fatal error", "error", err)
+		os.Exit(1)
+	}
+	pool, err := connectDB(dbURL)
+	if err != nil {
+		slog.Error("db connect failed", "error", err)
+		os.Exit(1)
+	}
+	defer pool.Close()
+
+	service := osmsync.NewService(osmsync.NewRepository(pool), cfg.OSMSync)
+
+	var source osmsync.Source
+	if path := strings.TrimSpace(*fromFile); path != "" {
+		source = osmsync.FileSource{Path: path}
+	} else {
+		live := service.OverpassSource()
+		if path := strings.TrimSpace(*record); path != "" {
+			file, err := os.Create(path)
+			if err != nil {
+				slog.Error("create record file failed", "error", err)
+				os.Exit(1)
+			}
+			defer file.Close()
+			live.Record = file
+		}
+		source = live
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.OSMSync.Timeout+2*time.Minute)
+	defer cancel()
+
+	result, err := service.Run(ctx, source, *dryRun)
+	if err != nil {
+		slog.Error("osm sync failed", "error", err)
+		os.Exit(1)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		slog.Error("print result failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+func resolveDatabaseURL() (string, error) {
+	for _, key := range []string{"DATABASE_URL", "DATABASE_URL_2", "DATABASE_URL_3"} {
+		if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+			return value, nil
+		}
+	}
+	return "", fmt.Errorf("DATABASE_URL or DATABASE_URL_2 or DATABASE_URL_3 is required")
+}
+
+func connectDB(dbURL string) (*pgxpool.Pool, error) {
+	cfgPool, err := pgxpool.ParseConfig(dbURL)
+	if err != nil {
+		return nil, err
+	}
+	cfgPool.MinConns = 1
+	cfgPool.MaxConns = 2
+	cfgPool.ConnConfig.ConnectTimeout = 15 * time.Second
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+	return pgxpool.NewWithConfig(ctx, cfgPool)
+}
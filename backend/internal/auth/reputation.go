@@ -12,6 +12,7 @@ const sqlSelectUserReputationEvents = `select
 	id,
 	event_type,
 	points::float8,
+	source_id,
 	created_at
 from reputation_events
 where user_id = $1::uuid
@@ -50,15 +51,17 @@ func loadUserReputationEvents(ctx context.Context, q queryer, userID string) ([]
 			id        int64
 			eventType string
 			points    float64
+			sourceID  string
 			createdAt time.Time
 		)
-		if err := rows.Scan(&id, &eventType, &points, &createdAt); err != nil {
+		if err := rows.Scan(&id, &eventType, &points, &sourceID, &createdAt); err != nil {
 			return nil, err
 		}
 		events = append(events, reputation.ScoreEvent{
 			ID:        id,
 			EventType: eventType,
 			Points:    points,
+			SourceID:  sourceID,
 			CreatedAt: createdAt,
 		})
 	}
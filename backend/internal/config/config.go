@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"math"
 	"os"
 	"strconv"
 	"strings"
@@ -10,15 +11,18 @@ import (
 )
 
 type Config struct {
-	Port      string
-	PublicDir string
-	CORS      CORSConfig
-	Limits    LimitsConfig
-	Auth      AuthConfig
-	Mailer    MailerConfig
-	Feedback  FeedbackConfig
-	Media     MediaConfig
-	Geocoding GeocodingConfig
+	Port       string
+	PublicDir  string
+	CORS       CORSConfig
+	Limits     LimitsConfig
+	Auth       AuthConfig
+	Mailer     MailerConfig
+	Feedback   FeedbackConfig
+	Media      MediaConfig
+	Geocoding  GeocodingConfig
+	Pagination PaginationConfig
+	OSMSync    OSMSyncConfig
+	Ranking    RankingConfig
 }
 
 type CORSConfig struct {
@@ -88,11 +92,46 @@ type MediaConfig struct {
 	PhotoFormatEncoderFormats  []string
 }
 
+type PaginationConfig struct {
+	CursorSecret string
+}
+
 type GeocodingConfig struct {
 	YandexAPIKey     string
 	NominatimBaseURL string
 	UserAgent        string
 	Timeout          time.Duration
+	// Providers are tried in order; unconfigured ones (yandex without a key)
+	// are skipped.
+	Providers        []string
+	CacheTTL         time.Duration
+	NegativeCacheTTL time.Duration
+	// RateLimits caps requests per second per provider; providers without an
+	// entry are not limited.
+	RateLimits map[string]float64
+}
+
+type OSMSyncConfig struct {
+	Enabled     bool
+	Interval    time.Duration
+	OverpassURL string
+	AreaID      int64
+	UserAgent   string
+	Timeout     time.Duration
+	// MaxRemovedRatio is the share of linked cafes a single run may report
+	// as removed before the fetch is treated as incomplete.
+	MaxRemovedRatio float64
+}
+
+// RankingConfig weighs the components of sort=relevance. Weights are relative
+// to each other; DistanceHalfLifeM is the distance at which the distance
+// component drops to one half.
+type RankingConfig struct {
+	DistanceWeight    float64
+	RatingWeight      float64
+	VerifiedWeight    float64
+	TasteWeight       float64
+	DistanceHalfLifeM float64
 }
 
 func Load() (Config, error) {
@@ -201,6 +240,58 @@ func Load() (Config, error) {
 	if err != nil {
 		return cfg, err
 	}
+	geocodingCacheTTL, err := getEnvDuration("GEOCODING_CACHE_TTL", 30*24*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+	geocodingNegativeCacheTTL, err := getEnvDuration("GEOCODING_NEGATIVE_CACHE_TTL", 6*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+	geocodingRateLimits, err := parseRateLimits(getEnvTrim("GEOCODING_RATE_LIMITS", "nominatim=1,yandex=20"))
+	if err != nil {
+		return cfg, err
+	}
+	osmSyncEnabled, err := getEnvBool("OSM_SYNC_ENABLED", false)
+	if err != nil {
+		return cfg, err
+	}
+	osmSyncInterval, err := getEnvDuration("OSM_SYNC_INTERVAL", 24*time.Hour)
+	if err != nil {
+		return cfg, err
+	}
+	osmSyncTimeout, err := getEnvDuration("OSM_SYNC_TIMEOUT", 3*time.Minute)
+	if err != nil {
+		return cfg, err
+	}
+	osmSyncAreaID, err := getEnvInt("OSM_SYNC_AREA_ID", 3600337422)
+	if err != nil {
+		return cfg, err
+	}
+	osmSyncMaxRemovedRatio, err := getEnvFloat("OSM_SYNC_MAX_REMOVED_RATIO", 0.2)
+	if err != nil {
+		return cfg, err
+	}
+	rankingDistanceWeight, err := getEnvFloat("RANKING_WEIGHT_DISTANCE", 0.45)
+	if err != nil {
+		return cfg, err
+	}
+	rankingRatingWeight, err := getEnvFloat("RANKING_WEIGHT_RATING", 0.3)
+	if err != nil {
+		return cfg, err
+	}
+	rankingVerifiedWeight, err := getEnvFloat("RANKING_WEIGHT_VERIFIED", 0.1)
+	if err != nil {
+		return cfg, err
+	}
+	rankingTasteWeight, err := getEnvFloat("RANKING_WEIGHT_TASTE", 0.15)
+	if err != nil {
+		return cfg, err
+	}
+	rankingDistanceHalfLifeM, err := getEnvFloat("RANKING_DISTANCE_HALF_LIFE_M", 1000)
+	if err != nil {
+		return cfg, err
+	}
 
 	cfg.CORS = CORSConfig{
 		AllowOrigins:     splitEnvList("CORS_ALLOW_ORIGINS", []string{"http://localhost:3001", "http://localhost:5173"}),
@@ -278,6 +369,29 @@ func Load() (Config, error) {
 		NominatimBaseURL: getEnvTrim("NOMINATIM_BASE_URL", "https://nominatim.openstreetmap.org"),
 		UserAgent:        getEnvTrim("GEOCODER_USER_AGENT", "gde-kofe geocoder/1.0"),
 		Timeout:          geocodingTimeout,
+		Providers:        normalizeLowerList(splitEnvList("GEOCODING_PROVIDERS", []string{"yandex", "nominatim"})),
+		CacheTTL:         geocodingCacheTTL,
+		NegativeCacheTTL: geocodingNegativeCacheTTL,
+		RateLimits:       geocodingRateLimits,
+	}
+	cfg.Pagination = PaginationConfig{
+		CursorSecret: getEnvTrim("CURSOR_SIGNING_SECRET", ""),
+	}
+	cfg.OSMSync = OSMSyncConfig{
+		Enabled:         osmSyncEnabled,
+		Interval:        osmSyncInterval,
+		OverpassURL:     getEnvTrim("OVERPASS_URL", "https://overpass-api.de/api/interpreter"),
+		AreaID:          int64(osmSyncAreaID),
+		UserAgent:       getEnvTrim("OSM_SYNC_USER_AGENT", "gde-kofe osmsync/1.0"),
+		Timeout:         osmSyncTimeout,
+		MaxRemovedRatio: osmSyncMaxRemovedRatio,
+	}
+	cfg.Ranking = RankingConfig{
+		DistanceWeight:    rankingDistanceWeight,
+		RatingWeight:      rankingRatingWeight,
+		VerifiedWeight:    rankingVerifiedWeight,
+		TasteWeight:       rankingTasteWeight,
+		DistanceHalfLifeM: rankingDistanceHalfLifeM,
 	}
 
 	slog.Info("config loaded",
@@ -368,6 +482,41 @@ func Load() (Config, error) {
 	if strings.TrimSpace(cfg.Geocoding.NominatimBaseURL) == "" {
 		return cfg, fmt.Errorf("NOMINATIM_BASE_URL must not be empty")
 	}
+	if len(cfg.Geocoding.Providers) == 0 {
+		return cfg, fmt.Errorf("GEOCODING_PROVIDERS must contain at least one provider")
+	}
+	if cfg.Geocoding.CacheTTL < 0 || cfg.Geocoding.NegativeCacheTTL < 0 {
+		return cfg, fmt.Errorf("GEOCODING_CACHE_TTL and GEOCODING_NEGATIVE_CACHE_TTL must be >= 0")
+	}
+	if cfg.OSMSync.Interval <= 0 {
+		return cfg, fmt.Errorf("OSM_SYNC_INTERVAL must be > 0")
+	}
+	if cfg.OSMSync.Timeout <= 0 {
+		return cfg, fmt.Errorf("OSM_SYNC_TIMEOUT must be > 0")
+	}
+	if cfg.OSMSync.AreaID <= 0 {
+		return cfg, fmt.Errorf("OSM_SYNC_AREA_ID must be > 0")
+	}
+	if cfg.OSMSync.MaxRemovedRatio <= 0 || cfg.OSMSync.MaxRemovedRatio > 1 {
+		return cfg, fmt.Errorf("OSM_SYNC_MAX_REMOVED_RATIO must be in range (0, 1]")
+	}
+	if cfg.OSMSync.Enabled && strings.TrimSpace(cfg.OSMSync.OverpassURL) == "" {
+		return cfg, fmt.Errorf("OVERPASS_URL must not be empty when OSM_SYNC_ENABLED=true")
+	}
+	rankingWeights := []float64{cfg.Ranking.DistanceWeight, cfg.Ranking.RatingWeight, cfg.Ranking.VerifiedWeight, cfg.Ranking.TasteWeight}
+	rankingWeightsSum := 0.0
+	for _, weight := range rankingWeights {
+		if weight < 0 || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return cfg, fmt.Errorf("RANKING_WEIGHT_* must be finite and >= 0")
+		}
+		rankingWeightsSum += weight
+	}
+	if rankingWeightsSum <= 0 {
+		return cfg, fmt.Errorf("at least one RANKING_WEIGHT_* must be > 0")
+	}
+	if cfg.Ranking.DistanceHalfLifeM <= 0 || math.IsInf(cfg.Ranking.DistanceHalfLifeM, 0) {
+		return cfg, fmt.Errorf("RANKING_DISTANCE_HALF_LIFE_M must be > 0")
+	}
 
 	return cfg, nil
 }
@@ -425,6 +574,45 @@ func splitEnvList(key string, def []string) []string {
 	return out
 }
 
+func normalizeLowerList(values []string) []string {
+	out := make([]string, 0, len(values))
+	seen := make(map[string]struct{}, len(values))
+	for _, raw := range values {
+		v := strings.ToLower(strings.TrimSpace(raw))
+		if v == "" {
+			continue
+		}
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		out = append(out, v)
+	}
+	return out
+}
+
+// parseRateLimits reads "name=rps,name=rps".
+func parseRateLimits(raw string) (map[string]float64, error) {
+	out := map[string]float64{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return nil, fmt.Errorf("GEOCODING_RATE_LIMITS must look like provider=rps,provider=rps")
+		}
+		rps, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || rps <= 0 {
+			return nil, fmt.Errorf("GEOCODING_RATE_LIMITS: %s must be a positive number", name)
+		}
+		out[name] = rps
+	}
+	return out, nil
+}
+
 func normalizePhotoFormatList(values []string) []string {
 	if len(values) == 0 {
 		return nil
@@ -460,6 +648,18 @@ func getEnvInt(key string, def int) (int, error) {
 	return value, nil
 }
 
+func getEnvFloat(key string, def float64) (float64, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+	return value, nil
+}
+
 func getEnvBool(key string, def bool) (bool, error) {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
@@ -1,7 +1,12 @@
 package config
 
 const (
-	EarthRadiusM   = 6371000.0
-	DefaultSort    = "distance"
-	SortByDistance = "distance"
+	EarthRadiusM    = 6371000.0
+	DefaultSort     = "distance"
+	SortByDistance  = "distance"
+	SortByWork      = "work"
+	SortByRating    = "rating"
+	SortByRelevance = "relevance"
+
+	DefaultTimezone = "Europe/Moscow"
 )
@@ -0,0 +1,237 @@
+package amenities
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func NewDefaultHandler(pool *pgxpool.Pool) *Handler {
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	return NewHandler(service)
+}
+
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+func (h *Handler) List(c *gin.Context) {
+	includeDeprecated := false
+	if raw := strings.TrimSpace(c.Query("include_deprecated")); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "include_deprecated должен быть true/false.", nil)
+			return
+		}
+		includeDeprecated = value
+	}
+	locale := c.Query("locale")
+	if strings.TrimSpace(locale) == "" {
+		locale, _, _ = strings.Cut(c.GetHeader("Accept-Language"), ",")
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.List(ctx, normalizeLocale(locale), includeDeprecated)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить список удобств.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminCreate(c *gin.Context) {
+	var req createAmenityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	input, err := normalizeCreateInput(req)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	item, err := h.service.Create(ctx, input)
+	if err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *Handler) AdminUpdate(c *gin.Context) {
+	key, ok := readKey(c)
+	if !ok {
+		return
+	}
+	var req updateAmenityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	input, err := normalizeUpdateInput(key, req)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	item, err := h.service.Update(ctx, input)
+	if err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) AdminRename(c *gin.Context) {
+	from, ok := readKey(c)
+	if !ok {
+		return
+	}
+	var req renameAmenityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	to, ok := validation.ParseAmenityKey(req.To)
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный новый ключ удобства (to).", nil)
+		return
+	}
+	if to == from {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Новый ключ совпадает с текущим.", nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	// Every cafe with the old key is rewritten in one transaction.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := h.service.Rename(ctx, from, to, actorID)
+	if err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	key, ok := readKey(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.Delete(ctx, key); err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func (h *Handler) AdminAddAlias(c *gin.Context) {
+	key, ok := readKey(c)
+	if !ok {
+		return
+	}
+	var req aliasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	alias, ok := validation.ParseAmenityAlias(req.Alias)
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Синоним должен быть непустым, без запятых и не длиннее 60 символов.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	item, err := h.service.AddAlias(ctx, key, alias)
+	if err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, item)
+}
+
+func (h *Handler) AdminDeleteAlias(c *gin.Context) {
+	key, ok := readKey(c)
+	if !ok {
+		return
+	}
+	alias, ok := validation.ParseAmenityAlias(c.Param("alias"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный синоним.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	item, err := h.service.DeleteAlias(ctx, key, alias)
+	if err != nil {
+		respondAmenityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func readKey(c *gin.Context) (string, bool) {
+	key, ok := validation.ParseAmenityKey(c.Param("key"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный ключ удобства.", nil)
+		return "", false
+	}
+	return key, true
+}
+
+func respondAmenityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Удобство не найдено.", nil)
+	case errors.Is(err, ErrAliasNotFound):
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Синоним не найден.", nil)
+	case errors.Is(err, ErrAlreadyExists):
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Удобство с таким ключом уже есть.", nil)
+	case errors.Is(err, ErrAliasConflict):
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Такое имя уже занято другим удобством или синонимом.", nil)
+	case errors.Is(err, ErrInUse):
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Удобство указано у кофеен: пометьте его устаревшим или переименуйте.", nil)
+	case errors.Is(err, ErrTooManyAliases):
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Слишком много синонимов у одного удобства.", nil)
+	default:
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+	}
+}
@@ -0,0 +1,510 @@
+package amenities
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"backend/internal/shared/cafeaudit"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// taxonomyLockKey serializes taxonomy writes so key/alias uniqueness across
+// the two tables holds without retry loops.
+const taxonomyLockKey = 740054
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const amenityColumns = `a.key,
+  a.labels,
+  a.category,
+  a.icon,
+  a.position,
+  a.deprecated_at,
+  coalesce((
+    select array_agg(al.alias order by al.alias)
+    from public.amenity_aliases al
+    where al.amenity_key = a.key
+  ), '{}'::text[]),
+  (
+    select count(*)::int
+    from public.cafes c
+    where c.amenities @> array[a.key]::text[]
+  ) as cafes_count,
+  a.updated_at`
+
+type queryer interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func scanAmenity(row pgx.Row) (Amenity, error) {
+	var (
+		item         Amenity
+		labels       []byte
+		deprecatedAt *time.Time
+		updatedAt    time.Time
+	)
+	if err := row.Scan(
+		&item.Key,
+		&labels,
+		&item.Category,
+		&item.Icon,
+		&item.Position,
+		&deprecatedAt,
+		&item.Aliases,
+		&item.CafesCount,
+		&updatedAt,
+	); err != nil {
+		return Amenity{}, err
+	}
+	item.Labels = map[string]string{}
+	if err := json.Unmarshal(labels, &item.Labels); err != nil {
+		return Amenity{}, err
+	}
+	if deprecatedAt != nil {
+		value := deprecatedAt.UTC().Format(time.RFC3339)
+		item.Deprecated = true
+		item.DeprecatedAt = &value
+	}
+	item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return item, nil
+}
+
+func (r *Repository) ListAmenities(ctx context.Context, includeDeprecated bool) ([]Amenity, error) {
+	rows, err := r.pool.Query(ctx, `select `+amenityColumns+`
+from public.amenities a
+where $1 or a.deprecated_at is null
+order by a.category asc, a.position asc, a.key asc`, includeDeprecated)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]Amenity, 0, 16)
+	for rows.Next() {
+		item, err := scanAmenity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repository) GetAmenity(ctx context.Context, key string) (Amenity, error) {
+	return getAmenity(ctx, r.pool, key)
+}
+
+func getAmenity(ctx context.Context, q queryer, key string) (Amenity, error) {
+	item, err := scanAmenity(q.QueryRow(ctx, `select `+amenityColumns+`
+from public.amenities a
+where a.key = $1`, key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Amenity{}, ErrNotFound
+	}
+	return item, err
+}
+
+// LoadCatalog returns every key split by status plus the alias map, the
+// input of validation.NewAmenityCatalog.
+func (r *Repository) LoadCatalog(ctx context.Context) ([]string, []string, map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `select key, deprecated_at is not null from public.amenities`)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	var active, deprecated []string
+	for rows.Next() {
+		var (
+			key          string
+			isDeprecated bool
+		)
+		if err := rows.Scan(&key, &isDeprecated); err != nil {
+			rows.Close()
+			return nil, nil, nil, err
+		}
+		if isDeprecated {
+			deprecated = append(deprecated, key)
+		} else {
+			active = append(active, key)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows, err = r.pool.Query(ctx, `select alias, amenity_key from public.amenity_aliases`)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer rows.Close()
+	aliases := make(map[string]string)
+	for rows.Next() {
+		var alias, key string
+		if err := rows.Scan(&alias, &key); err != nil {
+			return nil, nil, nil, err
+		}
+		aliases[alias] = key
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, nil, err
+	}
+	return active, deprecated, aliases, nil
+}
+
+func (r *Repository) CreateAmenity(ctx context.Context, input AmenityInput) (Amenity, error) {
+	tx, err := r.beginTaxonomyTx(ctx)
+	if err != nil {
+		return Amenity{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	taken, err := nameTaken(ctx, tx, input.Key)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if taken == nameIsKey {
+		return Amenity{}, ErrAlreadyExists
+	}
+	if taken == nameIsAlias {
+		return Amenity{}, ErrAliasConflict
+	}
+
+	labels, err := json.Marshal(input.Labels)
+	if err != nil {
+		return Amenity{}, err
+	}
+	category := defaultCategory
+	if input.Category != nil {
+		category = *input.Category
+	}
+	position := 0
+	if input.Position != nil {
+		position = *input.Position
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`insert into public.amenities (key, labels, category, icon, position)
+		 values ($1, $2::jsonb, $3, $4, $5)`,
+		input.Key,
+		string(labels),
+		category,
+		input.Icon,
+		position,
+	); err != nil {
+		return Amenity{}, err
+	}
+	for _, alias := range input.Aliases {
+		if err := insertAlias(ctx, tx, input.Key, alias); err != nil {
+			return Amenity{}, err
+		}
+	}
+
+	item, err := getAmenity(ctx, tx, input.Key)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Amenity{}, err
+	}
+	return item, nil
+}
+
+// UpdateAmenity changes presentation fields and the deprecation flag. The key
+// is changed by RenameAmenity.
+func (r *Repository) UpdateAmenity(ctx context.Context, input AmenityInput) (Amenity, error) {
+	var labels *string
+	if input.Labels != nil {
+		raw, err := json.Marshal(input.Labels)
+		if err != nil {
+			return Amenity{}, err
+		}
+		value := string(raw)
+		labels = &value
+	}
+
+	tag, err := r.pool.Exec(
+		ctx,
+		`update public.amenities
+		    set labels = coalesce($2::jsonb, labels),
+		        category = coalesce($3, category),
+		        icon = case when $5 then null else coalesce($4, icon) end,
+		        position = coalesce($6, position),
+		        deprecated_at = case
+		          when $7::boolean is null then deprecated_at
+		          when $7 then coalesce(deprecated_at, now())
+		          else null
+		        end,
+		        updated_at = now()
+		  where key = $1`,
+		input.Key,
+		labels,
+		input.Category,
+		input.Icon,
+		input.ClearIcon,
+		input.Position,
+		input.Deprecated,
+	)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Amenity{}, ErrNotFound
+	}
+	return r.GetAmenity(ctx, input.Key)
+}
+
+// RenameAmenity moves every cafe from one key to another and keeps the old
+// key as an alias. Renaming onto an existing key merges the two amenities.
+func (r *Repository) RenameAmenity(ctx context.Context, from, to string, change cafeaudit.Change) (RenameResult, error) {
+	tx, err := r.beginTaxonomyTx(ctx)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	source, err := nameTaken(ctx, tx, from)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	if source != nameIsKey {
+		return RenameResult{}, ErrNotFound
+	}
+
+	// An alias of the amenity itself simply becomes its key.
+	if _, err := tx.Exec(
+		ctx,
+		`delete from public.amenity_aliases where alias = $1 and amenity_key = $2`,
+		to,
+		from,
+	); err != nil {
+		return RenameResult{}, err
+	}
+	target, err := nameTaken(ctx, tx, to)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	if target == nameIsAlias {
+		return RenameResult{}, ErrAliasConflict
+	}
+
+	result := RenameResult{From: from, Merged: target == nameIsKey}
+	if result.Merged {
+		if _, err := tx.Exec(
+			ctx,
+			`update public.amenity_aliases set amenity_key = $2 where amenity_key = $1`,
+			from,
+			to,
+		); err != nil {
+			return RenameResult{}, err
+		}
+		if _, err := tx.Exec(ctx, `delete from public.amenities where key = $1`, from); err != nil {
+			return RenameResult{}, err
+		}
+	} else {
+		// amenity_aliases follows through ON UPDATE CASCADE.
+		if _, err := tx.Exec(
+			ctx,
+			`update public.amenities set key = $2, updated_at = now() where key = $1`,
+			from,
+			to,
+		); err != nil {
+			return RenameResult{}, err
+		}
+	}
+	if err := insertAlias(ctx, tx, to, from); err != nil {
+		return RenameResult{}, err
+	}
+
+	if err := cafeaudit.Tag(ctx, tx, change); err != nil {
+		return RenameResult{}, err
+	}
+	// Replace the key in place, keeping the array order and dropping the
+	// duplicate when a cafe already had the target.
+	tag, err := tx.Exec(
+		ctx,
+		`update public.cafes c
+		    set amenities = array(
+		      select s.amenity
+		        from (
+		          select case when t.amenity = $1 then $2 else t.amenity end as amenity,
+		                 min(t.ord) as ord
+		            from unnest(c.amenities) with ordinality as t(amenity, ord)
+		           group by 1
+		        ) s
+		       order by s.ord
+		    )
+		  where c.amenities @> array[$1]::text[]`,
+		from,
+		to,
+	)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	result.CafesUpdated = tag.RowsAffected()
+
+	result.Amenity, err = getAmenity(ctx, tx, to)
+	if err != nil {
+		return RenameResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return RenameResult{}, err
+	}
+	return result, nil
+}
+
+// DeleteAmenity removes an amenity no cafe uses; used ones should be
+// deprecated or renamed instead.
+func (r *Repository) DeleteAmenity(ctx context.Context, key string) error {
+	tx, err := r.beginTaxonomyTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var inUse bool
+	if err := tx.QueryRow(
+		ctx,
+		`select exists(select 1 from public.cafes where amenities @> array[$1]::text[])`,
+		key,
+	).Scan(&inUse); err != nil {
+		return err
+	}
+	if inUse {
+		return ErrInUse
+	}
+	tag, err := tx.Exec(ctx, `delete from public.amenities where key = $1`, key)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) AddAlias(ctx context.Context, key, alias string) (Amenity, error) {
+	tx, err := r.beginTaxonomyTx(ctx)
+	if err != nil {
+		return Amenity{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	owner, err := nameTaken(ctx, tx, key)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if owner != nameIsKey {
+		return Amenity{}, ErrNotFound
+	}
+	var count int
+	if err := tx.QueryRow(
+		ctx,
+		`select count(*)::int from public.amenity_aliases where amenity_key = $1`,
+		key,
+	).Scan(&count); err != nil {
+		return Amenity{}, err
+	}
+	if count >= maxAliasesPerKey {
+		return Amenity{}, ErrTooManyAliases
+	}
+	if err := insertAlias(ctx, tx, key, alias); err != nil {
+		return Amenity{}, err
+	}
+
+	item, err := getAmenity(ctx, tx, key)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Amenity{}, err
+	}
+	return item, nil
+}
+
+func (r *Repository) DeleteAlias(ctx context.Context, key, alias string) (Amenity, error) {
+	tag, err := r.pool.Exec(
+		ctx,
+		`delete from public.amenity_aliases where alias = $1 and amenity_key = $2`,
+		alias,
+		key,
+	)
+	if err != nil {
+		return Amenity{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return Amenity{}, ErrAliasNotFound
+	}
+	return r.GetAmenity(ctx, key)
+}
+
+func (r *Repository) beginTaxonomyTx(ctx context.Context) (pgx.Tx, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, `select pg_advisory_xact_lock($1)`, taxonomyLockKey); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+type nameKind int
+
+const (
+	nameFree nameKind = iota
+	nameIsKey
+	nameIsAlias
+)
+
+// nameTaken reports whether name is already an amenity key or an alias; the
+// two share one namespace.
+func nameTaken(ctx context.Context, tx pgx.Tx, name string) (nameKind, error) {
+	var isKey, isAlias bool
+	if err := tx.QueryRow(
+		ctx,
+		`select exists(select 1 from public.amenities where key = $1),
+		        exists(select 1 from public.amenity_aliases where alias = $1)`,
+		name,
+	).Scan(&isKey, &isAlias); err != nil {
+		return nameFree, err
+	}
+	switch {
+	case isKey:
+		return nameIsKey, nil
+	case isAlias:
+		return nameIsAlias, nil
+	default:
+		return nameFree, nil
+	}
+}
+
+func insertAlias(ctx context.Context, tx pgx.Tx, key, alias string) error {
+	taken, err := nameTaken(ctx, tx, alias)
+	if err != nil {
+		return err
+	}
+	if taken != nameFree {
+		return ErrAliasConflict
+	}
+	_, err = tx.Exec(
+		ctx,
+		`insert into public.amenity_aliases (alias, amenity_key) values ($1, $2)`,
+		alias,
+		key,
+	)
+	return err
+}
@@ -0,0 +1,265 @@
+package amenities
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/shared/cafeaudit"
+	"backend/internal/shared/validation"
+)
+
+var (
+	localePattern   = regexp.MustCompile(`^[a-z]{2}$`)
+	categoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:[_-][a-z0-9]+)*$`)
+)
+
+type Service struct {
+	repository *Repository
+}
+
+func NewService(repository *Repository) *Service {
+	return &Service{repository: repository}
+}
+
+func (s *Service) List(ctx context.Context, locale string, includeDeprecated bool) (ListResponse, error) {
+	items, err := s.repository.ListAmenities(ctx, includeDeprecated)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	for i := range items {
+		items[i].Label = resolveLabel(items[i], locale)
+	}
+	return ListResponse{Amenities: items}, nil
+}
+
+func (s *Service) Create(ctx context.Context, input AmenityInput) (Amenity, error) {
+	item, err := s.repository.CreateAmenity(ctx, input)
+	return s.afterWrite(ctx, item, err)
+}
+
+func (s *Service) Update(ctx context.Context, input AmenityInput) (Amenity, error) {
+	item, err := s.repository.UpdateAmenity(ctx, input)
+	return s.afterWrite(ctx, item, err)
+}
+
+func (s *Service) Rename(ctx context.Context, from, to, actorID string) (RenameResult, error) {
+	result, err := s.repository.RenameAmenity(ctx, from, to, cafeaudit.Change{
+		Source:    cafeaudit.SourceAdmin,
+		ActorID:   actorID,
+		Reference: "amenity_rename:" + from + "->" + to,
+	})
+	if err != nil {
+		return RenameResult{}, err
+	}
+	result.Amenity.Label = resolveLabel(result.Amenity, defaultLocale)
+	s.reloadAfterWrite(ctx)
+	return result, nil
+}
+
+func (s *Service) Delete(ctx context.Context, key string) error {
+	if err := s.repository.DeleteAmenity(ctx, key); err != nil {
+		return err
+	}
+	s.reloadAfterWrite(ctx)
+	return nil
+}
+
+func (s *Service) AddAlias(ctx context.Context, key, alias string) (Amenity, error) {
+	item, err := s.repository.AddAlias(ctx, key, alias)
+	return s.afterWrite(ctx, item, err)
+}
+
+func (s *Service) DeleteAlias(ctx context.Context, key, alias string) (Amenity, error) {
+	item, err := s.repository.DeleteAlias(ctx, key, alias)
+	return s.afterWrite(ctx, item, err)
+}
+
+func (s *Service) afterWrite(ctx context.Context, item Amenity, err error) (Amenity, error) {
+	if err != nil {
+		return Amenity{}, err
+	}
+	item.Label = resolveLabel(item, defaultLocale)
+	s.reloadAfterWrite(ctx)
+	return item, nil
+}
+
+// reloadAfterWrite applies a committed change to this instance right away;
+// other instances pick it up on their next refresh.
+func (s *Service) reloadAfterWrite(ctx context.Context) {
+	if err := s.Reload(ctx); err != nil {
+		slog.Warn("amenity catalog reload failed", "error", err)
+	}
+}
+
+// Reload replaces the process-wide validation catalog with the table
+// contents.
+func (s *Service) Reload(ctx context.Context) error {
+	active, deprecated, aliases, err := s.repository.LoadCatalog(ctx)
+	if err != nil {
+		return err
+	}
+	validation.SetAmenityCatalog(validation.NewAmenityCatalog(active, deprecated, aliases))
+	return nil
+}
+
+// StartCatalogRefresh keeps the validation catalog in sync with changes made
+// through other instances. Until the first successful load the built-in
+// amenity list stays in effect.
+func (s *Service) StartCatalogRefresh(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := slog.Default().With("worker_name", "amenity_catalog")
+	logger.Info("worker started", "interval", interval)
+	defer logger.Info("worker stopped")
+
+	reload := func() {
+		runCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		if err := s.Reload(runCtx); err != nil {
+			logger.Error("catalog reload failed", "error", err)
+		}
+	}
+
+	reload()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reload()
+		}
+	}
+}
+
+func resolveLabel(item Amenity, locale string) string {
+	if label := item.Labels[locale]; label != "" {
+		return label
+	}
+	if label := item.Labels[defaultLocale]; label != "" {
+		return label
+	}
+	return item.Key
+}
+
+func normalizeLocale(raw string) string {
+	locale := strings.ToLower(strings.TrimSpace(raw))
+	if base, _, found := strings.Cut(locale, "-"); found {
+		locale = base
+	}
+	if !localePattern.MatchString(locale) {
+		return defaultLocale
+	}
+	return locale
+}
+
+// normalizeCreateInput validates an admin create. Errors are user-facing.
+func normalizeCreateInput(req createAmenityRequest) (AmenityInput, error) {
+	key, ok := validation.ParseAmenityKey(req.Key)
+	if !ok {
+		return AmenityInput{}, fmt.Errorf("Ключ удобства должен состоять из латиницы в нижнем регистре, цифр, «_» или «-» (до 40 символов).")
+	}
+	input, err := normalizeFields(req.Labels, nullableString(req.Category), req.Icon, req.Position)
+	if err != nil {
+		return AmenityInput{}, err
+	}
+	if input.Labels == nil {
+		return AmenityInput{}, fmt.Errorf("Нужна хотя бы подпись на русском (labels.ru).")
+	}
+	input.Key = key
+	if len(req.Aliases) > maxAliasesPerKey {
+		return AmenityInput{}, fmt.Errorf("Слишком много синонимов: не больше %d.", maxAliasesPerKey)
+	}
+	seen := map[string]struct{}{key: {}}
+	for _, raw := range req.Aliases {
+		alias, ok := validation.ParseAmenityAlias(raw)
+		if !ok {
+			return AmenityInput{}, fmt.Errorf("Некорректный синоним %q.", raw)
+		}
+		if _, dup := seen[alias]; dup {
+			continue
+		}
+		seen[alias] = struct{}{}
+		input.Aliases = append(input.Aliases, alias)
+	}
+	return input, nil
+}
+
+// normalizeUpdateInput validates an admin update. Errors are user-facing.
+func normalizeUpdateInput(key string, req updateAmenityRequest) (AmenityInput, error) {
+	input, err := normalizeFields(req.Labels, req.Category, req.Icon, req.Position)
+	if err != nil {
+		return AmenityInput{}, err
+	}
+	input.Key = key
+	input.Deprecated = req.Deprecated
+	if input.Labels == nil && input.Category == nil && input.Icon == nil && !input.ClearIcon &&
+		input.Position == nil && input.Deprecated == nil {
+		return AmenityInput{}, fmt.Errorf("Нет полей для обновления.")
+	}
+	return input, nil
+}
+
+func normalizeFields(labels map[string]string, category, icon *string, position *int) (AmenityInput, error) {
+	var input AmenityInput
+	if labels != nil {
+		input.Labels = make(map[string]string, len(labels))
+		for rawLocale, rawLabel := range labels {
+			locale := strings.ToLower(strings.TrimSpace(rawLocale))
+			if !localePattern.MatchString(locale) {
+				return AmenityInput{}, fmt.Errorf("Некорректный код языка %q: ожидается двухбуквенный код, например ru или en.", rawLocale)
+			}
+			label := strings.TrimSpace(rawLabel)
+			if label == "" {
+				continue
+			}
+			if utf8.RuneCountInString(label) > maxLabelRunes {
+				return AmenityInput{}, fmt.Errorf("Подпись для %s слишком длинная.", locale)
+			}
+			input.Labels[locale] = label
+		}
+		if input.Labels[defaultLocale] == "" {
+			return AmenityInput{}, fmt.Errorf("Нужна хотя бы подпись на русском (labels.ru).")
+		}
+	}
+	if category != nil {
+		value := strings.ToLower(strings.TrimSpace(*category))
+		if !categoryPattern.MatchString(value) || len(value) > 40 {
+			return AmenityInput{}, fmt.Errorf("Категория должна состоять из латиницы в нижнем регистре, цифр, «_» или «-».")
+		}
+		input.Category = &value
+	}
+	if icon != nil {
+		value := strings.TrimSpace(*icon)
+		if len(value) > maxIconLen {
+			return AmenityInput{}, fmt.Errorf("Название иконки слишком длинное.")
+		}
+		if value == "" {
+			input.ClearIcon = true
+		} else {
+			input.Icon = &value
+		}
+	}
+	if position != nil {
+		if *position < 0 || *position > 10000 {
+			return AmenityInput{}, fmt.Errorf("position должен быть от 0 до 10000.")
+		}
+		input.Position = position
+	}
+	return input, nil
+}
+
+func nullableString(value string) *string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return &value
+}
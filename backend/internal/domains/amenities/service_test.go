@@ -0,0 +1,71 @@
+package amenities
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeCreateInput(t *testing.T) {
+	t.Parallel()
+
+	input, err := normalizeCreateInput(createAmenityRequest{
+		Key:     " Alt_Milk ",
+		Labels:  map[string]string{"RU": " Альтернативное молоко ", "en": "Plant milk", "de": " "},
+		Aliases: []string{"Oat milk", "oat  milk", "alt_milk"},
+	})
+	if err != nil {
+		t.Fatalf("normalizeCreateInput: %v", err)
+	}
+	if input.Key != "alt_milk" || input.Labels["ru"] != "Альтернативное молоко" || len(input.Labels) != 2 {
+		t.Fatalf("unexpected input: %+v", input)
+	}
+	if len(input.Aliases) != 1 || input.Aliases[0] != "oat milk" {
+		t.Fatalf("aliases should be cleaned and deduplicated: %v", input.Aliases)
+	}
+
+	_, err = normalizeCreateInput(createAmenityRequest{Key: "alt_milk", Labels: map[string]string{"en": "Plant milk"}})
+	if err == nil || !strings.Contains(err.Error(), "labels.ru") {
+		t.Fatalf("expected a missing Russian label error, got %v", err)
+	}
+	_, err = normalizeCreateInput(createAmenityRequest{Key: "alt milk", Labels: map[string]string{"ru": "Молоко"}})
+	if err == nil {
+		t.Fatalf("expected an invalid key error")
+	}
+}
+
+func TestNormalizeUpdateInput(t *testing.T) {
+	t.Parallel()
+
+	empty := ""
+	deprecated := true
+	input, err := normalizeUpdateInput("laptop", updateAmenityRequest{Icon: &empty, Deprecated: &deprecated})
+	if err != nil {
+		t.Fatalf("normalizeUpdateInput: %v", err)
+	}
+	if !input.ClearIcon || input.Icon != nil || input.Deprecated == nil || !*input.Deprecated {
+		t.Fatalf("unexpected input: %+v", input)
+	}
+
+	if _, err := normalizeUpdateInput("laptop", updateAmenityRequest{}); err == nil {
+		t.Fatalf("expected an error for an empty update")
+	}
+	bad := "Work Space"
+	if _, err := normalizeUpdateInput("laptop", updateAmenityRequest{Category: &bad}); err == nil {
+		t.Fatalf("expected an invalid category error")
+	}
+}
+
+func TestResolveLabel(t *testing.T) {
+	t.Parallel()
+
+	item := Amenity{Key: "power", Labels: map[string]string{"ru": "Розетки", "en": "Power outlets"}}
+	if got := resolveLabel(item, normalizeLocale("en-US")); got != "Power outlets" {
+		t.Fatalf("en label = %q", got)
+	}
+	if got := resolveLabel(item, normalizeLocale("de")); got != "Розетки" {
+		t.Fatalf("fallback label = %q", got)
+	}
+	if got := resolveLabel(Amenity{Key: "power"}, "ru"); got != "power" {
+		t.Fatalf("key fallback = %q", got)
+	}
+}
@@ -0,0 +1,87 @@
+package amenities
+
+import "errors"
+
+const (
+	defaultCategory  = "other"
+	defaultLocale    = "ru"
+	maxLabelRunes    = 60
+	maxIconLen       = 64
+	maxAliasesPerKey = 20
+)
+
+var (
+	ErrNotFound       = errors.New("amenity not found")
+	ErrAlreadyExists  = errors.New("amenity already exists")
+	ErrAliasConflict  = errors.New("amenity alias conflicts with an existing key or alias")
+	ErrAliasNotFound  = errors.New("amenity alias not found")
+	ErrInUse          = errors.New("amenity is used by cafes")
+	ErrTooManyAliases = errors.New("too many aliases")
+)
+
+// Amenity is an entry of the amenity taxonomy. Label is resolved from Labels
+// for the requested locale, falling back to Russian and then to the key.
+type Amenity struct {
+	Key          string            `json:"key"`
+	Label        string            `json:"label"`
+	Labels       map[string]string `json:"labels"`
+	Category     string            `json:"category"`
+	Icon         *string           `json:"icon"`
+	Position     int               `json:"position"`
+	Deprecated   bool              `json:"deprecated"`
+	DeprecatedAt *string           `json:"deprecated_at,omitempty"`
+	Aliases      []string          `json:"aliases"`
+	CafesCount   int               `json:"cafes_count"`
+	UpdatedAt    string            `json:"updated_at"`
+}
+
+type ListResponse struct {
+	Amenities []Amenity `json:"amenities"`
+}
+
+// RenameResult reports a rename. Merged is true when the target key already
+// existed and the old amenity was folded into it.
+type RenameResult struct {
+	Amenity      Amenity `json:"amenity"`
+	From         string  `json:"from"`
+	Merged       bool    `json:"merged"`
+	CafesUpdated int64   `json:"cafes_updated"`
+}
+
+type createAmenityRequest struct {
+	Key      string            `json:"key"`
+	Labels   map[string]string `json:"labels"`
+	Category string            `json:"category"`
+	Icon     *string           `json:"icon"`
+	Position *int              `json:"position"`
+	Aliases  []string          `json:"aliases"`
+}
+
+type updateAmenityRequest struct {
+	Labels     map[string]string `json:"labels"`
+	Category   *string           `json:"category"`
+	Icon       *string           `json:"icon"`
+	Position   *int              `json:"position"`
+	Deprecated *bool             `json:"deprecated"`
+}
+
+type renameAmenityRequest struct {
+	To string `json:"to"`
+}
+
+type aliasRequest struct {
+	Alias string `json:"alias"`
+}
+
+// AmenityInput is a validated create or update. Nil fields are left unchanged
+// on update.
+type AmenityInput struct {
+	Key        string
+	Labels     map[string]string
+	Category   *string
+	Icon       *string
+	ClearIcon  bool
+	Position   *int
+	Deprecated *bool
+	Aliases    []string
+}
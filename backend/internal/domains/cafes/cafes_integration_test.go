@@ -0,0 +1,454 @@
+package cafes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	dbmigrations "backend/migrations"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var (
+	integrationPoolOnce sync.Once
+	integrationPool     *pgxpool.Pool
+	integrationPoolErr  error
+)
+
+func integrationTestPool(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	dbURL := strings.TrimSpace(os.Getenv("TEST_DATABASE_URL"))
+	usingFallback := false
+	if dbURL == "" {
+		for _, key := range []string{"DATABASE_URL", "DATABASE_URL_2", "DATABASE_URL_3"} {
+			value := strings.TrimSpace(os.Getenv(key))
+			if value == "" {
+				continue
+			}
+			dbURL = value
+			usingFallback = true
+			break
+		}
+	}
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL and DATABASE_URL* are not set; skipping DB integration tests")
+	}
+	if usingFallback {
+		t.Log("TEST_DATABASE_URL is not set, using DATABASE_URL fallback for integration tests")
+	}
+
+	integrationPoolOnce.Do(func() {
+		if err := dbmigrations.Run(dbURL); err != nil {
+			integrationPoolErr = fmt.Errorf("run migrations: %w", err)
+			return
+		}
+		pool, err := pgxpool.New(context.Background(), dbURL)
+		if err != nil {
+			integrationPoolErr = fmt.Errorf("create pool: %w", err)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := pool.Ping(ctx); err != nil {
+			integrationPoolErr = fmt.Errorf("ping pool: %w", err)
+			pool.Close()
+			return
+		}
+		integrationPool = pool
+	})
+
+	if integrationPoolErr != nil {
+		t.Fatalf("integration pool init failed: %v", integrationPoolErr)
+	}
+	return integrationPool
+}
+
+func mustCreateTestUser(t *testing.T, pool *pgxpool.Pool, role string) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	email := fmt.Sprintf("cafes-it-%d@example.com", time.Now().UnixNano())
+	var id string
+	err := pool.QueryRow(
+		ctx,
+		`insert into users (email_normalized, display_name, role)
+		 values ($1, $2, $3)
+		 returning id::text`,
+		email,
+		"it user",
+		strings.ToLower(strings.TrimSpace(role)),
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return id
+}
+
+func mustCreateTestCafe(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var id string
+	err := pool.QueryRow(
+		ctx,
+		`insert into cafes (name, lat, lng, address)
+		 values ($1, $2, $3, $4)
+		 returning id::text`,
+		fmt.Sprintf("test cafe %d", time.Now().UnixNano()),
+		55.751244,
+		37.618423,
+		"integration test",
+	).Scan(&id)
+	if err != nil {
+		t.Fatalf("create cafe: %v", err)
+	}
+	return id
+}
+
+func mustExec(t *testing.T, pool *pgxpool.Pool, query string, args ...interface{}) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := pool.Exec(ctx, query, args...); err != nil {
+		t.Fatalf("exec query failed: %v", err)
+	}
+}
+
+func mustDeleteTestUser(t *testing.T, pool *pgxpool.Pool, userID string) {
+	t.Helper()
+	if strings.TrimSpace(userID) == "" {
+		return
+	}
+	mustExec(t, pool, `delete from users where id = $1::uuid`, userID)
+}
+
+func mustDeleteTestCafe(t *testing.T, pool *pgxpool.Pool, cafeID string) {
+	t.Helper()
+	if strings.TrimSpace(cafeID) == "" {
+		return
+	}
+	mustExec(t, pool, `delete from cafes where id = $1::uuid`, cafeID)
+}
+
+func TestMergeCafesKeepsSourceOwners(t *testing.T) {
+	pool := integrationTestPool(t)
+	repository := NewRepository(pool)
+
+	targetID := mustCreateTestCafe(t, pool)
+	sourceID := mustCreateTestCafe(t, pool)
+	sharedOwnerID := mustCreateTestUser(t, pool, "barista")
+	sourceOwnerID := mustCreateTestUser(t, pool, "barista")
+	t.Cleanup(func() {
+		mustDeleteTestCafe(t, pool, sourceID)
+		mustDeleteTestCafe(t, pool, targetID)
+		mustDeleteTestUser(t, pool, sharedOwnerID)
+		mustDeleteTestUser(t, pool, sourceOwnerID)
+	})
+
+	mustExec(
+		t,
+		pool,
+		`insert into cafe_owners (cafe_id, user_id) values ($1::uuid, $3::uuid), ($2::uuid, $3::uuid), ($2::uuid, $4::uuid)`,
+		targetID,
+		sourceID,
+		sharedOwnerID,
+		sourceOwnerID,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := repository.MergeCafes(ctx, targetID, sourceID, ""); err != nil {
+		t.Fatalf("merge cafes: %v", err)
+	}
+
+	rows, err := pool.Query(
+		ctx,
+		`select user_id::text from cafe_owners where cafe_id = $1::uuid`,
+		targetID,
+	)
+	if err != nil {
+		t.Fatalf("load owners: %v", err)
+	}
+	defer rows.Close()
+	owners := map[string]bool{}
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			t.Fatalf("scan owner: %v", err)
+		}
+		owners[userID] = true
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("iterate owners: %v", err)
+	}
+	if len(owners) != 2 || !owners[sharedOwnerID] || !owners[sourceOwnerID] {
+		t.Fatalf("expected both owners on the surviving cafe, got %v", owners)
+	}
+}
+
+func TestMergeCafesRepointsOSMLink(t *testing.T) {
+	pool := integrationTestPool(t)
+	repository := NewRepository(pool)
+
+	targetID := mustCreateTestCafe(t, pool)
+	sourceID := mustCreateTestCafe(t, pool)
+	osmID := time.Now().UnixNano()
+	t.Cleanup(func() {
+		mustExec(t, pool, `delete from osm_cafe_links where osm_type = 'node' and osm_id = $1`, osmID)
+		mustDeleteTestCafe(t, pool, sourceID)
+		mustDeleteTestCafe(t, pool, targetID)
+	})
+
+	mustExec(
+		t,
+		pool,
+		`insert into osm_cafe_links (osm_type, osm_id, cafe_id, name, address, lat, lng)
+		 values ('node', $1, $2::uuid, 'osm cafe', 'osm address', 55.751244, 37.618423)`,
+		osmID,
+		sourceID,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := repository.MergeCafes(ctx, targetID, sourceID, ""); err != nil {
+		t.Fatalf("merge cafes: %v", err)
+	}
+
+	var linkedCafeID *string
+	if err := pool.QueryRow(
+		ctx,
+		`select cafe_id::text from osm_cafe_links where osm_type = 'node' and osm_id = $1`,
+		osmID,
+	).Scan(&linkedCafeID); err != nil {
+		t.Fatalf("load osm link: %v", err)
+	}
+	if linkedCafeID == nil || *linkedCafeID != targetID {
+		t.Fatalf("expected osm link to point at %s, got %v", targetID, linkedCafeID)
+	}
+}
+
+func TestRevertCafeToRevisionRestoresStatusReason(t *testing.T) {
+	pool := integrationTestPool(t)
+	repository := NewRepository(pool)
+
+	cafeID := mustCreateTestCafe(t, pool)
+	t.Cleanup(func() {
+		mustDeleteTestCafe(t, pool, cafeID)
+	})
+
+	mustExec(
+		t,
+		pool,
+		`update cafes set status = 'permanently_closed', status_reason = 'moved away' where id = $1::uuid`,
+		cafeID,
+	)
+	mustExec(
+		t,
+		pool,
+		`update cafes set status = 'active', status_reason = null where id = $1::uuid`,
+		cafeID,
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var closedRevision int
+	if err := pool.QueryRow(
+		ctx,
+		`select revision from cafe_revisions
+		  where cafe_id = $1::uuid and snapshot->>'status' = 'permanently_closed'`,
+		cafeID,
+	).Scan(&closedRevision); err != nil {
+		t.Fatalf("load closure revision: %v", err)
+	}
+
+	result, err := repository.RevertCafeToRevision(ctx, cafeID, closedRevision, "")
+	if err != nil {
+		t.Fatalf("revert cafe: %v", err)
+	}
+	if !result.Changed {
+		t.Fatalf("expected the revert to change the cafe")
+	}
+
+	var (
+		status string
+		reason *string
+	)
+	if err := pool.QueryRow(
+		ctx,
+		`select status, status_reason from cafes where id = $1::uuid`,
+		cafeID,
+	).Scan(&status, &reason); err != nil {
+		t.Fatalf("load cafe: %v", err)
+	}
+	if status != "permanently_closed" || reason == nil || *reason != "moved away" {
+		t.Fatalf("expected closure with its reason restored, got status=%q reason=%v", status, reason)
+	}
+}
+
+type fakeCafeReviewsReader struct {
+	snapshot map[string]interface{}
+	reviews  []map[string]interface{}
+}
+
+func (f fakeCafeReviewsReader) GetCafeRatingSnapshot(ctx context.Context, cafeID string) (map[string]interface{}, error) {
+	return f.snapshot, nil
+}
+
+func (f fakeCafeReviewsReader) ListCafeReviews(
+	ctx context.Context,
+	cafeID string,
+	sortBy string,
+	positionFilter string,
+	offset int,
+	limit int,
+) ([]map[string]interface{}, []map[string]interface{}, bool, int, error) {
+	return f.reviews, nil, false, 0, nil
+}
+
+func TestGetByIDReturnsNotFoundForUnknownAndDeletedCafes(t *testing.T) {
+	pool := integrationTestPool(t)
+	gin.SetMode(gin.TestMode)
+
+	deletedID := mustCreateTestCafe(t, pool)
+	t.Cleanup(func() {
+		mustDeleteTestCafe(t, pool, deletedID)
+	})
+	mustExec(t, pool, `update cafes set status = 'deleted' where id = $1::uuid`, deletedID)
+
+	handler := NewHandler(NewService(NewRepository(pool), config.Config{}))
+	router := gin.New()
+	router.GET("/api/cafes/:id", handler.GetByID)
+
+	for _, cafeID := range []string{"5b0a1c52-3f4e-4d6a-9b8c-7d6e5f4a3b2c", deletedID} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cafes/"+cafeID, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected 404, got %d, body=%s", cafeID, rec.Code, rec.Body.String())
+		}
+		var apiErr struct {
+			Code string `json:"code"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil {
+			t.Fatalf("decode error response: %v", err)
+		}
+		if apiErr.Code != "not_found" {
+			t.Fatalf("%s: expected not_found code, got %q", cafeID, apiErr.Code)
+		}
+	}
+}
+
+func TestGetDetailsAggregatesCafeRatingReviewsAndViewer(t *testing.T) {
+	pool := integrationTestPool(t)
+
+	cafeID := mustCreateTestCafe(t, pool)
+	userID := mustCreateTestUser(t, pool, "user")
+	t.Cleanup(func() {
+		mustDeleteTestCafe(t, pool, cafeID)
+		mustDeleteTestUser(t, pool, userID)
+	})
+	mustExec(t, pool, `insert into user_favorite_cafes (user_id, cafe_id) values ($1::uuid, $2::uuid)`, userID, cafeID)
+
+	service := NewService(NewRepository(pool), config.Config{})
+	service.SetReviewsReader(fakeCafeReviewsReader{
+		snapshot: map[string]interface{}{
+			"rating":           4.5,
+			"descriptive_tags": []interface{}{"cozy"},
+		},
+		reviews: []map[string]interface{}{{"id": "review-1"}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	details, err := service.GetDetails(ctx, cafeID, &userID)
+	if err != nil {
+		t.Fatalf("get details: %v", err)
+	}
+	if details.Cafe.ID != cafeID {
+		t.Fatalf("expected cafe %s, got %s", cafeID, details.Cafe.ID)
+	}
+	if details.Rating["rating"] != 4.5 {
+		t.Fatalf("expected rating snapshot to be passed through, got %v", details.Rating)
+	}
+	if len(details.TopReviews) != 1 || details.TopReviews[0]["id"] != "review-1" {
+		t.Fatalf("expected top reviews from the reviews reader, got %v", details.TopReviews)
+	}
+	if details.Viewer == nil || !details.Viewer.IsFavorite || details.Viewer.HasReview || details.Viewer.OwnReviewID != nil {
+		t.Fatalf("unexpected viewer state: %+v", details.Viewer)
+	}
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		t.Fatalf("marshal details: %v", err)
+	}
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &body); err != nil {
+		t.Fatalf("decode details: %v", err)
+	}
+	for _, key := range []string{"cafe", "photos", "menu_photos", "rating", "descriptive_tags", "top_reviews", "viewer"} {
+		value, ok := body[key]
+		if !ok {
+			t.Fatalf("expected %q in details, got %s", key, raw)
+		}
+		if string(value) == "null" {
+			t.Fatalf("expected %q to be non-null, got %s", key, raw)
+		}
+	}
+	if string(body["photos"]) != "[]" || string(body["menu_photos"]) != "[]" {
+		t.Fatalf("expected empty photo lists, got photos=%s menu_photos=%s", body["photos"], body["menu_photos"])
+	}
+	if string(body["descriptive_tags"]) != `["cozy"]` {
+		t.Fatalf("expected descriptive tags from the rating snapshot, got %s", body["descriptive_tags"])
+	}
+
+	anonymous, err := service.GetDetails(ctx, cafeID, nil)
+	if err != nil {
+		t.Fatalf("get anonymous details: %v", err)
+	}
+	if anonymous.Viewer != nil {
+		t.Fatalf("expected no viewer state for anonymous requests, got %+v", anonymous.Viewer)
+	}
+}
+
+func TestListUnknownCityNotFound(t *testing.T) {
+	pool := integrationTestPool(t)
+	gin.SetMode(gin.TestMode)
+
+	cfg := config.Config{}
+	cfg.Limits.DefaultResults = 20
+	handler := NewHandler(NewService(NewRepository(pool), cfg))
+	router := gin.New()
+	router.GET("/api/cafes", handler.List)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cafes?lat=55.75&lng=37.62&radius_m=1000&city=no-such-city", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d, body=%s", rec.Code, rec.Body.String())
+	}
+	var apiErr struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &apiErr); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if apiErr.Code != "not_found" || apiErr.Message != "Город не найден." {
+		t.Fatalf("unexpected error response: %+v", apiErr)
+	}
+}
@@ -0,0 +1,28 @@
+package cafes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetByIDRejectsInvalidID(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	handler := NewHandler(nil)
+	router := gin.New()
+	router.GET("/api/cafes/:id", handler.GetByID)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/cafes/not-a-uuid", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "Некорректный id кофейни") {
+		t.Fatalf("unexpected body: %s", rec.Body.String())
+	}
+}
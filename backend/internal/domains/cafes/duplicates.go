@@ -0,0 +1,28 @@
+package cafes
+
+const (
+	DuplicateStatusOpen      = "open"
+	DuplicateStatusDismissed = "dismissed"
+
+	// A pair is a candidate when the names are similar enough within
+	// duplicateScanRadiusM, or barely similar but almost on the same spot
+	// (OSM imports often name the same place "Кофейня X" vs "X Coffee").
+	duplicateScanRadiusM       = 75.0
+	duplicateMinNameSimilarity = 0.5
+	duplicateNearRadiusM       = 20.0
+	duplicateNearMinSimilarity = 0.3
+	// duplicateNameWeight blends name similarity with proximity into score.
+	duplicateNameWeight = 0.7
+
+	duplicateListDefaultLimit = 50
+	duplicateListMaxLimit     = 200
+	duplicateScanLockKey      = 740052
+
+	// cafeMergedEventType is consumed by the reviews rating projector, which
+	// recomputes the surviving cafe snapshot.
+	cafeMergedEventType = "cafe.merged"
+)
+
+func isValidDuplicateStatus(status string) bool {
+	return status == DuplicateStatusOpen || status == DuplicateStatusDismissed
+}
@@ -0,0 +1,57 @@
+package cafes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminMergeRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	const cafeID = "5b0a1c52-3f4e-4d6a-9b8c-7d6e5f4a3b2c"
+	handler := NewHandler(nil)
+	router := gin.New()
+	router.POST("/admin/cafes/:id/merge", handler.AdminMerge)
+
+	cases := []struct {
+		name string
+		path string
+		body string
+		want string
+	}{
+		{name: "bad target", path: "/admin/cafes/abc/merge", body: `{"source_id":"` + cafeID + `"}`, want: "Некорректный id кофейни"},
+		{name: "bad json", path: "/admin/cafes/" + cafeID + "/merge", body: `{`, want: "Некорректный JSON"},
+		{name: "bad source", path: "/admin/cafes/" + cafeID + "/merge", body: `{"source_id":"nope"}`, want: "Некорректный source_id"},
+		{name: "self merge", path: "/admin/cafes/" + cafeID + "/merge", body: `{"source_id":"` + strings.ToUpper(cafeID) + `"}`, want: "саму с собой"},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, tc.path, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(rec, req)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", tc.name, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("%s: expected %q in %s", tc.name, tc.want, rec.Body.String())
+		}
+	}
+}
+
+func TestIsValidDuplicateStatus(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []string{DuplicateStatusOpen, DuplicateStatusDismissed} {
+		if !isValidDuplicateStatus(status) {
+			t.Fatalf("expected %q to be valid", status)
+		}
+	}
+	if isValidDuplicateStatus("merged") {
+		t.Fatal("merged pairs are deleted with the source cafe, not kept as a status")
+	}
+}
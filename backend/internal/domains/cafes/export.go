@@ -0,0 +1,290 @@
+package cafes
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/model"
+	"backend/internal/shared/validation"
+)
+
+const (
+	CafeExportFormatJSON    = "json"
+	CafeExportFormatCSV     = "csv"
+	CafeExportFormatGeoJSON = "geojson"
+)
+
+// NormalizeCafeExportFormat maps the format parameter, GeoJSON by default.
+func NormalizeCafeExportFormat(raw string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", "geojson", "geo_json":
+		return CafeExportFormatGeoJSON, nil
+	case "csv":
+		return CafeExportFormatCSV, nil
+	case "json":
+		return CafeExportFormatJSON, nil
+	default:
+		return "", errors.New("format должен быть geojson, csv или json.")
+	}
+}
+
+// CafeExportFileExtension is the file extension for an export format.
+func CafeExportFileExtension(format string) string {
+	if format == CafeExportFormatGeoJSON {
+		return "geojson"
+	}
+	return format
+}
+
+// ParseCafeExportFilter reads the export filters: bbox as
+// "min_lng,min_lat,max_lng,max_lat" (GeoJSON order; min_lng > max_lng
+// crosses the antimeridian), a comma separated list of required amenities
+// and updated_since as RFC3339 or a UTC date.
+func ParseCafeExportFilter(bbox, amenities, updatedSince string) (CafeExportFilter, error) {
+	var filter CafeExportFilter
+
+	if raw := strings.TrimSpace(bbox); raw != "" {
+		parts := strings.Split(raw, ",")
+		if len(parts) != 4 {
+			return CafeExportFilter{}, errors.New("bbox должен иметь вид min_lng,min_lat,max_lng,max_lat.")
+		}
+		var box [4]float64
+		for i, part := range parts {
+			value, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || !isFinite(value) {
+				return CafeExportFilter{}, errors.New("bbox должен содержать четыре числа.")
+			}
+			box[i] = value
+		}
+		if box[0] < -180 || box[2] > 180 || box[0] > 180 || box[2] < -180 {
+			return CafeExportFilter{}, errors.New("Долгота bbox должна быть в диапазоне от -180 до 180.")
+		}
+		if box[1] < -90 || box[3] > 90 || box[1] > box[3] {
+			return CafeExportFilter{}, errors.New("Широта bbox должна быть в диапазоне от -90 до 90, min_lat не больше max_lat.")
+		}
+		filter.BBox = &box
+	}
+
+	if strings.TrimSpace(amenities) != "" {
+		filter.Amenities = validation.ParseAmenities(amenities)
+		if len(filter.Amenities) == 0 {
+			return CafeExportFilter{}, errors.New("amenities не содержит известных удобств.")
+		}
+	}
+
+	if raw := strings.TrimSpace(updatedSince); raw != "" {
+		since, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			since, err = time.Parse(time.DateOnly, raw)
+		}
+		if err != nil {
+			return CafeExportFilter{}, errors.New("updated_since должен быть датой YYYY-MM-DD или временем в RFC3339.")
+		}
+		since = since.UTC()
+		filter.UpdatedSince = &since
+	}
+
+	return filter, nil
+}
+
+// cafeExportEncoder writes one format item by item, so exports of any size
+// are streamed without being held in memory.
+type cafeExportEncoder interface {
+	begin() error
+	item(item CafeExportItem) error
+	end() error
+}
+
+func newCafeExportEncoder(format string, w io.Writer) cafeExportEncoder {
+	switch format {
+	case CafeExportFormatCSV:
+		return &csvCafeExportEncoder{w: w}
+	case CafeExportFormatJSON:
+		return &jsonCafeExportEncoder{w: w}
+	default:
+		return &geoJSONCafeExportEncoder{jsonCafeExportEncoder{w: w}}
+	}
+}
+
+// jsonCafeExportEncoder writes an adminCafeImportRequest in upsert mode, so
+// the file can be fed back to the import as is. Fields the import does not
+// know (id, status, rating, photos) are ignored by it.
+type jsonCafeExportEncoder struct {
+	w     io.Writer
+	count int
+}
+
+func (e *jsonCafeExportEncoder) begin() error {
+	_, err := io.WriteString(e.w, `{"mode":"upsert","dry_run":false,"cafes":[`)
+	return err
+}
+
+func (e *jsonCafeExportEncoder) item(item CafeExportItem) error {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	if err := e.separator(); err != nil {
+		return err
+	}
+	_, err = e.w.Write(raw)
+	return err
+}
+
+func (e *jsonCafeExportEncoder) separator() error {
+	sep := ",\n"
+	if e.count == 0 {
+		sep = "\n"
+	}
+	e.count++
+	_, err := io.WriteString(e.w, sep)
+	return err
+}
+
+func (e *jsonCafeExportEncoder) end() error {
+	_, err := io.WriteString(e.w, "\n]}\n")
+	return err
+}
+
+type geoJSONCafeExportEncoder struct {
+	jsonCafeExportEncoder
+}
+
+type geoJSONExportFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   geoJSONExportPoint     `json:"geometry"`
+	Properties geoJSONExportCafeProps `json:"properties"`
+}
+
+type geoJSONExportPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type geoJSONExportCafeProps struct {
+	Name         string              `json:"name"`
+	Address      string              `json:"address"`
+	Description  string              `json:"description"`
+	Amenities    []string            `json:"amenities"`
+	OpeningHours *model.OpeningHours `json:"opening_hours,omitempty"`
+	Status       string              `json:"status"`
+	Rating       *CafeExportRating   `json:"rating,omitempty"`
+	Photos       []CafeExportPhoto   `json:"photos"`
+	UpdatedAt    string              `json:"updated_at"`
+}
+
+func (e *geoJSONCafeExportEncoder) begin() error {
+	_, err := io.WriteString(e.w, `{"type":"FeatureCollection","features":[`)
+	return err
+}
+
+func (e *geoJSONCafeExportEncoder) item(item CafeExportItem) error {
+	props := geoJSONExportCafeProps{
+		Name:         item.Name,
+		Address:      item.Address,
+		Description:  item.Description,
+		Amenities:    item.Amenities,
+		OpeningHours: item.OpeningHours,
+		Status:       item.Status,
+		Rating:       item.Rating,
+		Photos:       item.Photos,
+		UpdatedAt:    item.UpdatedAt,
+	}
+	raw, err := json.Marshal(geoJSONExportFeature{
+		Type: "Feature",
+		ID:   item.ID,
+		Geometry: geoJSONExportPoint{
+			Type:        "Point",
+			Coordinates: [2]float64{item.Longitude, item.Latitude},
+		},
+		Properties: props,
+	})
+	if err != nil {
+		return err
+	}
+	if err := e.separator(); err != nil {
+		return err
+	}
+	_, err = e.w.Write(raw)
+	return err
+}
+
+var cafeExportCSVHeader = []string{
+	"id",
+	"name",
+	"address",
+	"latitude",
+	"longitude",
+	"description",
+	"amenities",
+	"opening_hours",
+	"status",
+	"rating",
+	"reviews_count",
+	"verified_reviews_count",
+	"photo_urls",
+	"updated_at",
+}
+
+// csvCafeExportEncoder uses the import column names; amenities are joined
+// with ";", opening hours are a JSON cell and photo URLs are space separated.
+type csvCafeExportEncoder struct {
+	w      io.Writer
+	writer *csv.Writer
+}
+
+func (e *csvCafeExportEncoder) begin() error {
+	if _, err := e.w.Write(utf8BOM); err != nil {
+		return err
+	}
+	e.writer = csv.NewWriter(e.w)
+	return e.writer.Write(cafeExportCSVHeader)
+}
+
+func (e *csvCafeExportEncoder) item(item CafeExportItem) error {
+	hours := ""
+	if item.OpeningHours != nil {
+		raw, err := json.Marshal(item.OpeningHours)
+		if err != nil {
+			return err
+		}
+		hours = string(raw)
+	}
+	rating, reviews, verified := "", "", ""
+	if item.Rating != nil {
+		rating = strconv.FormatFloat(item.Rating.Rating, 'f', -1, 64)
+		reviews = strconv.Itoa(item.Rating.ReviewsCount)
+		verified = strconv.Itoa(item.Rating.VerifiedReviewsCount)
+	}
+	urls := make([]string, 0, len(item.Photos))
+	for _, photo := range item.Photos {
+		urls = append(urls, photo.URL)
+	}
+	return e.writer.Write([]string{
+		item.ID,
+		item.Name,
+		item.Address,
+		strconv.FormatFloat(item.Latitude, 'f', -1, 64),
+		strconv.FormatFloat(item.Longitude, 'f', -1, 64),
+		item.Description,
+		strings.Join(item.Amenities, ";"),
+		hours,
+		item.Status,
+		rating,
+		reviews,
+		verified,
+		strings.Join(urls, " "),
+		item.UpdatedAt,
+	})
+}
+
+func (e *csvCafeExportEncoder) end() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
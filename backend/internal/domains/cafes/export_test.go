@@ -0,0 +1,122 @@
+package cafes
+
+import (
+	"bytes"
+	"testing"
+
+	"backend/internal/model"
+)
+
+func testCafeExportItem() CafeExportItem {
+	return CafeExportItem{
+		ID:          "3f0c1d8e-2a4b-4c6d-8e9f-0a1b2c3d4e5f",
+		Name:        "Зерно, кофе",
+		Address:     "Тверская 1",
+		Description: "Тихо; есть \"розетки\"",
+		Latitude:    55.7601,
+		Longitude:   37.6101,
+		Amenities:   []string{"wifi", "power"},
+		OpeningHours: &model.OpeningHours{
+			Timezone: "Europe/Moscow",
+			Weekly:   map[string][]model.OpeningInterval{"mon": {{Open: "08:00", Close: "20:00"}}},
+		},
+		Status: "active",
+		Rating: &CafeExportRating{Rating: 4.5, ReviewsCount: 12, VerifiedReviewsCount: 3},
+		Photos: []CafeExportPhoto{
+			{URL: "https://cdn.example/a.jpg", Kind: "cafe", IsCover: true},
+			{URL: "https://cdn.example/b.jpg", Kind: "menu"},
+		},
+		UpdatedAt: "2026-03-10T12:00:00Z",
+	}
+}
+
+func TestCafeExportRoundTripsThroughImport(t *testing.T) {
+	t.Parallel()
+
+	source := testCafeExportItem()
+	for _, format := range []string{CafeExportFormatJSON, CafeExportFormatCSV, CafeExportFormatGeoJSON} {
+		var buf bytes.Buffer
+		encoder := newCafeExportEncoder(format, &buf)
+		if err := encoder.begin(); err != nil {
+			t.Fatalf("%s: begin: %v", format, err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := encoder.item(source); err != nil {
+				t.Fatalf("%s: item: %v", format, err)
+			}
+		}
+		if err := encoder.end(); err != nil {
+			t.Fatalf("%s: end: %v", format, err)
+		}
+
+		detected, err := detectCafeImportFormat("", "", "", buf.Bytes())
+		if err != nil || detected != format {
+			t.Fatalf("%s: export detected as %q (%v)", format, detected, err)
+		}
+		payload, err := parseCafeImportPayload(format, buf.Bytes())
+		if err != nil {
+			t.Fatalf("%s: import parse: %v", format, err)
+		}
+		if len(payload.Records) != 2 {
+			t.Fatalf("%s: expected 2 records, got %d", format, len(payload.Records))
+		}
+		if format == CafeExportFormatJSON && payload.Mode != AdminCafeImportModeUpsert {
+			t.Fatalf("json export must request upsert, got %q", payload.Mode)
+		}
+
+		record := payload.Records[1]
+		if len(record.Issues) > 0 {
+			t.Fatalf("%s: unexpected parse issues %+v", format, record.Issues)
+		}
+		normalized, issues := normalizeCafeImportItem(record.Item)
+		if len(issues) > 0 {
+			t.Fatalf("%s: unexpected validation issues %+v", format, issues)
+		}
+		if normalized.Name != source.Name || normalized.Address != source.Address ||
+			normalized.Description != source.Description ||
+			normalized.Latitude != source.Latitude || normalized.Longitude != source.Longitude {
+			t.Fatalf("%s: fields changed in round trip: %+v", format, normalized)
+		}
+		if len(normalized.Amenities) != 2 || normalized.Amenities[0] != "wifi" || normalized.Amenities[1] != "power" {
+			t.Fatalf("%s: amenities changed: %v", format, normalized.Amenities)
+		}
+		if normalized.OpeningHours == nil || normalized.OpeningHours.Timezone != "Europe/Moscow" ||
+			len(normalized.OpeningHours.Weekly["mon"]) != 1 {
+			t.Fatalf("%s: opening hours changed: %+v", format, normalized.OpeningHours)
+		}
+	}
+}
+
+func TestParseCafeExportFilter(t *testing.T) {
+	t.Parallel()
+
+	filter, err := ParseCafeExportFilter("37.5,55.6,37.8,55.9", "wifi, power", "2026-03-01")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if filter.BBox == nil || filter.BBox[0] != 37.5 || filter.BBox[3] != 55.9 {
+		t.Fatalf("unexpected bbox: %v", filter.BBox)
+	}
+	if len(filter.Amenities) != 2 {
+		t.Fatalf("unexpected amenities: %v", filter.Amenities)
+	}
+	if filter.UpdatedSince == nil || filter.UpdatedSince.Format("2006-01-02T15:04:05Z07:00") != "2026-03-01T00:00:00Z" {
+		t.Fatalf("unexpected updated_since: %v", filter.UpdatedSince)
+	}
+
+	if _, err := ParseCafeExportFilter("179,10,-179,20", "", ""); err != nil {
+		t.Fatalf("antimeridian bbox must be accepted: %v", err)
+	}
+
+	for _, bad := range []struct{ bbox, amenities, since string }{
+		{bbox: "1,2,3"},
+		{bbox: "37,56,38,55"},
+		{bbox: "37,95,38,96"},
+		{amenities: "jacuzzi"},
+		{since: "yesterday"},
+	} {
+		if _, err := ParseCafeExportFilter(bad.bbox, bad.amenities, bad.since); err == nil {
+			t.Fatalf("expected %+v to be rejected", bad)
+		}
+	}
+}
@@ -3,6 +3,10 @@ package cafes
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
@@ -10,6 +14,7 @@ import (
 
 	"backend/internal/auth"
 	"backend/internal/config"
+	"backend/internal/model"
 	"backend/internal/shared/httpx"
 	"backend/internal/shared/validation"
 
@@ -31,6 +36,10 @@ func NewDefaultHandler(pool *pgxpool.Pool, cfg config.Config) *Handler {
 	return NewHandler(service)
 }
 
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
 func (h *Handler) List(c *gin.Context) {
 	const maxRadiusM = 50000.0
 
@@ -77,23 +86,38 @@ func (h *Handler) List(c *gin.Context) {
 	}
 
 	sortBy := strings.TrimSpace(c.Query("sort"))
-	if sortBy != "" && sortBy != config.SortByDistance {
-		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "sort поддерживает только значение 'distance'.", nil)
+	if sortBy == "" {
+		sortBy = config.DefaultSort
+	}
+	if _, ok := cafeListOrderClause[sortBy]; !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "sort поддерживает значения 'distance', 'work', 'rating' и 'relevance'.", nil)
+		return
+	}
+
+	favoritesOnly, ok := parseBoolQuery(c.DefaultQuery("favorites_only", "false"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "favorites_only должен быть boolean-значением.", nil)
+		return
+	}
+
+	openNow, ok := parseBoolQuery(c.DefaultQuery("open_now", "false"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "open_now должен быть boolean-значением.", nil)
 		return
 	}
 
-	favoritesOnly := false
-	favoritesOnlyRaw := strings.TrimSpace(strings.ToLower(c.DefaultQuery("favorites_only", "false")))
-	if favoritesOnlyRaw != "" {
-		switch favoritesOnlyRaw {
-		case "1", "true", "yes", "on":
-			favoritesOnly = true
-		case "0", "false", "no", "off":
-			favoritesOnly = false
-		default:
-			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "favorites_only должен быть boolean-значением.", nil)
+	var openAt *time.Time
+	if openAtRaw := strings.TrimSpace(c.Query("open_at")); openAtRaw != "" {
+		if openNow {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Используйте либо open_now, либо open_at.", nil)
+			return
+		}
+		parsed, err := time.Parse(time.RFC3339, openAtRaw)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "open_at должен быть в формате RFC3339.", nil)
 			return
 		}
+		openAt = &parsed
 	}
 
 	limit, err := validation.ParseLimit(c.Query("limit"), h.service.cfg.Limits)
@@ -102,6 +126,27 @@ func (h *Handler) List(c *gin.Context) {
 		return
 	}
 
+	city, err := validation.ParseCitySlug(c.Query("city"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	aspect, err := validation.ParseReviewAspect(c.Query("aspect"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	minAspectRating, err := validation.ParseMinAspectRating(c.Query("min_aspect_rating"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	if minAspectRating > 0 && aspect == "" {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "min_aspect_rating передаётся вместе с aspect.", nil)
+		return
+	}
+
 	requiredAmenities := validation.ParseAmenities(c.Query("amenities"))
 	var userID *string
 	if authUserID, ok := auth.UserIDFromContext(c); ok {
@@ -111,16 +156,269 @@ func (h *Handler) List(c *gin.Context) {
 		}
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
-	defer cancel()
-
-	items, err := h.service.List(ctx, ListParams{
+	params := ListParams{
 		Latitude:          lat,
 		Longitude:         lng,
 		RadiusM:           radiusM,
 		RequiredAmenities: requiredAmenities,
+		City:              city,
 		UserID:            userID,
 		FavoritesOnly:     favoritesOnly,
+		SortBy:            sortBy,
+		Aspect:            aspect,
+		MinAspectRating:   minAspectRating,
+		OpenAt:            openAt,
+		OnlyOpen:          openNow || openAt != nil,
+		Limit:             limit,
+	}
+	if cursor := strings.TrimSpace(c.Query("cursor")); cursor != "" {
+		if err := applyCafeListCursor(&params, cursor); err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	page, err := h.service.List(ctx, params)
+	if err != nil {
+		if errors.Is(err, errCityNotFound) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", errCityNotFound.Error(), nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	// The body stays a bare array for older clients; the next page is
+	// advertised out of band.
+	if page.NextCursor != "" {
+		c.Header("X-Next-Cursor", page.NextCursor)
+	}
+	c.JSON(http.StatusOK, page.Items)
+}
+
+func (h *Handler) Search(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len([]rune(query)) < searchMinQueryRunes {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "q должен содержать минимум 2 символа.", nil)
+		return
+	}
+	if len([]rune(query)) > searchMaxQueryRunes {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "q слишком длинный.", gin.H{"max_chars": searchMaxQueryRunes})
+		return
+	}
+	variants := searchQueryVariants(query)
+	if len(variants) == 0 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "q должен содержать буквы или цифры.", nil)
+		return
+	}
+
+	latStr := strings.TrimSpace(c.Query("lat"))
+	lngStr := strings.TrimSpace(c.Query("lng"))
+	if (latStr == "") != (lngStr == "") {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Параметры lat и lng передаются вместе.", nil)
+		return
+	}
+	var latPtr, lngPtr *float64
+	if latStr != "" {
+		lat, err := validation.ParseFloat(latStr)
+		if err != nil || !validation.IsFinite(lat) || lat < -90 || lat > 90 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lat должен быть в диапазоне от -90 до 90.", nil)
+			return
+		}
+		lng, err := validation.ParseFloat(lngStr)
+		if err != nil || !validation.IsFinite(lng) || lng < -180 || lng > 180 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lng должен быть в диапазоне от -180 до 180.", nil)
+			return
+		}
+		latPtr = &lat
+		lngPtr = &lng
+	}
+
+	limit := searchDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		value, err := strconv.Atoi(rawLimit)
+		if err != nil || value <= 0 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть целым числом больше 0.", nil)
+			return
+		}
+		if value > searchMaxLimit {
+			value = searchMaxLimit
+		}
+		limit = value
+	}
+
+	var userID *string
+	if authUserID, ok := auth.UserIDFromContext(c); ok {
+		trimmed := strings.TrimSpace(authUserID)
+		if trimmed != "" {
+			userID = &trimmed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.service.Search(ctx, SearchParams{
+		Query:     query,
+		Variants:  variants,
+		Latitude:  latPtr,
+		Longitude: lngPtr,
+		UserID:    userID,
+		Limit:     limit,
+	})
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось выполнить поиск кофеен.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, SearchResult{Query: query, Items: items})
+}
+
+func (h *Handler) Viewport(c *gin.Context) {
+	boundKeys := []string{"min_lat", "min_lng", "max_lat", "max_lng"}
+	values := make(map[string]float64, len(boundKeys))
+	for _, key := range boundKeys {
+		raw := strings.TrimSpace(c.Query(key))
+		if raw == "" {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Параметры min_lat, min_lng, max_lat, max_lng и zoom обязательны.", nil)
+			return
+		}
+		value, err := validation.ParseFloat(raw)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректное значение "+key+".", nil)
+			return
+		}
+		values[key] = value
+	}
+	bounds := viewportBounds{
+		MinLat: values["min_lat"],
+		MinLng: values["min_lng"],
+		MaxLat: values["max_lat"],
+		MaxLng: values["max_lng"],
+	}
+	if err := bounds.validate(); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	zoom, err := strconv.Atoi(strings.TrimSpace(c.Query("zoom")))
+	if err != nil || zoom < viewportMinZoom || zoom > viewportMaxZoom {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "zoom должен быть целым числом от 0 до 22.", nil)
+		return
+	}
+
+	var userID *string
+	if authUserID, ok := auth.UserIDFromContext(c); ok {
+		trimmed := strings.TrimSpace(authUserID)
+		if trimmed != "" {
+			userID = &trimmed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.Viewport(ctx, ViewportParams{
+		Bounds:            bounds,
+		Zoom:              zoom,
+		RequiredAmenities: validation.ParseAmenities(c.Query("amenities")),
+		UserID:            userID,
+	})
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+type routeRequest struct {
+	Polyline          string          `json:"polyline"`
+	PolylinePrecision int             `json:"polyline_precision"`
+	Geometry          json.RawMessage `json:"geometry"`
+	WidthM            *float64        `json:"width_m"`
+	Amenities         []string        `json:"amenities"`
+	OpenNow           bool            `json:"open_now"`
+	Limit             *int            `json:"limit"`
+}
+
+func (h *Handler) Route(c *gin.Context) {
+	var req routeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+
+	polyline := strings.TrimSpace(req.Polyline)
+	hasGeometry := len(req.Geometry) > 0 && string(req.Geometry) != "null"
+	if (polyline == "") == !hasGeometry {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Передайте маршрут либо в polyline, либо в geometry.", nil)
+		return
+	}
+	var (
+		route routeLine
+		err   error
+	)
+	if polyline != "" {
+		precision := req.PolylinePrecision
+		if precision == 0 {
+			precision = 5
+		}
+		if precision != 5 && precision != 6 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "polyline_precision поддерживает значения 5 и 6.", nil)
+			return
+		}
+		route, err = decodePolyline(polyline, precision)
+	} else {
+		route, err = parseGeoJSONLine(req.Geometry)
+	}
+	if err == nil {
+		err = route.validate()
+	}
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	widthM := routeDefaultWidthM
+	if req.WidthM != nil {
+		widthM = *req.WidthM
+	}
+	if !validation.IsFinite(widthM) || widthM < routeMinWidthM || widthM > routeMaxWidthM {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "width_m должен быть в диапазоне от 20 до 2000.", gin.H{"min_width_m": routeMinWidthM, "max_width_m": routeMaxWidthM})
+		return
+	}
+
+	limits := h.service.cfg.Limits
+	limit := limits.DefaultResults
+	if req.Limit != nil {
+		limit = *req.Limit
+		if limit <= 0 || (limits.MaxResults > 0 && limit > limits.MaxResults) {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", fmt.Sprintf("limit должен быть в диапазоне от 1 до %d.", limits.MaxResults), nil)
+			return
+		}
+	}
+
+	var userID *string
+	if authUserID, ok := auth.UserIDFromContext(c); ok {
+		trimmed := strings.TrimSpace(authUserID)
+		if trimmed != "" {
+			userID = &trimmed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.Route(ctx, RouteParams{
+		Route:             route,
+		WidthM:            widthM,
+		RequiredAmenities: validation.ParseAmenities(strings.Join(req.Amenities, ",")),
+		OnlyOpen:          req.OpenNow,
+		UserID:            userID,
 		Limit:             limit,
 	})
 	if err != nil {
@@ -128,7 +426,101 @@ func (h *Handler) List(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, items)
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) GetByID(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if cafeID == "" || !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var userID *string
+	if authUserID, ok := auth.UserIDFromContext(c); ok {
+		trimmed := strings.TrimSpace(authUserID)
+		if trimmed != "" {
+			userID = &trimmed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	details, err := h.service.GetDetails(ctx, cafeID, userID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+func (h *Handler) Similar(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if cafeID == "" || !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	radiusM := similarDefaultRadiusM
+	if raw := strings.TrimSpace(c.Query("radius_m")); raw != "" {
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil || !validation.IsFinite(value) || value <= 0 || value > similarMaxRadiusM {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", fmt.Sprintf("radius_m должен быть в диапазоне от 1 до %.0f.", similarMaxRadiusM), gin.H{"max_radius_m": similarMaxRadiusM})
+			return
+		}
+		radiusM = value
+	}
+
+	limit := similarDefaultLimit
+	if raw := strings.TrimSpace(c.Query("limit")); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 || value > similarMaxLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", fmt.Sprintf("limit должен быть в диапазоне от 1 до %d.", similarMaxLimit), nil)
+			return
+		}
+		limit = value
+	}
+
+	openNow, ok := parseBoolQuery(c.DefaultQuery("open_now", "false"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "open_now должен быть boolean-значением.", nil)
+		return
+	}
+
+	var userID *string
+	if authUserID, ok := auth.UserIDFromContext(c); ok {
+		trimmed := strings.TrimSpace(authUserID)
+		if trimmed != "" {
+			userID = &trimmed
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	result, err := h.service.Similar(ctx, SimilarParams{
+		CafeID:   cafeID,
+		RadiusM:  radiusM,
+		OnlyOpen: openNow,
+		UserID:   userID,
+		Limit:    limit,
+	})
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
 }
 
 func (h *Handler) UpdateDescription(c *gin.Context) {
@@ -154,10 +546,12 @@ func (h *Handler) UpdateDescription(c *gin.Context) {
 		return
 	}
 
+	actorID, _ := auth.UserIDFromContext(c)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	saved, err := h.service.UpdateDescription(ctx, cafeID, description)
+	saved, err := h.service.UpdateDescription(ctx, cafeID, description, actorID)
 	if err != nil {
 		if h.service.IsNotFound(err) {
 			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
@@ -190,6 +584,26 @@ func (h *Handler) GeocodeLookup(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+func (h *Handler) ReverseGeocode(c *gin.Context) {
+	lat, err := validation.ParseFloat(c.Query("lat"))
+	if err != nil || !validation.IsFinite(lat) || lat < -90 || lat > 90 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lat должен быть в диапазоне от -90 до 90.", nil)
+		return
+	}
+	lng, err := validation.ParseFloat(c.Query("lng"))
+	if err != nil || !validation.IsFinite(lng) || lng < -180 || lng > 180 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lng должен быть в диапазоне от -180 до 180.", nil)
+		return
+	}
+
+	result, err := h.service.ReverseGeocode(c.Request.Context(), lat, lng)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadGateway, "upstream_error", "Не удалось определить адрес по координатам.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
 func (h *Handler) ImportJSON(c *gin.Context) {
 	raw, err := c.GetRawData()
 	if err != nil {
@@ -224,10 +638,12 @@ func (h *Handler) ImportJSON(c *gin.Context) {
 		return
 	}
 
+	actorID, _ := auth.UserIDFromContext(c)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
 	defer cancel()
 
-	result, err := h.service.ImportJSON(ctx, req)
+	result, err := h.service.ImportJSON(ctx, req, actorID)
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
 		return
@@ -316,10 +732,12 @@ func (h *Handler) AdminUpdateByID(c *gin.Context) {
 		return
 	}
 
+	actorID, _ := auth.UserIDFromContext(c)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	updated, err := h.service.UpdateAdminCafeByID(ctx, cafeID, normalized)
+	updated, err := h.service.UpdateAdminCafeByID(ctx, cafeID, normalized, actorID)
 	if err != nil {
 		if h.service.IsNotFound(err) {
 			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
@@ -339,10 +757,12 @@ func (h *Handler) AdminDeleteByID(c *gin.Context) {
 		return
 	}
 
+	actorID, _ := auth.UserIDFromContext(c)
+
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Second)
 	defer cancel()
 
-	if err := h.service.DeleteCafeByID(ctx, cafeID); err != nil {
+	if err := h.service.DeleteCafeByID(ctx, cafeID, actorID); err != nil {
 		if h.service.IsNotFound(err) {
 			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
 			return
@@ -354,5 +774,530 @@ func (h *Handler) AdminDeleteByID(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"deleted": true,
 		"id":      cafeID,
+		"status":  model.CafeStatusDeleted,
 	})
 }
+
+func (h *Handler) AdminSetStatus(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var req adminCafeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	update, err := normalizeCafeStatusRequest(req, time.Now())
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	updated, err := h.service.SetCafeStatus(ctx, cafeID, update, actorID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось изменить статус кофейни.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+func (h *Handler) AdminRestore(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	restored, err := h.service.RestoreCafe(ctx, cafeID, actorID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось восстановить кофейню.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, restored)
+}
+
+func (h *Handler) AdminListDuplicates(c *gin.Context) {
+	status := strings.ToLower(strings.TrimSpace(c.DefaultQuery("status", DuplicateStatusOpen)))
+	if !isValidDuplicateStatus(status) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "status должен быть open или dismissed.", nil)
+		return
+	}
+
+	limit := duplicateListDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		value, err := strconv.Atoi(rawLimit)
+		if err != nil || value <= 0 || value > duplicateListMaxLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть в диапазоне от 1 до 200.", nil)
+			return
+		}
+		limit = value
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.service.ListDuplicates(ctx, status, limit)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status": status,
+		"items":  items,
+	})
+}
+
+func (h *Handler) AdminScanDuplicates(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	result, err := h.service.ScanDuplicates(ctx)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось выполнить поиск дубликатов.", nil)
+		return
+	}
+	if result.Skipped {
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Поиск дубликатов уже выполняется.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminDismissDuplicate(c *gin.Context) {
+	candidateID, err := strconv.ParseInt(strings.TrimSpace(c.Param("id")), 10, 64)
+	if err != nil || candidateID <= 0 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кандидата.", nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	if err := h.service.DismissDuplicate(ctx, candidateID, actorID); err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кандидат в дубликаты не найден.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":     candidateID,
+		"status": DuplicateStatusDismissed,
+	})
+}
+
+// AdminMerge merges the cafe from the body into the cafe in the path; the
+// path cafe survives.
+func (h *Handler) AdminMerge(c *gin.Context) {
+	targetID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(targetID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var req cafeMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	sourceID := strings.TrimSpace(req.SourceID)
+	if !validation.IsValidUUID(sourceID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный source_id.", nil)
+		return
+	}
+	if strings.EqualFold(sourceID, targetID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Нельзя объединить кофейню саму с собой.", nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	result, err := h.service.MergeCafes(ctx, targetID, sourceID, actorID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось объединить кофейни.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminListRevisions(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	limit := revisionListDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		value, err := strconv.Atoi(rawLimit)
+		if err != nil || value <= 0 || value > revisionListMaxLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть в диапазоне от 1 до 200.", nil)
+			return
+		}
+		limit = value
+	}
+	before := 0
+	if rawBefore := strings.TrimSpace(c.Query("before")); rawBefore != "" {
+		value, err := strconv.Atoi(rawBefore)
+		if err != nil || value <= 0 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный параметр before.", nil)
+			return
+		}
+		before = value
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.ListRevisions(ctx, cafeID, before, limit)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminRevertRevision(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+	revision, err := strconv.Atoi(strings.TrimSpace(c.Param("revision")))
+	if err != nil || revision <= 0 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный номер ревизии.", nil)
+		return
+	}
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.RevertToRevision(ctx, cafeID, revision, actorID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня или ревизия не найдены.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось откатить кофейню.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseBoolQuery(raw string) (bool, bool) {
+	switch strings.TrimSpace(strings.ToLower(raw)) {
+	case "", "0", "false", "no", "off":
+		return false, true
+	case "1", "true", "yes", "on":
+		return true, true
+	default:
+		return false, false
+	}
+}
+
+func (h *Handler) AdminCreateImportJob(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, AdminCafeImportJobMaxBytes)
+	raw, filename, contentType, err := readCafeImportUpload(c)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			httpx.RespondError(c, http.StatusRequestEntityTooLarge, "payload_too_large", "Файл импорта слишком большой.", gin.H{
+				"max_bytes": AdminCafeImportJobMaxBytes,
+			})
+			return
+		}
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Не удалось прочитать файл импорта.", nil)
+		return
+	}
+
+	format, err := detectCafeImportFormat(c.Query("format"), contentType, filename, raw)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	payload, err := parseCafeImportPayload(format, raw)
+	if err != nil {
+		if errors.Is(err, errTooManyImportItems) {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), gin.H{
+				"max_items": AdminCafeImportJobMaxItems,
+			})
+			return
+		}
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	if len(payload.Records) == 0 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Файл импорта не содержит ни одной кофейни.", nil)
+		return
+	}
+	if len(payload.Records) > AdminCafeImportJobMaxItems {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", errTooManyImportItems.Error(), gin.H{
+			"max_items": AdminCafeImportJobMaxItems,
+		})
+		return
+	}
+
+	rawMode := strings.TrimSpace(c.Query("mode"))
+	if rawMode == "" {
+		rawMode = payload.Mode
+	}
+	mode, err := normalizeAdminCafeImportMode(rawMode)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	dryRun := payload.DryRun
+	if rawDryRun := strings.TrimSpace(c.Query("dry_run")); rawDryRun != "" {
+		value, err := strconv.ParseBool(rawDryRun)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "dry_run должен быть true/false.", nil)
+			return
+		}
+		dryRun = value
+	}
+
+	sourceName := strings.TrimSpace(c.Query("source_name"))
+	if sourceName == "" {
+		sourceName = filename
+	}
+	if runes := []rune(sourceName); len(runes) > 200 {
+		sourceName = string(runes[:200])
+	}
+
+	actorID, _ := auth.UserIDFromContext(c)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 60*time.Second)
+	defer cancel()
+
+	job, err := h.service.CreateImportJob(ctx, cafeImportJobInput{
+		Format:     format,
+		Mode:       mode,
+		DryRun:     dryRun,
+		SourceName: sourceName,
+		ActorID:    actorID,
+		Records:    payload.Records,
+	})
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось создать задачу импорта.", nil)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// readCafeImportUpload accepts the file either as the raw request body or as
+// the "file" field of a multipart form.
+func readCafeImportUpload(c *gin.Context) ([]byte, string, string, error) {
+	contentType := c.ContentType()
+	if contentType != "multipart/form-data" {
+		raw, err := io.ReadAll(c.Request.Body)
+		return raw, "", c.GetHeader("Content-Type"), err
+	}
+
+	header, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", "", err
+	}
+	file, err := header.Open()
+	if err != nil {
+		return nil, "", "", err
+	}
+	defer file.Close()
+	raw, err := io.ReadAll(file)
+	return raw, header.Filename, header.Header.Get("Content-Type"), err
+}
+
+func (h *Handler) AdminListImportJobs(c *gin.Context) {
+	status := strings.ToLower(strings.TrimSpace(c.Query("status")))
+	if status != "" && !isValidImportJobStatus(status) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "status должен быть queued, running, completed, failed или cancelled.", nil)
+		return
+	}
+
+	limit := importJobListDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		value, err := strconv.Atoi(rawLimit)
+		if err != nil || value <= 0 || value > importJobListMaxLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть в диапазоне от 1 до 100.", nil)
+			return
+		}
+		limit = value
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.service.ListImportJobs(ctx, status, limit)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+func (h *Handler) AdminGetImportJob(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(jobID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id задачи импорта.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	job, err := h.service.GetImportJob(ctx, jobID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Задача импорта не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+func (h *Handler) AdminCancelImportJob(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(jobID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id задачи импорта.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	job, cancelled, err := h.service.CancelImportJob(ctx, jobID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Задача импорта не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось отменить задачу импорта.", nil)
+		return
+	}
+	if !cancelled {
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Задача импорта уже завершена.", gin.H{
+			"status": job.Status,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// AdminImportJobIssues downloads the issue report of a job as CSV, or as
+// JSON with format=json.
+func (h *Handler) AdminImportJobIssues(c *gin.Context) {
+	jobID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(jobID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id задачи импорта.", nil)
+		return
+	}
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "csv")))
+	if format != "csv" && format != "json" {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "format должен быть csv или json.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	issues, err := h.service.ListImportJobIssues(ctx, jobID)
+	if err != nil {
+		if h.service.IsNotFound(err) {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Задача импорта не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	if format == "json" {
+		c.JSON(http.StatusOK, gin.H{"items": issues})
+		return
+	}
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="cafe-import-`+jobID+`-issues.csv"`)
+	c.Status(http.StatusOK)
+	_ = writeCafeImportIssuesCSV(c.Writer, issues)
+}
+
+// AdminExport streams cafes as GeoJSON (default), CSV or import-compatible
+// JSON. The response starts before rows are read, so a failure mid-export
+// can only be logged and leaves the client with a truncated file.
+func (h *Handler) AdminExport(c *gin.Context) {
+	format, err := NormalizeCafeExportFormat(c.Query("format"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	filter, err := ParseCafeExportFilter(c.Query("bbox"), c.Query("amenities"), c.Query("updated_since"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 10*time.Minute)
+	defer cancel()
+
+	contentType := "application/geo+json"
+	switch format {
+	case CafeExportFormatCSV:
+		contentType = "text/csv; charset=utf-8"
+	case CafeExportFormatJSON:
+		contentType = "application/json; charset=utf-8"
+	}
+	filename := "cafes-" + time.Now().UTC().Format("20060102-150405") + "." + CafeExportFileExtension(format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	c.Status(http.StatusOK)
+
+	count, err := h.service.ExportCafes(ctx, filter, format, c.Writer)
+	if err != nil {
+		slog.Error("cafe export failed", "format", format, "written", count, "error", err)
+		c.Abort()
+	}
+}
@@ -0,0 +1,348 @@
+package cafes
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+
+	"backend/internal/model"
+)
+
+const (
+	CafeImportFormatJSON    = "json"
+	CafeImportFormatCSV     = "csv"
+	CafeImportFormatGeoJSON = "geojson"
+)
+
+// cafeImportRecord is one parsed source row. Issues are problems the parser
+// found before validation, e.g. coordinates that are not numbers.
+type cafeImportRecord struct {
+	Item   adminCafeImportItem    `json:"item"`
+	Issues []adminCafeImportIssue `json:"issues,omitempty"`
+}
+
+// cafeImportPayload is a parsed import file. Only the JSON format can carry
+// mode and dry_run in the body; for it they act as defaults for the query.
+type cafeImportPayload struct {
+	Mode    string
+	DryRun  bool
+	Records []cafeImportRecord
+}
+
+// cafeImportCSVColumns maps accepted CSV headers to import fields. Unknown
+// columns are ignored so exported files can be imported back as is.
+var cafeImportCSVColumns = map[string]string{
+	"name":          "name",
+	"address":       "address",
+	"latitude":      "latitude",
+	"lat":           "latitude",
+	"longitude":     "longitude",
+	"lng":           "longitude",
+	"lon":           "longitude",
+	"description":   "description",
+	"amenities":     "amenities",
+	"opening_hours": "opening_hours",
+}
+
+var cafeImportCSVRequired = []string{"name", "address", "latitude", "longitude"}
+
+// detectCafeImportFormat picks the format from the explicit parameter, then
+// the content type, the file extension and finally the body itself.
+func detectCafeImportFormat(explicit, contentType, filename string, raw []byte) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(explicit)) {
+	case "":
+	case "json":
+		return CafeImportFormatJSON, nil
+	case "csv":
+		return CafeImportFormatCSV, nil
+	case "geojson", "geo_json":
+		return CafeImportFormatGeoJSON, nil
+	default:
+		return "", errors.New("format должен быть json, csv или geojson")
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "text/csv":
+			return CafeImportFormatCSV, nil
+		case "application/geo+json":
+			return CafeImportFormatGeoJSON, nil
+		}
+	}
+
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return CafeImportFormatCSV, nil
+	case ".geojson":
+		return CafeImportFormatGeoJSON, nil
+	case ".json":
+		return CafeImportFormatJSON, nil
+	}
+
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(raw, utf8BOM))
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		var probe struct {
+			Type string `json:"type"`
+		}
+		if trimmed[0] == '{' && json.Unmarshal(trimmed, &probe) == nil && probe.Type == "FeatureCollection" {
+			return CafeImportFormatGeoJSON, nil
+		}
+		return CafeImportFormatJSON, nil
+	}
+	return CafeImportFormatCSV, nil
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func parseCafeImportPayload(format string, raw []byte) (cafeImportPayload, error) {
+	raw = bytes.TrimPrefix(raw, utf8BOM)
+	switch format {
+	case CafeImportFormatCSV:
+		records, err := parseCafeImportCSV(raw)
+		return cafeImportPayload{Records: records}, err
+	case CafeImportFormatGeoJSON:
+		records, err := parseCafeImportGeoJSON(raw)
+		return cafeImportPayload{Records: records}, err
+	default:
+		req, err := decodeAdminCafeImportRequest(raw)
+		if err != nil {
+			return cafeImportPayload{}, errors.New("Некорректный JSON в запросе.")
+		}
+		records := make([]cafeImportRecord, 0, len(req.Cafes))
+		for _, item := range req.Cafes {
+			records = append(records, cafeImportRecord{Item: item})
+		}
+		return cafeImportPayload{Mode: req.Mode, DryRun: req.DryRun, Records: records}, nil
+	}
+}
+
+// parseCafeImportCSV reads a CSV file with a header row. The delimiter is a
+// comma or, as spreadsheet exports with a decimal comma do, a semicolon.
+// A present column always counts as provided: an empty description or
+// amenities cell clears the value on upsert.
+func parseCafeImportCSV(raw []byte) ([]cafeImportRecord, error) {
+	header, _, _ := bytes.Cut(raw, []byte("\n"))
+	reader := csv.NewReader(bytes.NewReader(raw))
+	semicolon := bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(","))
+	if semicolon {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+
+	columns, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("CSV-файл пуст.")
+		}
+		return nil, fmt.Errorf("Не удалось прочитать заголовок CSV: %v", err)
+	}
+	positions := make(map[string]int, len(columns))
+	for i, column := range columns {
+		field, ok := cafeImportCSVColumns[strings.ToLower(strings.TrimSpace(column))]
+		if !ok {
+			continue
+		}
+		if _, seen := positions[field]; !seen {
+			positions[field] = i
+		}
+	}
+	for _, field := range cafeImportCSVRequired {
+		if _, ok := positions[field]; !ok {
+			return nil, fmt.Errorf("В CSV нет колонки %s.", field)
+		}
+	}
+
+	records := make([]cafeImportRecord, 0, 64)
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Некорректный CSV: %v", err)
+		}
+		if len(records) >= AdminCafeImportJobMaxItems {
+			return nil, errTooManyImportItems
+		}
+		records = append(records, parseCafeImportCSVRow(row, positions, semicolon))
+	}
+	return records, nil
+}
+
+func parseCafeImportCSVRow(row []string, positions map[string]int, decimalComma bool) cafeImportRecord {
+	cell := func(field string) (string, bool) {
+		i, ok := positions[field]
+		if !ok {
+			return "", false
+		}
+		if i >= len(row) {
+			return "", true
+		}
+		return strings.TrimSpace(row[i]), true
+	}
+
+	var record cafeImportRecord
+	record.Item.Name, _ = cell("name")
+	record.Item.Address, _ = cell("address")
+
+	for _, field := range []string{"latitude", "longitude"} {
+		value, _ := cell(field)
+		if value == "" {
+			continue
+		}
+		if decimalComma {
+			value = strings.Replace(value, ",", ".", 1)
+		}
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			record.Issues = append(record.Issues, adminCafeImportIssue{
+				Field:   field,
+				Message: field + " должен быть числом.",
+			})
+			continue
+		}
+		if field == "latitude" {
+			record.Item.Latitude = &number
+		} else {
+			record.Item.Longitude = &number
+		}
+	}
+
+	if value, ok := cell("description"); ok {
+		record.Item.Description = &value
+	}
+	if value, ok := cell("amenities"); ok {
+		record.Item.Amenities = splitCafeImportAmenities(value)
+	}
+	if value, ok := cell("opening_hours"); ok && value != "" {
+		var hours model.OpeningHours
+		if err := json.Unmarshal([]byte(value), &hours); err != nil {
+			record.Issues = append(record.Issues, adminCafeImportIssue{
+				Field:   "opening_hours",
+				Message: "opening_hours должен быть JSON-объектом с расписанием.",
+			})
+		} else {
+			record.Item.OpeningHours = &hours
+		}
+	}
+	return record
+}
+
+// splitCafeImportAmenities splits a flat amenities value. The result is never
+// nil, so an empty cell still counts as an explicitly empty list.
+func splitCafeImportAmenities(value string) []string {
+	parts := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';' || r == '|'
+	})
+	out := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+type geoJSONFeature struct {
+	Type       string           `json:"type"`
+	Geometry   *geoJSONGeometry `json:"geometry"`
+	Properties json.RawMessage  `json:"properties"`
+}
+
+type geoJSONGeometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+type geoJSONCafeProperties struct {
+	Name         string              `json:"name"`
+	Address      string              `json:"address"`
+	Description  *string             `json:"description"`
+	Amenities    json.RawMessage     `json:"amenities"`
+	OpeningHours *model.OpeningHours `json:"opening_hours"`
+}
+
+// parseCafeImportGeoJSON reads a FeatureCollection of Point features. The
+// cafe fields come from feature properties; amenities may be an array or a
+// delimited string.
+func parseCafeImportGeoJSON(raw []byte) ([]cafeImportRecord, error) {
+	var collection geoJSONFeatureCollection
+	if err := json.Unmarshal(raw, &collection); err != nil {
+		return nil, errors.New("Некорректный GeoJSON.")
+	}
+	if collection.Type != "FeatureCollection" {
+		return nil, errors.New("GeoJSON должен быть объектом FeatureCollection.")
+	}
+	if len(collection.Features) > AdminCafeImportJobMaxItems {
+		return nil, errTooManyImportItems
+	}
+
+	records := make([]cafeImportRecord, 0, len(collection.Features))
+	for _, feature := range collection.Features {
+		records = append(records, parseCafeImportFeature(feature))
+	}
+	return records, nil
+}
+
+func parseCafeImportFeature(feature geoJSONFeature) cafeImportRecord {
+	var record cafeImportRecord
+
+	var props geoJSONCafeProperties
+	if len(feature.Properties) > 0 && string(feature.Properties) != "null" {
+		if err := json.Unmarshal(feature.Properties, &props); err != nil {
+			record.Issues = append(record.Issues, adminCafeImportIssue{
+				Field:   "properties",
+				Message: "properties содержат значения неверного типа.",
+			})
+		}
+	}
+	record.Item.Name = props.Name
+	record.Item.Address = props.Address
+	record.Item.Description = props.Description
+	record.Item.OpeningHours = props.OpeningHours
+
+	if len(props.Amenities) > 0 && string(props.Amenities) != "null" {
+		var list []string
+		var flat string
+		switch {
+		case json.Unmarshal(props.Amenities, &list) == nil:
+			record.Item.Amenities = list
+			if list == nil {
+				record.Item.Amenities = []string{}
+			}
+		case json.Unmarshal(props.Amenities, &flat) == nil:
+			record.Item.Amenities = splitCafeImportAmenities(flat)
+		default:
+			record.Issues = append(record.Issues, adminCafeImportIssue{
+				Field:   "amenities",
+				Message: "amenities должен быть массивом строк.",
+			})
+		}
+	}
+
+	var point []float64
+	if feature.Geometry == nil || feature.Geometry.Type != "Point" ||
+		json.Unmarshal(feature.Geometry.Coordinates, &point) != nil || len(point) < 2 {
+		record.Issues = append(record.Issues, adminCafeImportIssue{
+			Field:   "geometry",
+			Message: "geometry должна быть точкой (Point) с координатами [longitude, latitude].",
+		})
+		return record
+	}
+	lng, lat := point[0], point[1]
+	record.Item.Longitude = &lng
+	record.Item.Latitude = &lat
+	return record
+}
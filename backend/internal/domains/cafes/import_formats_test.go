@@ -0,0 +1,210 @@
+package cafes
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseCafeImportCSV(t *testing.T) {
+	t.Parallel()
+
+	raw := "\xEF\xBB\xBFName;Address;Lat;Lng;Amenities;Description;rating\n" +
+		"Зерно;Тверская 1;55,7601;37,6101;wifi| power;;4.5\n" +
+		"Broken;Арбат 2;north;37.59;;\"Тихое место\";\n"
+
+	payload, err := parseCafeImportPayload(CafeImportFormatCSV, []byte(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payload.Records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(payload.Records))
+	}
+
+	first := payload.Records[0]
+	if len(first.Issues) != 0 || first.Item.Name != "Зерно" {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	if first.Item.Latitude == nil || *first.Item.Latitude != 55.7601 || *first.Item.Longitude != 37.6101 {
+		t.Fatalf("decimal comma coordinates not parsed: %+v", first.Item)
+	}
+	if strings.Join(first.Item.Amenities, ",") != "wifi,power" {
+		t.Fatalf("unexpected amenities: %v", first.Item.Amenities)
+	}
+	if first.Item.Description == nil || *first.Item.Description != "" {
+		t.Fatalf("present description column must count as provided: %v", first.Item.Description)
+	}
+
+	second := payload.Records[1]
+	if len(second.Issues) != 1 || second.Issues[0].Field != "latitude" || second.Item.Latitude != nil {
+		t.Fatalf("expected latitude parse issue, got %+v", second)
+	}
+	if second.Item.Amenities == nil || len(second.Item.Amenities) != 0 {
+		t.Fatalf("empty amenities cell must be an explicit empty list: %#v", second.Item.Amenities)
+	}
+}
+
+func TestParseCafeImportCSVRequiresColumns(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseCafeImportPayload(CafeImportFormatCSV, []byte("name,address,latitude\nA,B,1\n"))
+	if err == nil || !strings.Contains(err.Error(), "longitude") {
+		t.Fatalf("expected missing longitude column error, got %v", err)
+	}
+}
+
+func TestParseCafeImportGeoJSON(t *testing.T) {
+	t.Parallel()
+
+	raw := `{"type":"FeatureCollection","features":[
+		{"type":"Feature","geometry":{"type":"Point","coordinates":[37.61,55.75]},
+		 "properties":{"name":"One","address":"Street 1","amenities":"wifi;quiet"}},
+		{"type":"Feature","geometry":{"type":"LineString","coordinates":[[37.6,55.7],[37.7,55.8]]},
+		 "properties":{"name":"Two","address":"Street 2","amenities":["power"]}}
+	]}`
+
+	payload, err := parseCafeImportPayload(CafeImportFormatGeoJSON, []byte(raw))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payload.Records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(payload.Records))
+	}
+
+	first := payload.Records[0].Item
+	if first.Latitude == nil || *first.Latitude != 55.75 || *first.Longitude != 37.61 {
+		t.Fatalf("coordinates must be read as [lng, lat]: %+v", first)
+	}
+	if strings.Join(first.Amenities, ",") != "wifi,quiet" {
+		t.Fatalf("unexpected amenities: %v", first.Amenities)
+	}
+
+	second := payload.Records[1]
+	if len(second.Issues) != 1 || second.Issues[0].Field != "geometry" {
+		t.Fatalf("expected geometry issue, got %+v", second.Issues)
+	}
+
+	if _, err := parseCafeImportPayload(CafeImportFormatGeoJSON, []byte(`{"type":"Feature"}`)); err == nil {
+		t.Fatalf("expected non-collection GeoJSON to be rejected")
+	}
+}
+
+func TestDetectCafeImportFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		explicit    string
+		contentType string
+		filename    string
+		body        string
+		expected    string
+	}{
+		{explicit: "GeoJSON", expected: CafeImportFormatGeoJSON},
+		{contentType: "text/csv; charset=utf-8", expected: CafeImportFormatCSV},
+		{filename: "cafes.geojson", body: "{}", expected: CafeImportFormatGeoJSON},
+		{body: ` {"type":"FeatureCollection","features":[]}`, expected: CafeImportFormatGeoJSON},
+		{body: `{"mode":"upsert","cafes":[]}`, expected: CafeImportFormatJSON},
+		{body: "name,address,latitude,longitude\n", expected: CafeImportFormatCSV},
+	}
+	for _, tc := range cases {
+		got, err := detectCafeImportFormat(tc.explicit, tc.contentType, tc.filename, []byte(tc.body))
+		if err != nil || got != tc.expected {
+			t.Fatalf("detect(%+v): expected %q, got %q (%v)", tc, tc.expected, got, err)
+		}
+	}
+
+	if _, err := detectCafeImportFormat("xlsx", "", "", nil); err == nil {
+		t.Fatalf("expected unknown format to be rejected")
+	}
+}
+
+type fakeImportTarget struct {
+	existingID string
+	failWrites bool
+	inserted   int
+	updated    int
+}
+
+func (f *fakeImportTarget) FindCafeByNameAddress(context.Context, string, string) (string, bool, error) {
+	return f.existingID, f.existingID != "", nil
+}
+
+func (f *fakeImportTarget) InsertCafe(context.Context, normalizedCafeImportItem) (string, error) {
+	if f.failWrites {
+		return "", errors.New("boom")
+	}
+	f.inserted++
+	return "new-id", nil
+}
+
+func (f *fakeImportTarget) UpdateCafeByID(context.Context, string, normalizedCafeImportItem) error {
+	if f.failWrites {
+		return errors.New("boom")
+	}
+	f.updated++
+	return nil
+}
+
+func TestImportCafeItemModes(t *testing.T) {
+	t.Parallel()
+
+	lat, lng := 55.75, 37.61
+	item := adminCafeImportItem{Name: "Cafe", Address: "Street 1", Latitude: &lat, Longitude: &lng}
+	ctx := context.Background()
+
+	cases := []struct {
+		name     string
+		target   *fakeImportTarget
+		mode     string
+		dryRun   bool
+		expected string
+	}{
+		{"create", &fakeImportTarget{}, AdminCafeImportModeSkipExisting, false, "created"},
+		{"dry create", &fakeImportTarget{}, AdminCafeImportModeUpsert, true, "would_create"},
+		{"skip existing", &fakeImportTarget{existingID: "c1"}, AdminCafeImportModeSkipExisting, false, "skipped"},
+		{"upsert", &fakeImportTarget{existingID: "c1"}, AdminCafeImportModeUpsert, false, "updated"},
+		{"dry upsert", &fakeImportTarget{existingID: "c1"}, AdminCafeImportModeUpsert, true, "would_update"},
+		{"failed write", &fakeImportTarget{failWrites: true}, AdminCafeImportModeSkipExisting, false, "failed"},
+	}
+	for _, tc := range cases {
+		result, issues := importCafeItem(ctx, tc.target, 7, item, nil, tc.mode, tc.dryRun)
+		if result.Status != tc.expected || result.Index != 7 {
+			t.Fatalf("%s: expected %q, got %+v", tc.name, tc.expected, result)
+		}
+		if tc.dryRun && tc.target.inserted+tc.target.updated != 0 {
+			t.Fatalf("%s: dry run must not write", tc.name)
+		}
+		if (tc.expected == "failed") != (len(issues) == 1) {
+			t.Fatalf("%s: unexpected issues %+v", tc.name, issues)
+		}
+	}
+}
+
+func TestImportCafeItemMergesParseIssues(t *testing.T) {
+	t.Parallel()
+
+	parseIssues := []adminCafeImportIssue{{Field: "geometry", Message: "bad geometry"}}
+	result, issues := importCafeItem(
+		context.Background(),
+		&fakeImportTarget{},
+		3,
+		adminCafeImportItem{Address: "Street 1"},
+		parseIssues,
+		AdminCafeImportModeSkipExisting,
+		false,
+	)
+	if result.Status != "invalid" || result.Message != "bad geometry" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	fields := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Index != 3 {
+			t.Fatalf("issue without index: %+v", issue)
+		}
+		fields = append(fields, issue.Field)
+	}
+	if strings.Join(fields, ",") != "geometry,name" {
+		t.Fatalf("expected geometry to cover coordinates, got %v", fields)
+	}
+}
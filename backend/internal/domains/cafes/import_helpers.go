@@ -2,11 +2,13 @@ package cafes
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"strings"
 
+	"backend/internal/model"
 	"backend/internal/shared/validation"
 )
 
@@ -19,6 +21,8 @@ type normalizedCafeImportItem struct {
 	HasDescription  bool
 	Amenities       []string
 	HasAmenitiesRaw bool
+	OpeningHours    *model.OpeningHours
+	HasOpeningHours bool
 }
 
 func decodeAdminCafeImportRequest(raw []byte) (adminCafeImportRequest, error) {
@@ -139,6 +143,19 @@ func normalizeCafeImportItem(raw adminCafeImportItem) (normalizedCafeImportItem,
 		amenities = filtered
 	}
 
+	var openingHours *model.OpeningHours
+	if raw.OpeningHours != nil {
+		normalizedHours, err := validation.NormalizeOpeningHours(*raw.OpeningHours)
+		if err != nil {
+			issues = append(issues, adminCafeImportIssue{
+				Field:   "opening_hours",
+				Message: err.Error(),
+			})
+		} else {
+			openingHours = &normalizedHours
+		}
+	}
+
 	if len(issues) > 0 {
 		return normalizedCafeImportItem{}, issues
 	}
@@ -152,9 +169,145 @@ func normalizeCafeImportItem(raw adminCafeImportItem) (normalizedCafeImportItem,
 		HasDescription:  hasDescription,
 		Amenities:       amenities,
 		HasAmenitiesRaw: raw.Amenities != nil,
+		OpeningHours:    openingHours,
+		HasOpeningHours: raw.OpeningHours != nil,
 	}, nil
 }
 
+// cafeImportTarget performs the lookups and writes of one import item.
+// ImportJSON writes through the pool with a transaction per cafe, import jobs
+// write inside the transaction of the batch they process.
+type cafeImportTarget interface {
+	FindCafeByNameAddress(ctx context.Context, name, address string) (string, bool, error)
+	InsertCafe(ctx context.Context, item normalizedCafeImportItem) (string, error)
+	UpdateCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem) error
+}
+
+// importCafeItem validates and applies one import item. parseIssues are
+// problems the source format already found in the row (e.g. a CSV cell that
+// is not a number); they make the item invalid and take precedence over
+// validation issues for the same field. Returned issues carry index.
+func importCafeItem(
+	ctx context.Context,
+	target cafeImportTarget,
+	index int,
+	raw adminCafeImportItem,
+	parseIssues []adminCafeImportIssue,
+	mode string,
+	dryRun bool,
+) (adminCafeImportResultItem, []adminCafeImportIssue) {
+	normalized, validationIssues := normalizeCafeImportItem(raw)
+	if len(parseIssues) > 0 {
+		validationIssues = mergeCafeImportIssues(parseIssues, validationIssues)
+	}
+	if len(validationIssues) > 0 {
+		for i := range validationIssues {
+			validationIssues[i].Index = index
+		}
+		return adminCafeImportResultItem{
+			Index:   index,
+			Status:  "invalid",
+			Name:    strings.TrimSpace(raw.Name),
+			Address: strings.TrimSpace(raw.Address),
+			Message: validationIssues[0].Message,
+		}, validationIssues
+	}
+
+	result := adminCafeImportResultItem{
+		Index:   index,
+		Name:    normalized.Name,
+		Address: normalized.Address,
+	}
+
+	existingID, found, err := target.FindCafeByNameAddress(ctx, normalized.Name, normalized.Address)
+	if err != nil {
+		result.Status = "failed"
+		result.Message = "Не удалось проверить существующую кофейню."
+		return result, []adminCafeImportIssue{{
+			Index:   index,
+			Message: "Ошибка проверки дубликатов в БД.",
+		}}
+	}
+
+	if found {
+		result.CafeID = &existingID
+		if mode == AdminCafeImportModeSkipExisting {
+			result.Status = "skipped"
+			result.Message = "Кофейня уже существует."
+			return result, nil
+		}
+		if dryRun {
+			result.Status = "would_update"
+			return result, nil
+		}
+		if err := target.UpdateCafeByID(ctx, existingID, normalized); err != nil {
+			result.Status = "failed"
+			result.Message = "Не удалось обновить кофейню."
+			return result, []adminCafeImportIssue{{
+				Index:   index,
+				Message: "Ошибка обновления кофейни в БД.",
+			}}
+		}
+		result.Status = "updated"
+		return result, nil
+	}
+
+	if dryRun {
+		result.Status = "would_create"
+		return result, nil
+	}
+
+	createdID, err := target.InsertCafe(ctx, normalized)
+	if err != nil {
+		result.Status = "failed"
+		result.Message = "Не удалось создать кофейню."
+		return result, []adminCafeImportIssue{{
+			Index:   index,
+			Message: "Ошибка создания кофейни в БД.",
+		}}
+	}
+	result.Status = "created"
+	result.CafeID = &createdID
+	return result, nil
+}
+
+// mergeCafeImportIssues keeps all parse issues and the validation issues of
+// fields the parser had no complaint about.
+func mergeCafeImportIssues(parseIssues, validationIssues []adminCafeImportIssue) []adminCafeImportIssue {
+	reported := make(map[string]struct{}, len(parseIssues))
+	merged := make([]adminCafeImportIssue, 0, len(parseIssues)+len(validationIssues))
+	for _, issue := range parseIssues {
+		reported[issue.Field] = struct{}{}
+		if issue.Field == "geometry" {
+			reported["latitude"] = struct{}{}
+			reported["longitude"] = struct{}{}
+		}
+		merged = append(merged, issue)
+	}
+	for _, issue := range validationIssues {
+		if _, ok := reported[issue.Field]; ok {
+			continue
+		}
+		merged = append(merged, issue)
+	}
+	return merged
+}
+
+func (s *adminCafeImportSummary) count(status string) {
+	switch status {
+	case "created", "would_create":
+		s.Created++
+	case "updated", "would_update":
+		s.Updated++
+	case "skipped":
+		s.Skipped++
+	case "invalid":
+		s.Invalid++
+	case "failed":
+		s.Failed++
+	}
+}
+
 func isFinite(value float64) bool {
 	return !math.IsInf(value, 0) && !math.IsNaN(value)
 }
@@ -0,0 +1,142 @@
+package cafes
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	CafeImportJobQueued    = "queued"
+	CafeImportJobRunning   = "running"
+	CafeImportJobCompleted = "completed"
+	CafeImportJobFailed    = "failed"
+	CafeImportJobCancelled = "cancelled"
+)
+
+const (
+	// importJobBatchSize items are applied per transaction; progress and
+	// results are committed together with the cafe writes, so a restarted
+	// worker resumes exactly after the last committed batch.
+	importJobBatchSize    = 100
+	importJobBatchTimeout = time.Minute
+	// importJobLease is how long a running job without heartbeat is left to
+	// its worker before another instance takes it over.
+	importJobLease = 2 * time.Minute
+	// importJobMaxAttempts bounds takeovers without a committed batch, so a
+	// batch that always fails ends the job instead of looping forever.
+	importJobMaxAttempts = 5
+
+	importJobListDefaultLimit = 20
+	importJobListMaxLimit     = 100
+)
+
+var errTooManyImportItems = errors.New("Слишком много элементов для импорта.")
+
+func isValidImportJobStatus(status string) bool {
+	switch status {
+	case CafeImportJobQueued, CafeImportJobRunning, CafeImportJobCompleted, CafeImportJobFailed, CafeImportJobCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+func importJobReference(jobID string) string {
+	return "import_job:" + jobID
+}
+
+// txImportTarget applies import items inside a batch transaction. Every
+// write runs in its own savepoint so a failing item does not abort the
+// batch.
+type txImportTarget struct {
+	tx pgx.Tx
+}
+
+func (t txImportTarget) FindCafeByNameAddress(ctx context.Context, name, address string) (string, bool, error) {
+	return findCafeByNameAddress(ctx, t.tx, name, address)
+}
+
+func (t txImportTarget) InsertCafe(ctx context.Context, item normalizedCafeImportItem) (string, error) {
+	var cafeID string
+	err := t.savepoint(ctx, func(tx pgx.Tx) error {
+		var err error
+		cafeID, err = insertCafe(ctx, tx, item)
+		return err
+	})
+	return cafeID, err
+}
+
+func (t txImportTarget) UpdateCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem) error {
+	return t.savepoint(ctx, func(tx pgx.Tx) error {
+		return updateCafeByID(ctx, tx, cafeID, item)
+	})
+}
+
+func (t txImportTarget) savepoint(ctx context.Context, fn func(tx pgx.Tx) error) error {
+	nested, err := t.tx.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(nested); err != nil {
+		_ = nested.Rollback(ctx)
+		return err
+	}
+	return nested.Commit(ctx)
+}
+
+// importJobBatch applies items of a job through target.
+func importJobBatch(
+	ctx context.Context,
+	target cafeImportTarget,
+	job cafeImportJobState,
+	items []cafeImportJobItem,
+) []cafeImportJobItemResult {
+	results := make([]cafeImportJobItemResult, 0, len(items))
+	for _, item := range items {
+		result, issues := importCafeItem(ctx, target, item.Index, item.Item, item.ParseIssues, job.Mode, job.DryRun)
+		results = append(results, cafeImportJobItemResult{Result: result, Issues: issues})
+	}
+	return results
+}
+
+func importJobProgress(processed, total int) float64 {
+	if total <= 0 {
+		return 1
+	}
+	return float64(processed) / float64(total)
+}
+
+var cafeImportIssuesCSVHeader = []string{"index", "status", "field", "message", "name", "address", "cafe_id"}
+
+// writeCafeImportIssuesCSV writes the issue report. It starts with a UTF-8
+// BOM so spreadsheet apps detect the encoding of Cyrillic messages.
+func writeCafeImportIssuesCSV(w io.Writer, issues []CafeImportJobIssue) error {
+	if _, err := w.Write(utf8BOM); err != nil {
+		return err
+	}
+	writer := csv.NewWriter(w)
+	if err := writer.Write(cafeImportIssuesCSVHeader); err != nil {
+		return err
+	}
+	for _, issue := range issues {
+		if err := writer.Write([]string{
+			strconv.Itoa(issue.Index),
+			issue.Status,
+			issue.Field,
+			issue.Message,
+			issue.Name,
+			issue.Address,
+			issue.CafeID,
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,42 @@
+package cafes
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/model"
+	"backend/internal/shared/validation"
+)
+
+const maxCafeStatusReasonChars = 500
+
+// normalizeCafeStatusRequest validates an admin status change. Soft deletion
+// goes through DELETE /api/admin/cafes/:id, not through this request.
+func normalizeCafeStatusRequest(req adminCafeStatusRequest, now time.Time) (cafeStatusUpdate, error) {
+	update := cafeStatusUpdate{
+		Status: strings.ToLower(strings.TrimSpace(req.Status)),
+		Reason: strings.TrimSpace(req.Reason),
+	}
+	switch update.Status {
+	case model.CafeStatusActive, model.CafeStatusTemporarilyClosed, model.CafeStatusPermanentlyClosed:
+	default:
+		return cafeStatusUpdate{}, errors.New("status должен быть active, temporarily_closed или permanently_closed.")
+	}
+	if len([]rune(update.Reason)) > maxCafeStatusReasonChars {
+		return cafeStatusUpdate{}, errors.New("Причина слишком длинная.")
+	}
+
+	if strings.TrimSpace(req.ReopensOn) == "" {
+		return update, nil
+	}
+	if update.Status != model.CafeStatusTemporarilyClosed {
+		return cafeStatusUpdate{}, errors.New("reopens_on можно указать только для временно закрытой кофейни.")
+	}
+	reopensOn, err := validation.ParseReopenDate(req.ReopensOn, now)
+	if err != nil {
+		return cafeStatusUpdate{}, err
+	}
+	update.ReopensOn = &reopensOn
+	return update, nil
+}
@@ -0,0 +1,50 @@
+package cafes
+
+import (
+	"testing"
+	"time"
+
+	"backend/internal/model"
+)
+
+func TestNormalizeCafeStatusRequest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC)
+
+	update, err := normalizeCafeStatusRequest(adminCafeStatusRequest{
+		Status:    "temporarily_closed",
+		ReopensOn: "2026-03-11",
+		Reason:    " ремонт ",
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if update.Status != model.CafeStatusTemporarilyClosed || update.Reason != "ремонт" {
+		t.Fatalf("unexpected update: %+v", update)
+	}
+	if update.ReopensOn == nil || update.ReopensOn.Format(time.DateOnly) != "2026-03-11" {
+		t.Fatalf("unexpected reopens_on: %v", update.ReopensOn)
+	}
+
+	update, err = normalizeCafeStatusRequest(adminCafeStatusRequest{Status: "ACTIVE"}, now)
+	if err != nil || update.Status != model.CafeStatusActive || update.ReopensOn != nil {
+		t.Fatalf("expected plain active update, got %+v, %v", update, err)
+	}
+
+	cases := []struct {
+		name string
+		req  adminCafeStatusRequest
+	}{
+		{name: "deleted via status", req: adminCafeStatusRequest{Status: model.CafeStatusDeleted}},
+		{name: "unknown status", req: adminCafeStatusRequest{Status: "paused"}},
+		{name: "reopen on permanent", req: adminCafeStatusRequest{Status: model.CafeStatusPermanentlyClosed, ReopensOn: "2026-04-01"}},
+		{name: "reopen today", req: adminCafeStatusRequest{Status: model.CafeStatusTemporarilyClosed, ReopensOn: "2026-03-10"}},
+		{name: "reopen too far", req: adminCafeStatusRequest{Status: model.CafeStatusTemporarilyClosed, ReopensOn: "2027-06-01"}},
+	}
+	for _, tc := range cases {
+		if _, err := normalizeCafeStatusRequest(tc.req, now); err == nil {
+			t.Fatalf("%s: expected error", tc.name)
+		}
+	}
+}
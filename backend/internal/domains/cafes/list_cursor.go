@@ -0,0 +1,211 @@
+package cafes
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/shared/pagination"
+)
+
+const (
+	cafeListCursorVersion = 1
+
+	cafeListCursorKeyset = "keyset"
+	cafeListCursorTaste  = "taste"
+
+	// maxCafeListTasteOffset bounds the window re-ranked for taste pages:
+	// every taste page re-reads all rows before it.
+	maxCafeListTasteOffset = 1000
+)
+
+// tasteRankingWindowSlack is how many rows past the page end are ranked so a
+// cafe boosted by taste personalization cannot jump in from the next page.
+// A match moves an item up by at most 1.2*tasteRankingBoostPositions slots.
+const tasteRankingWindowSlack = 4
+
+// cafeListKeyset is the position of a row in cafeListOrderClause order.
+type cafeListKeyset struct {
+	DistanceM      float64
+	ID             string
+	WorkScore      float64
+	RatingScore    float64
+	RelevanceScore float64
+}
+
+// cafeListKeysetClause continues cafeListOrderClause after the given keyset.
+// Parameters start at $15, see cafeListKeyset.args.
+var cafeListKeysetClause = map[string]string{
+	config.SortByDistance:  "(distance_m, id) > ($15::float8, $16::text)",
+	config.SortByWork:      "(work_score < $15::float8 OR (work_score = $15::float8 AND (distance_m, id) > ($16::float8, $17::text)))",
+	config.SortByRating:    "(rating_score < $15::float8 OR (rating_score = $15::float8 AND (distance_m, id) > ($16::float8, $17::text)))",
+	config.SortByRelevance: "(relevance_score < $15::float8 OR (relevance_score = $15::float8 AND (distance_m, id) > ($16::float8, $17::text)))",
+}
+
+// score is the leading sort key of the score-based orders.
+func (k cafeListKeyset) score(sortBy string) (float64, bool) {
+	switch sortBy {
+	case config.SortByWork:
+		return k.WorkScore, true
+	case config.SortByRating:
+		return k.RatingScore, true
+	case config.SortByRelevance:
+		return k.RelevanceScore, true
+	default:
+		return 0, false
+	}
+}
+
+func (k *cafeListKeyset) setScore(sortBy string, score float64) {
+	switch sortBy {
+	case config.SortByWork:
+		k.WorkScore = score
+	case config.SortByRating:
+		k.RatingScore = score
+	case config.SortByRelevance:
+		k.RelevanceScore = score
+	}
+}
+
+func (k cafeListKeyset) args(sortBy string) []interface{} {
+	if score, ok := k.score(sortBy); ok {
+		return []interface{}{score, k.DistanceM, k.ID}
+	}
+	return []interface{}{k.DistanceM, k.ID}
+}
+
+// cafeListCursor is the signed payload behind X-Next-Cursor. Distance and
+// score orderings page by keyset (WorkScore holds the score of whichever
+// score-based sort the cursor belongs to); taste-personalized lists re-rank a window
+// on every request and therefore page by offset within that ranking.
+// EvaluatedAt (unix seconds) pins is_open to the first page's time, since
+// work_score depends on it; cursors without it fall back to now.
+type cafeListCursor struct {
+	Version     int     `json:"v"`
+	Sort        string  `json:"s"`
+	Query       string  `json:"q"`
+	Mode        string  `json:"m"`
+	Offset      int     `json:"o,omitempty"`
+	DistanceM   float64 `json:"d,omitempty"`
+	ID          string  `json:"id,omitempty"`
+	WorkScore   float64 `json:"w,omitempty"`
+	EvaluatedAt int64   `json:"t,omitempty"`
+}
+
+// cafeListFingerprint pins a cursor to the filters it was issued for, so a
+// cursor from one map position is not replayed against another.
+func cafeListFingerprint(params ListParams) string {
+	amenities := append([]string(nil), params.RequiredAmenities...)
+	sort.Strings(amenities)
+	userID := ""
+	if params.UserID != nil {
+		userID = strings.TrimSpace(*params.UserID)
+	}
+	openAt := ""
+	if params.OpenAt != nil {
+		openAt = params.OpenAt.UTC().Format(time.RFC3339)
+	}
+	raw := fmt.Sprintf(
+		"%.6f|%.6f|%.1f|%s|%t|%t|%s|%s",
+		params.Latitude,
+		params.Longitude,
+		params.RadiusM,
+		strings.Join(amenities, ","),
+		params.FavoritesOnly,
+		params.OnlyOpen,
+		openAt,
+		userID,
+	)
+	// Appended only when set, so cursors issued before cities existed stay
+	// valid.
+	if params.City != "" {
+		raw += "|" + params.City
+	}
+	if params.Aspect != "" {
+		raw += fmt.Sprintf("|aspect:%s:%.2f", params.Aspect, params.MinAspectRating)
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeCafeListKeysetCursor(params ListParams, keyset cafeListKeyset) (string, error) {
+	cursor := cafeListCursor{
+		Version:   cafeListCursorVersion,
+		Sort:      params.SortBy,
+		Query:     cafeListFingerprint(params),
+		Mode:      cafeListCursorKeyset,
+		DistanceM: keyset.DistanceM,
+		ID:        keyset.ID,
+	}
+	if !params.EvaluatedAt.IsZero() {
+		cursor.EvaluatedAt = params.EvaluatedAt.Unix()
+	}
+	if score, ok := keyset.score(params.SortBy); ok {
+		cursor.WorkScore = score
+	}
+	return pagination.Default().Encode(cursor)
+}
+
+func encodeCafeListTasteCursor(params ListParams, offset int) (string, error) {
+	cursor := cafeListCursor{
+		Version: cafeListCursorVersion,
+		Sort:    params.SortBy,
+		Query:   cafeListFingerprint(params),
+		Mode:    cafeListCursorTaste,
+		Offset:  offset,
+	}
+	if !params.EvaluatedAt.IsZero() {
+		cursor.EvaluatedAt = params.EvaluatedAt.Unix()
+	}
+	return pagination.Default().Encode(cursor)
+}
+
+// applyCafeListCursor validates a client cursor against params and sets
+// either params.After or params.Offset.
+func applyCafeListCursor(params *ListParams, raw string) error {
+	var cursor cafeListCursor
+	if err := pagination.Default().Decode(raw, &cursor); err != nil {
+		if errors.Is(err, pagination.ErrCursorSignature) {
+			return errors.New("cursor устарел или повреждён, загрузите список заново")
+		}
+		return errors.New("cursor имеет некорректный формат")
+	}
+	if cursor.Version != cafeListCursorVersion {
+		return errors.New("cursor устарел или повреждён, загрузите список заново")
+	}
+	if cursor.Sort != params.SortBy {
+		return errors.New("cursor не соответствует выбранной сортировке")
+	}
+	if cursor.Query != cafeListFingerprint(*params) {
+		return errors.New("cursor не соответствует параметрам запроса")
+	}
+
+	switch cursor.Mode {
+	case cafeListCursorKeyset:
+		if strings.TrimSpace(cursor.ID) == "" {
+			return errors.New("cursor имеет некорректный формат")
+		}
+		after := &cafeListKeyset{
+			DistanceM: cursor.DistanceM,
+			ID:        cursor.ID,
+		}
+		after.setScore(params.SortBy, cursor.WorkScore)
+		params.After = after
+	case cafeListCursorTaste:
+		if cursor.Offset <= 0 || cursor.Offset > maxCafeListTasteOffset {
+			return errors.New("cursor содержит некорректное смещение")
+		}
+		params.Offset = cursor.Offset
+	default:
+		return errors.New("cursor имеет некорректный формат")
+	}
+	if cursor.EvaluatedAt > 0 {
+		params.EvaluatedAt = time.Unix(cursor.EvaluatedAt, 0)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package cafes
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+)
+
+func testListParams(sortBy string) ListParams {
+	return ListParams{
+		Latitude:          55.7558,
+		Longitude:         37.6173,
+		RadiusM:           2000,
+		RequiredAmenities: []string{"wifi", "power"},
+		SortBy:            sortBy,
+		Limit:             20,
+	}
+}
+
+func TestCafeListKeysetCursorRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	for _, sortBy := range []string{config.SortByDistance, config.SortByWork, config.SortByRating, config.SortByRelevance} {
+		params := testListParams(sortBy)
+		keyset := cafeListKeyset{
+			DistanceM:      812.4471290339,
+			ID:             "c1",
+			WorkScore:      7.1875529,
+			RatingScore:    4.37,
+			RelevanceScore: 0.6123456789,
+		}
+		token, err := encodeCafeListKeysetCursor(params, keyset)
+		if err != nil {
+			t.Fatalf("%s: unexpected encode error: %v", sortBy, err)
+		}
+
+		next := testListParams(sortBy)
+		next.RequiredAmenities = []string{"power", "wifi"}
+		if err := applyCafeListCursor(&next, token); err != nil {
+			t.Fatalf("%s: unexpected apply error: %v", sortBy, err)
+		}
+		if next.After == nil || next.After.DistanceM != keyset.DistanceM || next.After.ID != keyset.ID {
+			t.Fatalf("%s: unexpected keyset %+v", sortBy, next.After)
+		}
+		want, scored := keyset.score(sortBy)
+		if got, _ := next.After.score(sortBy); scored && got != want {
+			t.Fatalf("%s cursor lost its score: %+v", sortBy, next.After)
+		}
+		placeholders := map[string]bool{}
+		for _, match := range regexp.MustCompile(`\$\d+`).FindAllString(cafeListKeysetClause[sortBy], -1) {
+			placeholders[match] = true
+		}
+		if got := len(next.After.args(sortBy)); got != len(placeholders) {
+			t.Fatalf("%s: keyset args=%d, clause placeholders=%d", sortBy, got, len(placeholders))
+		}
+	}
+}
+
+func TestCafeListCursorPinsEvaluationTime(t *testing.T) {
+	t.Parallel()
+
+	params := testListParams(config.SortByWork)
+	params.EvaluatedAt = time.Date(2026, 3, 2, 21, 59, 30, 0, time.UTC)
+	token, err := encodeCafeListKeysetCursor(params, cafeListKeyset{DistanceM: 10, ID: "c1", WorkScore: 5})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	next := testListParams(config.SortByWork)
+	if err := applyCafeListCursor(&next, token); err != nil {
+		t.Fatalf("unexpected apply error: %v", err)
+	}
+	if !next.EvaluatedAt.Equal(params.EvaluatedAt) {
+		t.Fatalf("cursor evaluated_at=%v, want %v", next.EvaluatedAt, params.EvaluatedAt)
+	}
+}
+
+func TestCafeListTasteCursor(t *testing.T) {
+	t.Parallel()
+
+	params := testListParams(config.SortByDistance)
+	token, err := encodeCafeListTasteCursor(params, 40)
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	next := testListParams(config.SortByDistance)
+	if err := applyCafeListCursor(&next, token); err != nil {
+		t.Fatalf("unexpected apply error: %v", err)
+	}
+	if next.After != nil || next.Offset != 40 {
+		t.Fatalf("expected offset cursor, got after=%+v offset=%d", next.After, next.Offset)
+	}
+}
+
+func TestApplyCafeListCursorRejectsMismatch(t *testing.T) {
+	t.Parallel()
+
+	token, err := encodeCafeListKeysetCursor(testListParams(config.SortByDistance), cafeListKeyset{DistanceM: 10, ID: "c1"})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+
+	moved := testListParams(config.SortByDistance)
+	moved.Latitude = 59.9386
+	if err := applyCafeListCursor(&moved, token); err == nil || !strings.Contains(err.Error(), "параметрам запроса") {
+		t.Fatalf("expected fingerprint mismatch, got %v", err)
+	}
+
+	work := testListParams(config.SortByWork)
+	if err := applyCafeListCursor(&work, token); err == nil || !strings.Contains(err.Error(), "сортировке") {
+		t.Fatalf("expected sort mismatch, got %v", err)
+	}
+
+	tampered := testListParams(config.SortByDistance)
+	if err := applyCafeListCursor(&tampered, "eyJ2IjoxfQ.AAAAAAAAAAAAAAAAAAAAAA"); err == nil {
+		t.Fatalf("expected forged cursor to be rejected")
+	}
+}
@@ -0,0 +1,45 @@
+package cafes
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/model"
+)
+
+var cafeListOrderClause = map[string]string{
+	config.SortByDistance:  "distance_m asc, id asc",
+	config.SortByWork:      "work_score desc, distance_m asc, id asc",
+	config.SortByRating:    "rating_score desc, distance_m asc, id asc",
+	config.SortByRelevance: "relevance_score desc, distance_m asc, id asc",
+}
+
+// sqlCafeWorkScore ranks candidates for sort=work: work amenities and being
+// open right now dominate, while every kilometre away costs one point so a
+// nearby cafe with wifi is not buried under a perfect one across the city.
+const sqlCafeWorkScore = `(
+    case when amenities @> '{wifi}'::text[] then 3 else 0 end
+  + case when amenities @> '{power}'::text[] then 3 else 0 end
+  + case when amenities @> '{quiet}'::text[] then 2 else 0 end
+  + case when amenities @> '{laptop}'::text[] then 2 else 0 end
+  + case is_open when true then 2 when false then -5 else 0 end
+  - distance_m / 1000.0
+)::double precision`
+
+func decodeOpeningHours(raw []byte, timezone string) *model.OpeningHours {
+	if len(raw) == 0 {
+		return nil
+	}
+	var hours model.OpeningHours
+	if err := json.Unmarshal(raw, &hours); err != nil {
+		slog.Warn("skip malformed cafe opening hours", "error", err)
+		return nil
+	}
+	hours.Timezone = strings.TrimSpace(timezone)
+	if hours.Timezone == "" {
+		hours.Timezone = config.DefaultTimezone
+	}
+	return &hours
+}
@@ -0,0 +1,101 @@
+package cafes
+
+import (
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/model"
+	"backend/internal/shared/validation"
+)
+
+func TestNormalizeCafeImportItem_OpeningHours(t *testing.T) {
+	t.Parallel()
+
+	lat := 55.75
+	lng := 37.61
+	item := adminCafeImportItem{
+		Name:      "Cafe",
+		Address:   "Street 1",
+		Latitude:  &lat,
+		Longitude: &lng,
+		OpeningHours: &model.OpeningHours{
+			Weekly: map[string][]model.OpeningInterval{
+				"Friday": {{Open: "20:00", Close: "02:00"}, {Open: "8:00", Close: "14:00"}},
+				"sun":    {{Open: "10:00", Close: "24:00"}},
+			},
+			Exceptions: []model.OpeningHoursException{
+				{Date: "2026-12-31", Intervals: []model.OpeningInterval{{Open: "10:00", Close: "18:00"}}},
+				{Date: "2026-01-01", Closed: true},
+			},
+		},
+	}
+
+	normalized, issues := normalizeCafeImportItem(item)
+	if len(issues) > 0 {
+		t.Fatalf("expected no issues, got %+v", issues)
+	}
+	if !normalized.HasOpeningHours || normalized.OpeningHours == nil {
+		t.Fatal("expected opening hours to be kept")
+	}
+	hours := normalized.OpeningHours
+	if hours.Timezone != config.DefaultTimezone {
+		t.Fatalf("expected default timezone, got %q", hours.Timezone)
+	}
+	friday := hours.Weekly["fri"]
+	if len(friday) != 2 || friday[0].Open != "08:00" || friday[1].Close != "02:00" {
+		t.Fatalf("unexpected friday intervals: %+v", friday)
+	}
+	if hours.Exceptions[0].Date != "2026-01-01" {
+		t.Fatalf("expected exceptions sorted by date, got %+v", hours.Exceptions)
+	}
+}
+
+func TestNormalizeCafeImportItem_InvalidOpeningHours(t *testing.T) {
+	t.Parallel()
+
+	lat := 55.75
+	lng := 37.61
+	cases := map[string]model.OpeningHours{
+		"unknown day": {Weekly: map[string][]model.OpeningInterval{"funday": {{Open: "08:00", Close: "10:00"}}}},
+		"bad time":    {Weekly: map[string][]model.OpeningInterval{"mon": {{Open: "8am", Close: "10:00"}}}},
+		"overlap":     {Weekly: map[string][]model.OpeningInterval{"mon": {{Open: "08:00", Close: "12:00"}, {Open: "11:00", Close: "15:00"}}}},
+		"timezone":    {Timezone: "Mars/Olympus", Weekly: map[string][]model.OpeningInterval{}},
+		"closed+intervals": {Exceptions: []model.OpeningHoursException{
+			{Date: "2026-01-01", Closed: true, Intervals: []model.OpeningInterval{{Open: "10:00", Close: "12:00"}}},
+		}},
+	}
+
+	for name, hours := range cases {
+		hours := hours
+		item := adminCafeImportItem{
+			Name:         "Cafe",
+			Address:      "Street 1",
+			Latitude:     &lat,
+			Longitude:    &lng,
+			OpeningHours: &hours,
+		}
+		_, issues := normalizeCafeImportItem(item)
+		if len(issues) == 0 || issues[0].Field != "opening_hours" {
+			t.Fatalf("%s: expected opening_hours issue, got %+v", name, issues)
+		}
+	}
+}
+
+func TestEncodeOpeningHours_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	hours := &model.OpeningHours{
+		Timezone: "Europe/Samara",
+		Weekly: map[string][]model.OpeningInterval{
+			"mon": {{Open: "08:00", Close: "22:00"}},
+		},
+	}
+	raw, timezone, err := validation.EncodeOpeningHours(hours)
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	decoded := decodeOpeningHours(raw, timezone)
+	if decoded == nil || decoded.Timezone != "Europe/Samara" || len(decoded.Weekly["mon"]) != 1 {
+		t.Fatalf("unexpected decoded hours: %+v", decoded)
+	}
+}
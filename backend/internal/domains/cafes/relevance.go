@@ -0,0 +1,220 @@
+package cafes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/model"
+)
+
+// cafeRankSignals are the per-cafe inputs of sort=rating and sort=relevance,
+// returned with every list row so the order can be explained.
+type cafeRankSignals struct {
+	// RatingScore is the snapshot rating (already a Bayesian mean). Cafes
+	// without reviews get the mean rating of rated cafes, the Bayesian prior.
+	RatingScore   float64
+	ReviewsCount  int
+	VerifiedShare float64
+	// DistanceScore decays from 1 at the search point to 0.5 at the
+	// configured half-life distance.
+	DistanceScore float64
+	// Aspect is set when RatingScore is the rating of one review aspect
+	// (ListParams.Aspect); AspectReviewsCount is how many reviews scored it.
+	Aspect             string
+	AspectReviewsCount int
+}
+
+// ratedReviews is the number of reviews behind RatingScore.
+func (s cafeRankSignals) ratedReviews() int {
+	if s.Aspect != "" {
+		return s.AspectReviewsCount
+	}
+	return s.ReviewsCount
+}
+
+// relevanceWeights are the RankingConfig weights scaled to sum to one, so a
+// relevance score stays within [-taste, 1].
+type relevanceWeights struct {
+	Distance  float64
+	Rating    float64
+	Verified  float64
+	Taste     float64
+	HalfLifeM float64
+}
+
+func newRelevanceWeights(cfg config.RankingConfig) relevanceWeights {
+	weights := relevanceWeights{
+		Distance:  math.Max(cfg.DistanceWeight, 0),
+		Rating:    math.Max(cfg.RatingWeight, 0),
+		Verified:  math.Max(cfg.VerifiedWeight, 0),
+		Taste:     math.Max(cfg.TasteWeight, 0),
+		HalfLifeM: cfg.DistanceHalfLifeM,
+	}
+	sum := weights.Distance + weights.Rating + weights.Verified + weights.Taste
+	if sum <= 0 {
+		weights = relevanceWeights{Distance: 0.45, Rating: 0.3, Verified: 0.1, Taste: 0.15}
+		sum = 1
+	}
+	weights.Distance /= sum
+	weights.Rating /= sum
+	weights.Verified /= sum
+	weights.Taste /= sum
+	if weights.HalfLifeM <= 0 {
+		weights.HalfLifeM = 1000
+	}
+	return weights
+}
+
+// sqlArgs feeds the params CTE of QueryCafes ($12).
+func (w relevanceWeights) sqlArgs() []float64 {
+	return []float64{w.Distance, w.Rating, w.Verified, w.HalfLifeM}
+}
+
+// relevanceParts splits the SQL relevance score into its weighted components;
+// the sum equals sqlCafeRelevanceScore for the same row.
+type relevanceParts struct {
+	Distance float64
+	Rating   float64
+	Verified float64
+	Taste    float64
+}
+
+func (p relevanceParts) Total() float64 {
+	return p.Distance + p.Rating + p.Verified + p.Taste
+}
+
+func (w relevanceWeights) parts(signals cafeRankSignals) relevanceParts {
+	return relevanceParts{
+		Distance: w.Distance * signals.DistanceScore,
+		Rating:   w.Rating * (signals.RatingScore - 1) / 4,
+		Verified: w.Verified * signals.VerifiedShare,
+	}
+}
+
+// sqlCafeRelevanceScore must match relevanceWeights.parts.
+const sqlCafeRelevanceScore = `(
+    params.w_distance * distance_score
+  + params.w_rating * (rating_score - 1) / 4.0
+  + params.w_verified * verified_share
+)::double precision`
+
+// explainCafeRanking fills Explainability for the score-based sorts. signals
+// is parallel to items.
+func explainCafeRanking(items []model.CafeResponse, signals []cafeRankSignals, sortBy string, weights relevanceWeights) {
+	for i := range items {
+		if i >= len(signals) {
+			return
+		}
+		var text string
+		switch sortBy {
+		case config.SortByRating:
+			text = buildRatingExplainability(signals[i])
+		case config.SortByRelevance:
+			text = buildRelevanceExplainability(weights.parts(signals[i]), signals[i], false)
+		default:
+			continue
+		}
+		items[i].Explainability = &text
+	}
+}
+
+// applyRelevanceTaste adds the taste component to relevance scores and
+// re-sorts the window, returning the items with their final scores. Taste
+// moves a score by at most weights.Taste.
+func applyRelevanceTaste(
+	items []model.CafeResponse,
+	signals []cafeRankSignals,
+	weights relevanceWeights,
+	userSignals []userTasteSignal,
+	cafeTasteTokens map[string][]string,
+) ([]model.CafeResponse, []float64) {
+	type scoredItem struct {
+		item  model.CafeResponse
+		index int
+		score float64
+	}
+	scored := make([]scoredItem, 0, len(items))
+	for index, item := range items {
+		if index >= len(signals) {
+			break
+		}
+		match := calculateTasteMatch(cafeTasteTokens[strings.TrimSpace(item.ID)], userSignals)
+		parts := weights.parts(signals[index])
+		parts.Taste = weights.Taste * clampFloat(match.Score/1.25, -1, 1)
+		text := buildRelevanceExplainability(parts, signals[index], true)
+		if taste := buildTasteExplainability(match); taste != nil {
+			text += " " + *taste
+		}
+		item.Explainability = &text
+		scored = append(scored, scoredItem{item: item, index: index, score: parts.Total()})
+	}
+
+	sort.SliceStable(scored, func(i, j int) bool {
+		if !almostEqual(scored[i].score, scored[j].score) {
+			return scored[i].score > scored[j].score
+		}
+		return scored[i].index < scored[j].index
+	})
+	out := make([]model.CafeResponse, 0, len(scored))
+	scores := make([]float64, 0, len(scored))
+	for _, entry := range scored {
+		out = append(out, entry.item)
+		scores = append(scores, entry.score)
+	}
+	return out, scores
+}
+
+// relevanceWindowSettled reports whether the first end items of a re-ranked
+// window are final: a row past the window has a base score of at most
+// lastBase and gains at most weights.Taste, so it cannot overtake an item
+// scoring above that.
+func relevanceWindowSettled(scores []float64, end int, lastBase float64, weights relevanceWeights) bool {
+	if end <= 0 || len(scores) == 0 {
+		return true
+	}
+	if end > len(scores) {
+		end = len(scores)
+	}
+	return scores[end-1] > lastBase+weights.Taste || almostEqual(scores[end-1], lastBase+weights.Taste)
+}
+
+func buildRatingExplainability(signals cafeRankSignals) string {
+	if signals.Aspect != "" {
+		label := model.ReviewAspectLabel(signals.Aspect)
+		if signals.AspectReviewsCount == 0 {
+			return fmt.Sprintf("Оценок «%s» пока нет: учтён средний рейтинг %.2f.", label, signals.RatingScore)
+		}
+		return fmt.Sprintf("Оценка «%s» %.2f с поправкой на число оценок (оценок: %d).", label, signals.RatingScore, signals.AspectReviewsCount)
+	}
+	if signals.ReviewsCount == 0 {
+		return fmt.Sprintf("Отзывов пока нет: учтён средний рейтинг %.2f.", signals.RatingScore)
+	}
+	text := fmt.Sprintf("Рейтинг %.2f с поправкой на число отзывов (отзывов: %d", signals.RatingScore, signals.ReviewsCount)
+	if signals.VerifiedShare > 0 {
+		text += fmt.Sprintf(", с подтверждённым визитом: %d%%", int(math.Round(signals.VerifiedShare*100)))
+	}
+	return text + ")."
+}
+
+func buildRelevanceExplainability(parts relevanceParts, signals cafeRankSignals, withTaste bool) string {
+	ratingLabel := "рейтинг"
+	if signals.Aspect != "" {
+		ratingLabel = "оценка «" + model.ReviewAspectLabel(signals.Aspect) + "»"
+	}
+	components := []string{
+		fmt.Sprintf("расстояние %.2f", parts.Distance),
+		fmt.Sprintf("%s %.2f", ratingLabel, parts.Rating),
+		fmt.Sprintf("подтверждённые визиты %.2f", parts.Verified),
+	}
+	if withTaste {
+		components = append(components, fmt.Sprintf("вкус %+.2f", parts.Taste))
+	}
+	text := fmt.Sprintf("Релевантность %.2f: %s.", parts.Total(), strings.Join(components, ", "))
+	if signals.ratedReviews() == 0 {
+		text += " Отзывов пока нет, рейтинг взят средний."
+	}
+	return text
+}
@@ -0,0 +1,105 @@
+package cafes
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"backend/internal/config"
+	"backend/internal/model"
+)
+
+func TestNewRelevanceWeightsNormalizes(t *testing.T) {
+	t.Parallel()
+
+	weights := newRelevanceWeights(config.RankingConfig{
+		DistanceWeight:    2,
+		RatingWeight:      1,
+		VerifiedWeight:    1,
+		TasteWeight:       0,
+		DistanceHalfLifeM: 500,
+	})
+	if weights.Distance != 0.5 || weights.Rating != 0.25 || weights.Verified != 0.25 || weights.Taste != 0 {
+		t.Fatalf("unexpected weights: %+v", weights)
+	}
+	if weights.HalfLifeM != 500 {
+		t.Fatalf("half-life should be kept: %+v", weights)
+	}
+
+	fallback := newRelevanceWeights(config.RankingConfig{})
+	sum := fallback.Distance + fallback.Rating + fallback.Verified + fallback.Taste
+	if math.Abs(sum-1) > 1e-9 || fallback.HalfLifeM <= 0 {
+		t.Fatalf("zero config should fall back to defaults: %+v", fallback)
+	}
+}
+
+func TestExplainCafeRanking(t *testing.T) {
+	t.Parallel()
+
+	weights := newRelevanceWeights(config.RankingConfig{DistanceWeight: 0.5, RatingWeight: 0.5, DistanceHalfLifeM: 1000})
+	items := []model.CafeResponse{{ID: "a"}, {ID: "b"}}
+	signals := []cafeRankSignals{
+		{RatingScore: 4.6, ReviewsCount: 12, VerifiedShare: 0.5, DistanceScore: 0.8},
+		{RatingScore: 4.1, DistanceScore: 1},
+	}
+
+	explainCafeRanking(items, signals, config.SortByRelevance, weights)
+	if items[0].Explainability == nil || !strings.HasPrefix(*items[0].Explainability, "Релевантность 0.85:") {
+		t.Fatalf("unexpected relevance explanation: %v", items[0].Explainability)
+	}
+	if !strings.Contains(*items[1].Explainability, "Отзывов пока нет") {
+		t.Fatalf("unrated cafe should say so: %q", *items[1].Explainability)
+	}
+
+	explainCafeRanking(items, signals, config.SortByRating, weights)
+	if got := *items[0].Explainability; !strings.Contains(got, "4.60") || !strings.Contains(got, "50%") {
+		t.Fatalf("unexpected rating explanation: %q", got)
+	}
+
+	aspectSignals := []cafeRankSignals{
+		{RatingScore: 4.4, ReviewsCount: 12, Aspect: model.ReviewAspectCoffee, AspectReviewsCount: 5},
+		{RatingScore: 4.1, ReviewsCount: 3, Aspect: model.ReviewAspectCoffee},
+	}
+	explainCafeRanking(items, aspectSignals, config.SortByRating, weights)
+	if got := *items[0].Explainability; got != "Оценка «Кофе» 4.40 с поправкой на число оценок (оценок: 5)." {
+		t.Fatalf("unexpected aspect explanation: %q", got)
+	}
+	explainCafeRanking(items, aspectSignals, config.SortByRelevance, weights)
+	if got := *items[1].Explainability; !strings.Contains(got, "оценка «Кофе»") || !strings.Contains(got, "Отзывов пока нет") {
+		t.Fatalf("relevance should name the aspect and count its reviews: %q", got)
+	}
+
+	plain := []model.CafeResponse{{ID: "a"}}
+	explainCafeRanking(plain, signals[:1], config.SortByDistance, weights)
+	if plain[0].Explainability != nil {
+		t.Fatalf("distance sort must not be explained")
+	}
+}
+
+func TestApplyRelevanceTasteReordersWithinTasteWeight(t *testing.T) {
+	t.Parallel()
+
+	weights := newRelevanceWeights(config.RankingConfig{DistanceWeight: 0.8, TasteWeight: 0.2, DistanceHalfLifeM: 1000})
+	items := []model.CafeResponse{{ID: "near"}, {ID: "tasty"}}
+	signals := []cafeRankSignals{{DistanceScore: 1}, {DistanceScore: 0.9}}
+	userSignals := []userTasteSignal{{TasteCode: "espresso", Polarity: "positive", Score: 1, Confidence: 1}}
+	tokens := map[string][]string{"tasty": {"espresso"}}
+
+	ranked, scores := applyRelevanceTaste(items, signals, weights, userSignals, tokens)
+	if ranked[0].ID != "tasty" || ranked[1].ID != "near" {
+		t.Fatalf("taste match should lift the second cafe: %v, %v", ranked[0].ID, ranked[1].ID)
+	}
+	if scores[0] <= scores[1] || scores[0]-0.72 > weights.Taste+1e-9 {
+		t.Fatalf("unexpected scores: %v", scores)
+	}
+	if !strings.Contains(*ranked[0].Explainability, "вкус +") || !strings.Contains(*ranked[0].Explainability, "Под ваш вкус") {
+		t.Fatalf("taste should be explained: %q", *ranked[0].Explainability)
+	}
+
+	if !relevanceWindowSettled(scores, 1, 0.5, weights) {
+		t.Fatalf("top item beats anything past a window ending at 0.5")
+	}
+	if relevanceWindowSettled(scores, 2, 0.75, weights) {
+		t.Fatalf("a row past the window could still overtake the second item")
+	}
+}
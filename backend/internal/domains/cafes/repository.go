@@ -2,12 +2,18 @@ package cafes
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"strings"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/model"
+	"backend/internal/shared/cafeaudit"
+	"backend/internal/shared/validation"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -26,55 +32,170 @@ func NewRepository(pool *pgxpool.Pool) *Repository {
 	return &Repository{pool: pool}
 }
 
-func (r *Repository) QueryCafes(ctx context.Context, params ListParams, limits config.LimitsConfig) ([]model.CafeResponse, error) {
+// amenityFilter returns the predicate keeping cafes whose amenities column
+// holds every required key, bound to placeholder $argPos, and the value to
+// bind there. No required amenities binds null and matches every cafe.
+func amenityFilter(column string, argPos int, required []string) (string, []string) {
+	clause := fmt.Sprintf("($%[1]d::text[] is null or coalesce(%[2]s, '{}'::text[]) @> $%[1]d::text[])", argPos, column)
+	if len(required) == 0 {
+		return clause, nil
+	}
+	return clause, required
+}
+
+func (r *Repository) QueryCafes(
+	ctx context.Context,
+	params ListParams,
+	limits config.LimitsConfig,
+	weights relevanceWeights,
+) ([]model.CafeResponse, []cafeListKeyset, []cafeRankSignals, error) {
 	dbLimit := params.Limit
 	if dbLimit <= 0 {
 		dbLimit = limits.DefaultResults
 	}
 
-	var amenitiesParam []string
-	if len(params.RequiredAmenities) > 0 {
-		amenitiesParam = params.RequiredAmenities
-	}
+	amenityClause, amenitiesParam := amenityFilter("amenities", 4, params.RequiredAmenities)
 
 	var userIDArg any
 	if params.UserID != nil && strings.TrimSpace(*params.UserID) != "" {
 		userIDArg = strings.TrimSpace(*params.UserID)
 	}
 
-	const sqlDistance = `WITH params AS (
-  SELECT ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography AS p
+	sortBy := strings.TrimSpace(params.SortBy)
+	orderClause, ok := cafeListOrderClause[sortBy]
+	if !ok {
+		sortBy = config.DefaultSort
+		orderClause = cafeListOrderClause[sortBy]
+	}
+
+	openAt := params.EvaluatedAt
+	if openAt.IsZero() {
+		openAt = time.Now()
+	}
+	if params.OpenAt != nil {
+		openAt = *params.OpenAt
+	}
+
+	offset := params.Offset
+	if offset < 0 || params.After != nil {
+		offset = 0
+	}
+	keysetClause := "true"
+	var keysetArgs []interface{}
+	if params.After != nil {
+		keysetClause = cafeListKeysetClause[sortBy]
+		keysetArgs = params.After.args(sortBy)
+	}
+
+	query := fmt.Sprintf(`WITH params AS (
+  SELECT
+    ST_SetSRID(ST_MakePoint($2, $1), 4326)::geography AS p,
+    ($12::float8[])[1] AS w_distance,
+    ($12::float8[])[2] AS w_rating,
+    ($12::float8[])[3] AS w_verified,
+    ($12::float8[])[4] AS half_life_m,
+    (
+      SELECT COALESCE(avg(rating)::float8, 4.0)
+      FROM public.cafe_rating_snapshots
+      WHERE reviews_count > 0
+    ) AS mean_rating
+),
+candidates AS (
+  SELECT
+    id::text AS id,
+    name,
+    address,
+    COALESCE(description, '') AS description,
+    lat,
+    lng,
+    COALESCE(amenities, '{}'::text[]) AS amenities,
+    opening_hours,
+    timezone,
+    status,
+    to_char(reopens_on, 'YYYY-MM-DD') AS reopens_on,
+    CASE
+      WHEN status = 'active' THEN public.cafe_is_open_at(opening_hours, timezone, $8::timestamptz)
+      ELSE false
+    END AS is_open,
+    ST_Distance(geog, params.p) AS distance_m,
+    (fav.user_id is not null) as is_favorite,
+    COALESCE(
+      CASE
+        WHEN $13::text <> '' THEN (crs.components #>> ARRAY['aspect_ratings', $13::text, 'rating'])::float8
+        WHEN crs.reviews_count > 0 THEN crs.rating::float8
+      END,
+      params.mean_rating
+    ) AS rating_score,
+    COALESCE(crs.reviews_count, 0) AS reviews_count,
+    COALESCE((crs.components #>> ARRAY['aspect_ratings', $13::text, 'reviews_count'])::int, 0) AS aspect_reviews_count,
+    CASE
+      WHEN crs.reviews_count > 0 THEN crs.verified_reviews_count::float8 / crs.reviews_count
+      ELSE 0
+    END AS verified_share
+  FROM public.cafes
+  CROSS JOIN params
+  LEFT JOIN public.user_favorite_cafes fav
+    ON fav.cafe_id = cafes.id
+   AND fav.user_id = $6::uuid
+  LEFT JOIN public.cafe_rating_snapshots crs
+    ON crs.cafe_id = cafes.id
+  WHERE
+    geog IS NOT NULL
+    AND cafes.status IN ('active', 'temporarily_closed')
+    AND ($3 = 0 OR ST_DWithin(geog, params.p, $3))
+    AND %s
+    AND ($7::boolean = false OR fav.user_id IS NOT NULL)
+    AND ($11::text = '' OR cafes.city_id = (SELECT id FROM public.cities WHERE slug = $11::text))
+    AND (
+      $14::float8 <= 0
+      OR (crs.components #>> ARRAY['aspect_ratings', $13::text, 'rating'])::float8 >= $14::float8
+    )
+),
+filtered AS (
+  SELECT
+    candidates.*,
+    %s AS work_score,
+    exp(-ln(2.0) * distance_m / params.half_life_m)::double precision AS distance_score
+  FROM candidates
+  CROSS JOIN params
+  WHERE ($9::boolean = false OR is_open IS TRUE)
+),
+ranked AS (
+  SELECT
+    filtered.*,
+    %s AS relevance_score
+  FROM filtered
+  CROSS JOIN params
 )
 SELECT
-  id::text AS id,
+  id,
   name,
   address,
-  COALESCE(description, '') AS description,
+  description,
   lat,
   lng,
-  COALESCE(amenities, '{}'::text[]) AS amenities,
-  ST_Distance(geog, params.p) AS distance_m,
-  (fav.user_id is not null) as is_favorite
-FROM public.cafes
-CROSS JOIN params
-LEFT JOIN public.user_favorite_cafes fav
-  ON fav.cafe_id = cafes.id
- AND fav.user_id = $6::uuid
-WHERE
-  geog IS NOT NULL
-  AND ($3 = 0 OR ST_DWithin(geog, params.p, $3))
-  AND (
-    $4::text[] IS NULL
-    OR cardinality($4::text[]) = 0
-    OR COALESCE(amenities, '{}'::text[]) @> $4::text[]
-  )
-  AND ($7::boolean = false OR fav.user_id IS NOT NULL)
-ORDER BY distance_m ASC
-LIMIT $5;`
+  amenities,
+  opening_hours,
+  timezone,
+  status,
+  reopens_on,
+  is_open,
+  distance_m,
+  is_favorite,
+  work_score,
+  rating_score,
+  reviews_count,
+  aspect_reviews_count,
+  verified_share,
+  distance_score,
+  relevance_score
+FROM ranked
+WHERE %s
+ORDER BY %s
+LIMIT $5
+OFFSET $10;`, amenityClause, sqlCafeWorkScore, sqlCafeRelevanceScore, keysetClause, orderClause)
 
-	rows, err := r.pool.Query(
-		ctx,
-		sqlDistance,
+	args := []interface{}{
 		params.Latitude,
 		params.Longitude,
 		params.RadiusM,
@@ -82,24 +203,42 @@ LIMIT $5;`
 		dbLimit,
 		userIDArg,
 		params.FavoritesOnly,
-	)
+		openAt,
+		params.OnlyOpen,
+		offset,
+		params.City,
+		weights.sqlArgs(),
+		params.Aspect,
+		params.MinAspectRating,
+	}
+	rows, err := r.pool.Query(ctx, query, append(args, keysetArgs...)...)
 	if err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 	defer rows.Close()
 
 	out := make([]model.CafeResponse, 0, 32)
+	keysets := make([]cafeListKeyset, 0, 32)
+	signals := make([]cafeRankSignals, 0, 32)
 	for rows.Next() {
 		var (
-			id      string
-			name    string
-			address string
-			desc    string
-			latDB   float64
-			lngDB   float64
-			ams     []string
-			dist    float64
-			isFav   bool
+			id        string
+			name      string
+			address   string
+			desc      string
+			latDB     float64
+			lngDB     float64
+			ams       []string
+			hoursRaw  []byte
+			timezone  string
+			status    string
+			reopensOn *string
+			isOpen    *bool
+			dist      float64
+			isFav     bool
+			workScore float64
+			rank      cafeRankSignals
+			relevance float64
 		)
 
 		if err := rows.Scan(
@@ -110,10 +249,22 @@ LIMIT $5;`
 			&latDB,
 			&lngDB,
 			&ams,
+			&hoursRaw,
+			&timezone,
+			&status,
+			&reopensOn,
+			&isOpen,
 			&dist,
 			&isFav,
+			&workScore,
+			&rank.RatingScore,
+			&rank.ReviewsCount,
+			&rank.AspectReviewsCount,
+			&rank.VerifiedShare,
+			&rank.DistanceScore,
+			&relevance,
 		); err != nil {
-			return nil, err
+			return nil, nil, nil, err
 		}
 
 		var description *string
@@ -123,73 +274,286 @@ LIMIT $5;`
 		}
 
 		out = append(out, model.CafeResponse{
-			ID:          id,
-			Name:        name,
-			Address:     address,
-			Description: description,
-			Latitude:    latDB,
-			Longitude:   lngDB,
-			Amenities:   ams,
-			DistanceM:   dist,
-			IsFavorite:  isFav,
+			ID:           id,
+			Name:         name,
+			Address:      address,
+			Description:  description,
+			Latitude:     latDB,
+			Longitude:    lngDB,
+			Amenities:    ams,
+			OpeningHours: decodeOpeningHours(hoursRaw, timezone),
+			IsOpen:       isOpen,
+			Status:       status,
+			ReopensOn:    reopensOn,
+			DistanceM:    dist,
+			IsFavorite:   isFav,
+		})
+		keysets = append(keysets, cafeListKeyset{
+			DistanceM:      dist,
+			ID:             id,
+			WorkScore:      workScore,
+			RatingScore:    rank.RatingScore,
+			RelevanceScore: relevance,
 		})
+		rank.Aspect = params.Aspect
+		signals = append(signals, rank)
 	}
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, nil, nil, err
 	}
 
 	if params.Limit > 0 && len(out) > params.Limit {
 		out = out[:params.Limit]
+		keysets = keysets[:params.Limit]
+		signals = signals[:params.Limit]
 	}
 
-	return out, nil
+	return out, keysets, signals, nil
 }
 
-func (r *Repository) ListUserActiveTasteSignals(
-	ctx context.Context,
-	userID string,
-	limit int,
-) ([]userTasteSignal, error) {
-	if strings.TrimSpace(userID) == "" {
-		return []userTasteSignal{}, nil
+func (r *Repository) SearchCafes(ctx context.Context, params SearchParams) ([]model.CafeResponse, error) {
+	var userIDArg any
+	if params.UserID != nil && strings.TrimSpace(*params.UserID) != "" {
+		userIDArg = strings.TrimSpace(*params.UserID)
 	}
-	if limit <= 0 {
-		limit = 12
+	var latArg, lngArg any
+	if params.Latitude != nil && params.Longitude != nil {
+		latArg = *params.Latitude
+		lngArg = *params.Longitude
 	}
 
-	const query = `select
-	taste_code,
-	polarity,
-	score::double precision,
-	confidence::double precision
-from public.user_taste_tags
-where user_id = $1::uuid
-  and status = 'active'
-  and confidence > 0::numeric
-order by abs(score::double precision) * confidence::double precision desc, updated_at desc
-limit $2::int`
+	// The trigram operators in the prefilter compare against these
+	// thresholds; lowering them to searchMinTextScore keeps every row the
+	// final text_score cut would accept.
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	if _, err := tx.Exec(
+		ctx,
+		`select set_config('pg_trgm.similarity_threshold', $1::double precision::text, true),
+		        set_config('pg_trgm.word_similarity_threshold', $1::double precision::text, true)`,
+		searchMinTextScore,
+	); err != nil {
+		return nil, err
+	}
 
-	rows, err := r.pool.Query(ctx, query, strings.TrimSpace(userID), limit)
+	const query = `with params as (
+  select
+    $1::text[] as variants,
+    case
+      when $2::double precision is null or $3::double precision is null then null
+      else ST_SetSRID(ST_MakePoint($3::double precision, $2::double precision), 4326)::geography
+    end as p
+),
+candidates as (
+  select distinct c.id
+  from params
+  cross join unnest(params.variants) as v
+  join public.cafes c
+    on lower(c.name) % v
+    or v <% lower(c.name)
+    or lower(c.name) like '%' || v || '%'
+    or v <% lower(c.address)
+),
+scored as (
+  select
+    c.id,
+    case when params.p is null then 0 else ST_Distance(c.geog, params.p) end as distance_m,
+    (
+      select max(greatest(
+        similarity(v, lower(c.name)),
+        word_similarity(v, lower(c.name)),
+        case when strpos(lower(c.name), v) > 0 then 1.0 else 0 end,
+        0.7 * word_similarity(v, lower(coalesce(c.address, '')))
+      ))
+      from unnest(params.variants) as v
+    )::double precision as text_score,
+    params.p is not null as has_point
+  from candidates
+  join public.cafes c on c.id = candidates.id
+  cross join params
+  where c.geog is not null
+    and c.status <> 'deleted'
+),
+ranked as (
+  select
+    id,
+    distance_m,
+    case
+      when has_point then
+        (1 - $6::double precision) * text_score
+        + $6::double precision * ($7::double precision / ($7::double precision + distance_m))
+      else text_score
+    end as relevance
+  from scored
+  where text_score >= $5::double precision
+  order by relevance desc, distance_m asc, id asc
+  limit $8
+)
+select
+  c.id::text,
+  c.name,
+  coalesce(c.address, ''),
+  coalesce(c.description, ''),
+  c.lat,
+  c.lng,
+  coalesce(c.amenities, '{}'::text[]),
+  c.opening_hours,
+  c.timezone,
+  c.status,
+  to_char(c.reopens_on, 'YYYY-MM-DD'),
+  case when c.status = 'active' then public.cafe_is_open_at(c.opening_hours, c.timezone, now()) else false end,
+  ranked.distance_m,
+  (fav.user_id is not null)
+from ranked
+join public.cafes c on c.id = ranked.id
+left join public.user_favorite_cafes fav
+  on fav.cafe_id = c.id
+ and fav.user_id = $4::uuid
+order by ranked.relevance desc, ranked.distance_m asc, ranked.id asc`
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		params.Variants,
+		latArg,
+		lngArg,
+		userIDArg,
+		searchMinTextScore,
+		searchProximityWeight,
+		searchProximityHalfM,
+		params.Limit,
+	)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return []userTasteSignal{}, nil
-		}
 		return nil, err
 	}
 	defer rows.Close()
 
-	result := make([]userTasteSignal, 0, limit)
+	out := make([]model.CafeResponse, 0, params.Limit)
 	for rows.Next() {
-		var row userTasteSignal
-		if err := rows.Scan(&row.TasteCode, &row.Polarity, &row.Score, &row.Confidence); err != nil {
+		var (
+			item     model.CafeResponse
+			desc     string
+			hoursRaw []byte
+			timezone string
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Address,
+			&desc,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Amenities,
+			&hoursRaw,
+			&timezone,
+			&item.Status,
+			&item.ReopensOn,
+			&item.IsOpen,
+			&item.DistanceM,
+			&item.IsFavorite,
+		); err != nil {
 			return nil, err
 		}
-		row.TasteCode = strings.TrimSpace(strings.ToLower(row.TasteCode))
-		row.Polarity = strings.TrimSpace(strings.ToLower(row.Polarity))
-		if row.TasteCode == "" || row.Polarity == "" {
-			continue
+		desc = strings.TrimSpace(desc)
+		if desc != "" {
+			item.Description = &desc
 		}
-		result = append(result, row)
+		item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repository) QueryViewportClusters(
+	ctx context.Context,
+	bounds viewportBounds,
+	cellDegrees float64,
+	requiredAmenities []string,
+) ([]ViewportCluster, error) {
+	var amenitiesParam []string
+	if len(requiredAmenities) > 0 {
+		amenitiesParam = requiredAmenities
+	}
+
+	const query = `with cells as (
+  select
+    c.id,
+    c.lat,
+    c.lng,
+    crs.rating,
+    coalesce(crs.reviews_count, 0) as reviews_count,
+    floor(c.lng / $5::double precision) as cell_x,
+    floor(c.lat / $5::double precision) as cell_y
+  from public.cafes c
+  left join public.cafe_rating_snapshots crs on crs.cafe_id = c.id
+  where c.geog is not null
+    and c.status in ('active', 'temporarily_closed')
+    and c.lat between $1::double precision and $3::double precision
+    and c.lng between $2::double precision and $4::double precision
+    and (
+      $6::text[] is null
+      or cardinality($6::text[]) = 0
+      or coalesce(c.amenities, '{}'::text[]) @> $6::text[]
+    )
+)
+select
+  count(*)::int,
+  avg(lat)::double precision,
+  avg(lng)::double precision,
+  (max(rating) filter (where reviews_count > 0))::double precision,
+  case when count(*) = 1 then min(id::text) end,
+  min(lat)::double precision,
+  min(lng)::double precision,
+  max(lat)::double precision,
+  max(lng)::double precision
+from cells
+group by cell_x, cell_y
+order by count(*) desc, min(id::text) asc`
+
+	rows, err := r.pool.Query(
+		ctx,
+		query,
+		bounds.MinLat,
+		bounds.MinLng,
+		bounds.MaxLat,
+		bounds.MaxLng,
+		cellDegrees,
+		amenitiesParam,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]ViewportCluster, 0, 64)
+	for rows.Next() {
+		var item ViewportCluster
+		if err := rows.Scan(
+			&item.Count,
+			&item.Latitude,
+			&item.Longitude,
+			&item.BestRating,
+			&item.CafeID,
+			&item.MinLat,
+			&item.MinLng,
+			&item.MaxLat,
+			&item.MaxLng,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -197,136 +561,713 @@ limit $2::int`
 	return result, nil
 }
 
-func (r *Repository) ListCafeTasteTokens(
+// QueryViewportCafes returns up to limit cafes inside bounds, best rated
+// first, together with the total number of matches in the viewport.
+func (r *Repository) QueryViewportCafes(
 	ctx context.Context,
-	cafeIDs []string,
-) (map[string][]string, error) {
-	result := make(map[string][]string, len(cafeIDs))
-	if len(cafeIDs) == 0 {
-		return result, nil
+	params ViewportParams,
+	limit int,
+) ([]model.CafeResponse, int, error) {
+	var amenitiesParam []string
+	if len(params.RequiredAmenities) > 0 {
+		amenitiesParam = params.RequiredAmenities
+	}
+	var userIDArg any
+	if params.UserID != nil && strings.TrimSpace(*params.UserID) != "" {
+		userIDArg = strings.TrimSpace(*params.UserID)
 	}
+	centerLat, centerLng := params.Bounds.center()
 
 	const query = `select
-	c.id::text,
-	coalesce(array_remove(array_agg(distinct lower(trim(coalesce(t.tag->>'key', '')))), ''), '{}'::text[]) as tag_keys,
-	coalesce(array_remove(array_agg(distinct lower(trim(coalesce(t.tag->>'label', '')))), ''), '{}'::text[]) as tag_labels
+  c.id::text,
+  c.name,
+  coalesce(c.address, '') as address,
+  coalesce(c.description, '') as description,
+  c.lat,
+  c.lng,
+  coalesce(c.amenities, '{}'::text[]) as amenities,
+  c.opening_hours,
+  c.timezone,
+  c.status,
+  to_char(c.reopens_on, 'YYYY-MM-DD') as reopens_on,
+  case when c.status = 'active' then public.cafe_is_open_at(c.opening_hours, c.timezone, now()) else false end as is_open,
+  ST_Distance(c.geog, ST_SetSRID(ST_MakePoint($6::double precision, $5::double precision), 4326)::geography) as distance_m,
+  (fav.user_id is not null) as is_favorite,
+  count(*) over ()::int as total
 from public.cafes c
 left join public.cafe_rating_snapshots crs on crs.cafe_id = c.id
-left join lateral (
-	select tag
-	from jsonb_array_elements(coalesce(crs.components->'specific_tags', '[]'::jsonb)) as tag
-	union all
-	select tag
-	from jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
-) t on true
-where c.id = any($1::uuid[])
-group by c.id`
+left join public.user_favorite_cafes fav
+  on fav.cafe_id = c.id
+ and fav.user_id = $8::uuid
+where c.geog is not null
+  and c.status in ('active', 'temporarily_closed')
+  and c.lat between $1::double precision and $3::double precision
+  and c.lng between $2::double precision and $4::double precision
+  and (
+    $7::text[] is null
+    or cardinality($7::text[]) = 0
+    or coalesce(c.amenities, '{}'::text[]) @> $7::text[]
+  )
+order by coalesce(crs.rating, 0) desc, coalesce(crs.reviews_count, 0) desc, c.id asc
+limit $9`
 
-	rows, err := r.pool.Query(ctx, query, cafeIDs)
+	rows, err := r.pool.Query(
+		ctx,
+		query,
+		params.Bounds.MinLat,
+		params.Bounds.MinLng,
+		params.Bounds.MaxLat,
+		params.Bounds.MaxLng,
+		centerLat,
+		centerLng,
+		amenitiesParam,
+		userIDArg,
+		limit,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	out := make([]model.CafeResponse, 0, 64)
+	for rows.Next() {
+		var (
+			item     model.CafeResponse
+			desc     string
+			hoursRaw []byte
+			timezone string
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Address,
+			&desc,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Amenities,
+			&hoursRaw,
+			&timezone,
+			&item.Status,
+			&item.ReopensOn,
+			&item.IsOpen,
+			&item.DistanceM,
+			&item.IsFavorite,
+			&total,
+		); err != nil {
+			return nil, 0, err
+		}
+		desc = strings.TrimSpace(desc)
+		if desc != "" {
+			item.Description = &desc
+		}
+		item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return out, total, nil
+}
+
+// QueryRouteCafes returns cafes within widthM/2 of the route ordered by
+// where they project onto it. The projection is done in Web Mercator, which
+// keeps proportions along a city-sized route; the fraction is then scaled by
+// the geodesic route length.
+func (r *Repository) QueryRouteCafes(ctx context.Context, params RouteParams) ([]RouteCafe, error) {
+	amenityClause, amenitiesParam := amenityFilter("c.amenities", 3, params.RequiredAmenities)
+	var userIDArg any
+	if params.UserID != nil && strings.TrimSpace(*params.UserID) != "" {
+		userIDArg = strings.TrimSpace(*params.UserID)
+	}
+
+	query := fmt.Sprintf(`with route as (
+  select
+    g::geography as geog,
+    ST_Transform(g, 3857) as merc
+  from ST_GeomFromText($1::text, 4326) as g
+),
+candidates as (
+  select
+    c.id::text as id,
+    c.name,
+    coalesce(c.address, '') as address,
+    coalesce(c.description, '') as description,
+    c.lat,
+    c.lng,
+    coalesce(c.amenities, '{}'::text[]) as amenities,
+    c.opening_hours,
+    c.timezone,
+    c.status,
+    to_char(c.reopens_on, 'YYYY-MM-DD') as reopens_on,
+    case when c.status = 'active' then public.cafe_is_open_at(c.opening_hours, c.timezone, now()) else false end as is_open,
+    ST_Distance(c.geog, route.geog) as distance_m,
+    ST_LineLocatePoint(route.merc, ST_Transform(c.geog::geometry, 3857)) * ST_Length(route.geog) as position_m,
+    (fav.user_id is not null) as is_favorite
+  from public.cafes c
+  cross join route
+  left join public.user_favorite_cafes fav
+    on fav.cafe_id = c.id
+   and fav.user_id = $4::uuid
+  where c.geog is not null
+    and c.status in ('active', 'temporarily_closed')
+    and ST_DWithin(c.geog, route.geog, $2::double precision)
+    and %s
+)
+select
+  id,
+  name,
+  address,
+  description,
+  lat,
+  lng,
+  amenities,
+  opening_hours,
+  timezone,
+  status,
+  reopens_on,
+  is_open,
+  distance_m,
+  position_m,
+  is_favorite
+from candidates
+where ($5::boolean = false or is_open is true)
+order by position_m asc, distance_m asc, id asc
+limit $6`, amenityClause)
+
+	rows, err := r.pool.Query(
+		ctx,
+		query,
+		params.Route.WKT(),
+		params.WidthM/2,
+		amenitiesParam,
+		userIDArg,
+		params.OnlyOpen,
+		params.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
+	out := make([]RouteCafe, 0, params.Limit)
 	for rows.Next() {
 		var (
-			cafeID string
-			keys   []string
-			labels []string
+			item     RouteCafe
+			desc     string
+			hoursRaw []byte
+			timezone string
 		)
-		if err := rows.Scan(&cafeID, &keys, &labels); err != nil {
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Address,
+			&desc,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Amenities,
+			&hoursRaw,
+			&timezone,
+			&item.Status,
+			&item.ReopensOn,
+			&item.IsOpen,
+			&item.DistanceM,
+			&item.RoutePositionM,
+			&item.IsFavorite,
+		); err != nil {
 			return nil, err
 		}
-		merged := make([]string, 0, len(keys)+len(labels))
-		for _, raw := range keys {
-			token := normalizeTasteToken(raw)
-			if token == "" {
-				continue
-			}
-			merged = append(merged, token)
-		}
-		for _, raw := range labels {
-			token := normalizeTasteToken(raw)
-			if token == "" {
-				continue
-			}
-			merged = append(merged, token)
+		desc = strings.TrimSpace(desc)
+		if desc != "" {
+			item.Description = &desc
 		}
-		result[strings.TrimSpace(cafeID)] = dedupeTasteTokens(merged)
+		item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+		item.DetourM = routeDetourM(item.DistanceM)
+		out = append(out, item)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return result, nil
+	return out, nil
 }
 
-func (r *Repository) UpdateDescription(ctx context.Context, cafeID, description string) (string, error) {
-	var saved string
+func (r *Repository) GetCafeByID(ctx context.Context, cafeID string, userID *string) (model.CafeResponse, error) {
+	var userIDArg any
+	if userID != nil && strings.TrimSpace(*userID) != "" {
+		userIDArg = strings.TrimSpace(*userID)
+	}
+
+	var (
+		item     model.CafeResponse
+		desc     string
+		hoursRaw []byte
+		timezone string
+	)
 	err := r.pool.QueryRow(
 		ctx,
-		`update cafes
-		    set description = $2
-		  where id = $1::uuid
-		  returning COALESCE(description, '')`,
+		`select
+		    c.id::text,
+		    c.name,
+		    coalesce(c.address, '') as address,
+		    coalesce(c.description, '') as description,
+		    c.lat,
+		    c.lng,
+		    coalesce(c.amenities, '{}'::text[]) as amenities,
+		    c.opening_hours,
+		    c.timezone,
+		    c.status,
+		    to_char(c.reopens_on, 'YYYY-MM-DD') as reopens_on,
+		    case when c.status = 'active' then public.cafe_is_open_at(c.opening_hours, c.timezone, now()) else false end as is_open,
+		    (fav.user_id is not null) as is_favorite
+		   from public.cafes c
+		   left join public.user_favorite_cafes fav
+		     on fav.cafe_id = c.id
+		    and fav.user_id = $2::uuid
+		  where c.id = $1::uuid
+		    and c.status <> 'deleted'`,
 		cafeID,
-		description,
-	).Scan(&saved)
+		userIDArg,
+	).Scan(
+		&item.ID,
+		&item.Name,
+		&item.Address,
+		&desc,
+		&item.Latitude,
+		&item.Longitude,
+		&item.Amenities,
+		&hoursRaw,
+		&timezone,
+		&item.Status,
+		&item.ReopensOn,
+		&item.IsOpen,
+		&item.IsFavorite,
+	)
 	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(saved), nil
-}
-
-func (r *Repository) EnsureCafeExists(ctx context.Context, cafeID string) error {
-	var exists bool
-	if err := r.pool.QueryRow(ctx, `select exists(select 1 from cafes where id = $1::uuid)`, cafeID).Scan(&exists); err != nil {
-		return err
+		return model.CafeResponse{}, err
 	}
-	if !exists {
-		return pgx.ErrNoRows
+	desc = strings.TrimSpace(desc)
+	if desc != "" {
+		item.Description = &desc
 	}
-	return nil
+	item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+	return item, nil
 }
 
-func (r *Repository) FindCafeByNameAddress(ctx context.Context, name, address string) (string, bool, error) {
-	var cafeID string
+func (r *Repository) FindUserCafeReviewID(ctx context.Context, cafeID, userID string) (string, bool, error) {
+	var reviewID string
 	err := r.pool.QueryRow(
 		ctx,
 		`select id::text
-		   from cafes
-		  where lower(trim(name)) = lower(trim($1::text))
-		    and lower(trim(coalesce(address, ''))) = lower(trim($2::text))
-		  order by created_at asc
+		   from public.reviews
+		  where cafe_id = $1::uuid
+		    and user_id = $2::uuid
+		    and status = 'published'
 		  limit 1`,
-		name,
-		address,
-	).Scan(&cafeID)
+		cafeID,
+		userID,
+	).Scan(&reviewID)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return "", false, nil
 		}
 		return "", false, err
 	}
-	return strings.TrimSpace(cafeID), true, nil
+	return strings.TrimSpace(reviewID), true, nil
 }
 
-func (r *Repository) InsertCafe(ctx context.Context, item normalizedCafeImportItem) (string, error) {
-	amenities := item.Amenities
-	if amenities == nil {
-		amenities = []string{}
-	}
+// IsCafeOwner reports whether userID is a verified owner of the cafe.
+func (r *Repository) CityExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(
+		ctx,
+		`select exists(select 1 from public.cities where slug = $1)`,
+		slug,
+	).Scan(&exists)
+	return exists, err
+}
 
-	var cafeID string
+func (r *Repository) IsCafeOwner(ctx context.Context, cafeID, userID string) (bool, error) {
+	var owner bool
 	err := r.pool.QueryRow(
 		ctx,
-		`insert into cafes (name, address, description, lat, lng, amenities, geog)
-		 values (
-		   $1::text,
-		   $2::text,
+		`select exists(select 1 from public.cafe_owners where cafe_id = $1::uuid and user_id = $2::uuid)`,
+		cafeID,
+		userID,
+	).Scan(&owner)
+	return owner, err
+}
+
+func (r *Repository) ListUserActiveTasteSignals(
+	ctx context.Context,
+	userID string,
+	limit int,
+) ([]userTasteSignal, error) {
+	if strings.TrimSpace(userID) == "" {
+		return []userTasteSignal{}, nil
+	}
+	if limit <= 0 {
+		limit = 12
+	}
+
+	const query = `select
+	taste_code,
+	polarity,
+	score::double precision,
+	confidence::double precision
+from public.user_taste_tags
+where user_id = $1::uuid
+  and status = 'active'
+  and confidence > 0::numeric
+order by abs(score::double precision) * confidence::double precision desc, updated_at desc
+limit $2::int`
+
+	rows, err := r.pool.Query(ctx, query, strings.TrimSpace(userID), limit)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return []userTasteSignal{}, nil
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]userTasteSignal, 0, limit)
+	for rows.Next() {
+		var row userTasteSignal
+		if err := rows.Scan(&row.TasteCode, &row.Polarity, &row.Score, &row.Confidence); err != nil {
+			return nil, err
+		}
+		row.TasteCode = strings.TrimSpace(strings.ToLower(row.TasteCode))
+		row.Polarity = strings.TrimSpace(strings.ToLower(row.Polarity))
+		if row.TasteCode == "" || row.Polarity == "" {
+			continue
+		}
+		result = append(result, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QuerySimilarCandidates returns the source cafe first, followed by up to
+// limit active cafes within radiusM of it, nearest first. Nothing is
+// returned when the source does not exist or has no location.
+func (r *Repository) QuerySimilarCandidates(ctx context.Context, params SimilarParams, limit int) ([]similarCandidate, error) {
+	var userIDArg any
+	if params.UserID != nil && strings.TrimSpace(*params.UserID) != "" {
+		userIDArg = strings.TrimSpace(*params.UserID)
+	}
+
+	const query = `with source as (
+  select c.id, c.geog
+  from public.cafes c
+  where c.id = $1::uuid
+    and c.geog is not null
+    and c.status <> 'deleted'
+),
+picked as (
+  select c.id, ST_Distance(c.geog, source.geog) as distance_m
+  from source
+  join public.cafes c on c.id = source.id
+  union all
+  (
+    select c.id, ST_Distance(c.geog, source.geog) as distance_m
+    from source
+    join public.cafes c
+      on c.id <> source.id
+     and c.geog is not null
+     and c.status = 'active'
+     and ST_DWithin(c.geog, source.geog, $2::double precision)
+    order by ST_Distance(c.geog, source.geog) asc, c.id asc
+    limit $3
+  )
+)
+select
+  c.id::text,
+  c.name,
+  coalesce(c.address, ''),
+  coalesce(c.description, ''),
+  c.lat,
+  c.lng,
+  coalesce(c.amenities, '{}'::text[]),
+  c.opening_hours,
+  c.timezone,
+  c.status,
+  to_char(c.reopens_on, 'YYYY-MM-DD'),
+  case when c.status = 'active' then public.cafe_is_open_at(c.opening_hours, c.timezone, now()) else false end,
+  picked.distance_m,
+  (fav.user_id is not null),
+  coalesce(crs.rating::float8, 0),
+  coalesce(crs.reviews_count, 0),
+  coalesce((
+    select jsonb_object_agg(lower(trim(tag->>'label')), trim(tag->>'label'))
+    from jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
+    where trim(coalesce(tag->>'label', '')) <> ''
+  ), '{}'::jsonb)
+from picked
+join public.cafes c on c.id = picked.id
+left join public.cafe_rating_snapshots crs on crs.cafe_id = c.id
+left join public.user_favorite_cafes fav
+  on fav.cafe_id = c.id
+ and fav.user_id = $4::uuid
+order by (c.id = $1::uuid) desc, picked.distance_m asc, c.id asc`
+
+	rows, err := r.pool.Query(ctx, query, params.CafeID, params.RadiusM, limit, userIDArg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]similarCandidate, 0, limit+1)
+	for rows.Next() {
+		var (
+			item     similarCandidate
+			desc     string
+			hoursRaw []byte
+			timezone string
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Address,
+			&desc,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Amenities,
+			&hoursRaw,
+			&timezone,
+			&item.Status,
+			&item.ReopensOn,
+			&item.IsOpen,
+			&item.DistanceM,
+			&item.IsFavorite,
+			&item.Rating,
+			&item.ReviewsCount,
+			&item.Tags,
+		); err != nil {
+			return nil, err
+		}
+		desc = strings.TrimSpace(desc)
+		if desc != "" {
+			item.Description = &desc
+		}
+		item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ListAmenityLabels returns the Russian label of every amenity key.
+func (r *Repository) ListAmenityLabels(ctx context.Context) (map[string]string, error) {
+	rows, err := r.pool.Query(ctx, `select key, coalesce(nullif(labels->>'ru', ''), key) from public.amenities`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make(map[string]string, 16)
+	for rows.Next() {
+		var key, label string
+		if err := rows.Scan(&key, &label); err != nil {
+			return nil, err
+		}
+		out[key] = label
+	}
+	return out, rows.Err()
+}
+
+func (r *Repository) ListCafeTasteTokens(
+	ctx context.Context,
+	cafeIDs []string,
+) (map[string][]string, error) {
+	result := make(map[string][]string, len(cafeIDs))
+	if len(cafeIDs) == 0 {
+		return result, nil
+	}
+
+	const query = `select
+	c.id::text,
+	coalesce(array_remove(array_agg(distinct lower(trim(coalesce(t.tag->>'key', '')))), ''), '{}'::text[]) as tag_keys,
+	coalesce(array_remove(array_agg(distinct lower(trim(coalesce(t.tag->>'label', '')))), ''), '{}'::text[]) as tag_labels
+from public.cafes c
+left join public.cafe_rating_snapshots crs on crs.cafe_id = c.id
+left join lateral (
+	select tag
+	from jsonb_array_elements(coalesce(crs.components->'specific_tags', '[]'::jsonb)) as tag
+	union all
+	select tag
+	from jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
+) t on true
+where c.id = any($1::uuid[])
+group by c.id`
+
+	rows, err := r.pool.Query(ctx, query, cafeIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			cafeID string
+			keys   []string
+			labels []string
+		)
+		if err := rows.Scan(&cafeID, &keys, &labels); err != nil {
+			return nil, err
+		}
+		merged := make([]string, 0, len(keys)+len(labels))
+		for _, raw := range keys {
+			token := normalizeTasteToken(raw)
+			if token == "" {
+				continue
+			}
+			merged = append(merged, token)
+		}
+		for _, raw := range labels {
+			token := normalizeTasteToken(raw)
+			if token == "" {
+				continue
+			}
+			merged = append(merged, token)
+		}
+		result[strings.TrimSpace(cafeID)] = dedupeTasteTokens(merged)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// withCafeChange runs fn in a transaction whose cafe writes are attributed
+// to change in cafe_revisions.
+func (r *Repository) withCafeChange(ctx context.Context, change cafeaudit.Change, fn func(tx pgx.Tx) error) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	if err := cafeaudit.Tag(ctx, tx, change); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+func (r *Repository) UpdateDescription(ctx context.Context, cafeID, description string, change cafeaudit.Change) (string, error) {
+	var saved string
+	err := r.withCafeChange(ctx, change, func(tx pgx.Tx) error {
+		return tx.QueryRow(
+			ctx,
+			`update cafes
+			    set description = $2
+			  where id = $1::uuid
+			  returning COALESCE(description, '')`,
+			cafeID,
+			description,
+		).Scan(&saved)
+	})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(saved), nil
+}
+
+func (r *Repository) EnsureCafeExists(ctx context.Context, cafeID string) error {
+	var exists bool
+	if err := r.pool.QueryRow(ctx, `select exists(select 1 from cafes where id = $1::uuid)`, cafeID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// cafeQuerier is the part of pgxpool.Pool and pgx.Tx the cafe write helpers
+// need, so import jobs can run them inside their batch transaction.
+type cafeQuerier interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+func (r *Repository) FindCafeByNameAddress(ctx context.Context, name, address string) (string, bool, error) {
+	return findCafeByNameAddress(ctx, r.pool, name, address)
+}
+
+func (r *Repository) InsertCafe(ctx context.Context, item normalizedCafeImportItem, change cafeaudit.Change) (string, error) {
+	var cafeID string
+	err := r.withCafeChange(ctx, change, func(tx pgx.Tx) error {
+		var err error
+		cafeID, err = insertCafe(ctx, tx, item)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return cafeID, nil
+}
+
+func (r *Repository) UpdateCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem, change cafeaudit.Change) error {
+	return r.withCafeChange(ctx, change, func(tx pgx.Tx) error {
+		return updateCafeByID(ctx, tx, cafeID, item)
+	})
+}
+
+func findCafeByNameAddress(ctx context.Context, q cafeQuerier, name, address string) (string, bool, error) {
+	var cafeID string
+	err := q.QueryRow(
+		ctx,
+		`select id::text
+		   from cafes
+		  where lower(trim(name)) = lower(trim($1::text))
+		    and lower(trim(coalesce(address, ''))) = lower(trim($2::text))
+		  order by (status = 'deleted') asc, created_at asc
+		  limit 1`,
+		name,
+		address,
+	).Scan(&cafeID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return strings.TrimSpace(cafeID), true, nil
+}
+
+func insertCafe(ctx context.Context, q cafeQuerier, item normalizedCafeImportItem) (string, error) {
+	amenities := item.Amenities
+	if amenities == nil {
+		amenities = []string{}
+	}
+
+	hoursJSON, timezone, err := validation.EncodeOpeningHours(item.OpeningHours)
+	if err != nil {
+		return "", err
+	}
+
+	var cafeID string
+	err = q.QueryRow(
+		ctx,
+		`insert into cafes (name, address, description, lat, lng, amenities, opening_hours, timezone, geog)
+		 values (
+		   $1::text,
+		   $2::text,
 		   nullif($3::text, ''),
 		   $4::double precision,
 		   $5::double precision,
 		   $6::text[],
+		   $7::jsonb,
+		   $8::text,
 		   ST_SetSRID(ST_MakePoint($5::double precision, $4::double precision), 4326)::geography
 		 )
 		 returning id::text`,
@@ -336,6 +1277,8 @@ func (r *Repository) InsertCafe(ctx context.Context, item normalizedCafeImportIt
 		item.Latitude,
 		item.Longitude,
 		amenities,
+		hoursJSON,
+		timezone,
 	).Scan(&cafeID)
 	if err != nil {
 		return "", err
@@ -343,13 +1286,18 @@ func (r *Repository) InsertCafe(ctx context.Context, item normalizedCafeImportIt
 	return strings.TrimSpace(cafeID), nil
 }
 
-func (r *Repository) UpdateCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem) error {
+func updateCafeByID(ctx context.Context, q cafeQuerier, cafeID string, item normalizedCafeImportItem) error {
 	amenities := item.Amenities
 	if amenities == nil {
 		amenities = []string{}
 	}
 
-	result, err := r.pool.Exec(
+	hoursJSON, timezone, err := validation.EncodeOpeningHours(item.OpeningHours)
+	if err != nil {
+		return err
+	}
+
+	result, err := q.Exec(
 		ctx,
 		`update cafes
 		    set name = $2::text,
@@ -358,7 +1306,9 @@ func (r *Repository) UpdateCafeByID(ctx context.Context, cafeID string, item nor
 		        lng = $5::double precision,
 		        geog = ST_SetSRID(ST_MakePoint($5::double precision, $4::double precision), 4326)::geography,
 		        description = case when $6::boolean then nullif($7::text, '') else description end,
-		        amenities = case when $8::boolean then $9::text[] else amenities end
+		        amenities = case when $8::boolean then $9::text[] else amenities end,
+		        opening_hours = case when $10::boolean then $11::jsonb else opening_hours end,
+		        timezone = case when $10::boolean then $12::text else timezone end
 		  where id = $1::uuid`,
 		cafeID,
 		item.Name,
@@ -369,6 +1319,9 @@ func (r *Repository) UpdateCafeByID(ctx context.Context, cafeID string, item nor
 		item.Description,
 		item.HasAmenitiesRaw,
 		amenities,
+		item.HasOpeningHours,
+		hoursJSON,
+		timezone,
 	)
 	if err != nil {
 		return err
@@ -386,7 +1339,8 @@ func (r *Repository) SearchAdminCafesByName(ctx context.Context, query string, l
 		`select
 		    id::text,
 		    name,
-		    coalesce(address, '') as address
+		    coalesce(address, '') as address,
+		    status
 		  from public.cafes
 		  where name ilike $1
 		  order by
@@ -409,7 +1363,7 @@ func (r *Repository) SearchAdminCafesByName(ctx context.Context, query string, l
 	result := make([]AdminCafeSearchItem, 0, limit)
 	for rows.Next() {
 		var item AdminCafeSearchItem
-		if err := rows.Scan(&item.ID, &item.Name, &item.Address); err != nil {
+		if err := rows.Scan(&item.ID, &item.Name, &item.Address, &item.Status); err != nil {
 			return nil, err
 		}
 		result = append(result, item)
@@ -421,7 +1375,12 @@ func (r *Repository) SearchAdminCafesByName(ctx context.Context, query string, l
 }
 
 func (r *Repository) GetAdminCafeByID(ctx context.Context, cafeID string) (AdminCafeDetails, error) {
-	var item AdminCafeDetails
+	var (
+		item            AdminCafeDetails
+		hoursRaw        []byte
+		timezone        string
+		statusChangedAt *time.Time
+	)
 	err := r.pool.QueryRow(
 		ctx,
 		`select
@@ -431,7 +1390,13 @@ func (r *Repository) GetAdminCafeByID(ctx context.Context, cafeID string) (Admin
 		    coalesce(description, '') as description,
 		    lat,
 		    lng,
-		    coalesce(amenities, '{}'::text[]) as amenities
+		    coalesce(amenities, '{}'::text[]) as amenities,
+		    opening_hours,
+		    timezone,
+		    status,
+		    to_char(reopens_on, 'YYYY-MM-DD'),
+		    status_reason,
+		    status_changed_at
 		   from public.cafes
 		  where id = $1::uuid`,
 		cafeID,
@@ -443,6 +1408,12 @@ func (r *Repository) GetAdminCafeByID(ctx context.Context, cafeID string) (Admin
 		&item.Latitude,
 		&item.Longitude,
 		&item.Amenities,
+		&hoursRaw,
+		&timezone,
+		&item.Status,
+		&item.ReopensOn,
+		&item.StatusReason,
+		&statusChangedAt,
 	)
 	if err != nil {
 		return AdminCafeDetails{}, err
@@ -454,16 +1425,1162 @@ func (r *Repository) GetAdminCafeByID(ctx context.Context, cafeID string) (Admin
 	if item.Amenities == nil {
 		item.Amenities = []string{}
 	}
+	item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+	if statusChangedAt != nil {
+		formatted := statusChangedAt.UTC().Format(time.RFC3339)
+		item.StatusChangedAt = &formatted
+	}
 	return item, nil
 }
 
-func (r *Repository) DeleteCafeByID(ctx context.Context, cafeID string) error {
-	result, err := r.pool.Exec(ctx, `delete from public.cafes where id = $1::uuid`, cafeID)
+// SetCafeStatus moves a cafe to another lifecycle status. Reviews, photos and
+// favorites are kept for every status, so any transition can be undone.
+func (r *Repository) SetCafeStatus(ctx context.Context, cafeID string, update cafeStatusUpdate, change cafeaudit.Change) error {
+	return r.withCafeChange(ctx, change, func(tx pgx.Tx) error {
+		result, err := tx.Exec(
+			ctx,
+			`update public.cafes
+			    set status = $2::text,
+			        reopens_on = $3::date,
+			        status_reason = nullif($4::text, ''),
+			        status_changed_at = now()
+			  where id = $1::uuid`,
+			cafeID,
+			update.Status,
+			update.ReopensOn,
+			update.Reason,
+		)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return pgx.ErrNoRows
+		}
+		return nil
+	})
+}
+
+// ReopenDueCafes activates temporarily closed cafes whose reopen date has
+// come in the cafe's own timezone.
+func (r *Repository) ReopenDueCafes(ctx context.Context) (int64, error) {
+	var reopened int64
+	err := r.withCafeChange(ctx, cafeaudit.Change{
+		Source:    cafeaudit.SourceSystem,
+		Reference: "reopen_date",
+	}, func(tx pgx.Tx) error {
+		result, err := tx.Exec(
+			ctx,
+			`update public.cafes
+			    set status = 'active',
+			        reopens_on = null,
+			        status_reason = null,
+			        status_changed_at = now()
+			  where status = 'temporarily_closed'
+			    and reopens_on is not null
+			    and reopens_on <= (now() at time zone coalesce(nullif(timezone, ''), $1::text))::date`,
+			config.DefaultTimezone,
+		)
+		if err != nil {
+			return err
+		}
+		reopened = result.RowsAffected()
+		return nil
+	})
 	if err != nil {
-		return err
+		return 0, err
 	}
-	if result.RowsAffected() == 0 {
-		return pgx.ErrNoRows
-	}
-	return nil
+	return reopened, nil
+}
+
+// ScanDuplicateCandidates refreshes open duplicate candidates in one
+// statement: matching pairs are upserted, open pairs that no longer match are
+// dropped, dismissed pairs are left alone. Concurrent scans are serialized by
+// an advisory lock; the loser reports Skipped.
+func (r *Repository) ScanDuplicateCandidates(ctx context.Context) (CafeDuplicateScanResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return CafeDuplicateScanResult{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `select pg_try_advisory_xact_lock($1::bigint)`, int64(duplicateScanLockKey)).Scan(&locked); err != nil {
+		return CafeDuplicateScanResult{}, err
+	}
+	if !locked {
+		return CafeDuplicateScanResult{Skipped: true}, nil
+	}
+
+	const query = `WITH pairs AS (
+  SELECT
+    a.id AS cafe_id,
+    b.id AS duplicate_cafe_id,
+    ST_Distance(a.geog, b.geog) AS distance_m,
+    similarity(lower(a.name), lower(b.name)) AS name_similarity
+  FROM public.cafes a
+  JOIN public.cafes b
+    ON a.id < b.id
+   AND ST_DWithin(a.geog, b.geog, $1)
+  WHERE a.geog IS NOT NULL
+    AND b.geog IS NOT NULL
+    AND a.status <> 'deleted'
+    AND b.status <> 'deleted'
+),
+matched AS (
+  SELECT
+    cafe_id,
+    duplicate_cafe_id,
+    distance_m,
+    name_similarity,
+    ($5 * name_similarity + (1 - $5) * greatest(0, 1 - distance_m / $1))::real AS score
+  FROM pairs
+  WHERE name_similarity >= $2
+     OR (distance_m <= $3 AND name_similarity >= $4)
+),
+upserted AS (
+  INSERT INTO public.cafe_duplicate_candidates (cafe_id, duplicate_cafe_id, distance_m, name_similarity, score)
+  SELECT cafe_id, duplicate_cafe_id, distance_m, name_similarity, score
+  FROM matched
+  ON CONFLICT (cafe_id, duplicate_cafe_id) DO UPDATE
+     SET distance_m = EXCLUDED.distance_m,
+         name_similarity = EXCLUDED.name_similarity,
+         score = EXCLUDED.score,
+         updated_at = now()
+   WHERE cafe_duplicate_candidates.status = 'open'
+  RETURNING (xmax = 0) AS inserted
+),
+removed AS (
+  DELETE FROM public.cafe_duplicate_candidates c
+  WHERE c.status = 'open'
+    AND NOT EXISTS (
+      SELECT 1
+      FROM matched m
+      WHERE m.cafe_id = c.cafe_id
+        AND m.duplicate_cafe_id = c.duplicate_cafe_id
+    )
+  RETURNING 1
+)
+SELECT
+  (SELECT count(*) FROM matched)::int,
+  (SELECT count(*) FROM upserted WHERE inserted)::int,
+  (SELECT count(*) FROM removed)::int;`
+
+	var result CafeDuplicateScanResult
+	if err := tx.QueryRow(
+		ctx,
+		query,
+		duplicateScanRadiusM,
+		duplicateMinNameSimilarity,
+		duplicateNearRadiusM,
+		duplicateNearMinSimilarity,
+		duplicateNameWeight,
+	).Scan(&result.Found, &result.New, &result.Removed); err != nil {
+		return CafeDuplicateScanResult{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return CafeDuplicateScanResult{}, err
+	}
+	return result, nil
+}
+
+func (r *Repository) ListDuplicateCandidates(ctx context.Context, status string, limit int) ([]CafeDuplicateCandidate, error) {
+	const query = `SELECT
+  d.id,
+  d.status,
+  d.distance_m,
+  d.name_similarity::float8,
+  d.score::float8,
+  d.detected_at,
+  a.id::text, a.name, COALESCE(a.address, ''), a.lat, a.lng,
+  COALESCE(sa.reviews_count, 0),
+  (SELECT count(*) FROM public.cafe_photos p WHERE p.cafe_id = a.id)::int,
+  b.id::text, b.name, COALESCE(b.address, ''), b.lat, b.lng,
+  COALESCE(sb.reviews_count, 0),
+  (SELECT count(*) FROM public.cafe_photos p WHERE p.cafe_id = b.id)::int
+FROM public.cafe_duplicate_candidates d
+JOIN public.cafes a ON a.id = d.cafe_id
+JOIN public.cafes b ON b.id = d.duplicate_cafe_id
+LEFT JOIN public.cafe_rating_snapshots sa ON sa.cafe_id = a.id
+LEFT JOIN public.cafe_rating_snapshots sb ON sb.cafe_id = b.id
+WHERE d.status = $1
+ORDER BY d.score DESC, d.id DESC
+LIMIT $2;`
+
+	rows, err := r.pool.Query(ctx, query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CafeDuplicateCandidate, 0, limit)
+	for rows.Next() {
+		var (
+			item       CafeDuplicateCandidate
+			detectedAt time.Time
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.Status,
+			&item.DistanceM,
+			&item.NameSimilarity,
+			&item.Score,
+			&detectedAt,
+			&item.Cafe.ID,
+			&item.Cafe.Name,
+			&item.Cafe.Address,
+			&item.Cafe.Latitude,
+			&item.Cafe.Longitude,
+			&item.Cafe.ReviewsCount,
+			&item.Cafe.PhotosCount,
+			&item.Duplicate.ID,
+			&item.Duplicate.Name,
+			&item.Duplicate.Address,
+			&item.Duplicate.Latitude,
+			&item.Duplicate.Longitude,
+			&item.Duplicate.ReviewsCount,
+			&item.Duplicate.PhotosCount,
+		); err != nil {
+			return nil, err
+		}
+		item.DetectedAt = detectedAt.UTC().Format(time.RFC3339)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (r *Repository) DismissDuplicateCandidate(ctx context.Context, candidateID int64, actorID string) error {
+	result, err := r.pool.Exec(
+		ctx,
+		`update public.cafe_duplicate_candidates
+		    set status = 'dismissed',
+		        resolved_by = $2::uuid,
+		        updated_at = now()
+		  where id = $1`,
+		candidateID,
+		actorID,
+	)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// MergeCafes moves everything attached to sourceID onto targetID and deletes
+// the source cafe. When the same user reviewed both cafes the most recently
+// updated review survives (reviews are unique per user and cafe). The rating
+// of the target is recomputed asynchronously via cafeMergedEventType.
+func (r *Repository) MergeCafes(ctx context.Context, targetID, sourceID, actorID string) (CafeMergeResult, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return CafeMergeResult{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var locked int
+	if err := tx.QueryRow(
+		ctx,
+		`select count(*)::int from (
+			select id from public.cafes where id in ($1::uuid, $2::uuid) order by id for update
+		) locked`,
+		targetID,
+		sourceID,
+	).Scan(&locked); err != nil {
+		return CafeMergeResult{}, err
+	}
+	if locked != 2 {
+		return CafeMergeResult{}, pgx.ErrNoRows
+	}
+	if err := cafeaudit.Tag(ctx, tx, cafeaudit.Change{
+		Source:    cafeaudit.SourceMerge,
+		ActorID:   actorID,
+		Reference: "merge_from:" + sourceID,
+	}); err != nil {
+		return CafeMergeResult{}, err
+	}
+
+	var sourceSnapshot []byte
+	if err := tx.QueryRow(
+		ctx,
+		`select to_jsonb(c) - 'geog' from public.cafes c where c.id = $1::uuid`,
+		sourceID,
+	).Scan(&sourceSnapshot); err != nil {
+		return CafeMergeResult{}, err
+	}
+
+	result := CafeMergeResult{TargetID: targetID, SourceID: sourceID}
+	moved := &result.Moved
+	steps := []struct {
+		counter *int64
+		query   string
+	}{
+		{&moved.ReviewsDropped, `delete from public.reviews r
+		  using public.reviews o
+		  where r.user_id = o.user_id
+		    and (
+		      (r.cafe_id = $2::uuid and o.cafe_id = $1::uuid and r.updated_at <= o.updated_at)
+		      or (r.cafe_id = $1::uuid and o.cafe_id = $2::uuid and r.updated_at < o.updated_at)
+		    )`},
+		{&moved.Reviews, `update public.reviews set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		// Owner replies follow their reviews instead of cascading away with
+		// the source cafe.
+		{nil, `update public.review_replies set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		{&moved.VisitVerifications, `update public.visit_verifications set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		// Only one started check-in per user and cafe may exist.
+		{nil, `update public.review_checkins s
+		    set status = 'expired', updated_at = now()
+		  where s.cafe_id = $2::uuid
+		    and s.status = 'started'
+		    and exists (
+		      select 1 from public.review_checkins t
+		       where t.cafe_id = $1::uuid and t.user_id = s.user_id and t.status = 'started'
+		    )`},
+		{&moved.CheckIns, `update public.review_checkins set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		// Source photos go after the target ones and lose the cover flag when
+		// the target already has a cover of that kind.
+		{&moved.Photos, `update public.cafe_photos p
+		    set cafe_id = $1::uuid,
+		        position = p.position + coalesce((
+		          select max(t.position) + 1 from public.cafe_photos t
+		           where t.cafe_id = $1::uuid and t.kind = p.kind
+		        ), 0),
+		        is_cover = p.is_cover and not exists (
+		          select 1 from public.cafe_photos t
+		           where t.cafe_id = $1::uuid and t.kind = p.kind and t.is_cover
+		        )
+		  where p.cafe_id = $2::uuid`},
+		{&moved.Favorites, `insert into public.user_favorite_cafes (user_id, cafe_id, created_at)
+		  select user_id, $1::uuid, created_at
+		    from public.user_favorite_cafes
+		   where cafe_id = $2::uuid
+		  on conflict (user_id, cafe_id) do nothing`},
+		{&moved.RatingSnapshots, `update public.cafe_rating_snapshots
+		    set cafe_id = $1::uuid
+		  where cafe_id = $2::uuid
+		    and not exists (select 1 from public.cafe_rating_snapshots where cafe_id = $1::uuid)`},
+		{&moved.ModerationSubmissions, `update public.moderation_submissions
+		    set target_id = $1::uuid
+		  where target_id = $2::uuid
+		    and entity_type <> 'review'`},
+		{&moved.MetricsEvents, `update public.product_metrics_events set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		{nil, `update public.ai_summary_metrics set cafe_id = $1::uuid where cafe_id = $2::uuid`},
+		// Fill gaps on the survivor from the duplicate instead of losing data.
+		{nil, `update public.cafes t
+		    set amenities = (
+		          select coalesce(array_agg(distinct a order by a), '{}'::text[])
+		            from unnest(coalesce(t.amenities, '{}'::text[]) || coalesce(s.amenities, '{}'::text[])) a
+		        ),
+		        description = coalesce(nullif(trim(t.description), ''), s.description),
+		        address = coalesce(nullif(trim(t.address), ''), s.address),
+		        timezone = case when t.opening_hours is null and s.opening_hours is not null then s.timezone else t.timezone end,
+		        opening_hours = coalesce(t.opening_hours, s.opening_hours)
+		   from public.cafes s
+		  where t.id = $1::uuid and s.id = $2::uuid`},
+		// Verified owners of the duplicate keep owning the survivor.
+		{nil, `insert into public.cafe_owners (cafe_id, user_id, claim_submission_id, approved_by, created_at)
+		  select $1::uuid, user_id, claim_submission_id, approved_by, created_at
+		    from public.cafe_owners
+		   where cafe_id = $2::uuid
+		  on conflict (cafe_id, user_id) do nothing`},
+		// Keep OSM updates and removals flowing to the survivor; a cafe has
+		// at most one OSM link, so an already linked target keeps its own.
+		{nil, `update public.osm_cafe_links
+		    set cafe_id = $1::uuid
+		  where cafe_id = $2::uuid
+		    and not exists (select 1 from public.osm_cafe_links where cafe_id = $1::uuid)`},
+		{nil, `delete from public.cafes where id = $2::uuid`},
+	}
+	for _, step := range steps {
+		tag, err := tx.Exec(ctx, step.query, targetID, sourceID)
+		if err != nil {
+			return CafeMergeResult{}, err
+		}
+		if step.counter != nil {
+			*step.counter = tag.RowsAffected()
+		}
+	}
+
+	movedJSON, err := json.Marshal(result.Moved)
+	if err != nil {
+		return CafeMergeResult{}, err
+	}
+	var actorArg any
+	if strings.TrimSpace(actorID) != "" {
+		actorArg = actorID
+	}
+	if err := tx.QueryRow(
+		ctx,
+		`insert into public.cafe_merges (source_cafe_id, target_cafe_id, merged_by, source_snapshot, moved)
+		 values ($1::uuid, $2::uuid, $3::uuid, $4::jsonb, $5::jsonb)
+		 returning id`,
+		sourceID,
+		targetID,
+		actorArg,
+		sourceSnapshot,
+		movedJSON,
+	).Scan(&result.MergeID); err != nil {
+		return CafeMergeResult{}, err
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"merge_id":       result.MergeID,
+		"source_cafe_id": sourceID,
+	})
+	if err != nil {
+		return CafeMergeResult{}, err
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`insert into public.domain_events (event_type, aggregate_type, aggregate_id, dedupe_key, payload)
+		 values ($1, 'cafe', $2::uuid, $3, $4::jsonb)
+		 on conflict (dedupe_key) do nothing`,
+		cafeMergedEventType,
+		targetID,
+		fmt.Sprintf("%s:%d", cafeMergedEventType, result.MergeID),
+		payload,
+	); err != nil {
+		return CafeMergeResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return CafeMergeResult{}, err
+	}
+	return result, nil
+}
+
+// ListCafeRevisions returns revisions newest first; before > 0 continues a
+// previous page below that revision number.
+func (r *Repository) ListCafeRevisions(ctx context.Context, cafeID string, before, limit int) ([]CafeRevision, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`select
+		    r.revision,
+		    r.source,
+		    r.actor_user_id::text,
+		    nullif(trim(u.display_name), ''),
+		    r.reference,
+		    r.changed_fields,
+		    r.diff,
+		    r.created_at
+		   from public.cafe_revisions r
+		   left join public.users u on u.id = r.actor_user_id
+		  where r.cafe_id = $1::uuid
+		    and ($2::int <= 0 or r.revision < $2::int)
+		  order by r.revision desc
+		  limit $3`,
+		cafeID,
+		before,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CafeRevision, 0, limit)
+	for rows.Next() {
+		var (
+			item      CafeRevision
+			diffRaw   []byte
+			createdAt time.Time
+		)
+		if err := rows.Scan(
+			&item.Revision,
+			&item.Source,
+			&item.ActorUserID,
+			&item.ActorName,
+			&item.Reference,
+			&item.ChangedFields,
+			&diffRaw,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(diffRaw, &item.Diff); err != nil {
+			return nil, err
+		}
+		if item.ChangedFields == nil {
+			item.ChangedFields = []string{}
+		}
+		item.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RevertCafeToRevision restores the tracked fields from the snapshot of the
+// given revision. The restore itself is logged as a new 'revert' revision,
+// so history is never rewritten. Reverting to the current state is a no-op
+// and reports changed=false.
+func (r *Repository) RevertCafeToRevision(ctx context.Context, cafeID string, revision int, actorID string) (CafeRevertResult, error) {
+	result := CafeRevertResult{RevertedTo: revision}
+	err := r.withCafeChange(ctx, cafeaudit.Change{
+		Source:    cafeaudit.SourceRevert,
+		ActorID:   actorID,
+		Reference: revisionReference(revision),
+	}, func(tx pgx.Tx) error {
+		// The log trigger skips no-op updates, so the cafe changed exactly
+		// when the update produced a new revision.
+		var before int
+		if err := tx.QueryRow(
+			ctx,
+			`select (select max(r.revision) from public.cafe_revisions r where r.cafe_id = c.id)
+			   from public.cafes c
+			  where c.id = $1::uuid
+			    and exists (
+			      select 1 from public.cafe_revisions r where r.cafe_id = c.id and r.revision = $2
+			    )
+			  for update of c`,
+			cafeID,
+			revision,
+		).Scan(&before); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(
+			ctx,
+			`update public.cafes c
+			    set name = s.snapshot->>'name',
+			        address = s.snapshot->>'address',
+			        description = s.snapshot->>'description',
+			        lat = (s.snapshot->>'latitude')::double precision,
+			        lng = (s.snapshot->>'longitude')::double precision,
+			        geog = ST_SetSRID(ST_MakePoint(
+			          (s.snapshot->>'longitude')::double precision,
+			          (s.snapshot->>'latitude')::double precision
+			        ), 4326)::geography,
+			        amenities = array(select jsonb_array_elements_text(coalesce(s.snapshot->'amenities', '[]'::jsonb))),
+			        opening_hours = nullif(s.snapshot->'opening_hours', 'null'::jsonb),
+			        timezone = coalesce(s.snapshot->>'timezone', c.timezone),
+			        status = coalesce(s.snapshot->>'status', c.status),
+			        reopens_on = case
+			          when s.snapshot ? 'status' then (s.snapshot->>'reopens_on')::date
+			          else c.reopens_on
+			        end,
+			        status_reason = case
+			          when s.snapshot ? 'status_reason' then s.snapshot->>'status_reason'
+			          when s.snapshot ? 'status' and s.snapshot->>'status' is distinct from c.status then null
+			          else c.status_reason
+			        end,
+			        status_changed_at = case
+			          when s.snapshot ? 'status' and s.snapshot->>'status' is distinct from c.status then now()
+			          else c.status_changed_at
+			        end
+			   from public.cafe_revisions s
+			  where c.id = $1::uuid
+			    and s.cafe_id = c.id
+			    and s.revision = $2`,
+			cafeID,
+			revision,
+		); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(
+			ctx,
+			`select max(revision) from public.cafe_revisions where cafe_id = $1::uuid`,
+			cafeID,
+		).Scan(&result.Revision); err != nil {
+			return err
+		}
+		result.Changed = result.Revision > before
+		return nil
+	})
+	if err != nil {
+		return CafeRevertResult{}, err
+	}
+	return result, nil
+}
+
+const cafeImportJobColumns = `
+	j.id::text,
+	j.status,
+	j.format,
+	j.mode,
+	j.dry_run,
+	coalesce(j.source_name, ''),
+	j.total,
+	j.processed,
+	j.created_count,
+	j.updated_count,
+	j.skipped_count,
+	j.invalid_count,
+	j.failed_count,
+	coalesce(j.error, ''),
+	coalesce(j.created_by::text, ''),
+	j.created_at,
+	j.started_at,
+	j.finished_at`
+
+func scanCafeImportJob(row pgx.Row) (CafeImportJob, error) {
+	var (
+		job        CafeImportJob
+		createdAt  time.Time
+		startedAt  *time.Time
+		finishedAt *time.Time
+	)
+	if err := row.Scan(
+		&job.ID,
+		&job.Status,
+		&job.Format,
+		&job.Mode,
+		&job.DryRun,
+		&job.SourceName,
+		&job.Total,
+		&job.Processed,
+		&job.Summary.Created,
+		&job.Summary.Updated,
+		&job.Summary.Skipped,
+		&job.Summary.Invalid,
+		&job.Summary.Failed,
+		&job.Error,
+		&job.CreatedBy,
+		&createdAt,
+		&startedAt,
+		&finishedAt,
+	); err != nil {
+		return CafeImportJob{}, err
+	}
+	job.Summary.Total = job.Total
+	job.Progress = importJobProgress(job.Processed, job.Total)
+	job.CreatedAt = createdAt.UTC().Format(time.RFC3339)
+	if startedAt != nil {
+		value := startedAt.UTC().Format(time.RFC3339)
+		job.StartedAt = &value
+	}
+	if finishedAt != nil {
+		value := finishedAt.UTC().Format(time.RFC3339)
+		job.FinishedAt = &value
+	}
+	return job, nil
+}
+
+// CreateImportJob stores a parsed upload with all its records and queues it.
+func (r *Repository) CreateImportJob(ctx context.Context, input cafeImportJobInput) (string, error) {
+	recordsJSON, err := json.Marshal(input.Records)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var jobID string
+	if err := tx.QueryRow(
+		ctx,
+		`insert into public.cafe_import_jobs (format, mode, dry_run, source_name, total, created_by)
+		 values ($1, $2, $3, nullif($4::text, ''), $5, nullif($6::text, '')::uuid)
+		 returning id::text`,
+		input.Format,
+		input.Mode,
+		input.DryRun,
+		input.SourceName,
+		len(input.Records),
+		input.ActorID,
+	).Scan(&jobID); err != nil {
+		return "", err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`insert into public.cafe_import_job_items (job_id, item_index, payload, parse_issues)
+		 select $1::uuid,
+		        r.ord::int,
+		        r.value->'item',
+		        nullif(r.value->'issues', 'null'::jsonb)
+		   from jsonb_array_elements($2::jsonb) with ordinality as r(value, ord)`,
+		jobID,
+		recordsJSON,
+	); err != nil {
+		return "", err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return jobID, nil
+}
+
+func (r *Repository) GetImportJob(ctx context.Context, jobID string) (CafeImportJob, error) {
+	return scanCafeImportJob(r.pool.QueryRow(
+		ctx,
+		`select`+cafeImportJobColumns+`
+		   from public.cafe_import_jobs j
+		  where j.id = $1::uuid`,
+		jobID,
+	))
+}
+
+func (r *Repository) ListImportJobs(ctx context.Context, status string, limit int) ([]CafeImportJob, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`select`+cafeImportJobColumns+`
+		   from public.cafe_import_jobs j
+		  where ($1::text = '' or j.status = $1::text)
+		  order by j.created_at desc, j.id desc
+		  limit $2`,
+		status,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CafeImportJob, 0, limit)
+	for rows.Next() {
+		job, err := scanCafeImportJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ClaimImportJob takes the oldest queued job or a running job whose worker
+// stopped sending heartbeats. A job taken over too many times without a
+// committed batch is failed instead; the returned status tells which.
+func (r *Repository) ClaimImportJob(ctx context.Context, lease time.Duration, maxAttempts int) (string, string, bool, error) {
+	var jobID, status string
+	err := r.pool.QueryRow(
+		ctx,
+		`update public.cafe_import_jobs j
+		    set status = case when j.attempts >= $2 then 'failed' else 'running' end,
+		        error = case when j.attempts >= $2 then 'Импорт остановлен после повторных сбоев обработки.' else j.error end,
+		        finished_at = case when j.attempts >= $2 then now() else null end,
+		        attempts = j.attempts + 1,
+		        started_at = coalesce(j.started_at, now()),
+		        heartbeat_at = now()
+		  where j.id = (
+		        select c.id
+		          from public.cafe_import_jobs c
+		         where c.status = 'queued'
+		            or (c.status = 'running'
+		                and (c.heartbeat_at is null
+		                     or c.heartbeat_at < now() - make_interval(secs => $1::float8)))
+		         order by c.created_at asc
+		         limit 1
+		         for update skip locked
+		  )
+		  returning j.id::text, j.status`,
+		lease.Seconds(),
+		maxAttempts,
+	).Scan(&jobID, &status)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return "", "", false, nil
+		}
+		return "", "", false, err
+	}
+	return jobID, status, true, nil
+}
+
+// ReleaseImportJob hands a running job back right away, e.g. on shutdown,
+// instead of letting its lease run out.
+func (r *Repository) ReleaseImportJob(ctx context.Context, jobID string) error {
+	_, err := r.pool.Exec(
+		ctx,
+		`update public.cafe_import_jobs
+		    set heartbeat_at = null
+		  where id = $1::uuid
+		    and status = 'running'`,
+		jobID,
+	)
+	return err
+}
+
+// RunImportJobBatch applies the next batch of a running job in a single
+// transaction: cafe writes, item results, counters and the resume position
+// commit or roll back together. The job row stays locked for the batch, so
+// a concurrent cancel waits for it and a second worker cannot apply the
+// same items. It returns the job status after the batch.
+func (r *Repository) RunImportJobBatch(
+	ctx context.Context,
+	jobID string,
+	batchSize int,
+	apply func(ctx context.Context, target cafeImportTarget, job cafeImportJobState, items []cafeImportJobItem) []cafeImportJobItemResult,
+) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	job := cafeImportJobState{ID: jobID}
+	if err := tx.QueryRow(
+		ctx,
+		`select mode, dry_run, status, processed, total, coalesce(created_by::text, '')
+		   from public.cafe_import_jobs
+		  where id = $1::uuid
+		  for update`,
+		jobID,
+	).Scan(&job.Mode, &job.DryRun, &job.Status, &job.Processed, &job.Total, &job.CreatedBy); err != nil {
+		return "", err
+	}
+	if job.Status != CafeImportJobRunning {
+		return job.Status, tx.Commit(ctx)
+	}
+
+	if err := cafeaudit.Tag(ctx, tx, cafeaudit.Change{
+		Source:    cafeaudit.SourceImport,
+		ActorID:   job.CreatedBy,
+		Reference: importJobReference(jobID),
+	}); err != nil {
+		return "", err
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`select item_index, payload, parse_issues
+		   from public.cafe_import_job_items
+		  where job_id = $1::uuid
+		    and item_index > $2
+		  order by item_index asc
+		  limit $3`,
+		jobID,
+		job.Processed,
+		batchSize,
+	)
+	if err != nil {
+		return "", err
+	}
+	items := make([]cafeImportJobItem, 0, batchSize)
+	for rows.Next() {
+		var (
+			item      cafeImportJobItem
+			payload   []byte
+			issuesRaw []byte
+		)
+		if err := rows.Scan(&item.Index, &payload, &issuesRaw); err != nil {
+			rows.Close()
+			return "", err
+		}
+		if err := json.Unmarshal(payload, &item.Item); err != nil {
+			rows.Close()
+			return "", err
+		}
+		if len(issuesRaw) > 0 {
+			if err := json.Unmarshal(issuesRaw, &item.ParseIssues); err != nil {
+				rows.Close()
+				return "", err
+			}
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	var summary adminCafeImportSummary
+	processed := job.Processed
+	if len(items) > 0 {
+		results := apply(ctx, txImportTarget{tx: tx}, job, items)
+		type storedResult struct {
+			Index   int                    `json:"item_index"`
+			Status  string                 `json:"status"`
+			CafeID  *string                `json:"cafe_id"`
+			Message string                 `json:"message"`
+			Issues  []adminCafeImportIssue `json:"issues"`
+		}
+		stored := make([]storedResult, 0, len(results))
+		for _, result := range results {
+			summary.count(result.Result.Status)
+			stored = append(stored, storedResult{
+				Index:   result.Result.Index,
+				Status:  result.Result.Status,
+				CafeID:  result.Result.CafeID,
+				Message: result.Result.Message,
+				Issues:  result.Issues,
+			})
+		}
+		storedJSON, err := json.Marshal(stored)
+		if err != nil {
+			return "", err
+		}
+		if _, err := tx.Exec(
+			ctx,
+			`update public.cafe_import_job_items i
+			    set status = r.status,
+			        cafe_id = r.cafe_id::uuid,
+			        message = nullif(r.message, ''),
+			        issues = nullif(r.issues, 'null'::jsonb)
+			   from jsonb_to_recordset($2::jsonb)
+			        as r(item_index int, status text, cafe_id text, message text, issues jsonb)
+			  where i.job_id = $1::uuid
+			    and i.item_index = r.item_index`,
+			jobID,
+			storedJSON,
+		); err != nil {
+			return "", err
+		}
+		processed = items[len(items)-1].Index
+	}
+
+	done := len(items) < batchSize || processed >= job.Total
+	status := CafeImportJobRunning
+	if done {
+		status = CafeImportJobCompleted
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`update public.cafe_import_jobs
+		    set processed = $2,
+		        created_count = created_count + $3,
+		        updated_count = updated_count + $4,
+		        skipped_count = skipped_count + $5,
+		        invalid_count = invalid_count + $6,
+		        failed_count = failed_count + $7,
+		        status = $8,
+		        finished_at = case when $8 = 'completed' then now() else null end,
+		        attempts = 0,
+		        heartbeat_at = now()
+		  where id = $1::uuid`,
+		jobID,
+		processed,
+		summary.Created,
+		summary.Updated,
+		summary.Skipped,
+		summary.Invalid,
+		summary.Failed,
+		status,
+	); err != nil {
+		return "", err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+// CancelImportJob stops a queued or running job. Items already applied stay
+// applied. It reports false when the job had already finished.
+func (r *Repository) CancelImportJob(ctx context.Context, jobID string) (bool, error) {
+	tag, err := r.pool.Exec(
+		ctx,
+		`update public.cafe_import_jobs
+		    set status = 'cancelled',
+		        finished_at = now()
+		  where id = $1::uuid
+		    and status in ('queued', 'running')`,
+		jobID,
+	)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() > 0 {
+		return true, nil
+	}
+	var exists bool
+	if err := r.pool.QueryRow(
+		ctx,
+		`select exists(select 1 from public.cafe_import_jobs where id = $1::uuid)`,
+		jobID,
+	).Scan(&exists); err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, pgx.ErrNoRows
+	}
+	return false, nil
+}
+
+// ListImportJobIssues returns one line per issue of every invalid or failed
+// item, in source order.
+func (r *Repository) ListImportJobIssues(ctx context.Context, jobID string) ([]CafeImportJobIssue, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`select
+		    i.item_index,
+		    i.status,
+		    coalesce(issue->>'field', ''),
+		    coalesce(issue->>'message', i.message, ''),
+		    coalesce(i.payload->>'name', ''),
+		    coalesce(i.payload->>'address', ''),
+		    coalesce(i.cafe_id::text, '')
+		   from public.cafe_import_job_items i
+		   left join lateral jsonb_array_elements(coalesce(i.issues, '[]'::jsonb)) as issue on true
+		  where i.job_id = $1::uuid
+		    and i.status in ('invalid', 'failed')
+		  order by i.item_index asc`,
+		jobID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]CafeImportJobIssue, 0, 32)
+	for rows.Next() {
+		var issue CafeImportJobIssue
+		if err := rows.Scan(
+			&issue.Index,
+			&issue.Status,
+			&issue.Field,
+			&issue.Message,
+			&issue.Name,
+			&issue.Address,
+			&issue.CafeID,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, issue)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamCafeExport calls fn for every non-deleted cafe matching filter, in id
+// order, while rows are still being read. Photo URLs are raw object keys;
+// the caller resolves them against the media config.
+func (r *Repository) StreamCafeExport(ctx context.Context, filter CafeExportFilter, fn func(item CafeExportItem) error) error {
+	var bbox []float64
+	if filter.BBox != nil {
+		bbox = filter.BBox[:]
+	}
+	var amenities []string
+	if len(filter.Amenities) > 0 {
+		amenities = filter.Amenities
+	}
+
+	rows, err := r.pool.Query(
+		ctx,
+		`select
+		    c.id::text,
+		    c.name,
+		    coalesce(c.address, ''),
+		    coalesce(c.description, ''),
+		    c.lat,
+		    c.lng,
+		    coalesce(c.amenities, '{}'::text[]),
+		    c.opening_hours,
+		    c.timezone,
+		    c.status,
+		    rs.rating::float8,
+		    rs.reviews_count,
+		    rs.verified_reviews_count,
+		    u.updated_at,
+		    coalesce(ph.keys, '{}'::text[]),
+		    coalesce(ph.kinds, '{}'::text[]),
+		    coalesce(ph.covers, '{}'::boolean[])
+		   from public.cafes c
+		   left join public.cafe_rating_snapshots rs on rs.cafe_id = c.id
+		   cross join lateral (
+		        select greatest(c.created_at, coalesce(max(r.created_at), c.created_at)) as updated_at
+		          from public.cafe_revisions r
+		         where r.cafe_id = c.id
+		   ) u
+		   left join lateral (
+		        select array_agg(p.object_key order by p.kind, p.is_cover desc, p.position, p.created_at) as keys,
+		               array_agg(p.kind order by p.kind, p.is_cover desc, p.position, p.created_at) as kinds,
+		               array_agg(p.is_cover order by p.kind, p.is_cover desc, p.position, p.created_at) as covers
+		          from public.cafe_photos p
+		         where p.cafe_id = c.id
+		   ) ph on true
+		  where c.status <> 'deleted'
+		    and (
+		        $1::float8[] is null
+		        or (
+		            c.lat between ($1::float8[])[2] and ($1::float8[])[4]
+		            and case
+		                when ($1::float8[])[1] <= ($1::float8[])[3] then c.lng between ($1::float8[])[1] and ($1::float8[])[3]
+		                else c.lng >= ($1::float8[])[1] or c.lng <= ($1::float8[])[3]
+		            end
+		        )
+		    )
+		    and ($2::text[] is null or coalesce(c.amenities, '{}'::text[]) @> $2::text[])
+		    and ($3::timestamptz is null or u.updated_at >= $3::timestamptz)
+		  order by c.id`,
+		bbox,
+		amenities,
+		filter.UpdatedSince,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			item       CafeExportItem
+			hoursRaw   []byte
+			timezone   string
+			rating     *float64
+			reviews    *int
+			verified   *int
+			updatedAt  time.Time
+			photoKeys  []string
+			photoKinds []string
+			covers     []bool
+		)
+		if err := rows.Scan(
+			&item.ID,
+			&item.Name,
+			&item.Address,
+			&item.Description,
+			&item.Latitude,
+			&item.Longitude,
+			&item.Amenities,
+			&hoursRaw,
+			&timezone,
+			&item.Status,
+			&rating,
+			&reviews,
+			&verified,
+			&updatedAt,
+			&photoKeys,
+			&photoKinds,
+			&covers,
+		); err != nil {
+			return err
+		}
+		item.OpeningHours = decodeOpeningHours(hoursRaw, timezone)
+		if rating != nil && reviews != nil && verified != nil {
+			item.Rating = &CafeExportRating{
+				Rating:               *rating,
+				ReviewsCount:         *reviews,
+				VerifiedReviewsCount: *verified,
+			}
+		}
+		item.Photos = make([]CafeExportPhoto, 0, len(photoKeys))
+		for i, key := range photoKeys {
+			photo := CafeExportPhoto{URL: key}
+			if i < len(photoKinds) {
+				photo.Kind = photoKinds[i]
+			}
+			if i < len(covers) {
+				photo.IsCover = covers[i]
+			}
+			item.Photos = append(item.Photos, photo)
+		}
+		item.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
 }
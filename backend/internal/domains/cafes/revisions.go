@@ -0,0 +1,13 @@
+package cafes
+
+import "strconv"
+
+const (
+	revisionListDefaultLimit = 50
+	revisionListMaxLimit     = 200
+)
+
+// revisionReference links a revert revision to the revision it restored.
+func revisionReference(revision int) string {
+	return "revision:" + strconv.Itoa(revision)
+}
@@ -0,0 +1,44 @@
+package cafes
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAdminRevisionEndpointsRejectInvalidRequests(t *testing.T) {
+	t.Parallel()
+	gin.SetMode(gin.TestMode)
+
+	const cafeID = "5b0a1c52-3f4e-4d6a-9b8c-7d6e5f4a3b2c"
+	handler := NewHandler(nil)
+	router := gin.New()
+	router.GET("/admin/cafes/:id/revisions", handler.AdminListRevisions)
+	router.POST("/admin/cafes/:id/revisions/:revision/revert", handler.AdminRevertRevision)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		want   string
+	}{
+		{name: "list bad id", method: http.MethodGet, path: "/admin/cafes/abc/revisions", want: "Некорректный id кофейни"},
+		{name: "list bad limit", method: http.MethodGet, path: "/admin/cafes/" + cafeID + "/revisions?limit=500", want: "limit"},
+		{name: "list bad before", method: http.MethodGet, path: "/admin/cafes/" + cafeID + "/revisions?before=-1", want: "before"},
+		{name: "revert bad id", method: http.MethodPost, path: "/admin/cafes/abc/revisions/3/revert", want: "Некорректный id кофейни"},
+		{name: "revert bad revision", method: http.MethodPost, path: "/admin/cafes/" + cafeID + "/revisions/0/revert", want: "номер ревизии"},
+	}
+	for _, tc := range cases {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400, got %d", tc.name, rec.Code)
+		}
+		if !strings.Contains(rec.Body.String(), tc.want) {
+			t.Fatalf("%s: expected %q in %s", tc.name, tc.want, rec.Body.String())
+		}
+	}
+}
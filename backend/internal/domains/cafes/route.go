@@ -0,0 +1,162 @@
+package cafes
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"backend/internal/shared/geo"
+	"backend/internal/shared/validation"
+)
+
+const (
+	routeDefaultWidthM = 300.0
+	routeMinWidthM     = 20.0
+	routeMaxWidthM     = 2000.0
+	routeMaxPoints     = 5000
+	// routeMaxLengthM keeps the corridor query bounded: a longer route is
+	// not a walk or a ride across the city.
+	routeMaxLengthM = 100000.0
+	// routeMaxAbsLat is the Web Mercator limit; positions along the route are
+	// measured in that projection.
+	routeMaxAbsLat = 85.0
+)
+
+// routeLine is a validated route, points in travel order.
+type routeLine struct {
+	Lats []float64
+	Lngs []float64
+}
+
+// WKT renders the route as a 4326 LINESTRING (lng lat order).
+func (l routeLine) WKT() string {
+	var b strings.Builder
+	b.WriteString("LINESTRING(")
+	for i := range l.Lats {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(strconv.FormatFloat(l.Lngs[i], 'f', -1, 64))
+		b.WriteString(" ")
+		b.WriteString(strconv.FormatFloat(l.Lats[i], 'f', -1, 64))
+	}
+	b.WriteString(")")
+	return b.String()
+}
+
+// LengthM is the haversine length of the route.
+func (l routeLine) LengthM() float64 {
+	total := 0.0
+	for i := 1; i < len(l.Lats); i++ {
+		total += geo.HaversineM(l.Lats[i-1], l.Lngs[i-1], l.Lats[i], l.Lngs[i])
+	}
+	return total
+}
+
+func (l *routeLine) add(lat, lng float64) {
+	// Routers repeat a point at every step boundary; a zero-length segment
+	// only makes the line harder to work with.
+	if n := len(l.Lats); n > 0 && l.Lats[n-1] == lat && l.Lngs[n-1] == lng {
+		return
+	}
+	l.Lats = append(l.Lats, lat)
+	l.Lngs = append(l.Lngs, lng)
+}
+
+func (l routeLine) validate() error {
+	if len(l.Lats) < 2 {
+		return fmt.Errorf("Маршрут должен содержать минимум две разные точки.")
+	}
+	if len(l.Lats) > routeMaxPoints {
+		return fmt.Errorf("Маршрут должен содержать не больше %d точек.", routeMaxPoints)
+	}
+	for i := range l.Lats {
+		if !validation.IsFinite(l.Lats[i]) || math.Abs(l.Lats[i]) > routeMaxAbsLat ||
+			!validation.IsFinite(l.Lngs[i]) || l.Lngs[i] < -180 || l.Lngs[i] > 180 {
+			return fmt.Errorf("Точка маршрута %d вне допустимого диапазона координат.", i)
+		}
+	}
+	if l.LengthM() > routeMaxLengthM {
+		return fmt.Errorf("Маршрут длиннее %d км.", int(routeMaxLengthM/1000))
+	}
+	return nil
+}
+
+// decodePolyline decodes a Google encoded polyline. precision is the number
+// of decimals: 5 for Google and Yandex, 6 for OSRM/Valhalla.
+func decodePolyline(encoded string, precision int) (routeLine, error) {
+	factor := math.Pow10(precision)
+	var (
+		line     routeLine
+		lat, lng int64
+	)
+	for i := 0; i < len(encoded); {
+		var deltas [2]int64
+		for k := range deltas {
+			var (
+				result int64
+				shift  uint
+			)
+			for {
+				if i >= len(encoded) {
+					return routeLine{}, fmt.Errorf("Некорректная закодированная полилиния.")
+				}
+				b := int64(encoded[i]) - 63
+				i++
+				if b < 0 || b > 0x3f || shift > 60 {
+					return routeLine{}, fmt.Errorf("Некорректная закодированная полилиния.")
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[k] = ^(result >> 1)
+			} else {
+				deltas[k] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lng += deltas[1]
+		line.add(float64(lat)/factor, float64(lng)/factor)
+	}
+	return line, nil
+}
+
+type geoJSONLineString struct {
+	Type        string           `json:"type"`
+	Coordinates [][]float64      `json:"coordinates"`
+	Geometry    *json.RawMessage `json:"geometry,omitempty"`
+}
+
+// parseGeoJSONLine accepts a LineString geometry or a Feature wrapping one.
+func parseGeoJSONLine(raw json.RawMessage) (routeLine, error) {
+	var geometry geoJSONLineString
+	if err := json.Unmarshal(raw, &geometry); err != nil {
+		return routeLine{}, fmt.Errorf("geometry должен быть GeoJSON LineString.")
+	}
+	if geometry.Type == "Feature" && geometry.Geometry != nil {
+		return parseGeoJSONLine(*geometry.Geometry)
+	}
+	if geometry.Type != "LineString" {
+		return routeLine{}, fmt.Errorf("geometry должен быть GeoJSON LineString.")
+	}
+	var line routeLine
+	for _, position := range geometry.Coordinates {
+		if len(position) < 2 {
+			return routeLine{}, fmt.Errorf("Каждая точка LineString должна содержать долготу и широту.")
+		}
+		line.add(position[1], position[0])
+	}
+	return line, nil
+}
+
+// routeDetourM estimates the extra distance of stopping at a cafe: walking
+// from the route to it and back.
+func routeDetourM(distanceToRouteM float64) float64 {
+	return 2 * distanceToRouteM
+}
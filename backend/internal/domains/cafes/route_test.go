@@ -0,0 +1,93 @@
+package cafes
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDecodePolyline(t *testing.T) {
+	t.Parallel()
+
+	// The reference example from the encoded polyline format description.
+	line, err := decodePolyline("_p~iF~ps|U_ulLnnqC_mqNvxq`@", 5)
+	if err != nil {
+		t.Fatalf("decodePolyline: %v", err)
+	}
+	want := [][2]float64{{38.5, -120.2}, {40.7, -120.95}, {43.252, -126.453}}
+	if len(line.Lats) != len(want) {
+		t.Fatalf("got %d points, want %d", len(line.Lats), len(want))
+	}
+	for i, point := range want {
+		if math.Abs(line.Lats[i]-point[0]) > 1e-9 || math.Abs(line.Lngs[i]-point[1]) > 1e-9 {
+			t.Fatalf("point %d = (%v, %v), want %v", i, line.Lats[i], line.Lngs[i], point)
+		}
+	}
+
+	if _, err := decodePolyline("_p~iF~ps|U_ulL", 5); err == nil {
+		t.Fatal("expected an error for a truncated polyline")
+	}
+}
+
+func TestDecodePolylinePrecision6(t *testing.T) {
+	t.Parallel()
+
+	line, err := decodePolyline("_izlhA~rlgdF_{geC~ywl@", 6)
+	if err != nil {
+		t.Fatalf("decodePolyline: %v", err)
+	}
+	if len(line.Lats) != 2 || math.Abs(line.Lats[0]-38.5) > 1e-9 || math.Abs(line.Lngs[1]+120.95) > 1e-9 {
+		t.Fatalf("unexpected line: %+v", line)
+	}
+}
+
+func TestParseGeoJSONLine(t *testing.T) {
+	t.Parallel()
+
+	line, err := parseGeoJSONLine([]byte(`{"type":"Feature","properties":{},"geometry":{
+		"type":"LineString",
+		"coordinates":[[30.3158,59.9391],[30.3158,59.9391],[30.3350,59.9343],[30.3609,59.9311]]
+	}}`))
+	if err != nil {
+		t.Fatalf("parseGeoJSONLine: %v", err)
+	}
+	if len(line.Lats) != 3 {
+		t.Fatalf("repeated points should collapse, got %d points", len(line.Lats))
+	}
+	if line.Lats[0] != 59.9391 || line.Lngs[0] != 30.3158 {
+		t.Fatalf("GeoJSON is lng,lat; got first point (%v, %v)", line.Lats[0], line.Lngs[0])
+	}
+	if err := line.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if got := line.WKT(); got != "LINESTRING(30.3158 59.9391,30.335 59.9343,30.3609 59.9311)" {
+		t.Fatalf("WKT = %q", got)
+	}
+	if length := line.LengthM(); length < 2650 || length > 2720 {
+		t.Fatalf("LengthM = %v, want about 2.68 km", length)
+	}
+
+	if _, err := parseGeoJSONLine([]byte(`{"type":"Point","coordinates":[30.3,59.9]}`)); err == nil {
+		t.Fatal("expected an error for a Point")
+	}
+}
+
+func TestRouteLineValidate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name string
+		line routeLine
+		want string
+	}{
+		{"single point", routeLine{Lats: []float64{59.9}, Lngs: []float64{30.3}}, "минимум две"},
+		{"out of range", routeLine{Lats: []float64{59.9, 89}, Lngs: []float64{30.3, 30.3}}, "вне допустимого"},
+		{"too long", routeLine{Lats: []float64{59.9, 55.75}, Lngs: []float64{30.3, 37.6}}, "длиннее"},
+	}
+	for _, tc := range cases {
+		err := tc.line.validate()
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("%s: got %v, want error containing %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package cafes
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	searchMinQueryRunes = 2
+	searchMaxQueryRunes = 100
+	searchDefaultLimit  = 20
+	searchMaxLimit      = 50
+
+	// searchMinTextScore drops matches that share only a couple of trigrams.
+	searchMinTextScore = 0.25
+	// searchProximityWeight is the share of the final relevance given to
+	// distance when the client sends lat/lng; the rest is text similarity.
+	searchProximityWeight = 0.25
+	// searchProximityHalfM is the distance at which proximity drops to 0.5.
+	searchProximityHalfM = 3000.0
+)
+
+var cyrillicToLatin = map[rune]string{
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "e",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+}
+
+// latinToCyrillic is ordered longest digraph first so "shch" wins over "sh".
+var latinToCyrillic = []struct {
+	latin    string
+	cyrillic string
+}{
+	{"shch", "щ"}, {"sch", "щ"},
+	{"zh", "ж"}, {"kh", "х"}, {"ts", "ц"}, {"ch", "ч"}, {"sh", "ш"},
+	{"yu", "ю"}, {"ya", "я"}, {"yo", "ё"}, {"ee", "и"}, {"oo", "у"},
+	{"a", "а"}, {"b", "б"}, {"c", "к"}, {"d", "д"}, {"e", "е"}, {"f", "ф"},
+	{"g", "г"}, {"h", "х"}, {"i", "и"}, {"j", "дж"}, {"k", "к"}, {"l", "л"},
+	{"m", "м"}, {"n", "н"}, {"o", "о"}, {"p", "п"}, {"q", "к"}, {"r", "р"},
+	{"s", "с"}, {"t", "т"}, {"u", "у"}, {"v", "в"}, {"w", "в"}, {"x", "кс"},
+	{"y", "й"}, {"z", "з"},
+}
+
+// normalizeSearchQuery lowercases the query, folds ё into е and collapses
+// punctuation and whitespace into single spaces.
+func normalizeSearchQuery(raw string) string {
+	var b strings.Builder
+	lastSpace := true
+	for _, r := range strings.ToLower(raw) {
+		if r == 'ё' {
+			r = 'е'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			lastSpace = false
+			continue
+		}
+		if !lastSpace {
+			b.WriteRune(' ')
+			lastSpace = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// searchQueryVariants returns the normalized query plus its Cyrillic/Latin
+// transliterations, so "кофемания" also finds "Coffeemania" and vice versa.
+func searchQueryVariants(query string) []string {
+	normalized := normalizeSearchQuery(query)
+	if normalized == "" {
+		return nil
+	}
+	variants := []string{normalized}
+	seen := map[string]struct{}{normalized: {}}
+	for _, candidate := range []string{transliterateToLatin(normalized), transliterateToCyrillic(normalized)} {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		if _, ok := seen[candidate]; ok {
+			continue
+		}
+		seen[candidate] = struct{}{}
+		variants = append(variants, candidate)
+	}
+	return variants
+}
+
+func transliterateToLatin(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if latin, ok := cyrillicToLatin[r]; ok {
+			b.WriteString(latin)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func transliterateToCyrillic(value string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); {
+		matched := false
+		for _, pair := range latinToCyrillic {
+			if strings.HasPrefix(value[i:], pair.latin) {
+				b.WriteString(pair.cyrillic)
+				i += len(pair.latin)
+				matched = true
+				break
+			}
+		}
+		if matched {
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(value[i:])
+		b.WriteRune(r)
+		i += size
+	}
+	return b.String()
+}
@@ -0,0 +1,43 @@
+package cafes
+
+import "testing"
+
+func TestNormalizeSearchQuery(t *testing.T) {
+	t.Parallel()
+
+	got := normalizeSearchQuery("  Кофе-Ёжик,  №1! ")
+	if got != "кофе ежик 1" {
+		t.Fatalf("unexpected normalized query: %q", got)
+	}
+}
+
+func TestSearchQueryVariants_Transliteration(t *testing.T) {
+	t.Parallel()
+
+	variants := searchQueryVariants("Шоколадница")
+	if len(variants) < 2 {
+		t.Fatalf("expected transliterated variant, got %v", variants)
+	}
+	if variants[0] != "шоколадница" || variants[1] != "shokoladnitsa" {
+		t.Fatalf("unexpected variants: %v", variants)
+	}
+
+	variants = searchQueryVariants("Shokoladnitsa")
+	found := false
+	for _, variant := range variants {
+		if variant == "шоколадница" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected cyrillic variant, got %v", variants)
+	}
+}
+
+func TestSearchQueryVariants_Empty(t *testing.T) {
+	t.Parallel()
+
+	if variants := searchQueryVariants(" -- "); variants != nil {
+		t.Fatalf("expected no variants, got %v", variants)
+	}
+}
@@ -2,66 +2,191 @@ package cafes
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
+	"io"
 	"log/slog"
-	"net/http"
-	"net/url"
-	"strconv"
 	"strings"
 	"time"
 
 	"backend/internal/config"
 	"backend/internal/domains/photos"
+	"backend/internal/geocoding"
 	"backend/internal/model"
+	"backend/internal/shared/cafeaudit"
 
 	"github.com/jackc/pgx/v5"
 )
 
+// errCityNotFound is returned for a ?city= slug that matches no city, so the
+// list answers 404 instead of an empty page.
+var errCityNotFound = errors.New("Город не найден.")
+
 type Service struct {
 	repository             *Repository
 	cfg                    config.Config
-	client                 *http.Client
 	tasteMapRankingEnabled bool
+	reviews                cafeReviewsReader
+	geocoder               addressGeocoder
+	// importJobWake nudges the local import worker when a job is queued so
+	// it does not wait for its next poll.
+	importJobWake chan struct{}
+}
+
+// cafeReviewsReader is the slice of the reviews service used by the cafe
+// details endpoint. It is injected after construction because the reviews
+// domain is built separately in main.
+type cafeReviewsReader interface {
+	GetCafeRatingSnapshot(ctx context.Context, cafeID string) (map[string]interface{}, error)
+	ListCafeReviews(
+		ctx context.Context,
+		cafeID string,
+		sortBy string,
+		positionFilter string,
+		offset int,
+		limit int,
+	) ([]map[string]interface{}, []map[string]interface{}, bool, int, error)
+}
+
+// addressGeocoder is the shared geocoding chain, injected by main so every
+// caller goes through the same cache and rate limits.
+type addressGeocoder interface {
+	Geocode(ctx context.Context, query string) (geocoding.Result, error)
+	Reverse(ctx context.Context, lat, lng float64) (geocoding.Result, error)
 }
 
 func NewService(repository *Repository, cfg config.Config) *Service {
 	return &Service{
-		repository: repository,
-		cfg:        cfg,
-		client: &http.Client{
-			Timeout: cfg.Geocoding.Timeout,
-		},
+		repository:             repository,
+		cfg:                    cfg,
 		tasteMapRankingEnabled: TasteMapRankingEnabledFromEnv(),
+		importJobWake:          make(chan struct{}, 1),
 	}
 }
 
-func (s *Service) List(ctx context.Context, params ListParams) ([]model.CafeResponse, error) {
-	items, err := s.repository.QueryCafes(ctx, params, s.cfg.Limits)
-	if err != nil {
-		return nil, err
+func (s *Service) List(ctx context.Context, params ListParams) (CafeListPage, error) {
+	limit := params.Limit
+	if limit <= 0 {
+		limit = s.cfg.Limits.DefaultResults
 	}
-	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, items, s.cfg.Media); err != nil {
-		return nil, err
+	if limit <= 0 {
+		return CafeListPage{Items: []model.CafeResponse{}}, nil
 	}
 
-	if !s.tasteMapRankingEnabled || params.UserID == nil {
-		return items, nil
+	if params.City != "" {
+		exists, err := s.repository.CityExists(ctx, params.City)
+		if err != nil {
+			return CafeListPage{}, err
+		}
+		if !exists {
+			return CafeListPage{}, errCityNotFound
+		}
 	}
-	userID := strings.TrimSpace(*params.UserID)
-	if userID == "" {
-		return items, nil
+
+	if params.EvaluatedAt.IsZero() {
+		params.EvaluatedAt = time.Now().Truncate(time.Second)
 	}
 
-	userSignals, err := s.repository.ListUserActiveTasteSignals(ctx, userID, 12)
+	if params.After == nil {
+		if userSignals := s.loadTasteSignals(ctx, params.UserID); len(userSignals) > 0 {
+			return s.listTasteRanked(ctx, params, limit, userSignals)
+		}
+	}
+
+	weights := newRelevanceWeights(s.cfg.Ranking)
+	query := params
+	query.Limit = limit + 1
+	items, keysets, signals, err := s.repository.QueryCafes(ctx, query, s.cfg.Limits, weights)
 	if err != nil {
-		slog.Warn("taste ranking fallback to distance order: failed to load user signals", "user_id", userID, "error", err)
-		return items, nil
+		return CafeListPage{}, err
 	}
-	if len(userSignals) == 0 {
-		return items, nil
+	explainCafeRanking(items, signals, params.SortBy, weights)
+	page := CafeListPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor, err = encodeCafeListKeysetCursor(params, keysets[limit-1])
+		if err != nil {
+			return CafeListPage{}, err
+		}
+	}
+	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, page.Items, s.cfg.Media); err != nil {
+		return CafeListPage{}, err
+	}
+	return page, nil
+}
+
+// listTasteRanked re-ranks everything up to the end of the requested page
+// (plus tasteRankingWindowSlack) and cuts the page out of that ranking.
+// Personalization only depends on an item's base position, so consecutive
+// pages agree with each other. sort=relevance blends taste into the score
+// instead and grows the window until no row past it can reach the page.
+func (s *Service) listTasteRanked(
+	ctx context.Context,
+	params ListParams,
+	limit int,
+	userSignals []userTasteSignal,
+) (CafeListPage, error) {
+	offset := params.Offset
+	if offset < 0 {
+		offset = 0
+	}
+	end := offset + limit
+	weights := newRelevanceWeights(s.cfg.Ranking)
+	window := params
+	window.Offset = 0
+	window.Limit = end + tasteRankingWindowSlack
+
+	var (
+		items []model.CafeResponse
+		err   error
+	)
+	for {
+		var (
+			keysets []cafeListKeyset
+			signals []cafeRankSignals
+		)
+		items, keysets, signals, err = s.repository.QueryCafes(ctx, window, s.cfg.Limits, weights)
+		if err != nil {
+			return CafeListPage{}, err
+		}
+		explainCafeRanking(items, signals, params.SortBy, weights)
+
+		cafeTasteTokens, ok := s.loadCafeTasteTokens(ctx, items)
+		if !ok {
+			break
+		}
+		if params.SortBy != config.SortByRelevance {
+			items = applyTastePersonalization(items, userSignals, cafeTasteTokens)
+			break
+		}
+		var scores []float64
+		items, scores = applyRelevanceTaste(items, signals, weights, userSignals, cafeTasteTokens)
+		exhausted := len(keysets) < window.Limit
+		if exhausted || window.Limit >= maxCafeListTasteOffset ||
+			relevanceWindowSettled(scores, end, keysets[len(keysets)-1].RelevanceScore, weights) {
+			break
+		}
+		window.Limit = min(window.Limit*2, maxCafeListTasteOffset)
+	}
+
+	page := CafeListPage{Items: []model.CafeResponse{}}
+	if offset < len(items) {
+		page.Items = items[offset:min(end, len(items))]
+	}
+	if len(items) > end && end <= maxCafeListTasteOffset {
+		page.NextCursor, err = encodeCafeListTasteCursor(params, end)
+		if err != nil {
+			return CafeListPage{}, err
+		}
+	}
+	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, page.Items, s.cfg.Media); err != nil {
+		return CafeListPage{}, err
 	}
+	return page, nil
+}
 
+// loadCafeTasteTokens returns false when the tokens cannot be loaded and the
+// list should keep its SQL order.
+func (s *Service) loadCafeTasteTokens(ctx context.Context, items []model.CafeResponse) (map[string][]string, bool) {
 	cafeIDs := make([]string, 0, len(items))
 	for _, item := range items {
 		id := strings.TrimSpace(item.ID)
@@ -71,187 +196,325 @@ func (s *Service) List(ctx context.Context, params ListParams) ([]model.CafeResp
 		cafeIDs = append(cafeIDs, id)
 	}
 	if len(cafeIDs) == 0 {
-		return items, nil
+		return nil, false
 	}
-
 	cafeTasteTokens, err := s.repository.ListCafeTasteTokens(ctx, cafeIDs)
 	if err != nil {
-		slog.Warn("taste ranking fallback to distance order: failed to load cafe taste tokens", "error", err)
-		return items, nil
+		slog.Warn("taste ranking fallback to SQL order: failed to load cafe taste tokens", "error", err)
+		return nil, false
 	}
-	items = applyTastePersonalization(items, userSignals, cafeTasteTokens)
-	return items, nil
+	return cafeTasteTokens, true
 }
 
-func (s *Service) UpdateDescription(ctx context.Context, cafeID, description string) (string, error) {
-	return s.repository.UpdateDescription(ctx, cafeID, description)
+// loadTasteSignals returns nil when taste ranking is off, the viewer is
+// anonymous or the signals cannot be loaded; callers fall back to the SQL
+// order in all of those cases.
+func (s *Service) loadTasteSignals(ctx context.Context, userID *string) []userTasteSignal {
+	if !s.tasteMapRankingEnabled || userID == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*userID)
+	if trimmed == "" {
+		return nil
+	}
+	userSignals, err := s.repository.ListUserActiveTasteSignals(ctx, trimmed, 12)
+	if err != nil {
+		slog.Warn("taste ranking fallback to distance order: failed to load user signals", "user_id", trimmed, "error", err)
+		return nil
+	}
+	return userSignals
 }
 
-func (s *Service) LookupAddress(ctx context.Context, address, city string) (GeocodeLookupResponse, error) {
-	fullQuery := strings.TrimSpace(address)
-	city = strings.TrimSpace(city)
-	if city != "" {
-		fullQuery = city + ", " + fullQuery
+func (s *Service) Search(ctx context.Context, params SearchParams) ([]model.CafeResponse, error) {
+	if len(params.Variants) == 0 {
+		params.Variants = searchQueryVariants(params.Query)
 	}
-
-	var yandexErr error
-	if strings.TrimSpace(s.cfg.Geocoding.YandexAPIKey) != "" {
-		result, err := s.lookupYandex(ctx, fullQuery)
-		if err == nil && result.Found {
-			return result, nil
-		}
-		if err != nil {
-			yandexErr = err
-		}
+	if len(params.Variants) == 0 {
+		return []model.CafeResponse{}, nil
+	}
+	if params.Limit <= 0 || params.Limit > searchMaxLimit {
+		params.Limit = searchDefaultLimit
 	}
 
-	result, err := s.lookupNominatim(ctx, fullQuery)
+	items, err := s.repository.SearchCafes(ctx, params)
 	if err != nil {
-		if yandexErr != nil {
-			return GeocodeLookupResponse{}, fmt.Errorf("yandex: %w; nominatim: %w", yandexErr, err)
+		return nil, err
+	}
+	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, items, s.cfg.Media); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (s *Service) Viewport(ctx context.Context, params ViewportParams) (ViewportResult, error) {
+	result := ViewportResult{Zoom: params.Zoom}
+	if params.Zoom >= viewportCafesMinZoom {
+		items, total, err := s.repository.QueryViewportCafes(ctx, params, viewportMaxCafes)
+		if err != nil {
+			return ViewportResult{}, err
 		}
-		return GeocodeLookupResponse{}, err
+		if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, items, s.cfg.Media); err != nil {
+			return ViewportResult{}, err
+		}
+		result.Mode = ViewportModeCafes
+		result.Cafes = items
+		result.Total = total
+		result.Truncated = total > len(items)
+		return result, nil
+	}
+
+	clusters, err := s.repository.QueryViewportClusters(
+		ctx,
+		params.Bounds,
+		viewportCellDegrees(params.Zoom),
+		params.RequiredAmenities,
+	)
+	if err != nil {
+		return ViewportResult{}, err
+	}
+	result.Mode = ViewportModeClusters
+	result.Clusters = clusters
+	for _, cluster := range clusters {
+		result.Total += cluster.Count
 	}
 	return result, nil
 }
 
-func (s *Service) lookupYandex(ctx context.Context, query string) (GeocodeLookupResponse, error) {
-	params := url.Values{}
-	params.Set("apikey", strings.TrimSpace(s.cfg.Geocoding.YandexAPIKey))
-	params.Set("format", "json")
-	params.Set("results", "1")
-	params.Set("lang", "ru_RU")
-	params.Set("geocode", query)
-
-	endpoint := "https://geocode-maps.yandex.ru/1.x/?" + params.Encode()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+func (s *Service) Route(ctx context.Context, params RouteParams) (RouteResult, error) {
+	items, err := s.repository.QueryRouteCafes(ctx, params)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		return RouteResult{}, err
+	}
+	cafes := make([]model.CafeResponse, len(items))
+	for i := range items {
+		cafes[i] = items[i].CafeResponse
+	}
+	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, cafes, s.cfg.Media); err != nil {
+		return RouteResult{}, err
 	}
-	req.Header.Set("Accept", "application/json")
-	if ua := strings.TrimSpace(s.cfg.Geocoding.UserAgent); ua != "" {
-		req.Header.Set("User-Agent", ua)
+	for i := range items {
+		items[i].CafeResponse = cafes[i]
 	}
+	return RouteResult{
+		RouteLengthM: params.Route.LengthM(),
+		WidthM:       params.WidthM,
+		Items:        items,
+	}, nil
+}
 
-	resp, err := s.client.Do(req)
+// Similar returns cafes near params.CafeID that share taste, descriptive
+// tags, amenities or rating band with it. pgx.ErrNoRows means the cafe does
+// not exist or has no location.
+func (s *Service) Similar(ctx context.Context, params SimilarParams) (SimilarResult, error) {
+	rows, err := s.repository.QuerySimilarCandidates(ctx, params, similarCandidatePool)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		return SimilarResult{}, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return GeocodeLookupResponse{}, fmt.Errorf("yandex geocoder status=%d", resp.StatusCode)
-	}
-
-	var payload struct {
-		Response struct {
-			GeoObjectCollection struct {
-				FeatureMember []struct {
-					GeoObject struct {
-						Point struct {
-							Pos string `json:"pos"`
-						} `json:"Point"`
-						MetaDataProperty struct {
-							GeocoderMetaData struct {
-								Text string `json:"text"`
-							} `json:"GeocoderMetaData"`
-						} `json:"metaDataProperty"`
-					} `json:"GeoObject"`
-				} `json:"featureMember"`
-			} `json:"GeoObjectCollection"`
-		} `json:"response"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return GeocodeLookupResponse{}, err
+	if len(rows) == 0 || rows[0].ID != params.CafeID {
+		return SimilarResult{}, pgx.ErrNoRows
 	}
-	if len(payload.Response.GeoObjectCollection.FeatureMember) == 0 {
-		return GeocodeLookupResponse{Found: false}, nil
+	source, candidates := rows[0], rows[1:]
+	if params.OnlyOpen {
+		open := candidates[:0]
+		for _, candidate := range candidates {
+			if candidate.IsOpen != nil && *candidate.IsOpen {
+				open = append(open, candidate)
+			}
+		}
+		candidates = open
 	}
 
-	item := payload.Response.GeoObjectCollection.FeatureMember[0].GeoObject
-	coords := strings.Fields(strings.TrimSpace(item.Point.Pos))
-	if len(coords) != 2 {
-		return GeocodeLookupResponse{Found: false}, nil
+	result := SimilarResult{CafeID: params.CafeID, RadiusM: params.RadiusM, Items: []SimilarCafe{}}
+	if len(candidates) == 0 {
+		return result, nil
 	}
 
-	lng, err := strconv.ParseFloat(coords[0], 64)
+	cafeIDs := make([]string, 0, len(rows))
+	for _, row := range rows {
+		cafeIDs = append(cafeIDs, row.ID)
+	}
+	tasteTokens, err := s.repository.ListCafeTasteTokens(ctx, cafeIDs)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		return SimilarResult{}, err
 	}
-	lat, err := strconv.ParseFloat(coords[1], 64)
+	amenityLabels, err := s.repository.ListAmenityLabels(ctx)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		slog.Warn("similar cafes: amenity labels unavailable, using keys", "error", err)
+		amenityLabels = nil
 	}
 
-	return GeocodeLookupResponse{
-		Found:       true,
-		Latitude:    lat,
-		Longitude:   lng,
-		DisplayName: strings.TrimSpace(item.MetaDataProperty.GeocoderMetaData.Text),
-		Provider:    "yandex",
-	}, nil
-}
+	traits := make([]similarTraits, len(candidates))
+	for i, candidate := range candidates {
+		traits[i] = newSimilarTraits(candidate, tasteTokens[candidate.ID])
+	}
+	result.Items = rankSimilarCafes(
+		newSimilarTraits(source, tasteTokens[source.ID]),
+		candidates,
+		traits,
+		params.RadiusM,
+		params.Limit,
+		amenityLabels,
+	)
 
-func (s *Service) lookupNominatim(ctx context.Context, query string) (GeocodeLookupResponse, error) {
-	baseURL := strings.TrimRight(strings.TrimSpace(s.cfg.Geocoding.NominatimBaseURL), "/")
-	if baseURL == "" {
-		baseURL = "https://nominatim.openstreetmap.org"
+	cafes := make([]model.CafeResponse, len(result.Items))
+	for i := range result.Items {
+		cafes[i] = result.Items[i].CafeResponse
+	}
+	if err := photos.AttachCafeCoverPhotos(ctx, s.repository.pool, cafes, s.cfg.Media); err != nil {
+		return SimilarResult{}, err
 	}
+	for i := range result.Items {
+		result.Items[i].CafeResponse = cafes[i]
+	}
+	return result, nil
+}
 
-	params := url.Values{}
-	params.Set("q", query)
-	params.Set("format", "jsonv2")
-	params.Set("limit", "1")
-	params.Set("addressdetails", "0")
-	params.Set("accept-language", "ru")
+func (s *Service) SetReviewsReader(reader cafeReviewsReader) {
+	s.reviews = reader
+}
 
-	endpoint := baseURL + "/search?" + params.Encode()
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+func (s *Service) SetGeocoder(geocoder addressGeocoder) {
+	s.geocoder = geocoder
+}
+
+func (s *Service) GetDetails(ctx context.Context, cafeID string, userID *string) (CafeDetailsResponse, error) {
+	cafe, err := s.repository.GetCafeByID(ctx, cafeID, userID)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
-	}
-	req.Header.Set("Accept", "application/json")
-	if ua := strings.TrimSpace(s.cfg.Geocoding.UserAgent); ua != "" {
-		req.Header.Set("User-Agent", ua)
+		return CafeDetailsResponse{}, err
 	}
 
-	resp, err := s.client.Do(req)
+	cafePhotos, err := photos.ListCafePhotos(ctx, s.repository.pool, cafeID, photos.KindCafe, s.cfg.Media)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		return CafeDetailsResponse{}, err
+	}
+	menuPhotos, err := photos.ListCafePhotos(ctx, s.repository.pool, cafeID, photos.KindMenu, s.cfg.Media)
+	if err != nil {
+		return CafeDetailsResponse{}, err
+	}
+	if len(cafePhotos) > 0 {
+		cover := cafePhotos[0].URL
+		cafe.CoverPhotoURL = &cover
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return GeocodeLookupResponse{}, fmt.Errorf("nominatim status=%d", resp.StatusCode)
+	resp := CafeDetailsResponse{
+		Cafe:            cafe,
+		Photos:          cafePhotos,
+		MenuPhotos:      menuPhotos,
+		DescriptiveTags: []interface{}{},
+		TopReviews:      []map[string]interface{}{},
 	}
 
-	var payload []struct {
-		Lat         string `json:"lat"`
-		Lon         string `json:"lon"`
-		DisplayName string `json:"display_name"`
+	if s.reviews != nil {
+		snapshot, err := s.reviews.GetCafeRatingSnapshot(ctx, cafeID)
+		if err != nil {
+			return CafeDetailsResponse{}, err
+		}
+		resp.Rating = snapshot
+		if tags, ok := snapshot["descriptive_tags"]; ok && tags != nil {
+			resp.DescriptiveTags = tags
+		}
+
+		topReviews, _, _, _, err := s.reviews.ListCafeReviews(ctx, cafeID, "helpful", "", 0, cafeDetailsTopReviews)
+		if err != nil {
+			return CafeDetailsResponse{}, err
+		}
+		resp.TopReviews = topReviews
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return GeocodeLookupResponse{}, err
+
+	if userID == nil || strings.TrimSpace(*userID) == "" {
+		return resp, nil
 	}
-	if len(payload) == 0 {
-		return GeocodeLookupResponse{Found: false}, nil
+	viewerID := strings.TrimSpace(*userID)
+	viewer := &CafeViewerState{IsFavorite: cafe.IsFavorite}
+	reviewID, found, err := s.repository.FindUserCafeReviewID(ctx, cafeID, viewerID)
+	if err != nil {
+		return CafeDetailsResponse{}, err
+	}
+	if found {
+		viewer.HasReview = true
+		viewer.OwnReviewID = &reviewID
+	}
+	if viewer.IsOwner, err = s.repository.IsCafeOwner(ctx, cafeID, viewerID); err != nil {
+		return CafeDetailsResponse{}, err
+	}
+	if s.tasteMapRankingEnabled {
+		viewer.TasteSummary = s.cafeTasteSummary(ctx, cafeID, viewerID)
+		resp.Cafe.Explainability = viewer.TasteSummary
 	}
+	resp.Viewer = viewer
+	return resp, nil
+}
 
-	lat, err := strconv.ParseFloat(strings.TrimSpace(payload[0].Lat), 64)
+// cafeTasteSummary explains how the cafe matches the viewer's taste map.
+// Failures only drop the hint, the card itself still renders.
+func (s *Service) cafeTasteSummary(ctx context.Context, cafeID, userID string) *string {
+	userSignals, err := s.repository.ListUserActiveTasteSignals(ctx, userID, 12)
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		slog.Warn("cafe details taste summary skipped: failed to load user signals", "user_id", userID, "error", err)
+		return nil
 	}
-	lng, err := strconv.ParseFloat(strings.TrimSpace(payload[0].Lon), 64)
+	if len(userSignals) == 0 {
+		return nil
+	}
+	tokens, err := s.repository.ListCafeTasteTokens(ctx, []string{cafeID})
 	if err != nil {
-		return GeocodeLookupResponse{}, err
+		slog.Warn("cafe details taste summary skipped: failed to load cafe taste tokens", "cafe_id", cafeID, "error", err)
+		return nil
 	}
+	return buildTasteExplainability(calculateTasteMatch(tokens[cafeID], userSignals))
+}
 
+func (s *Service) UpdateDescription(ctx context.Context, cafeID, description, actorID string) (string, error) {
+	return s.repository.UpdateDescription(ctx, cafeID, description, cafeaudit.Change{
+		Source:  cafeaudit.SourceAdmin,
+		ActorID: actorID,
+	})
+}
+
+func (s *Service) LookupAddress(ctx context.Context, address, city string) (GeocodeLookupResponse, error) {
+	if s.geocoder == nil {
+		return GeocodeLookupResponse{}, geocoding.ErrNoProviders
+	}
+	fullQuery := strings.TrimSpace(address)
+	city = strings.TrimSpace(city)
+	if city != "" {
+		fullQuery = city + ", " + fullQuery
+	}
+
+	result, err := s.geocoder.Geocode(ctx, fullQuery)
+	if err != nil {
+		return GeocodeLookupResponse{}, err
+	}
+	if !result.Found {
+		return GeocodeLookupResponse{Found: false}, nil
+	}
 	return GeocodeLookupResponse{
 		Found:       true,
-		Latitude:    lat,
-		Longitude:   lng,
-		DisplayName: strings.TrimSpace(payload[0].DisplayName),
-		Provider:    "nominatim",
+		Latitude:    result.Latitude,
+		Longitude:   result.Longitude,
+		DisplayName: result.DisplayName,
+		Provider:    result.Provider,
+	}, nil
+}
+
+func (s *Service) ReverseGeocode(ctx context.Context, lat, lng float64) (ReverseGeocodeResponse, error) {
+	if s.geocoder == nil {
+		return ReverseGeocodeResponse{}, geocoding.ErrNoProviders
+	}
+	result, err := s.geocoder.Reverse(ctx, lat, lng)
+	if err != nil {
+		return ReverseGeocodeResponse{}, err
+	}
+	if !result.Found || result.Address == "" {
+		return ReverseGeocodeResponse{Found: false}, nil
+	}
+	return ReverseGeocodeResponse{
+		Found:       true,
+		Address:     result.Address,
+		DisplayName: result.DisplayName,
+		Latitude:    result.Latitude,
+		Longitude:   result.Longitude,
+		Provider:    result.Provider,
 	}, nil
 }
 
@@ -263,7 +526,8 @@ func (s *Service) TimeoutContext(parent context.Context, timeout time.Duration)
 	return context.WithTimeout(parent, timeout)
 }
 
-func (s *Service) ImportJSON(ctx context.Context, req adminCafeImportRequest) (adminCafeImportResponse, error) {
+func (s *Service) ImportJSON(ctx context.Context, req adminCafeImportRequest, actorID string) (adminCafeImportResponse, error) {
+	change := cafeaudit.Change{Source: cafeaudit.SourceImport, ActorID: actorID}
 	resp := adminCafeImportResponse{
 		Mode:   req.Mode,
 		DryRun: req.DryRun,
@@ -281,140 +545,179 @@ func (s *Service) ImportJSON(ctx context.Context, req adminCafeImportRequest) (a
 		resp.Issues = append(resp.Issues, issue)
 	}
 
+	target := poolImportTarget{repository: s.repository, change: change}
 	for index, rawItem := range req.Cafes {
-		nextIndex := index + 1
-		normalized, validationIssues := normalizeCafeImportItem(rawItem)
-		if len(validationIssues) > 0 {
-			resp.Summary.Invalid++
-			result := adminCafeImportResultItem{
-				Index:   nextIndex,
-				Status:  "invalid",
-				Name:    strings.TrimSpace(rawItem.Name),
-				Address: strings.TrimSpace(rawItem.Address),
-				Message: validationIssues[0].Message,
-			}
-			resp.Results = append(resp.Results, result)
-			for _, issue := range validationIssues {
-				issue.Index = nextIndex
-				appendIssue(issue)
-			}
-			continue
+		result, issues := importCafeItem(ctx, target, index+1, rawItem, nil, req.Mode, req.DryRun)
+		resp.Summary.count(result.Status)
+		resp.Results = append(resp.Results, result)
+		for _, issue := range issues {
+			appendIssue(issue)
 		}
+	}
 
-		existingID, found, err := s.repository.FindCafeByNameAddress(ctx, normalized.Name, normalized.Address)
-		if err != nil {
-			resp.Summary.Failed++
-			resp.Results = append(resp.Results, adminCafeImportResultItem{
-				Index:   nextIndex,
-				Status:  "failed",
-				Name:    normalized.Name,
-				Address: normalized.Address,
-				Message: "Не удалось проверить существующую кофейню.",
-			})
-			appendIssue(adminCafeImportIssue{
-				Index:   nextIndex,
-				Message: "Ошибка проверки дубликатов в БД.",
-			})
-			continue
-		}
+	if len(resp.Issues) == 0 {
+		resp.Issues = nil
+	}
 
-		if found {
-			if req.Mode == AdminCafeImportModeSkipExisting {
-				resp.Summary.Skipped++
-				resp.Results = append(resp.Results, adminCafeImportResultItem{
-					Index:   nextIndex,
-					Status:  "skipped",
-					Name:    normalized.Name,
-					Address: normalized.Address,
-					CafeID:  &existingID,
-					Message: "Кофейня уже существует.",
-				})
-				continue
-			}
+	return resp, nil
+}
 
-			if req.DryRun {
-				resp.Summary.Updated++
-				resp.Results = append(resp.Results, adminCafeImportResultItem{
-					Index:   nextIndex,
-					Status:  "would_update",
-					Name:    normalized.Name,
-					Address: normalized.Address,
-					CafeID:  &existingID,
-				})
-				continue
-			}
+func (s *Service) CreateImportJob(ctx context.Context, input cafeImportJobInput) (CafeImportJob, error) {
+	jobID, err := s.repository.CreateImportJob(ctx, input)
+	if err != nil {
+		return CafeImportJob{}, err
+	}
+	select {
+	case s.importJobWake <- struct{}{}:
+	default:
+	}
+	return s.repository.GetImportJob(ctx, jobID)
+}
+
+func (s *Service) GetImportJob(ctx context.Context, jobID string) (CafeImportJob, error) {
+	return s.repository.GetImportJob(ctx, jobID)
+}
+
+func (s *Service) ListImportJobs(ctx context.Context, status string, limit int) ([]CafeImportJob, error) {
+	if limit <= 0 || limit > importJobListMaxLimit {
+		limit = importJobListDefaultLimit
+	}
+	return s.repository.ListImportJobs(ctx, status, limit)
+}
+
+// CancelImportJob reports cancelled=false for a job that already finished.
+func (s *Service) CancelImportJob(ctx context.Context, jobID string) (CafeImportJob, bool, error) {
+	cancelled, err := s.repository.CancelImportJob(ctx, jobID)
+	if err != nil {
+		return CafeImportJob{}, false, err
+	}
+	job, err := s.repository.GetImportJob(ctx, jobID)
+	return job, cancelled, err
+}
+
+func (s *Service) ListImportJobIssues(ctx context.Context, jobID string) ([]CafeImportJobIssue, error) {
+	if _, err := s.repository.GetImportJob(ctx, jobID); err != nil {
+		return nil, err
+	}
+	return s.repository.ListImportJobIssues(ctx, jobID)
+}
 
-			if err := s.repository.UpdateCafeByID(ctx, existingID, normalized); err != nil {
-				resp.Summary.Failed++
-				resp.Results = append(resp.Results, adminCafeImportResultItem{
-					Index:   nextIndex,
-					Status:  "failed",
-					Name:    normalized.Name,
-					Address: normalized.Address,
-					CafeID:  &existingID,
-					Message: "Не удалось обновить кофейню.",
-				})
-				appendIssue(adminCafeImportIssue{
-					Index:   nextIndex,
-					Message: "Ошибка обновления кофейни в БД.",
-				})
+// StartImportWorker runs queued import jobs batch by batch. Progress is
+// committed with every batch, so jobs interrupted by a restart or a crashed
+// instance are picked up again once their lease expires and continue after
+// the last committed item.
+func (s *Service) StartImportWorker(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := slog.Default().With("worker_name", "cafes_import_jobs")
+	logger.Info("worker started", "interval", interval)
+	defer logger.Info("worker stopped")
+
+	drain := func() {
+		for ctx.Err() == nil {
+			claimCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			jobID, status, ok, err := s.repository.ClaimImportJob(claimCtx, importJobLease, importJobMaxAttempts)
+			cancel()
+			if err != nil {
+				logger.Error("claim failed", "error", err)
+				return
+			}
+			if !ok {
+				return
+			}
+			if status != CafeImportJobRunning {
+				logger.Warn("import job failed after repeated attempts", "job_id", jobID)
 				continue
 			}
-
-			resp.Summary.Updated++
-			resp.Results = append(resp.Results, adminCafeImportResultItem{
-				Index:   nextIndex,
-				Status:  "updated",
-				Name:    normalized.Name,
-				Address: normalized.Address,
-				CafeID:  &existingID,
-			})
-			continue
+			s.runImportJob(ctx, jobID, logger)
 		}
+	}
 
-		if req.DryRun {
-			resp.Summary.Created++
-			resp.Results = append(resp.Results, adminCafeImportResultItem{
-				Index:   nextIndex,
-				Status:  "would_create",
-				Name:    normalized.Name,
-				Address: normalized.Address,
-			})
-			continue
+	drain()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			drain()
+		case <-s.importJobWake:
+			drain()
 		}
+	}
+}
 
-		createdID, err := s.repository.InsertCafe(ctx, normalized)
+func (s *Service) runImportJob(ctx context.Context, jobID string, logger *slog.Logger) {
+	logger = logger.With("job_id", jobID)
+	logger.Info("import job started")
+	for {
+		if ctx.Err() != nil {
+			releaseCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			if err := s.repository.ReleaseImportJob(releaseCtx, jobID); err != nil {
+				logger.Error("release failed", "error", err)
+			}
+			cancel()
+			return
+		}
+		batchCtx, cancel := context.WithTimeout(ctx, importJobBatchTimeout)
+		status, err := s.repository.RunImportJobBatch(batchCtx, jobID, importJobBatchSize, importJobBatch)
+		cancel()
 		if err != nil {
-			resp.Summary.Failed++
-			resp.Results = append(resp.Results, adminCafeImportResultItem{
-				Index:   nextIndex,
-				Status:  "failed",
-				Name:    normalized.Name,
-				Address: normalized.Address,
-				Message: "Не удалось создать кофейню.",
-			})
-			appendIssue(adminCafeImportIssue{
-				Index:   nextIndex,
-				Message: "Ошибка создания кофейни в БД.",
-			})
-			continue
+			if ctx.Err() != nil {
+				continue
+			}
+			// The batch rolled back; the job resumes from the same item once
+			// its lease expires.
+			logger.Error("import batch failed", "error", err)
+			return
+		}
+		if status != CafeImportJobRunning {
+			logger.Info("import job finished", "status", status)
+			return
 		}
-
-		resp.Summary.Created++
-		resp.Results = append(resp.Results, adminCafeImportResultItem{
-			Index:   nextIndex,
-			Status:  "created",
-			Name:    normalized.Name,
-			Address: normalized.Address,
-			CafeID:  &createdID,
-		})
 	}
+}
 
-	if len(resp.Issues) == 0 {
-		resp.Issues = nil
+// ExportCafes streams every cafe matching filter to w in the given format
+// and returns how many were written.
+func (s *Service) ExportCafes(ctx context.Context, filter CafeExportFilter, format string, w io.Writer) (int, error) {
+	encoder := newCafeExportEncoder(format, w)
+	if err := encoder.begin(); err != nil {
+		return 0, err
+	}
+	count := 0
+	err := s.repository.StreamCafeExport(ctx, filter, func(item CafeExportItem) error {
+		for i := range item.Photos {
+			item.Photos[i].URL = photos.BuildPhotoURL(s.cfg.Media, item.Photos[i].URL)
+		}
+		count++
+		return encoder.item(item)
+	})
+	if err != nil {
+		return count, err
 	}
+	return count, encoder.end()
+}
 
-	return resp, nil
+// poolImportTarget applies synchronous imports, one transaction per cafe.
+type poolImportTarget struct {
+	repository *Repository
+	change     cafeaudit.Change
+}
+
+func (t poolImportTarget) FindCafeByNameAddress(ctx context.Context, name, address string) (string, bool, error) {
+	return t.repository.FindCafeByNameAddress(ctx, name, address)
+}
+
+func (t poolImportTarget) InsertCafe(ctx context.Context, item normalizedCafeImportItem) (string, error) {
+	return t.repository.InsertCafe(ctx, item, t.change)
+}
+
+func (t poolImportTarget) UpdateCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem) error {
+	return t.repository.UpdateCafeByID(ctx, cafeID, item, t.change)
 }
 
 func (s *Service) SearchAdminCafesByName(ctx context.Context, query string, limit int) ([]AdminCafeSearchItem, error) {
@@ -435,13 +738,160 @@ func (s *Service) GetAdminCafeByID(ctx context.Context, cafeID string) (AdminCaf
 	return s.repository.GetAdminCafeByID(ctx, cafeID)
 }
 
-func (s *Service) UpdateAdminCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem) (AdminCafeDetails, error) {
-	if err := s.repository.UpdateCafeByID(ctx, cafeID, item); err != nil {
+func (s *Service) UpdateAdminCafeByID(ctx context.Context, cafeID string, item normalizedCafeImportItem, actorID string) (AdminCafeDetails, error) {
+	change := cafeaudit.Change{Source: cafeaudit.SourceAdmin, ActorID: actorID}
+	if err := s.repository.UpdateCafeByID(ctx, cafeID, item, change); err != nil {
 		return AdminCafeDetails{}, err
 	}
 	return s.repository.GetAdminCafeByID(ctx, cafeID)
 }
 
-func (s *Service) DeleteCafeByID(ctx context.Context, cafeID string) error {
-	return s.repository.DeleteCafeByID(ctx, cafeID)
+// DeleteCafeByID soft-deletes a cafe: it disappears from every public
+// surface but keeps its reviews, photos and reputation history.
+func (s *Service) DeleteCafeByID(ctx context.Context, cafeID, actorID string) error {
+	return s.repository.SetCafeStatus(ctx, cafeID, cafeStatusUpdate{Status: model.CafeStatusDeleted}, cafeaudit.Change{
+		Source:  cafeaudit.SourceAdmin,
+		ActorID: actorID,
+	})
+}
+
+func (s *Service) SetCafeStatus(ctx context.Context, cafeID string, update cafeStatusUpdate, actorID string) (AdminCafeDetails, error) {
+	change := cafeaudit.Change{Source: cafeaudit.SourceAdmin, ActorID: actorID}
+	if err := s.repository.SetCafeStatus(ctx, cafeID, update, change); err != nil {
+		return AdminCafeDetails{}, err
+	}
+	return s.repository.GetAdminCafeByID(ctx, cafeID)
+}
+
+// RestoreCafe brings a deleted or closed cafe back to active.
+func (s *Service) RestoreCafe(ctx context.Context, cafeID, actorID string) (AdminCafeDetails, error) {
+	return s.SetCafeStatus(ctx, cafeID, cafeStatusUpdate{Status: model.CafeStatusActive}, actorID)
+}
+
+// StartReopenWorker activates temporarily closed cafes once their reopen
+// date arrives. The update is idempotent, so instances need no leader lock.
+func (s *Service) StartReopenWorker(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := slog.Default().With("worker_name", "cafes_reopen")
+	logger.Info("worker started", "interval", interval)
+	defer logger.Info("worker stopped")
+
+	reopen := func() {
+		runCtx, cancel := context.WithTimeout(ctx, time.Minute)
+		defer cancel()
+		reopened, err := s.repository.ReopenDueCafes(runCtx)
+		if err != nil {
+			logger.Error("reopen failed", "error", err)
+			return
+		}
+		if reopened > 0 {
+			logger.Info("cafes reopened", "count", reopened)
+		}
+	}
+
+	reopen()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reopen()
+		}
+	}
+}
+
+func (s *Service) ScanDuplicates(ctx context.Context) (CafeDuplicateScanResult, error) {
+	return s.repository.ScanDuplicateCandidates(ctx)
+}
+
+func (s *Service) ListDuplicates(ctx context.Context, status string, limit int) ([]CafeDuplicateCandidate, error) {
+	if limit <= 0 || limit > duplicateListMaxLimit {
+		limit = duplicateListDefaultLimit
+	}
+	return s.repository.ListDuplicateCandidates(ctx, status, limit)
+}
+
+func (s *Service) DismissDuplicate(ctx context.Context, candidateID int64, actorID string) error {
+	return s.repository.DismissDuplicateCandidate(ctx, candidateID, actorID)
+}
+
+func (s *Service) MergeCafes(ctx context.Context, targetID, sourceID, actorID string) (CafeMergeResult, error) {
+	return s.repository.MergeCafes(ctx, targetID, sourceID, actorID)
+}
+
+// StartDuplicateScanWorker periodically refreshes duplicate candidates.
+// Cafes arrive from imports, moderation and the OpenStreetMap sync, so the
+// scan is global rather than tied to any single write path.
+func (s *Service) StartDuplicateScanWorker(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := slog.Default().With("worker_name", "cafes_duplicate_scan")
+	logger.Info("worker started", "interval", interval)
+	defer logger.Info("worker stopped")
+
+	scan := func() {
+		runCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		result, err := s.repository.ScanDuplicateCandidates(runCtx)
+		if err != nil {
+			logger.Error("scan failed", "error", err)
+			return
+		}
+		if result.Skipped {
+			logger.Info("scan skipped, another instance holds lock", "lock_key", duplicateScanLockKey)
+			return
+		}
+		logger.Info("scan completed", "found", result.Found, "new", result.New, "removed", result.Removed)
+	}
+
+	scan()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			scan()
+		}
+	}
+}
+
+func (s *Service) ListRevisions(ctx context.Context, cafeID string, before, limit int) (CafeRevisionList, error) {
+	if limit <= 0 || limit > revisionListMaxLimit {
+		limit = revisionListDefaultLimit
+	}
+	if err := s.repository.EnsureCafeExists(ctx, cafeID); err != nil {
+		return CafeRevisionList{}, err
+	}
+	items, err := s.repository.ListCafeRevisions(ctx, cafeID, before, limit+1)
+	if err != nil {
+		return CafeRevisionList{}, err
+	}
+	result := CafeRevisionList{Items: items}
+	if len(items) > limit {
+		result.Items = items[:limit]
+		nextBefore := items[limit-1].Revision
+		result.NextBefore = &nextBefore
+	}
+	return result, nil
+}
+
+func (s *Service) RevertToRevision(ctx context.Context, cafeID string, revision int, actorID string) (CafeRevertResult, error) {
+	result, err := s.repository.RevertCafeToRevision(ctx, cafeID, revision, actorID)
+	if err != nil {
+		return CafeRevertResult{}, err
+	}
+	result.Cafe, err = s.repository.GetAdminCafeByID(ctx, cafeID)
+	if err != nil {
+		return CafeRevertResult{}, err
+	}
+	return result, nil
 }
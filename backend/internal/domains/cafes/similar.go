@@ -0,0 +1,245 @@
+package cafes
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"backend/internal/model"
+)
+
+const (
+	similarDefaultRadiusM = 3000.0
+	similarMaxRadiusM     = 20000.0
+	similarDefaultLimit   = 6
+	similarMaxLimit       = 20
+	// similarCandidatePool is how many of the nearest cafes are scored.
+	similarCandidatePool = 100
+	// similarReasonItems caps how many shared traits the reason names.
+	similarReasonItems = 3
+
+	similarTasteWeight     = 0.35
+	similarTagWeight       = 0.25
+	similarAmenityWeight   = 0.2
+	similarRatingWeight    = 0.1
+	similarProximityWeight = 0.1
+)
+
+// similarCandidate is a cafe row with the traits compared by similarity.
+// Tags maps lowercased descriptive tag labels to their display form.
+type similarCandidate struct {
+	model.CafeResponse
+	Rating       float64
+	ReviewsCount int
+	Tags         map[string]string
+}
+
+// similarTraits is what two cafes are compared on.
+type similarTraits struct {
+	taste      []string
+	tags       map[string]string
+	amenities  []string
+	ratingBand int
+}
+
+func newSimilarTraits(candidate similarCandidate, tasteTokens []string) similarTraits {
+	return similarTraits{
+		taste:      tasteDescriptorCodes(tasteTokens),
+		tags:       candidate.Tags,
+		amenities:  candidate.Amenities,
+		ratingBand: ratingBand(candidate.Rating, candidate.ReviewsCount),
+	}
+}
+
+// tasteDescriptorCodes maps free-form taste tokens onto tasteDescriptors so
+// "эспрессо" and "espresso" count as the same trait.
+func tasteDescriptorCodes(tokens []string) []string {
+	normalized := dedupeTasteTokens(tokens)
+	if len(normalized) == 0 {
+		return nil
+	}
+	codes := make([]string, 0, 4)
+	for code, descriptor := range tasteDescriptors {
+		if tasteTokensMatch(normalized, descriptor.Tokens) {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// ratingBand buckets a rating; -1 means not rated yet.
+func ratingBand(rating float64, reviewsCount int) int {
+	switch {
+	case reviewsCount <= 0:
+		return -1
+	case rating < 3.5:
+		return 0
+	case rating < 4.0:
+		return 1
+	case rating < 4.5:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// rankSimilarCafes scores candidates against source, drops the ones with
+// nothing in common and returns the best limit items. amenityLabels names
+// amenity keys in reasons; unknown keys fall back to the key itself.
+func rankSimilarCafes(
+	source similarTraits,
+	candidates []similarCandidate,
+	traits []similarTraits,
+	radiusM float64,
+	limit int,
+	amenityLabels map[string]string,
+) []SimilarCafe {
+	out := make([]SimilarCafe, 0, len(candidates))
+	for i, candidate := range candidates {
+		if i >= len(traits) {
+			break
+		}
+		target := traits[i]
+		sharedTaste := intersectStrings(source.taste, target.taste)
+		sharedTags := intersectTagKeys(source.tags, target.tags)
+		sharedAmenities := intersectStrings(source.amenities, target.amenities)
+		if len(sharedTaste) == 0 && len(sharedTags) == 0 && len(sharedAmenities) == 0 {
+			continue
+		}
+
+		score := similarTasteWeight*jaccard(len(sharedTaste), len(source.taste), len(target.taste)) +
+			similarTagWeight*jaccard(len(sharedTags), len(source.tags), len(target.tags)) +
+			similarAmenityWeight*jaccard(len(sharedAmenities), len(source.amenities), len(target.amenities)) +
+			similarRatingWeight*ratingBandSimilarity(source.ratingBand, target.ratingBand)
+		if radiusM > 0 {
+			score += similarProximityWeight * clampFloat(1-candidate.DistanceM/radiusM, 0, 1)
+		}
+
+		reasons := make([]string, 0, similarReasonItems)
+		for _, code := range sharedTaste {
+			reasons = appendUniqueLabel(reasons, tasteDescriptors[code].Label)
+		}
+		for _, key := range sharedTags {
+			reasons = appendUniqueLabel(reasons, lowerLabel(target.tags[key]))
+		}
+		for _, key := range sharedAmenities {
+			label := amenityLabels[key]
+			if label == "" {
+				label = key
+			}
+			reasons = appendUniqueLabel(reasons, lowerLabel(label))
+		}
+		out = append(out, SimilarCafe{
+			CafeResponse: candidate.CafeResponse,
+			Similarity:   math.Round(score*1000) / 1000,
+			Reason:       buildSimilarReason(reasons, source.ratingBand >= 2 && source.ratingBand == target.ratingBand),
+		})
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if !almostEqual(out[i].Similarity, out[j].Similarity) {
+			return out[i].Similarity > out[j].Similarity
+		}
+		return out[i].DistanceM < out[j].DistanceM
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
+
+// buildSimilarReason turns shared traits into a short sentence such as
+// "Тоже цитрусовые ноты, Wi-Fi и тихо, с таким же высоким рейтингом".
+func buildSimilarReason(traits []string, sameHighRating bool) string {
+	if len(traits) > similarReasonItems {
+		traits = traits[:similarReasonItems]
+	}
+	text := "Тоже " + joinRussianList(traits)
+	if sameHighRating {
+		text += ", с таким же высоким рейтингом"
+	}
+	return text
+}
+
+func joinRussianList(items []string) string {
+	switch len(items) {
+	case 0:
+		return ""
+	case 1:
+		return items[0]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + " и " + items[len(items)-1]
+	}
+}
+
+// lowerLabel lowercases the first letter of a label for use mid-sentence,
+// leaving names with other capitals ("Wi-Fi") alone.
+func lowerLabel(label string) string {
+	label = strings.TrimSpace(label)
+	first, size := utf8.DecodeRuneInString(label)
+	if size == 0 || strings.IndexFunc(label[size:], unicode.IsUpper) >= 0 {
+		return label
+	}
+	return string(unicode.ToLower(first)) + label[size:]
+}
+
+func ratingBandSimilarity(left, right int) float64 {
+	if left < 0 || right < 0 {
+		return 0
+	}
+	switch diff := left - right; {
+	case diff == 0:
+		return 1
+	case diff == 1 || diff == -1:
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+func jaccard(shared, left, right int) float64 {
+	union := left + right - shared
+	if union <= 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+// intersectStrings returns the values present in both slices, in the order of
+// left, without duplicates.
+func intersectStrings(left, right []string) []string {
+	if len(left) == 0 || len(right) == 0 {
+		return nil
+	}
+	inRight := make(map[string]struct{}, len(right))
+	for _, value := range right {
+		inRight[value] = struct{}{}
+	}
+	out := make([]string, 0, len(left))
+	seen := make(map[string]struct{}, len(left))
+	for _, value := range left {
+		if _, ok := inRight[value]; !ok {
+			continue
+		}
+		if _, dup := seen[value]; dup {
+			continue
+		}
+		seen[value] = struct{}{}
+		out = append(out, value)
+	}
+	return out
+}
+
+func intersectTagKeys(left, right map[string]string) []string {
+	out := make([]string, 0, len(left))
+	for key := range left {
+		if _, ok := right[key]; ok {
+			out = append(out, key)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,101 @@
+package cafes
+
+import (
+	"testing"
+
+	"backend/internal/model"
+)
+
+func similarTestCandidate(id string, distanceM float64, amenities []string, tags map[string]string, rating float64, reviews int) similarCandidate {
+	return similarCandidate{
+		CafeResponse: model.CafeResponse{ID: id, DistanceM: distanceM, Amenities: amenities},
+		Rating:       rating,
+		ReviewsCount: reviews,
+		Tags:         tags,
+	}
+}
+
+func TestRankSimilarCafes(t *testing.T) {
+	t.Parallel()
+
+	source := newSimilarTraits(
+		similarTestCandidate("src", 0, []string{"wifi", "quiet"}, map[string]string{"уютно": "Уютно"}, 4.7, 20),
+		[]string{"citrus", "лимон"},
+	)
+	candidates := []similarCandidate{
+		similarTestCandidate("unrelated", 100, []string{"toilet"}, nil, 4.7, 10),
+		similarTestCandidate("partial", 200, []string{"wifi"}, nil, 3.2, 5),
+		similarTestCandidate("close-match", 900, []string{"wifi", "quiet"}, map[string]string{"уютно": "Уютно"}, 4.6, 8),
+	}
+	tokens := [][]string{nil, nil, {"grapefruit"}}
+	traits := make([]similarTraits, len(candidates))
+	for i, candidate := range candidates {
+		traits[i] = newSimilarTraits(candidate, tokens[i])
+	}
+
+	items := rankSimilarCafes(source, candidates, traits, 3000, 5, map[string]string{"wifi": "Wi-Fi", "quiet": "Тихо"})
+	if len(items) != 2 {
+		t.Fatalf("expected cafes without shared traits to be skipped, got %d items", len(items))
+	}
+	if items[0].ID != "close-match" || items[1].ID != "partial" {
+		t.Fatalf("unexpected order: %s, %s", items[0].ID, items[1].ID)
+	}
+	if items[0].Similarity <= items[1].Similarity {
+		t.Fatalf("expected descending similarity: %v <= %v", items[0].Similarity, items[1].Similarity)
+	}
+	if want := "Тоже цитрусовые ноты, уютно и Wi-Fi, с таким же высоким рейтингом"; items[0].Reason != want {
+		t.Fatalf("unexpected reason: %q, want %q", items[0].Reason, want)
+	}
+	if want := "Тоже Wi-Fi"; items[1].Reason != want {
+		t.Fatalf("unexpected reason: %q, want %q", items[1].Reason, want)
+	}
+
+	limited := rankSimilarCafes(source, candidates, traits, 3000, 1, nil)
+	if len(limited) != 1 || limited[0].ID != "close-match" {
+		t.Fatalf("limit should keep the best match: %+v", limited)
+	}
+	if limited[0].Reason != "Тоже цитрусовые ноты, уютно и wifi, с таким же высоким рейтингом" {
+		t.Fatalf("unknown amenity labels should fall back to keys: %q", limited[0].Reason)
+	}
+}
+
+func TestRatingBand(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		rating  float64
+		reviews int
+		want    int
+	}{
+		{rating: 4.9, reviews: 0, want: -1},
+		{rating: 3.2, reviews: 3, want: 0},
+		{rating: 3.5, reviews: 3, want: 1},
+		{rating: 4.2, reviews: 3, want: 2},
+		{rating: 4.5, reviews: 3, want: 3},
+	}
+	for _, tc := range cases {
+		if got := ratingBand(tc.rating, tc.reviews); got != tc.want {
+			t.Fatalf("ratingBand(%v, %d) = %d, want %d", tc.rating, tc.reviews, got, tc.want)
+		}
+	}
+	if ratingBandSimilarity(-1, -1) != 0 {
+		t.Fatalf("unrated cafes should not count as the same band")
+	}
+}
+
+func TestLowerLabel(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"Тихо":    "тихо",
+		" Уютно ": "уютно",
+		"Wi-Fi":   "Wi-Fi",
+		"":        "",
+		"розетки": "розетки",
+	}
+	for input, want := range cases {
+		if got := lowerLabel(input); got != want {
+			t.Fatalf("lowerLabel(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
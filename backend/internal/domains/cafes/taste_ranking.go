@@ -139,7 +139,7 @@ func applyTastePersonalization(
 	ranked := make([]rankedCafeItem, 0, len(baseItems))
 	for index, item := range baseItems {
 		match := calculateTasteMatch(cafeTasteTokens[strings.TrimSpace(item.ID)], userSignals)
-		item.Explainability = buildTasteExplainability(match)
+		item.Explainability = appendExplainability(item.Explainability, buildTasteExplainability(match))
 		sortScore := float64(index) - clampFloat(match.Score, -1.2, 1.2)*tasteRankingBoostPositions
 		ranked = append(ranked, rankedCafeItem{
 			Item:      item,
@@ -234,6 +234,19 @@ func buildTasteExplainability(match tasteMatchResult) *string {
 	return &text
 }
 
+// appendExplainability joins a taste note onto an existing explanation, e.g.
+// the rating breakdown of sort=rating.
+func appendExplainability(base *string, extra *string) *string {
+	switch {
+	case base == nil || *base == "":
+		return extra
+	case extra == nil || *extra == "":
+		return base
+	}
+	text := *base + " " + *extra
+	return &text
+}
+
 func tasteTokensMatch(tokens []string, rawNeedles []string) bool {
 	if len(tokens) == 0 || len(rawNeedles) == 0 {
 		return false
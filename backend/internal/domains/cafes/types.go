@@ -1,14 +1,25 @@
 package cafes
 
-import "backend/internal/model"
+import (
+	"encoding/json"
+	"time"
+
+	"backend/internal/model"
+)
 
 const MaxDescriptionChars = 2000
 
+const cafeDetailsTopReviews = 3
+
 const (
 	AdminCafeImportModeSkipExisting = "skip_existing"
 	AdminCafeImportModeUpsert       = "upsert"
 	AdminCafeImportMaxItems         = 1000
 	AdminCafeImportMaxIssues        = 300
+
+	// Import jobs run in the background and accept much larger files.
+	AdminCafeImportJobMaxItems = 50000
+	AdminCafeImportJobMaxBytes = 32 << 20
 )
 
 type ListParams struct {
@@ -16,11 +27,140 @@ type ListParams struct {
 	Longitude         float64
 	RadiusM           float64
 	RequiredAmenities []string
+	// City is a city slug; empty means no city scope.
+	City          string
+	UserID        *string
+	FavoritesOnly bool
+	SortBy        string
+	// Aspect (model.ReviewAspects) replaces the overall rating in the
+	// rating and relevance sorts; empty means the overall rating.
+	Aspect string
+	// MinAspectRating keeps cafes whose Aspect rating is at least this;
+	// zero disables the filter.
+	MinAspectRating float64
+	OpenAt          *time.Time
+	OnlyOpen        bool
+	// EvaluatedAt is when is_open (and with it work_score) is computed
+	// unless OpenAt is set. The first page picks it and the cursor carries
+	// it, so later pages continue the same ordering.
+	EvaluatedAt time.Time
+	Limit       int
+	Offset      int
+	After       *cafeListKeyset
+}
+
+type CafeListPage struct {
+	Items      []model.CafeResponse
+	NextCursor string
+}
+
+type SearchParams struct {
+	Query     string
+	Variants  []string
+	Latitude  *float64
+	Longitude *float64
+	UserID    *string
+	Limit     int
+}
+
+type SearchResult struct {
+	Query string               `json:"q"`
+	Items []model.CafeResponse `json:"items"`
+}
+
+type ViewportParams struct {
+	Bounds            viewportBounds
+	Zoom              int
+	RequiredAmenities []string
+	UserID            *string
+}
+
+type ViewportCluster struct {
+	Count      int      `json:"count"`
+	Latitude   float64  `json:"latitude"`
+	Longitude  float64  `json:"longitude"`
+	BestRating *float64 `json:"best_rating,omitempty"`
+	CafeID     *string  `json:"cafe_id,omitempty"`
+	MinLat     float64  `json:"min_lat"`
+	MinLng     float64  `json:"min_lng"`
+	MaxLat     float64  `json:"max_lat"`
+	MaxLng     float64  `json:"max_lng"`
+}
+
+type ViewportResult struct {
+	Mode      string               `json:"mode"`
+	Zoom      int                  `json:"zoom"`
+	Total     int                  `json:"total"`
+	Clusters  []ViewportCluster    `json:"clusters,omitempty"`
+	Cafes     []model.CafeResponse `json:"cafes,omitempty"`
+	Truncated bool                 `json:"truncated,omitempty"`
+}
+
+type RouteParams struct {
+	Route             routeLine
+	WidthM            float64
+	RequiredAmenities []string
+	OnlyOpen          bool
 	UserID            *string
-	FavoritesOnly     bool
 	Limit             int
 }
 
+// RouteCafe is a cafe inside the route corridor. DistanceM is measured to
+// the route line, RoutePositionM is where the cafe projects onto the route
+// counted from its start, and DetourM is the extra distance of going there
+// and back to the route.
+type RouteCafe struct {
+	model.CafeResponse
+	RoutePositionM float64 `json:"route_position_m"`
+	DetourM        float64 `json:"detour_m"`
+}
+
+type RouteResult struct {
+	RouteLengthM float64     `json:"route_length_m"`
+	WidthM       float64     `json:"width_m"`
+	Items        []RouteCafe `json:"items"`
+}
+
+type SimilarParams struct {
+	CafeID   string
+	RadiusM  float64
+	OnlyOpen bool
+	UserID   *string
+	Limit    int
+}
+
+// SimilarCafe is an alternative to another cafe. Similarity is in [0, 1];
+// Reason names what the two cafes have in common.
+type SimilarCafe struct {
+	model.CafeResponse
+	Similarity float64 `json:"similarity"`
+	Reason     string  `json:"reason"`
+}
+
+type SimilarResult struct {
+	CafeID  string        `json:"cafe_id"`
+	RadiusM float64       `json:"radius_m"`
+	Items   []SimilarCafe `json:"items"`
+}
+
+type CafeDetailsResponse struct {
+	Cafe            model.CafeResponse        `json:"cafe"`
+	Photos          []model.CafePhotoResponse `json:"photos"`
+	MenuPhotos      []model.CafePhotoResponse `json:"menu_photos"`
+	Rating          map[string]interface{}    `json:"rating,omitempty"`
+	DescriptiveTags interface{}               `json:"descriptive_tags"`
+	TopReviews      []map[string]interface{}  `json:"top_reviews"`
+	Viewer          *CafeViewerState          `json:"viewer,omitempty"`
+}
+
+type CafeViewerState struct {
+	IsFavorite   bool    `json:"is_favorite"`
+	HasReview    bool    `json:"has_review"`
+	OwnReviewID  *string `json:"own_review_id,omitempty"`
+	IsOwner      bool    `json:"is_owner"`
+	TasteSummary *string `json:"taste_summary,omitempty"`
+}
+
 type updateDescriptionRequest struct {
 	Description string `json:"description"`
 }
@@ -33,12 +173,22 @@ type GeocodeLookupResponse struct {
 	Provider    string  `json:"provider,omitempty"`
 }
 
+type ReverseGeocodeResponse struct {
+	Found       bool    `json:"found"`
+	Address     string  `json:"address,omitempty"`
+	DisplayName string  `json:"display_name,omitempty"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+	Provider    string  `json:"provider,omitempty"`
+}
+
 type ListResult []model.CafeResponse
 
 type AdminCafeSearchItem struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
 	Address string `json:"address"`
+	Status  string `json:"status"`
 }
 
 type AdminCafeDetails struct {
@@ -49,15 +199,23 @@ type AdminCafeDetails struct {
 	Latitude    float64  `json:"latitude"`
 	Longitude   float64  `json:"longitude"`
 	Amenities   []string `json:"amenities"`
+
+	OpeningHours *model.OpeningHours `json:"opening_hours,omitempty"`
+
+	Status          string  `json:"status"`
+	ReopensOn       *string `json:"reopens_on,omitempty"`
+	StatusReason    *string `json:"status_reason,omitempty"`
+	StatusChangedAt *string `json:"status_changed_at,omitempty"`
 }
 
 type adminCafeImportItem struct {
-	Name        string   `json:"name"`
-	Address     string   `json:"address"`
-	Description *string  `json:"description"`
-	Latitude    *float64 `json:"latitude"`
-	Longitude   *float64 `json:"longitude"`
-	Amenities   []string `json:"amenities"`
+	Name         string              `json:"name"`
+	Address      string              `json:"address"`
+	Description  *string             `json:"description"`
+	Latitude     *float64            `json:"latitude"`
+	Longitude    *float64            `json:"longitude"`
+	Amenities    []string            `json:"amenities"`
+	OpeningHours *model.OpeningHours `json:"opening_hours"`
 }
 
 type adminCafeImportRequest struct {
@@ -97,3 +255,199 @@ type adminCafeImportResponse struct {
 	Results []adminCafeImportResultItem `json:"results"`
 	Issues  []adminCafeImportIssue      `json:"issues,omitempty"`
 }
+
+type CafeDuplicateCafe struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Address      string  `json:"address"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	ReviewsCount int     `json:"reviews_count"`
+	PhotosCount  int     `json:"photos_count"`
+}
+
+type CafeDuplicateCandidate struct {
+	ID             int64             `json:"id"`
+	Status         string            `json:"status"`
+	DistanceM      float64           `json:"distance_m"`
+	NameSimilarity float64           `json:"name_similarity"`
+	Score          float64           `json:"score"`
+	DetectedAt     string            `json:"detected_at"`
+	Cafe           CafeDuplicateCafe `json:"cafe"`
+	Duplicate      CafeDuplicateCafe `json:"duplicate"`
+}
+
+type CafeDuplicateScanResult struct {
+	Found   int `json:"found"`
+	New     int `json:"new"`
+	Removed int `json:"removed"`
+	// Skipped is set when another instance holds the scan lock.
+	Skipped bool `json:"skipped,omitempty"`
+}
+
+type cafeMergeRequest struct {
+	SourceID string `json:"source_id"`
+}
+
+type CafeMergeMoved struct {
+	Reviews               int64 `json:"reviews"`
+	ReviewsDropped        int64 `json:"reviews_dropped"`
+	VisitVerifications    int64 `json:"visit_verifications"`
+	Photos                int64 `json:"photos"`
+	Favorites             int64 `json:"favorites"`
+	CheckIns              int64 `json:"check_ins"`
+	RatingSnapshots       int64 `json:"rating_snapshots"`
+	ModerationSubmissions int64 `json:"moderation_submissions"`
+	MetricsEvents         int64 `json:"metrics_events"`
+}
+
+type CafeMergeResult struct {
+	MergeID  int64          `json:"merge_id"`
+	TargetID string         `json:"target_id"`
+	SourceID string         `json:"source_id"`
+	Moved    CafeMergeMoved `json:"moved"`
+}
+
+// CafeRevisionChange holds the API-shaped values of one field before and
+// after a revision; From is null for the first revision of a cafe.
+type CafeRevisionChange struct {
+	From json.RawMessage `json:"from"`
+	To   json.RawMessage `json:"to"`
+}
+
+type CafeRevision struct {
+	Revision      int                           `json:"revision"`
+	Source        string                        `json:"source"`
+	ActorUserID   *string                       `json:"actor_user_id,omitempty"`
+	ActorName     *string                       `json:"actor_name,omitempty"`
+	Reference     *string                       `json:"reference,omitempty"`
+	ChangedFields []string                      `json:"changed_fields"`
+	Diff          map[string]CafeRevisionChange `json:"diff"`
+	CreatedAt     string                        `json:"created_at"`
+}
+
+type CafeRevisionList struct {
+	Items      []CafeRevision `json:"items"`
+	NextBefore *int           `json:"next_before,omitempty"`
+}
+
+type CafeRevertResult struct {
+	RevertedTo int              `json:"reverted_to"`
+	Changed    bool             `json:"changed"`
+	Revision   int              `json:"revision"`
+	Cafe       AdminCafeDetails `json:"cafe"`
+}
+
+type adminCafeStatusRequest struct {
+	Status    string `json:"status"`
+	ReopensOn string `json:"reopens_on"`
+	Reason    string `json:"reason"`
+}
+
+// cafeStatusUpdate is a validated lifecycle transition; ReopensOn is set only
+// for temporarily closed cafes.
+type cafeStatusUpdate struct {
+	Status    string
+	ReopensOn *time.Time
+	Reason    string
+}
+
+type CafeImportJob struct {
+	ID         string                 `json:"id"`
+	Status     string                 `json:"status"`
+	Format     string                 `json:"format"`
+	Mode       string                 `json:"mode"`
+	DryRun     bool                   `json:"dry_run"`
+	SourceName string                 `json:"source_name,omitempty"`
+	Total      int                    `json:"total"`
+	Processed  int                    `json:"processed"`
+	Progress   float64                `json:"progress"`
+	Summary    adminCafeImportSummary `json:"summary"`
+	Error      string                 `json:"error,omitempty"`
+	CreatedBy  string                 `json:"created_by,omitempty"`
+	CreatedAt  string                 `json:"created_at"`
+	StartedAt  *string                `json:"started_at,omitempty"`
+	FinishedAt *string                `json:"finished_at,omitempty"`
+}
+
+// cafeImportJobInput is a parsed upload ready to be queued.
+type cafeImportJobInput struct {
+	Format     string
+	Mode       string
+	DryRun     bool
+	SourceName string
+	ActorID    string
+	Records    []cafeImportRecord
+}
+
+// cafeImportJobState is what a batch needs to know about its job.
+type cafeImportJobState struct {
+	ID        string
+	Mode      string
+	DryRun    bool
+	Status    string
+	Processed int
+	Total     int
+	CreatedBy string
+}
+
+type cafeImportJobItem struct {
+	Index       int
+	Item        adminCafeImportItem
+	ParseIssues []adminCafeImportIssue
+}
+
+type cafeImportJobItemResult struct {
+	Result adminCafeImportResultItem
+	Issues []adminCafeImportIssue
+}
+
+// CafeImportJobIssue is one line of the downloadable issue report.
+type CafeImportJobIssue struct {
+	Index   int    `json:"index"`
+	Status  string `json:"status"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+	Name    string `json:"name"`
+	Address string `json:"address"`
+	CafeID  string `json:"cafe_id,omitempty"`
+}
+
+// CafeExportFilter narrows an export. BBox is min_lng, min_lat, max_lng,
+// max_lat.
+type CafeExportFilter struct {
+	BBox         *[4]float64
+	Amenities    []string
+	UpdatedSince *time.Time
+}
+
+type CafeExportRating struct {
+	Rating               float64 `json:"rating"`
+	ReviewsCount         int     `json:"reviews_count"`
+	VerifiedReviewsCount int     `json:"verified_reviews_count"`
+}
+
+type CafeExportPhoto struct {
+	URL     string `json:"url"`
+	Kind    string `json:"kind"`
+	IsCover bool   `json:"is_cover,omitempty"`
+}
+
+// CafeExportItem is one exported cafe. Its name, address, description,
+// coordinates, amenities and opening_hours use the adminCafeImportItem keys,
+// so an export can be imported back. UpdatedAt is the time of the latest
+// revision.
+type CafeExportItem struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	Address      string              `json:"address"`
+	Description  string              `json:"description"`
+	Latitude     float64             `json:"latitude"`
+	Longitude    float64             `json:"longitude"`
+	Amenities    []string            `json:"amenities"`
+	OpeningHours *model.OpeningHours `json:"opening_hours,omitempty"`
+	Status       string              `json:"status"`
+	Rating       *CafeExportRating   `json:"rating,omitempty"`
+	Photos       []CafeExportPhoto   `json:"photos"`
+	UpdatedAt    string              `json:"updated_at"`
+}
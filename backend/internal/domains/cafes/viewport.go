@@ -0,0 +1,57 @@
+package cafes
+
+import (
+	"fmt"
+	"math"
+
+	"backend/internal/shared/validation"
+)
+
+const (
+	ViewportModeClusters = "clusters"
+	ViewportModeCafes    = "cafes"
+
+	viewportMinZoom = 0
+	viewportMaxZoom = 22
+	// viewportCafesMinZoom is the first zoom at which individual cafes are
+	// returned instead of clusters.
+	viewportCafesMinZoom = 14
+	// viewportCellsPerTile splits one 256px tile into 4x4 cells of 64px.
+	viewportCellsPerTile = 4
+	viewportMaxCafes     = 500
+)
+
+type viewportBounds struct {
+	MinLat float64
+	MinLng float64
+	MaxLat float64
+	MaxLng float64
+}
+
+func (b viewportBounds) center() (float64, float64) {
+	return (b.MinLat + b.MaxLat) / 2, (b.MinLng + b.MaxLng) / 2
+}
+
+func (b viewportBounds) validate() error {
+	for _, value := range []float64{b.MinLat, b.MinLng, b.MaxLat, b.MaxLng} {
+		if !validation.IsFinite(value) {
+			return fmt.Errorf("Координаты bbox должны быть числами.")
+		}
+	}
+	if b.MinLat < -90 || b.MaxLat > 90 {
+		return fmt.Errorf("Широта bbox должна быть в диапазоне от -90 до 90.")
+	}
+	if b.MinLng < -180 || b.MaxLng > 180 {
+		return fmt.Errorf("Долгота bbox должна быть в диапазоне от -180 до 180.")
+	}
+	if b.MinLat >= b.MaxLat || b.MinLng >= b.MaxLng {
+		return fmt.Errorf("min_lat/min_lng должны быть меньше max_lat/max_lng.")
+	}
+	return nil
+}
+
+// viewportCellDegrees returns the clustering grid step for a zoom level: the
+// width of one tile in degrees divided into viewportCellsPerTile cells.
+func viewportCellDegrees(zoom int) float64 {
+	return 360.0 / math.Pow(2, float64(zoom)) / viewportCellsPerTile
+}
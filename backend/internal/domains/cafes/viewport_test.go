@@ -0,0 +1,38 @@
+package cafes
+
+import (
+	"math"
+	"testing"
+)
+
+func TestViewportCellDegrees(t *testing.T) {
+	t.Parallel()
+
+	if got := viewportCellDegrees(0); got != 90 {
+		t.Fatalf("zoom 0: expected 90 degrees, got %v", got)
+	}
+	if got := viewportCellDegrees(10); math.Abs(got-360.0/1024/4) > 1e-12 {
+		t.Fatalf("zoom 10: unexpected cell size %v", got)
+	}
+}
+
+func TestViewportBoundsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := viewportBounds{MinLat: 55.5, MinLng: 37.3, MaxLat: 55.9, MaxLng: 37.9}
+	if err := valid.validate(); err != nil {
+		t.Fatalf("expected valid bounds, got %v", err)
+	}
+
+	invalid := []viewportBounds{
+		{MinLat: 56, MinLng: 37.3, MaxLat: 55.9, MaxLng: 37.9},
+		{MinLat: -91, MinLng: 37.3, MaxLat: 55.9, MaxLng: 37.9},
+		{MinLat: 55.5, MinLng: 170, MaxLat: 55.9, MaxLng: -170},
+		{MinLat: math.NaN(), MinLng: 37.3, MaxLat: 55.9, MaxLng: 37.9},
+	}
+	for _, bounds := range invalid {
+		if err := bounds.validate(); err == nil {
+			t.Fatalf("expected error for %+v", bounds)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cities
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometry    json.RawMessage `json:"geometry"`
+}
+
+// parseBoundary accepts a GeoJSON Polygon or MultiPolygon, bare or wrapped in
+// a Feature, checks its shape and returns it as a MultiPolygon geometry for
+// ST_GeomFromGeoJSON. Topology (self-intersections and the like) is left to
+// PostGIS.
+func parseBoundary(raw json.RawMessage) ([]byte, error) {
+	if len(raw) > maxBoundaryBytes {
+		return nil, fmt.Errorf("%w: larger than %d bytes", ErrInvalidBoundary, maxBoundaryBytes)
+	}
+	var object geoJSONObject
+	if err := json.Unmarshal(raw, &object); err != nil {
+		return nil, fmt.Errorf("%w: not a GeoJSON object", ErrInvalidBoundary)
+	}
+	if object.Type == "Feature" {
+		if len(object.Geometry) == 0 {
+			return nil, fmt.Errorf("%w: feature without geometry", ErrInvalidBoundary)
+		}
+		return parseBoundary(object.Geometry)
+	}
+
+	var polygons [][][][2]float64
+	switch object.Type {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(object.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("%w: malformed Polygon coordinates", ErrInvalidBoundary)
+		}
+		polygons = [][][][2]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(object.Coordinates, &polygons); err != nil {
+			return nil, fmt.Errorf("%w: malformed MultiPolygon coordinates", ErrInvalidBoundary)
+		}
+	default:
+		return nil, fmt.Errorf("%w: expected Polygon or MultiPolygon, got %q", ErrInvalidBoundary, object.Type)
+	}
+
+	if err := validatePolygons(polygons); err != nil {
+		return nil, err
+	}
+	return json.Marshal(struct {
+		Type        string           `json:"type"`
+		Coordinates [][][][2]float64 `json:"coordinates"`
+	}{Type: "MultiPolygon", Coordinates: polygons})
+}
+
+func validatePolygons(polygons [][][][2]float64) error {
+	if len(polygons) == 0 {
+		return fmt.Errorf("%w: no polygons", ErrInvalidBoundary)
+	}
+	points := 0
+	for i, polygon := range polygons {
+		if len(polygon) == 0 {
+			return fmt.Errorf("%w: polygon %d has no rings", ErrInvalidBoundary, i)
+		}
+		for j, ring := range polygon {
+			if len(ring) < 4 {
+				return fmt.Errorf("%w: polygon %d ring %d has fewer than 4 points", ErrInvalidBoundary, i, j)
+			}
+			if ring[0] != ring[len(ring)-1] {
+				return fmt.Errorf("%w: polygon %d ring %d is not closed", ErrInvalidBoundary, i, j)
+			}
+			for _, point := range ring {
+				lng, lat := point[0], point[1]
+				if math.IsNaN(lng) || math.IsNaN(lat) || lng < -180 || lng > 180 || lat < -90 || lat > 90 {
+					return fmt.Errorf("%w: point [%v, %v] is out of range", ErrInvalidBoundary, lng, lat)
+				}
+			}
+			points += len(ring)
+		}
+	}
+	if points > maxBoundaryPoints {
+		return fmt.Errorf("%w: more than %d points", ErrInvalidBoundary, maxBoundaryPoints)
+	}
+	return nil
+}
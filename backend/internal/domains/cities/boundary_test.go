@@ -0,0 +1,91 @@
+package cities
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const square = `[[[30.0,59.8],[30.6,59.8],[30.6,60.1],[30.0,60.1],[30.0,59.8]]]`
+
+func TestParseBoundaryWrapsPolygonIntoMultiPolygon(t *testing.T) {
+	t.Parallel()
+
+	for name, raw := range map[string]string{
+		"polygon":      `{"type":"Polygon","coordinates":` + square + `}`,
+		"multipolygon": `{"type":"MultiPolygon","coordinates":[` + square + `]}`,
+		"feature":      `{"type":"Feature","properties":{"name":"СПб"},"geometry":{"type":"Polygon","coordinates":` + square + `}}`,
+	} {
+		out, err := parseBoundary(json.RawMessage(raw))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var geometry struct {
+			Type        string          `json:"type"`
+			Coordinates [][][][]float64 `json:"coordinates"`
+		}
+		if err := json.Unmarshal(out, &geometry); err != nil {
+			t.Fatalf("%s: output is not JSON: %v", name, err)
+		}
+		if geometry.Type != "MultiPolygon" || len(geometry.Coordinates) != 1 || len(geometry.Coordinates[0][0]) != 5 {
+			t.Fatalf("%s: unexpected output %s", name, out)
+		}
+	}
+}
+
+func TestParseBoundaryRejectsBadShapes(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"point":        `{"type":"Point","coordinates":[30.3,59.9]}`,
+		"open ring":    `{"type":"Polygon","coordinates":[[[30.0,59.8],[30.6,59.8],[30.6,60.1],[30.0,60.1]]]}`,
+		"short ring":   `{"type":"Polygon","coordinates":[[[30.0,59.8],[30.6,59.8],[30.0,59.8]]]}`,
+		"out of range": `{"type":"Polygon","coordinates":[[[30.0,59.8],[190.0,59.8],[30.6,60.1],[30.0,59.8]]]}`,
+		"empty":        `{"type":"MultiPolygon","coordinates":[]}`,
+		"not json":     `"Санкт-Петербург"`,
+	}
+	for name, raw := range cases {
+		_, err := parseBoundary(json.RawMessage(raw))
+		if !errors.Is(err, ErrInvalidBoundary) {
+			t.Fatalf("%s: expected ErrInvalidBoundary, got %v", name, err)
+		}
+	}
+}
+
+func TestNormalizeCityInput(t *testing.T) {
+	t.Parallel()
+
+	input, err := normalizeCityInput("saint-petersburg", upsertCityRequest{
+		Name:     " Санкт-Петербург ",
+		Boundary: json.RawMessage(`{"type":"Polygon","coordinates":` + square + `}`),
+	})
+	if err != nil {
+		t.Fatalf("normalizeCityInput: %v", err)
+	}
+	if input.Name != "Санкт-Петербург" || input.Timezone != "Europe/Moscow" || input.DefaultZoom != defaultZoom {
+		t.Fatalf("unexpected defaults: %+v", input)
+	}
+	if input.CenterLat != nil || input.CenterLng != nil {
+		t.Fatalf("center should be derived from the boundary when omitted: %+v", input)
+	}
+
+	lat := 59.93
+	_, err = normalizeCityInput("saint-petersburg", upsertCityRequest{
+		Name:      "Санкт-Петербург",
+		CenterLat: &lat,
+		Boundary:  json.RawMessage(`{"type":"Polygon","coordinates":` + square + `}`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "вместе") {
+		t.Fatalf("expected an error for a half-specified center, got %v", err)
+	}
+
+	_, err = normalizeCityInput("saint-petersburg", upsertCityRequest{
+		Name:     "Санкт-Петербург",
+		Timezone: "Mars/Olympus",
+		Boundary: json.RawMessage(`{"type":"Polygon","coordinates":` + square + `}`),
+	})
+	if err == nil || !strings.Contains(err.Error(), "часовой пояс") {
+		t.Fatalf("expected an unknown timezone error, got %v", err)
+	}
+}
@@ -0,0 +1,160 @@
+package cities
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func NewDefaultHandler(pool *pgxpool.Pool) *Handler {
+	repository := NewRepository(pool)
+	service := NewService(repository)
+	return NewHandler(service)
+}
+
+func (h *Handler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	result, err := h.service.List(ctx)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить список городов.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) Get(c *gin.Context) {
+	slug, ok := readSlug(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	city, err := h.service.Get(ctx, slug)
+	if err != nil {
+		respondCityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, city)
+}
+
+func (h *Handler) Locate(c *gin.Context) {
+	lat, err := validation.ParseFloat(c.Query("lat"))
+	if err != nil || !validation.IsFinite(lat) || lat < -90 || lat > 90 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lat должен быть в диапазоне от -90 до 90.", nil)
+		return
+	}
+	lng, err := validation.ParseFloat(c.Query("lng"))
+	if err != nil || !validation.IsFinite(lng) || lng < -180 || lng > 180 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "lng должен быть в диапазоне от -180 до 180.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	city, err := h.service.Locate(ctx, lat, lng)
+	if err != nil {
+		respondCityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, city)
+}
+
+func (h *Handler) AdminUpsert(c *gin.Context) {
+	slug, ok := readSlug(c)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBoundaryBytes+64<<10)
+	var req upsertCityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	input, err := normalizeCityInput(slug, req)
+	if errors.Is(err, ErrInvalidBoundary) {
+		respondCityError(c, err)
+		return
+	}
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	// Reassigning every cafe to its city can take a moment on a large table.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	city, created, err := h.service.Upsert(ctx, input)
+	if err != nil {
+		respondCityError(c, err)
+		return
+	}
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, city)
+}
+
+func (h *Handler) AdminDelete(c *gin.Context) {
+	slug, ok := readSlug(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := h.service.Delete(ctx, slug); err != nil {
+		respondCityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+func readSlug(c *gin.Context) (string, bool) {
+	slug, err := validation.ParseCitySlug(c.Param("slug"))
+	if err == nil && slug == "" {
+		err = errors.New("Некорректный slug города.")
+	}
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return "", false
+	}
+	return slug, true
+}
+
+func respondCityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+	case errors.Is(err, ErrInvalidBoundary):
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "boundary должен быть корректным GeoJSON Polygon или MultiPolygon.", gin.H{
+			"reason": strings.TrimPrefix(err.Error(), ErrInvalidBoundary.Error()+": "),
+		})
+	default:
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+	}
+}
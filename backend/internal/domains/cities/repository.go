@@ -0,0 +1,206 @@
+package cities
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+const cityColumns = `ci.id::text,
+  ci.slug,
+  ci.name,
+  ci.timezone,
+  ci.center_lat,
+  ci.center_lng,
+  ci.default_zoom,
+  ST_XMin(ci.boundary),
+  ST_YMin(ci.boundary),
+  ST_XMax(ci.boundary),
+  ST_YMax(ci.boundary),
+  (
+    select count(*)::int
+    from public.cafes c
+    where c.city_id = ci.id
+      and c.status in ('active', 'temporarily_closed')
+  ) as cafes_count,
+  ci.updated_at`
+
+func scanCity(row pgx.Row, extra ...any) (City, error) {
+	var (
+		city      City
+		updatedAt time.Time
+	)
+	dest := []any{
+		&city.ID,
+		&city.Slug,
+		&city.Name,
+		&city.Timezone,
+		&city.CenterLat,
+		&city.CenterLng,
+		&city.DefaultZoom,
+		&city.BBox[0],
+		&city.BBox[1],
+		&city.BBox[2],
+		&city.BBox[3],
+		&city.CafesCount,
+		&updatedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return City{}, err
+	}
+	city.UpdatedAt = updatedAt.UTC().Format(time.RFC3339)
+	return city, nil
+}
+
+func (r *Repository) ListCities(ctx context.Context) ([]City, error) {
+	rows, err := r.pool.Query(ctx, `select `+cityColumns+`
+from public.cities ci
+order by ci.name asc, ci.slug asc`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]City, 0, 8)
+	for rows.Next() {
+		city, err := scanCity(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, city)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetCity returns the city with its boundary.
+func (r *Repository) GetCity(ctx context.Context, slug string) (City, error) {
+	var boundary []byte
+	city, err := scanCity(r.pool.QueryRow(ctx, `select `+cityColumns+`,
+  ST_AsGeoJSON(ci.boundary, 6)
+from public.cities ci
+where ci.slug = $1`, slug), &boundary)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return City{}, ErrNotFound
+	}
+	if err != nil {
+		return City{}, err
+	}
+	city.Boundary = boundary
+	return city, nil
+}
+
+// LocateCity returns the city whose boundary covers the point, using the same
+// rule as the cafe assignment trigger.
+func (r *Repository) LocateCity(ctx context.Context, lat, lng float64) (City, error) {
+	city, err := scanCity(r.pool.QueryRow(ctx, `select `+cityColumns+`
+from public.cities ci
+where ci.id = public.city_for_point(ST_SetSRID(ST_MakePoint($2::double precision, $1::double precision), 4326))`, lat, lng))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return City{}, ErrNotFound
+	}
+	return city, err
+}
+
+// UpsertCity creates or replaces a city by slug. Cafes are reassigned by the
+// cities_reassign_cafes_trg trigger in the same transaction.
+func (r *Repository) UpsertCity(ctx context.Context, input CityInput) (City, bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return City{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		valid  bool
+		reason string
+	)
+	if err := tx.QueryRow(
+		ctx,
+		`select ST_IsValid(g), ST_IsValidReason(g)
+		   from ST_SetSRID(ST_GeomFromGeoJSON($1::text), 4326) as g`,
+		string(input.Boundary),
+	).Scan(&valid, &reason); err != nil {
+		return City{}, false, fmt.Errorf("%w: %v", ErrInvalidBoundary, err)
+	}
+	if !valid {
+		return City{}, false, fmt.Errorf("%w: %s", ErrInvalidBoundary, reason)
+	}
+
+	var (
+		id       string
+		inserted bool
+	)
+	if err := tx.QueryRow(
+		ctx,
+		`with boundary as (
+  select ST_Multi(ST_SetSRID(ST_GeomFromGeoJSON($6::text), 4326))::geometry(MultiPolygon, 4326) as g
+)
+insert into public.cities (slug, name, timezone, center_lat, center_lng, default_zoom, boundary)
+select
+  $1,
+  $2,
+  $3,
+  coalesce($4::double precision, ST_Y(ST_PointOnSurface(boundary.g))),
+  coalesce($5::double precision, ST_X(ST_PointOnSurface(boundary.g))),
+  $7,
+  boundary.g
+from boundary
+on conflict (slug) do update
+   set name = excluded.name,
+       timezone = excluded.timezone,
+       center_lat = excluded.center_lat,
+       center_lng = excluded.center_lng,
+       default_zoom = excluded.default_zoom,
+       boundary = excluded.boundary,
+       updated_at = now()
+returning id::text, (xmax = 0)`,
+		input.Slug,
+		input.Name,
+		input.Timezone,
+		input.CenterLat,
+		input.CenterLng,
+		string(input.Boundary),
+		input.DefaultZoom,
+	).Scan(&id, &inserted); err != nil {
+		return City{}, false, err
+	}
+
+	city, err := scanCity(tx.QueryRow(ctx, `select `+cityColumns+`
+from public.cities ci
+where ci.id = $1::uuid`, id))
+	if err != nil {
+		return City{}, false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return City{}, false, err
+	}
+	return city, inserted, nil
+}
+
+// DeleteCity removes a city; its cafes fall back to the next covering city,
+// if any.
+func (r *Repository) DeleteCity(ctx context.Context, slug string) error {
+	tag, err := r.pool.Exec(ctx, `delete from public.cities where slug = $1`, slug)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
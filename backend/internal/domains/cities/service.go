@@ -0,0 +1,94 @@
+package cities
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/config"
+	"backend/internal/shared/validation"
+)
+
+type Service struct {
+	repository *Repository
+}
+
+func NewService(repository *Repository) *Service {
+	return &Service{repository: repository}
+}
+
+func (s *Service) List(ctx context.Context) (ListResponse, error) {
+	items, err := s.repository.ListCities(ctx)
+	if err != nil {
+		return ListResponse{}, err
+	}
+	return ListResponse{Cities: items}, nil
+}
+
+func (s *Service) Get(ctx context.Context, slug string) (City, error) {
+	return s.repository.GetCity(ctx, slug)
+}
+
+func (s *Service) Locate(ctx context.Context, lat, lng float64) (City, error) {
+	return s.repository.LocateCity(ctx, lat, lng)
+}
+
+func (s *Service) Upsert(ctx context.Context, input CityInput) (City, bool, error) {
+	return s.repository.UpsertCity(ctx, input)
+}
+
+func (s *Service) Delete(ctx context.Context, slug string) error {
+	return s.repository.DeleteCity(ctx, slug)
+}
+
+// normalizeCityInput validates an admin upsert. Errors are user-facing,
+// except ErrInvalidBoundary, which carries an English detail.
+func normalizeCityInput(slug string, req upsertCityRequest) (CityInput, error) {
+	input := CityInput{
+		Slug:        slug,
+		Name:        strings.TrimSpace(req.Name),
+		Timezone:    strings.TrimSpace(req.Timezone),
+		DefaultZoom: defaultZoom,
+	}
+	if input.Name == "" {
+		return CityInput{}, fmt.Errorf("Название города обязательно.")
+	}
+	if utf8.RuneCountInString(input.Name) > maxNameRunes {
+		return CityInput{}, fmt.Errorf("Название города слишком длинное.")
+	}
+	if input.Timezone == "" {
+		input.Timezone = config.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(input.Timezone); err != nil {
+		return CityInput{}, fmt.Errorf("Неизвестный часовой пояс %q.", input.Timezone)
+	}
+	if (req.CenterLat == nil) != (req.CenterLng == nil) {
+		return CityInput{}, fmt.Errorf("center_lat и center_lng задаются вместе.")
+	}
+	if req.CenterLat != nil {
+		if !validation.IsFinite(*req.CenterLat) || *req.CenterLat < -90 || *req.CenterLat > 90 {
+			return CityInput{}, fmt.Errorf("center_lat должен быть в диапазоне от -90 до 90.")
+		}
+		if !validation.IsFinite(*req.CenterLng) || *req.CenterLng < -180 || *req.CenterLng > 180 {
+			return CityInput{}, fmt.Errorf("center_lng должен быть в диапазоне от -180 до 180.")
+		}
+		input.CenterLat, input.CenterLng = req.CenterLat, req.CenterLng
+	}
+	if req.DefaultZoom != nil {
+		if *req.DefaultZoom < 0 || *req.DefaultZoom > 22 {
+			return CityInput{}, fmt.Errorf("default_zoom должен быть целым числом от 0 до 22.")
+		}
+		input.DefaultZoom = *req.DefaultZoom
+	}
+	if len(req.Boundary) == 0 || string(req.Boundary) == "null" {
+		return CityInput{}, fmt.Errorf("Граница города (boundary) обязательна.")
+	}
+	boundary, err := parseBoundary(req.Boundary)
+	if err != nil {
+		return CityInput{}, err
+	}
+	input.Boundary = boundary
+	return input, nil
+}
@@ -0,0 +1,62 @@
+package cities
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+const (
+	defaultZoom      = 12
+	maxNameRunes     = 120
+	maxBoundaryBytes = 4 << 20
+	// maxBoundaryPoints bounds the polygons admins can upload; OSM city
+	// boundaries are a few thousand points.
+	maxBoundaryPoints = 200000
+)
+
+var (
+	ErrNotFound        = errors.New("city not found")
+	ErrInvalidBoundary = errors.New("invalid city boundary")
+)
+
+// City is the public view of a city. BBox is [min_lng, min_lat, max_lng,
+// max_lat] of the boundary; Boundary (GeoJSON MultiPolygon) is only loaded
+// for a single city.
+type City struct {
+	ID          string          `json:"id"`
+	Slug        string          `json:"slug"`
+	Name        string          `json:"name"`
+	Timezone    string          `json:"timezone"`
+	CenterLat   float64         `json:"center_lat"`
+	CenterLng   float64         `json:"center_lng"`
+	DefaultZoom int             `json:"default_zoom"`
+	BBox        [4]float64      `json:"bbox"`
+	CafesCount  int             `json:"cafes_count"`
+	Boundary    json.RawMessage `json:"boundary,omitempty"`
+	UpdatedAt   string          `json:"updated_at"`
+}
+
+type ListResponse struct {
+	Cities []City `json:"cities"`
+}
+
+type upsertCityRequest struct {
+	Name        string          `json:"name"`
+	Timezone    string          `json:"timezone"`
+	CenterLat   *float64        `json:"center_lat"`
+	CenterLng   *float64        `json:"center_lng"`
+	DefaultZoom *int            `json:"default_zoom"`
+	Boundary    json.RawMessage `json:"boundary"`
+}
+
+// CityInput is a validated upsert. A nil center is derived from the
+// boundary (a point guaranteed to lie inside it).
+type CityInput struct {
+	Slug        string
+	Name        string
+	Timezone    string
+	CenterLat   *float64
+	CenterLng   *float64
+	DefaultZoom int
+	Boundary    []byte
+}
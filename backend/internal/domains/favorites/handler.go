@@ -9,6 +9,7 @@ import (
 
 	"backend/internal/auth"
 	"backend/internal/config"
+	"backend/internal/model"
 	"backend/internal/shared/httpx"
 	"backend/internal/shared/validation"
 
@@ -47,7 +48,8 @@ func (h *Handler) Add(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
 	defer cancel()
 
-	if err := h.service.EnsureCafeExists(ctx, cafeID); err != nil {
+	status, err := h.service.CafeStatus(ctx, cafeID)
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
 			return
@@ -55,6 +57,10 @@ func (h *Handler) Add(c *gin.Context) {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
 		return
 	}
+	if status == model.CafeStatusPermanentlyClosed {
+		httpx.RespondError(c, http.StatusConflict, "cafe_closed", "Кофейня закрыта навсегда.", nil)
+		return
+	}
 
 	if err := h.service.Add(ctx, userID, cafeID); err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось добавить в избранное.", nil)
@@ -29,6 +29,21 @@ func (r *Repository) Add(ctx context.Context, userID, cafeID string) error {
 	return err
 }
 
+// CafeStatus returns the lifecycle status of a cafe; soft-deleted cafes are
+// reported as pgx.ErrNoRows.
+func (r *Repository) CafeStatus(ctx context.Context, cafeID string) (string, error) {
+	var status string
+	err := r.pool.QueryRow(
+		ctx,
+		`select status from cafes where id = $1::uuid and status <> 'deleted'`,
+		cafeID,
+	).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
 func (r *Repository) Remove(ctx context.Context, userID, cafeID string) error {
 	_, err := r.pool.Exec(
 		ctx,
@@ -48,10 +63,13 @@ func (r *Repository) List(ctx context.Context, userID string) ([]model.CafeRespo
 		        coalesce(c.description, '') as description,
 		        c.lat,
 		        c.lng,
-		        coalesce(c.amenities, '{}'::text[]) as amenities
+		        coalesce(c.amenities, '{}'::text[]) as amenities,
+		        c.status,
+		        to_char(c.reopens_on, 'YYYY-MM-DD') as reopens_on
 		   from user_favorite_cafes uf
 		   join cafes c on c.id = uf.cafe_id
 		  where uf.user_id = $1::uuid
+		    and c.status <> 'deleted'
 		  order by uf.created_at desc`,
 		userID,
 	)
@@ -74,6 +92,8 @@ func (r *Repository) List(ctx context.Context, userID string) ([]model.CafeRespo
 			&item.Latitude,
 			&item.Longitude,
 			&item.Amenities,
+			&item.Status,
+			&item.ReopensOn,
 		); err != nil {
 			return nil, err
 		}
@@ -36,6 +36,6 @@ func (s *Service) List(ctx context.Context, userID string) ([]model.CafeResponse
 	return items, nil
 }
 
-func (s *Service) EnsureCafeExists(ctx context.Context, cafeID string) error {
-	return photos.EnsureCafeExists(ctx, s.repository.Pool(), cafeID)
+func (s *Service) CafeStatus(ctx context.Context, cafeID string) (string, error) {
+	return s.repository.CafeStatus(ctx, cafeID)
 }
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -80,7 +81,7 @@ func (h *Handler) IngestEvents(c *gin.Context) {
 }
 
 func (h *Handler) GetNorthStar(c *gin.Context) {
-	days, cafeID, ok := readNorthStarRangeParams(c)
+	days, cafeID, city, ok := readNorthStarRangeParams(c)
 	if !ok {
 		return
 	}
@@ -88,7 +89,11 @@ func (h *Handler) GetNorthStar(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
 	defer cancel()
 
-	report, err := h.service.GetNorthStarReport(ctx, days, cafeID, time.Now())
+	report, err := h.service.GetNorthStarReport(ctx, days, cafeID, city, time.Now())
+	if errors.Is(err, ErrCityNotFound) {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+		return
+	}
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить метрики North Star.", nil)
 		return
@@ -98,7 +103,7 @@ func (h *Handler) GetNorthStar(c *gin.Context) {
 }
 
 func (h *Handler) GetFunnel(c *gin.Context) {
-	days, cafeID, ok := readNorthStarRangeParams(c)
+	days, cafeID, city, ok := readNorthStarRangeParams(c)
 	if !ok {
 		return
 	}
@@ -106,7 +111,11 @@ func (h *Handler) GetFunnel(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
 	defer cancel()
 
-	report, err := h.service.GetFunnelReport(ctx, days, cafeID, time.Now())
+	report, err := h.service.GetFunnelReport(ctx, days, cafeID, city, time.Now())
+	if errors.Is(err, ErrCityNotFound) {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+		return
+	}
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить funnel-метрики.", nil)
 		return
@@ -210,13 +219,13 @@ func (h *Handler) UpdateMapPerfAlertState(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "ok"})
 }
 
-func readNorthStarRangeParams(c *gin.Context) (int, string, bool) {
+func readNorthStarRangeParams(c *gin.Context) (int, string, string, bool) {
 	days := DefaultRangeDays
 	if rawDays := strings.TrimSpace(c.Query("days")); rawDays != "" {
 		value, err := strconv.Atoi(rawDays)
 		if err != nil || value <= 0 {
 			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "days должен быть целым числом больше 0.", nil)
-			return 0, "", false
+			return 0, "", "", false
 		}
 		if value > MaxRangeDays {
 			value = MaxRangeDays
@@ -226,9 +235,14 @@ func readNorthStarRangeParams(c *gin.Context) (int, string, bool) {
 	cafeID := strings.TrimSpace(c.Query("cafe_id"))
 	if cafeID != "" && !validation.IsValidUUID(cafeID) {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "cafe_id должен быть UUID.", nil)
-		return 0, "", false
+		return 0, "", "", false
+	}
+	city, err := validation.ParseCitySlug(c.Query("city"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return 0, "", "", false
 	}
-	return days, cafeID, true
+	return days, cafeID, city, true
 }
 
 func normalizeEvent(raw ingestEventRequest, userID string, now time.Time) (EventInput, error) {
@@ -17,6 +17,11 @@ type handlerRepositoryStub struct {
 	funnelCapturedCafeID string
 	lastAlertState       MapPerfAlertState
 	lastAlertAction      MapPerfAlertAction
+	missingCity          bool
+}
+
+func (r *handlerRepositoryStub) CityExists(ctx context.Context, slug string) (bool, error) {
+	return !r.missingCity, nil
 }
 
 func (r *handlerRepositoryStub) InsertEvents(ctx context.Context, events []EventInput) (int, error) {
@@ -28,6 +33,7 @@ func (r *handlerRepositoryStub) ListDailyNorthStarMetrics(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) ([]DailyNorthStarMetrics, error) {
 	r.capturedCafeID = cafeID
 	return nil, nil
@@ -38,6 +44,7 @@ func (r *handlerRepositoryStub) GetFunnelJourneyCounts(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) (FunnelJourneyCounts, error) {
 	r.funnelCapturedCafeID = cafeID
 	return FunnelJourneyCounts{}, nil
@@ -168,6 +175,26 @@ func TestGetNorthStar_ValidCafeID_PropagatesFilter(t *testing.T) {
 	}
 }
 
+func TestGetNorthStarAndFunnel_UnknownCity_ReturnsNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo := &handlerRepositoryStub{missingCity: true}
+	handler := NewHandler(NewService(repo))
+
+	router := gin.New()
+	router.GET("/api/admin/metrics/north-star", handler.GetNorthStar)
+	router.GET("/api/admin/metrics/funnel", handler.GetFunnel)
+
+	for _, path := range []string{"/api/admin/metrics/north-star", "/api/admin/metrics/funnel"} {
+		req := httptest.NewRequest(http.MethodGet, path+"?days=14&city=atlantis", nil)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%s: expected status 404, got %d, body=%s", path, rec.Code, rec.Body.String())
+		}
+	}
+}
+
 func TestGetFunnel_ValidCafeID_PropagatesFilter(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	repo := &handlerRepositoryStub{}
@@ -266,6 +266,7 @@ func (r *Repository) ListDailyNorthStarMetrics(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) ([]DailyNorthStarMetrics, error) {
 	const query = `with intents as (
 	select
@@ -275,6 +276,7 @@ func (r *Repository) ListDailyNorthStarMetrics(
 	where event_type in ('route_click', 'checkin_start')
 	  and journey_id <> ''
 	  and (nullif($3, '')::uuid is null or cafe_id = nullif($3, '')::uuid)
+	  and (nullif($4, '') is null or cafe_id in (select c.id from public.cafes c join public.cities ci on ci.id = c.city_id where ci.slug = $4))
 	  and occurred_at >= $1
 	  and occurred_at < $2
 	group by journey_id
@@ -290,11 +292,13 @@ func (r *Repository) ListDailyNorthStarMetrics(
 	join (
 		select review_id
 		from public.helpful_votes
+		where kind = 'helpful'
 		group by review_id
 	) hv on hv.review_id = e.review_id
 	where e.event_type = 'review_read'
 	  and e.journey_id <> ''
 	  and (nullif($3, '')::uuid is null or e.cafe_id = nullif($3, '')::uuid)
+	  and (nullif($4, '') is null or e.cafe_id in (select c.id from public.cafes c join public.cities ci on ci.id = c.city_id where ci.slug = $4))
 	  and e.occurred_at >= ($1 - interval '7 days')
 	  and e.occurred_at < $2
 	group by e.journey_id
@@ -318,7 +322,7 @@ from journeys
 group by day
 order by day asc`
 
-	rows, err := r.pool.Query(ctx, query, dateFrom.UTC(), dateTo.UTC(), cafeID)
+	rows, err := r.pool.Query(ctx, query, dateFrom.UTC(), dateTo.UTC(), cafeID, city)
 	if err != nil {
 		return nil, err
 	}
@@ -344,6 +348,7 @@ func (r *Repository) GetFunnelJourneyCounts(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) (FunnelJourneyCounts, error) {
 	const query = `with journey_events as (
 	select
@@ -358,6 +363,7 @@ func (r *Repository) GetFunnelJourneyCounts(
 	  and occurred_at >= $1
 	  and occurred_at < $2
 	  and (nullif($3, '')::uuid is null or cafe_id = nullif($3, '')::uuid)
+	  and (nullif($4, '') is null or cafe_id in (select c.id from public.cafes c join public.cities ci on ci.id = c.city_id where ci.slug = $4))
 	group by journey_id
 ), stage_flags as (
 	select
@@ -378,7 +384,7 @@ select
 from stage_flags`
 
 	var counts FunnelJourneyCounts
-	if err := r.pool.QueryRow(ctx, query, dateFrom.UTC(), dateTo.UTC(), cafeID).Scan(
+	if err := r.pool.QueryRow(ctx, query, dateFrom.UTC(), dateTo.UTC(), cafeID, city).Scan(
 		&counts.CardOpenJourneys,
 		&counts.ReviewReadJourneys,
 		&counts.RouteClickJourneys,
@@ -777,3 +783,13 @@ order by d.day asc`
 
 	return result, nil
 }
+
+func (r *Repository) CityExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(
+		ctx,
+		`select exists(select 1 from public.cities where slug = $1)`,
+		slug,
+	).Scan(&exists)
+	return exists, err
+}
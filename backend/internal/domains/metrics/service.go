@@ -2,16 +2,21 @@ package metrics
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"time"
 )
 
+// ErrCityNotFound is returned for a city filter that matches no city.
+var ErrCityNotFound = errors.New("city not found")
+
 type repository interface {
+	CityExists(ctx context.Context, slug string) (bool, error)
 	InsertEvents(ctx context.Context, events []EventInput) (int, error)
-	ListDailyNorthStarMetrics(ctx context.Context, dateFrom time.Time, dateTo time.Time, cafeID string) ([]DailyNorthStarMetrics, error)
-	GetFunnelJourneyCounts(ctx context.Context, dateFrom time.Time, dateTo time.Time, cafeID string) (FunnelJourneyCounts, error)
+	ListDailyNorthStarMetrics(ctx context.Context, dateFrom time.Time, dateTo time.Time, cafeID string, city string) ([]DailyNorthStarMetrics, error)
+	GetFunnelJourneyCounts(ctx context.Context, dateFrom time.Time, dateTo time.Time, cafeID string, city string) (FunnelJourneyCounts, error)
 	GetMapPerfSnapshot(ctx context.Context, dateFrom time.Time, dateTo time.Time) (MapPerfSnapshot, error)
 	ListMapPerfDailyMetrics(ctx context.Context, dateFrom time.Time, dateTo time.Time) ([]MapPerfDailyMetrics, error)
 	ListMapPerfNetworkMetrics(ctx context.Context, dateFrom time.Time, dateTo time.Time) ([]MapPerfNetworkMetrics, error)
@@ -37,15 +42,35 @@ func (s *Service) IngestEvents(ctx context.Context, events []EventInput) (int, e
 	return s.repository.InsertEvents(ctx, events)
 }
 
+// requireCity resolves a non-empty city filter, so an unknown slug is a 404
+// rather than an empty report.
+func (s *Service) requireCity(ctx context.Context, city string) error {
+	if city == "" {
+		return nil
+	}
+	exists, err := s.repository.CityExists(ctx, city)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCityNotFound
+	}
+	return nil
+}
+
 func (s *Service) GetNorthStarReport(
 	ctx context.Context,
 	days int,
 	cafeID string,
+	city string,
 	now time.Time,
 ) (NorthStarReport, error) {
+	if err := s.requireCity(ctx, city); err != nil {
+		return NorthStarReport{}, err
+	}
 	dateFrom, dateTo, normalizedDays := buildDateRange(days, now)
 
-	rawDaily, err := s.repository.ListDailyNorthStarMetrics(ctx, dateFrom, dateTo, cafeID)
+	rawDaily, err := s.repository.ListDailyNorthStarMetrics(ctx, dateFrom, dateTo, cafeID, city)
 	if err != nil {
 		return NorthStarReport{}, err
 	}
@@ -78,6 +103,7 @@ func (s *Service) GetNorthStarReport(
 			To:                  dateTo.Format(time.RFC3339),
 			Days:                normalizedDays,
 			CafeID:              cafeID,
+			City:                city,
 			VisitIntentJourneys: totalIntent,
 			NorthStarJourneys:   totalNorthStar,
 			Rate:                safeRate(totalNorthStar, totalIntent),
@@ -90,10 +116,14 @@ func (s *Service) GetFunnelReport(
 	ctx context.Context,
 	days int,
 	cafeID string,
+	city string,
 	now time.Time,
 ) (FunnelReport, error) {
+	if err := s.requireCity(ctx, city); err != nil {
+		return FunnelReport{}, err
+	}
 	dateFrom, dateTo, normalizedDays := buildDateRange(days, now)
-	counts, err := s.repository.GetFunnelJourneyCounts(ctx, dateFrom, dateTo, cafeID)
+	counts, err := s.repository.GetFunnelJourneyCounts(ctx, dateFrom, dateTo, cafeID, city)
 	if err != nil {
 		return FunnelReport{}, err
 	}
@@ -142,6 +172,7 @@ func (s *Service) GetFunnelReport(
 			To:     dateTo.Format(time.RFC3339),
 			Days:   normalizedDays,
 			CafeID: cafeID,
+			City:   city,
 		},
 		Stages: stages,
 	}, nil
@@ -10,6 +10,7 @@ type serviceRepositoryStub struct {
 	capturedDateFrom        time.Time
 	capturedDateTo          time.Time
 	capturedCafeID          string
+	capturedCity            string
 	rows                    []DailyNorthStarMetrics
 	funnelCounts            FunnelJourneyCounts
 	mapPerfSnapshot         MapPerfSnapshot
@@ -25,6 +26,10 @@ type serviceRepositoryStub struct {
 	lastResetExpiredAt      time.Time
 }
 
+func (r *serviceRepositoryStub) CityExists(ctx context.Context, slug string) (bool, error) {
+	return true, nil
+}
+
 func (r *serviceRepositoryStub) InsertEvents(ctx context.Context, events []EventInput) (int, error) {
 	return len(events), nil
 }
@@ -34,10 +39,12 @@ func (r *serviceRepositoryStub) ListDailyNorthStarMetrics(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) ([]DailyNorthStarMetrics, error) {
 	r.capturedDateFrom = dateFrom
 	r.capturedDateTo = dateTo
 	r.capturedCafeID = cafeID
+	r.capturedCity = city
 	return r.rows, nil
 }
 
@@ -46,6 +53,7 @@ func (r *serviceRepositoryStub) GetFunnelJourneyCounts(
 	dateFrom time.Time,
 	dateTo time.Time,
 	cafeID string,
+	city string,
 ) (FunnelJourneyCounts, error) {
 	r.capturedDateFrom = dateFrom
 	r.capturedDateTo = dateTo
@@ -150,7 +158,7 @@ func TestGetNorthStarReport_PropagatesCafeFilterAndBuildsDaily(t *testing.T) {
 	now := time.Date(2026, 2, 21, 15, 30, 0, 0, time.UTC)
 	cafeID := "550e8400-e29b-41d4-a716-446655440000"
 
-	report, err := service.GetNorthStarReport(context.Background(), 2, cafeID, now)
+	report, err := service.GetNorthStarReport(context.Background(), 2, cafeID, "saint-petersburg", now)
 	if err != nil {
 		t.Fatalf("GetNorthStarReport returned error: %v", err)
 	}
@@ -158,6 +166,9 @@ func TestGetNorthStarReport_PropagatesCafeFilterAndBuildsDaily(t *testing.T) {
 	if repo.capturedCafeID != cafeID {
 		t.Fatalf("expected cafe filter %q, got %q", cafeID, repo.capturedCafeID)
 	}
+	if repo.capturedCity != "saint-petersburg" || report.Summary.City != "saint-petersburg" {
+		t.Fatalf("city filter not propagated: repo=%q summary=%q", repo.capturedCity, report.Summary.City)
+	}
 
 	wantFrom := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
 	wantTo := time.Date(2026, 2, 22, 0, 0, 0, 0, time.UTC)
@@ -199,7 +210,7 @@ func TestGetFunnelReport_BuildsStagesAndConversions(t *testing.T) {
 	now := time.Date(2026, 2, 21, 15, 30, 0, 0, time.UTC)
 	cafeID := "550e8400-e29b-41d4-a716-446655440000"
 
-	report, err := service.GetFunnelReport(context.Background(), 14, cafeID, now)
+	report, err := service.GetFunnelReport(context.Background(), 14, cafeID, "", now)
 	if err != nil {
 		t.Fatalf("GetFunnelReport returned error: %v", err)
 	}
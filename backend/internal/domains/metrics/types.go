@@ -58,6 +58,7 @@ type NorthStarSummary struct {
 	To                  string  `json:"to"`
 	Days                int     `json:"days"`
 	CafeID              string  `json:"cafe_id,omitempty"`
+	City                string  `json:"city,omitempty"`
 	VisitIntentJourneys int     `json:"visit_intent_journeys"`
 	NorthStarJourneys   int     `json:"north_star_journeys"`
 	Rate                float64 `json:"rate"`
@@ -81,6 +82,7 @@ type FunnelSummary struct {
 	To     string `json:"to"`
 	Days   int    `json:"days"`
 	CafeID string `json:"cafe_id,omitempty"`
+	City   string `json:"city,omitempty"`
 }
 
 type FunnelStage struct {
@@ -12,9 +12,13 @@ import (
 
 	"backend/internal/auth"
 	"backend/internal/config"
+	"backend/internal/domains/osmsync"
 	"backend/internal/domains/photos"
+	"backend/internal/geocoding"
 	"backend/internal/media"
+	"backend/internal/model"
 	"backend/internal/reputation"
+	"backend/internal/shared/cafeaudit"
 	"backend/internal/shared/httpx"
 	"backend/internal/shared/validation"
 
@@ -25,7 +29,10 @@ import (
 
 const (
 	entityTypeCafe            = "cafe"
+	entityTypeCafeDetails     = "cafe_details"
 	entityTypeCafeDescription = "cafe_description"
+	entityTypeCafeHours       = "cafe_hours"
+	entityTypeCafeClosure     = "cafe_closure"
 	entityTypeCafePhoto       = "cafe_photo"
 	entityTypeMenuPhoto       = "menu_photo"
 	entityTypeReview          = "review"
@@ -45,6 +52,13 @@ type Handler struct {
 	cfg        config.MediaConfig
 	repository *Repository
 	service    *Service
+	geocoder   addressResolver
+}
+
+// addressResolver fills in the address of a cafe submitted with coordinates
+// only.
+type addressResolver interface {
+	Reverse(ctx context.Context, lat, lng float64) (geocoding.Result, error)
 }
 
 type moderationSubmissionResponse struct {
@@ -87,20 +101,31 @@ type submissionPhotoPresignResponse struct {
 }
 
 type submitCafeCreateRequest struct {
-	Name               string   `json:"name"`
-	Address            string   `json:"address"`
-	Description        string   `json:"description"`
-	Latitude           float64  `json:"latitude"`
-	Longitude          float64  `json:"longitude"`
-	Amenities          []string `json:"amenities"`
-	PhotoObjectKeys    []string `json:"photo_object_keys"`
-	MenuPhotoObjectKey []string `json:"menu_photo_object_keys"`
+	Name               string              `json:"name"`
+	Address            string              `json:"address"`
+	Description        string              `json:"description"`
+	Latitude           float64             `json:"latitude"`
+	Longitude          float64             `json:"longitude"`
+	Amenities          []string            `json:"amenities"`
+	OpeningHours       *model.OpeningHours `json:"opening_hours"`
+	PhotoObjectKeys    []string            `json:"photo_object_keys"`
+	MenuPhotoObjectKey []string            `json:"menu_photo_object_keys"`
 }
 
 type submitDescriptionRequest struct {
 	Description string `json:"description"`
 }
 
+type submitHoursRequest struct {
+	OpeningHours model.OpeningHours `json:"opening_hours"`
+}
+
+type submitClosureRequest struct {
+	Status    string `json:"status"`
+	ReopensOn string `json:"reopens_on"`
+	Comment   string `json:"comment"`
+}
+
 type submitPhotosRequest struct {
 	ObjectKeys []string `json:"object_keys"`
 }
@@ -110,20 +135,43 @@ type moderationDecisionRequest struct {
 }
 
 type cafeCreatePayload struct {
-	Name                string   `json:"name"`
-	Address             string   `json:"address"`
-	Description         string   `json:"description,omitempty"`
-	Latitude            float64  `json:"latitude"`
-	Longitude           float64  `json:"longitude"`
-	Amenities           []string `json:"amenities,omitempty"`
-	PhotoObjectKeys     []string `json:"photo_object_keys,omitempty"`
-	MenuPhotoObjectKeys []string `json:"menu_photo_object_keys,omitempty"`
+	Name                string              `json:"name"`
+	Address             string              `json:"address"`
+	Description         string              `json:"description,omitempty"`
+	Latitude            float64             `json:"latitude"`
+	Longitude           float64             `json:"longitude"`
+	Amenities           []string            `json:"amenities,omitempty"`
+	OpeningHours        *model.OpeningHours `json:"opening_hours,omitempty"`
+	PhotoObjectKeys     []string            `json:"photo_object_keys,omitempty"`
+	MenuPhotoObjectKeys []string            `json:"menu_photo_object_keys,omitempty"`
+	// OSMType and OSMID are set by the OpenStreetMap sync.
+	OSMType string `json:"osm_type,omitempty"`
+	OSMID   int64  `json:"osm_id,omitempty"`
+}
+
+// cafeDetailsPayload is proposed by the OpenStreetMap sync; only the fields
+// that changed are set, coordinates always come in pairs.
+type cafeDetailsPayload struct {
+	Name      *string  `json:"name,omitempty"`
+	Address   *string  `json:"address,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
 }
 
 type descriptionPayload struct {
 	Description string `json:"description"`
 }
 
+type hoursPayload struct {
+	OpeningHours model.OpeningHours `json:"opening_hours"`
+}
+
+type closurePayload struct {
+	Status    string `json:"status"`
+	ReopensOn string `json:"reopens_on,omitempty"`
+	Comment   string `json:"comment,omitempty"`
+}
+
 type photosPayload struct {
 	ObjectKeys []string `json:"object_keys"`
 }
@@ -140,6 +188,10 @@ func NewHandler(pool *pgxpool.Pool, s3 *media.Service, cfg config.MediaConfig) *
 	}
 }
 
+func (h *Handler) SetGeocoder(geocoder addressResolver) {
+	h.geocoder = geocoder
+}
+
 func (h *Handler) PresignPhoto(c *gin.Context) {
 	if h.s3 == nil || !h.s3.Enabled() {
 		httpx.RespondError(c, http.StatusServiceUnavailable, "service_unavailable", "Загрузка фото сейчас недоступна.", nil)
@@ -222,7 +274,7 @@ func (h *Handler) SubmitCafeCreate(c *gin.Context) {
 
 	name := strings.TrimSpace(req.Name)
 	address := strings.TrimSpace(req.Address)
-	if name == "" || address == "" {
+	if name == "" || (address == "" && h.geocoder == nil) {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Название и адрес обязательны.", nil)
 		return
 	}
@@ -234,6 +286,24 @@ func (h *Handler) SubmitCafeCreate(c *gin.Context) {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "longitude должен быть в диапазоне от -180 до 180.", nil)
 		return
 	}
+	if address == "" {
+		resolved, ok := h.resolveAddress(c.Request.Context(), req.Latitude, req.Longitude)
+		if !ok {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Не удалось определить адрес по координатам, укажите его вручную.", nil)
+			return
+		}
+		address = resolved
+	}
+
+	var openingHours *model.OpeningHours
+	if req.OpeningHours != nil {
+		normalized, err := validation.NormalizeOpeningHours(*req.OpeningHours)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+			return
+		}
+		openingHours = &normalized
+	}
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
 	defer cancel()
@@ -255,6 +325,7 @@ func (h *Handler) SubmitCafeCreate(c *gin.Context) {
 		Latitude:            req.Latitude,
 		Longitude:           req.Longitude,
 		Amenities:           normalizeAmenities(req.Amenities),
+		OpeningHours:        openingHours,
 		PhotoObjectKeys:     photoKeys,
 		MenuPhotoObjectKeys: menuPhotoKeys,
 	}
@@ -267,6 +338,20 @@ func (h *Handler) SubmitCafeCreate(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
+// resolveAddress reverse-geocodes a submitted point. A provider failure is
+// reported the same way as a miss: the user can always type the address.
+func (h *Handler) resolveAddress(parent context.Context, lat, lng float64) (string, bool) {
+	ctx, cancel := context.WithTimeout(parent, 5*time.Second)
+	defer cancel()
+	result, err := h.geocoder.Reverse(ctx, lat, lng)
+	if err != nil {
+		slog.Warn("moderation reverse geocoding failed", "error", err)
+		return "", false
+	}
+	address := strings.TrimSpace(result.Address)
+	return address, result.Found && address != ""
+}
+
 func (h *Handler) SubmitCafeDescription(c *gin.Context) {
 	userID, ok := auth.UserIDFromContext(c)
 	if !ok || strings.TrimSpace(userID) == "" {
@@ -306,14 +391,120 @@ func (h *Handler) SubmitCafeDescription(c *gin.Context) {
 		return
 	}
 
-	item, err := h.createSubmission(
+	item, err := h.submitCafeChange(
 		ctx,
 		userID,
 		entityTypeCafeDescription,
 		actionTypeUpdate,
-		&cafeID,
+		cafeID,
 		descriptionPayload{Description: description},
 	)
+	if err != nil {
+		respondCafeChangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func (h *Handler) SubmitCafeHours(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var req submitHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	openingHours, err := validation.NormalizeOpeningHours(req.OpeningHours)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	if len(openingHours.Weekly) == 0 {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Расписание не должно быть пустым.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+	if err := photos.EnsureCafeExists(ctx, h.pool, cafeID); err != nil {
+		if err == pgx.ErrNoRows {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	item, err := h.submitCafeChange(
+		ctx,
+		userID,
+		entityTypeCafeHours,
+		actionTypeUpdate,
+		cafeID,
+		hoursPayload{OpeningHours: openingHours},
+	)
+	if err != nil {
+		respondCafeChangeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+// SubmitCafeClosure reports that a cafe has closed, temporarily or for good.
+// The cafe status changes only after a moderator approves the report.
+func (h *Handler) SubmitCafeClosure(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var req submitClosureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	payload, err := normalizeClosurePayload(req, time.Now())
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+	if err := photos.EnsureCafeExists(ctx, h.pool, cafeID); err != nil {
+		if err == pgx.ErrNoRows {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	item, err := h.createSubmission(
+		ctx,
+		userID,
+		entityTypeCafeClosure,
+		actionTypeUpdate,
+		&cafeID,
+		payload,
+	)
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось создать заявку.", nil)
 		return
@@ -321,6 +512,31 @@ func (h *Handler) SubmitCafeDescription(c *gin.Context) {
 	c.JSON(http.StatusOK, item)
 }
 
+func normalizeClosurePayload(req submitClosureRequest, now time.Time) (closurePayload, error) {
+	payload := closurePayload{
+		Status:  strings.ToLower(strings.TrimSpace(req.Status)),
+		Comment: strings.TrimSpace(req.Comment),
+	}
+	if payload.Status != model.CafeStatusTemporarilyClosed && payload.Status != model.CafeStatusPermanentlyClosed {
+		return closurePayload{}, fmt.Errorf("status должен быть temporarily_closed или permanently_closed.")
+	}
+	if len([]rune(payload.Comment)) > 500 {
+		return closurePayload{}, fmt.Errorf("Комментарий слишком длинный.")
+	}
+	if strings.TrimSpace(req.ReopensOn) == "" {
+		return payload, nil
+	}
+	if payload.Status != model.CafeStatusTemporarilyClosed {
+		return closurePayload{}, fmt.Errorf("reopens_on можно указать только для временного закрытия.")
+	}
+	reopensOn, err := validation.ParseReopenDate(req.ReopensOn, now)
+	if err != nil {
+		return closurePayload{}, err
+	}
+	payload.ReopensOn = reopensOn.Format(time.DateOnly)
+	return payload, nil
+}
+
 func (h *Handler) SubmitCafePhotos(c *gin.Context) {
 	h.submitPhotos(c, entityTypeCafePhoto)
 }
@@ -348,7 +564,8 @@ func (h *Handler) submitPhotos(c *gin.Context, entityType string) {
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	// Owner uploads are optimized inline, like an approval.
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
 	defer cancel()
 
 	keys, err := h.validatePendingObjectKeys(ctx, userID, req.ObjectKeys)
@@ -370,16 +587,16 @@ func (h *Handler) submitPhotos(c *gin.Context, entityType string) {
 		return
 	}
 
-	item, err := h.createSubmission(
+	item, err := h.submitCafeChange(
 		ctx,
 		userID,
 		entityType,
 		actionTypeCreate,
-		&cafeID,
+		cafeID,
 		photosPayload{ObjectKeys: keys},
 	)
 	if err != nil {
-		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось создать заявку.", nil)
+		respondCafeChangeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, item)
@@ -583,6 +800,14 @@ func (h *Handler) decide(c *gin.Context, decision string) {
 	}
 
 	if decision == statusApprove {
+		if err := cafeaudit.Tag(ctx, tx, cafeaudit.Change{
+			Source:    cafeaudit.SourceModeration,
+			ActorID:   moderatorID,
+			Reference: "submission:" + submission.ID,
+		}); err != nil {
+			httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+			return
+		}
 		if err := h.applySubmission(ctx, tx, submission, moderatorID); err != nil {
 			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
 			return
@@ -643,11 +868,26 @@ func (h *Handler) applySubmission(
 			return fmt.Errorf("Неподдерживаемое действие для cafe")
 		}
 		return h.applyCafeCreate(ctx, tx, submission, moderatorID)
+	case entityTypeCafeDetails:
+		if submission.ActionType != actionTypeUpdate {
+			return fmt.Errorf("Неподдерживаемое действие для cafe_details")
+		}
+		return h.applyCafeDetails(ctx, tx, submission)
 	case entityTypeCafeDescription:
 		if submission.ActionType != actionTypeUpdate {
 			return fmt.Errorf("Неподдерживаемое действие для cafe_description")
 		}
 		return h.applyCafeDescription(ctx, tx, submission)
+	case entityTypeCafeHours:
+		if submission.ActionType != actionTypeUpdate {
+			return fmt.Errorf("Неподдерживаемое действие для cafe_hours")
+		}
+		return h.applyCafeHours(ctx, tx, submission)
+	case entityTypeCafeClosure:
+		if submission.ActionType != actionTypeUpdate {
+			return fmt.Errorf("Неподдерживаемое действие для cafe_closure")
+		}
+		return h.applyCafeClosure(ctx, tx, submission)
 	case entityTypeCafePhoto:
 		if submission.ActionType != actionTypeCreate {
 			return fmt.Errorf("Неподдерживаемое действие для cafe_photo")
@@ -658,6 +898,11 @@ func (h *Handler) applySubmission(
 			return fmt.Errorf("Неподдерживаемое действие для menu_photo")
 		}
 		return h.applyCafePhotos(ctx, tx, submission, photos.KindMenu, false)
+	case entityTypeCafeClaim:
+		if submission.ActionType != actionTypeCreate {
+			return fmt.Errorf("Неподдерживаемое действие для cafe_claim")
+		}
+		return h.applyCafeClaim(ctx, tx, submission, moderatorID)
 	default:
 		return fmt.Errorf("Этот тип заявки пока не поддерживается")
 	}
@@ -668,6 +913,11 @@ func applyReputationForApprovedSubmission(
 	tx pgx.Tx,
 	submission moderationSubmissionResponse,
 ) error {
+	// Sync submissions have no one to reward.
+	if submission.AuthorUserID == osmsync.SystemUserID {
+		return nil
+	}
+
 	points := 0
 	eventType := ""
 
@@ -678,7 +928,7 @@ func applyReputationForApprovedSubmission(
 		}
 		points = reputation.PointsCafeCreateApproved
 		eventType = reputation.EventCafeCreateApproved
-	case entityTypeCafeDescription, entityTypeCafePhoto, entityTypeMenuPhoto:
+	case entityTypeCafeDetails, entityTypeCafeDescription, entityTypeCafeHours, entityTypeCafeClosure, entityTypeCafePhoto, entityTypeMenuPhoto:
 		points = reputation.PointsDataUpdateApproved
 		eventType = reputation.EventDataUpdateApproved
 	default:
@@ -733,12 +983,23 @@ func (h *Handler) applyCafeCreate(
 	if amenities == nil {
 		amenities = []string{}
 	}
+	var (
+		hoursJSON []byte
+		timezone  = config.DefaultTimezone
+	)
+	if payload.OpeningHours != nil {
+		var err error
+		hoursJSON, timezone, err = encodeSubmissionOpeningHours(*payload.OpeningHours)
+		if err != nil {
+			return err
+		}
+	}
 
 	var cafeID string
 	if err := tx.QueryRow(
 		ctx,
-		`insert into cafes (name, address, description, lat, lng, amenities, geog)
-		 values ($1::text, $2::text, nullif($3::text, ''), $4::double precision, $5::double precision, $6::text[], ST_SetSRID(ST_MakePoint($5::double precision, $4::double precision), 4326)::geography)
+		`insert into cafes (name, address, description, lat, lng, amenities, opening_hours, timezone, geog)
+		 values ($1::text, $2::text, nullif($3::text, ''), $4::double precision, $5::double precision, $6::text[], $7::jsonb, $8::text, ST_SetSRID(ST_MakePoint($5::double precision, $4::double precision), 4326)::geography)
 		 returning id::text`,
 		name,
 		address,
@@ -746,10 +1007,18 @@ func (h *Handler) applyCafeCreate(
 		payload.Latitude,
 		payload.Longitude,
 		amenities,
+		hoursJSON,
+		timezone,
 	).Scan(&cafeID); err != nil {
 		slog.Error("moderation apply cafe create failed", "error", err)
 		return fmt.Errorf("Не удалось создать кофейню")
 	}
+	if payload.OSMType != "" && payload.OSMID > 0 {
+		if err := osmsync.LinkCafe(ctx, tx, payload.OSMType, payload.OSMID, cafeID); err != nil {
+			slog.Error("moderation link osm element failed", "error", err)
+			return fmt.Errorf("Не удалось создать кофейню")
+		}
+	}
 
 	combinedKeys := append([]string{}, payload.PhotoObjectKeys...)
 	combinedMenu := append([]string{}, payload.MenuPhotoObjectKeys...)
@@ -767,6 +1036,79 @@ func (h *Handler) applyCafeCreate(
 	return nil
 }
 
+func (h *Handler) applyCafeDetails(
+	ctx context.Context,
+	tx pgx.Tx,
+	submission moderationSubmissionResponse,
+) error {
+	if submission.TargetID == nil || strings.TrimSpace(*submission.TargetID) == "" {
+		return fmt.Errorf("Не указан target_id")
+	}
+	var payload cafeDetailsPayload
+	if err := decodeSubmissionPayload(submission.Payload, &payload); err != nil {
+		return fmt.Errorf("Некорректный payload заявки")
+	}
+	var name, address any
+	if payload.Name != nil {
+		value := strings.TrimSpace(*payload.Name)
+		if value == "" {
+			return fmt.Errorf("Название не должно быть пустым")
+		}
+		name = value
+	}
+	if payload.Address != nil {
+		value := strings.TrimSpace(*payload.Address)
+		if value == "" {
+			return fmt.Errorf("Адрес не должен быть пустым")
+		}
+		address = value
+	}
+	if (payload.Latitude == nil) != (payload.Longitude == nil) {
+		return fmt.Errorf("Координаты в заявке должны быть указаны вместе")
+	}
+	var lat, lng any
+	if payload.Latitude != nil {
+		if !validation.IsFinite(*payload.Latitude) || *payload.Latitude < -90 || *payload.Latitude > 90 {
+			return fmt.Errorf("Некорректное значение latitude в заявке")
+		}
+		if !validation.IsFinite(*payload.Longitude) || *payload.Longitude < -180 || *payload.Longitude > 180 {
+			return fmt.Errorf("Некорректное значение longitude в заявке")
+		}
+		lat, lng = *payload.Latitude, *payload.Longitude
+	}
+	if name == nil && address == nil && lat == nil {
+		return fmt.Errorf("Заявка не содержит изменений")
+	}
+
+	result, err := tx.Exec(
+		ctx,
+		`update cafes
+		    set name = coalesce($2::text, name),
+		        address = coalesce($3::text, address),
+		        lat = coalesce($4::double precision, lat),
+		        lng = coalesce($5::double precision, lng),
+		        geog = case
+		          when $4::double precision is null then geog
+		          else ST_SetSRID(ST_MakePoint($5::double precision, $4::double precision), 4326)::geography
+		        end
+		  where id = $1::uuid
+		    and status <> 'deleted'`,
+		*submission.TargetID,
+		name,
+		address,
+		lat,
+		lng,
+	)
+	if err != nil {
+		slog.Error("moderation apply cafe details failed", "error", err)
+		return fmt.Errorf("Не удалось обновить кофейню")
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("Кофейня не найдена")
+	}
+	return nil
+}
+
 func (h *Handler) applyCafeDescription(
 	ctx context.Context,
 	tx pgx.Tx,
@@ -786,7 +1128,7 @@ func (h *Handler) applyCafeDescription(
 
 	result, err := tx.Exec(
 		ctx,
-		`update cafes set description = $2 where id = $1::uuid`,
+		`update cafes set description = $2 where id = $1::uuid and status <> 'deleted'`,
 		*submission.TargetID,
 		description,
 	)
@@ -799,6 +1141,100 @@ func (h *Handler) applyCafeDescription(
 	return nil
 }
 
+func (h *Handler) applyCafeHours(
+	ctx context.Context,
+	tx pgx.Tx,
+	submission moderationSubmissionResponse,
+) error {
+	if submission.TargetID == nil || strings.TrimSpace(*submission.TargetID) == "" {
+		return fmt.Errorf("Не указан target_id")
+	}
+	var payload hoursPayload
+	if err := decodeSubmissionPayload(submission.Payload, &payload); err != nil {
+		return fmt.Errorf("Некорректный payload заявки")
+	}
+	hoursJSON, timezone, err := encodeSubmissionOpeningHours(payload.OpeningHours)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(
+		ctx,
+		`update cafes set opening_hours = $2::jsonb, timezone = $3::text where id = $1::uuid and status <> 'deleted'`,
+		*submission.TargetID,
+		hoursJSON,
+		timezone,
+	)
+	if err != nil {
+		return fmt.Errorf("Не удалось обновить часы работы")
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("Кофейня не найдена")
+	}
+	return nil
+}
+
+func (h *Handler) applyCafeClosure(
+	ctx context.Context,
+	tx pgx.Tx,
+	submission moderationSubmissionResponse,
+) error {
+	if submission.TargetID == nil || strings.TrimSpace(*submission.TargetID) == "" {
+		return fmt.Errorf("Не указан target_id")
+	}
+	var payload closurePayload
+	if err := decodeSubmissionPayload(submission.Payload, &payload); err != nil {
+		return fmt.Errorf("Некорректный payload заявки")
+	}
+	if payload.Status != model.CafeStatusTemporarilyClosed && payload.Status != model.CafeStatusPermanentlyClosed {
+		return fmt.Errorf("Некорректный статус в заявке")
+	}
+	// A reopen date that passed while the report waited in the queue is
+	// dropped: the cafe is closed until someone reports it open again.
+	var reopensOn any
+	if payload.ReopensOn != "" {
+		if parsed, err := time.Parse(time.DateOnly, payload.ReopensOn); err == nil && parsed.After(time.Now()) {
+			reopensOn = payload.ReopensOn
+		}
+	}
+
+	result, err := tx.Exec(
+		ctx,
+		`update cafes
+		    set status = $2::text,
+		        reopens_on = $3::date,
+		        status_reason = nullif($4::text, ''),
+		        status_changed_at = now()
+		  where id = $1::uuid
+		    and status <> 'deleted'`,
+		*submission.TargetID,
+		payload.Status,
+		reopensOn,
+		payload.Comment,
+	)
+	if err != nil {
+		return fmt.Errorf("Не удалось обновить статус кофейни")
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("Кофейня не найдена")
+	}
+	return nil
+}
+
+// encodeSubmissionOpeningHours re-validates hours stored in a submission
+// payload and returns the jsonb value (timezone lives in its own column).
+func encodeSubmissionOpeningHours(raw model.OpeningHours) ([]byte, string, error) {
+	hours, err := validation.NormalizeOpeningHours(raw)
+	if err != nil {
+		return nil, "", fmt.Errorf("Некорректные часы работы в заявке: %s", err.Error())
+	}
+	encoded, timezone, err := validation.EncodeOpeningHours(&hours)
+	if err != nil {
+		return nil, "", fmt.Errorf("Некорректные часы работы в заявке")
+	}
+	return encoded, timezone, nil
+}
+
 func (h *Handler) applyCafePhotos(
 	ctx context.Context,
 	tx pgx.Tx,
@@ -1134,6 +1570,12 @@ func (h *Handler) enrichSubmission(item *moderationSubmissionResponse) {
 		if len(menuKeys) > 0 {
 			item.Payload["menu_photo_urls"] = h.objectKeysToPublicURLs(menuKeys)
 		}
+	case entityTypeCafeClaim:
+		keys := extractStringArray(item.Payload["evidence_object_keys"])
+		if len(keys) == 0 {
+			return
+		}
+		item.Payload["evidence_urls"] = h.objectKeysToPublicURLs(keys)
 	}
 }
 
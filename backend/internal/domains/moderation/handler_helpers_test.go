@@ -3,6 +3,7 @@ package moderation
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"backend/internal/config"
 )
@@ -82,3 +83,73 @@ func TestObjectKeysToPublicURLsWithoutS3(t *testing.T) {
 		t.Fatalf("unexpected urls: %v", got)
 	}
 }
+
+func TestNormalizeClosurePayload(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	payload, err := normalizeClosurePayload(submitClosureRequest{
+		Status:    " Temporarily_Closed ",
+		ReopensOn: "2026-04-01",
+		Comment:   "  ремонт  ",
+	}, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Status != "temporarily_closed" || payload.ReopensOn != "2026-04-01" || payload.Comment != "ремонт" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+
+	invalid := []submitClosureRequest{
+		{Status: "deleted"},
+		{Status: "permanently_closed", ReopensOn: "2026-04-01"},
+		{Status: "temporarily_closed", ReopensOn: "2026-03-10"},
+		{Status: "temporarily_closed", ReopensOn: "01.04.2026"},
+	}
+	for _, req := range invalid {
+		if _, err := normalizeClosurePayload(req, now); err == nil {
+			t.Fatalf("expected %+v to be rejected", req)
+		}
+	}
+}
+
+func TestNormalizeClaimPayload(t *testing.T) {
+	payload, err := normalizeClaimPayload(submitClaimRequest{
+		Position:           "  владелец ",
+		Contact:            " +7 900 000-00-00 ",
+		EvidenceObjectKeys: []string{" pending/submissions/u/doc.jpg ", ""},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if payload.Position != "владелец" || payload.Contact != "+7 900 000-00-00" {
+		t.Fatalf("unexpected payload: %+v", payload)
+	}
+	if len(payload.EvidenceObjectKeys) != 1 || payload.EvidenceObjectKeys[0] != "pending/submissions/u/doc.jpg" {
+		t.Fatalf("unexpected evidence keys: %v", payload.EvidenceObjectKeys)
+	}
+
+	if _, err := normalizeClaimPayload(submitClaimRequest{
+		Position: "управляющий",
+		Contact:  "owner@example.com",
+		Comment:  "Указан на сайте кофейни",
+	}); err != nil {
+		t.Fatalf("a comment should be enough evidence: %v", err)
+	}
+
+	tooManyFiles := make([]string, maxClaimEvidenceFiles+1)
+	for i := range tooManyFiles {
+		tooManyFiles[i] = "pending/submissions/u/doc.jpg"
+	}
+	invalid := []submitClaimRequest{
+		{Contact: "owner@example.com", Comment: "docs"},
+		{Position: "владелец", Comment: "docs"},
+		{Position: "владелец", Contact: "owner@example.com"},
+		{Position: "владелец", Contact: "owner@example.com", Comment: strings.Repeat("а", maxClaimCommentRunes+1)},
+		{Position: "владелец", Contact: "owner@example.com", EvidenceObjectKeys: tooManyFiles},
+	}
+	for _, req := range invalid {
+		if _, err := normalizeClaimPayload(req); err == nil {
+			t.Fatalf("expected %+v to be rejected", req)
+		}
+	}
+}
@@ -503,3 +503,104 @@ func TestModerationApproveAlreadyProcessedConflict(t *testing.T) {
 		t.Fatalf("expected conflict code, got %q", apiErr.Code)
 	}
 }
+
+func TestModerationDeleteUserKeepsCafeRevisions(t *testing.T) {
+	pool := integrationTestPool(t)
+	router := newIntegrationRouter(pool)
+
+	authorID := mustCreateTestUser(t, pool, "user")
+	moderatorID := mustCreateTestUser(t, pool, "moderator")
+	cafeName := fmt.Sprintf("mod-revision-cafe-%d", time.Now().UnixNano())
+	cafeAddress := fmt.Sprintf("mod-revision-addr-%d", time.Now().UnixNano())
+	submissionID := mustCreateCafeCreateSubmission(t, pool, authorID, cafeName, cafeAddress)
+
+	t.Cleanup(func() {
+		mustExec(
+			t,
+			pool,
+			`delete from reputation_events
+			  where source_type = 'moderation_submission'
+			    and source_id = $1`,
+			submissionID,
+		)
+		mustExec(t, pool, `delete from moderation_submissions where id = $1::uuid`, submissionID)
+		mustDeleteCafeByNameAddress(t, pool, cafeName, cafeAddress)
+		mustDeleteTestUser(t, pool, authorID)
+	})
+
+	approveRec := performJSONRequest(
+		t,
+		router,
+		http.MethodPost,
+		"/api/moderation/submissions/"+submissionID+"/approve",
+		map[string]string{
+			"X-Test-User-ID": moderatorID,
+			"X-Test-Role":    "moderator",
+		},
+		nil,
+	)
+	if approveRec.Code != http.StatusOK {
+		t.Fatalf("approve expected 200, got %d, body=%s", approveRec.Code, approveRec.Body.String())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var cafeID string
+	if err := pool.QueryRow(
+		ctx,
+		`select id::text from cafes where name = $1 and address = $2`,
+		cafeName,
+		cafeAddress,
+	).Scan(&cafeID); err != nil {
+		t.Fatalf("load created cafe: %v", err)
+	}
+
+	var attributed int
+	if err := pool.QueryRow(
+		ctx,
+		`select count(*)::int
+		   from cafe_revisions
+		  where cafe_id = $1::uuid and actor_user_id = $2::uuid`,
+		cafeID,
+		moderatorID,
+	).Scan(&attributed); err != nil {
+		t.Fatalf("count moderator revisions: %v", err)
+	}
+	if attributed == 0 {
+		t.Fatalf("expected the approval revision to be attributed to the moderator")
+	}
+
+	mustDeleteTestUser(t, pool, moderatorID)
+
+	var (
+		revisions  int
+		anonymous  int
+		sourceKept int
+	)
+	if err := pool.QueryRow(
+		ctx,
+		`select count(*)::int,
+		        count(*) filter (where actor_user_id is null)::int,
+		        count(*) filter (where source = 'moderation')::int
+		   from cafe_revisions
+		  where cafe_id = $1::uuid`,
+		cafeID,
+	).Scan(&revisions, &anonymous, &sourceKept); err != nil {
+		t.Fatalf("load revisions after user delete: %v", err)
+	}
+	if revisions == 0 || anonymous != revisions {
+		t.Fatalf("expected all %d revisions to lose the actor, got %d anonymous", revisions, anonymous)
+	}
+	if sourceKept == 0 {
+		t.Fatalf("expected the moderation revision to survive the user delete")
+	}
+
+	if _, err := pool.Exec(
+		ctx,
+		`update cafe_revisions set source = 'system' where cafe_id = $1::uuid`,
+		cafeID,
+	); err == nil {
+		t.Fatalf("expected cafe_revisions to reject regular updates")
+	}
+}
@@ -0,0 +1,484 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"backend/internal/auth"
+	"backend/internal/domains/photos"
+	"backend/internal/shared/cafeaudit"
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	entityTypeCafeClaim = "cafe_claim"
+
+	// eventTypeOwnerApplied marks a change a verified owner applied without
+	// review.
+	eventTypeOwnerApplied = "owner_applied"
+
+	maxClaimEvidenceFiles = 5
+	maxClaimPositionRunes = 80
+	maxClaimContactRunes  = 120
+	maxClaimCommentRunes  = 1000
+	roleVerifiedOwner     = "barista"
+)
+
+type submitClaimRequest struct {
+	Position           string   `json:"position"`
+	Contact            string   `json:"contact"`
+	Comment            string   `json:"comment"`
+	EvidenceObjectKeys []string `json:"evidence_object_keys"`
+}
+
+// claimPayload asks for the author to become an owner of the target cafe.
+// Evidence is a description, uploaded documents or both.
+type claimPayload struct {
+	Position           string   `json:"position"`
+	Contact            string   `json:"contact"`
+	Comment            string   `json:"comment,omitempty"`
+	EvidenceObjectKeys []string `json:"evidence_object_keys,omitempty"`
+}
+
+type cafeOwnerResponse struct {
+	UserID            string    `json:"user_id"`
+	UserLabel         string    `json:"user_label"`
+	ClaimSubmissionID *string   `json:"claim_submission_id,omitempty"`
+	ApprovedBy        *string   `json:"approved_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+}
+
+type ownedCafeResponse struct {
+	CafeID     string    `json:"cafe_id"`
+	Name       string    `json:"name"`
+	Address    string    `json:"address"`
+	Status     string    `json:"status"`
+	OwnerSince time.Time `json:"owner_since"`
+}
+
+// ownerChangeError is a user-facing reason an owner change could not be
+// applied.
+type ownerChangeError struct {
+	err error
+}
+
+func (e ownerChangeError) Error() string {
+	return e.err.Error()
+}
+
+// SubmitCafeClaim files a request to become an owner of a cafe. A moderator
+// approves it like any other submission.
+func (h *Handler) SubmitCafeClaim(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	var req submitClaimRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	payload, err := normalizeClaimPayload(req)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+
+	payload.EvidenceObjectKeys, err = h.validatePendingObjectKeys(ctx, userID, payload.EvidenceObjectKeys)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	if err := photos.EnsureCafeExists(ctx, h.pool, cafeID); err != nil {
+		if err == pgx.ErrNoRows {
+			httpx.RespondError(c, http.StatusNotFound, "not_found", "Кофейня не найдена.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	owner, err := h.isCafeOwner(ctx, cafeID, userID)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	if owner {
+		httpx.RespondError(c, http.StatusConflict, "conflict", "Вы уже подтверждены как владелец этой кофейни.", nil)
+		return
+	}
+
+	item, err := h.createSubmission(ctx, userID, entityTypeCafeClaim, actionTypeCreate, &cafeID, payload)
+	if err != nil {
+		if photos.IsUniqueViolation(err) {
+			httpx.RespondError(c, http.StatusConflict, "conflict", "Ваша заявка на эту кофейню уже на рассмотрении.", nil)
+			return
+		}
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось создать заявку.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, item)
+}
+
+func normalizeClaimPayload(req submitClaimRequest) (claimPayload, error) {
+	payload := claimPayload{
+		Position: strings.TrimSpace(req.Position),
+		Contact:  strings.TrimSpace(req.Contact),
+		Comment:  strings.TrimSpace(req.Comment),
+	}
+	if payload.Position == "" {
+		return claimPayload{}, fmt.Errorf("Укажите вашу роль в кофейне (position).")
+	}
+	if utf8.RuneCountInString(payload.Position) > maxClaimPositionRunes {
+		return claimPayload{}, fmt.Errorf("Поле position слишком длинное.")
+	}
+	if payload.Contact == "" {
+		return claimPayload{}, fmt.Errorf("Укажите телефон или email для связи (contact).")
+	}
+	if utf8.RuneCountInString(payload.Contact) > maxClaimContactRunes {
+		return claimPayload{}, fmt.Errorf("Поле contact слишком длинное.")
+	}
+	if utf8.RuneCountInString(payload.Comment) > maxClaimCommentRunes {
+		return claimPayload{}, fmt.Errorf("Комментарий слишком длинный.")
+	}
+	for _, raw := range req.EvidenceObjectKeys {
+		if key := strings.TrimSpace(raw); key != "" {
+			payload.EvidenceObjectKeys = append(payload.EvidenceObjectKeys, key)
+		}
+	}
+	if len(payload.EvidenceObjectKeys) > maxClaimEvidenceFiles {
+		return claimPayload{}, fmt.Errorf("Можно приложить не больше %d файлов.", maxClaimEvidenceFiles)
+	}
+	if payload.Comment == "" && len(payload.EvidenceObjectKeys) == 0 {
+		return claimPayload{}, fmt.Errorf("Приложите документы или опишите, чем подтверждается владение.")
+	}
+	return payload, nil
+}
+
+// applyCafeClaim makes the claim author an owner of the cafe and marks the
+// account as a verified owner.
+func (h *Handler) applyCafeClaim(
+	ctx context.Context,
+	tx pgx.Tx,
+	submission moderationSubmissionResponse,
+	moderatorID string,
+) error {
+	if submission.TargetID == nil || strings.TrimSpace(*submission.TargetID) == "" {
+		return fmt.Errorf("Не указан target_id")
+	}
+	cafeID := strings.TrimSpace(*submission.TargetID)
+
+	var cafeExists bool
+	if err := tx.QueryRow(
+		ctx,
+		`select exists(select 1 from cafes where id = $1::uuid and status <> 'deleted')`,
+		cafeID,
+	).Scan(&cafeExists); err != nil {
+		return fmt.Errorf("Внутренняя ошибка проверки кофейни")
+	}
+	if !cafeExists {
+		return fmt.Errorf("Кофейня не найдена")
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`insert into cafe_owners (cafe_id, user_id, claim_submission_id, approved_by)
+		 values ($1::uuid, $2::uuid, $3::uuid, $4::uuid)
+		 on conflict (cafe_id, user_id) do nothing`,
+		cafeID,
+		submission.AuthorUserID,
+		submission.ID,
+		moderatorID,
+	); err != nil {
+		return fmt.Errorf("Не удалось добавить владельца")
+	}
+	// Staff accounts keep their role; regular users become verified owners.
+	if _, err := tx.Exec(
+		ctx,
+		`update users set role = $2, updated_at = now() where id = $1::uuid and role = 'user'`,
+		submission.AuthorUserID,
+		roleVerifiedOwner,
+	); err != nil {
+		return fmt.Errorf("Не удалось обновить роль пользователя")
+	}
+	return nil
+}
+
+func (h *Handler) isCafeOwner(ctx context.Context, cafeID, userID string) (bool, error) {
+	var owner bool
+	err := h.pool.QueryRow(
+		ctx,
+		`select exists(select 1 from cafe_owners where cafe_id = $1::uuid and user_id = $2::uuid)`,
+		cafeID,
+		userID,
+	).Scan(&owner)
+	return owner, err
+}
+
+// submitCafeChange files a change to an existing cafe. Changes by a verified
+// owner skip the queue: they are applied at once and stored as an approved
+// submission, so both the queue history and the cafe revision log keep them.
+func (h *Handler) submitCafeChange(
+	ctx context.Context,
+	userID string,
+	entityType string,
+	actionType string,
+	cafeID string,
+	payload any,
+) (moderationSubmissionResponse, error) {
+	owner, err := h.isCafeOwner(ctx, cafeID, userID)
+	if err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+	if !owner {
+		return h.createSubmission(ctx, userID, entityType, actionType, &cafeID, payload)
+	}
+	return h.applyOwnerChange(ctx, userID, entityType, actionType, cafeID, payload)
+}
+
+// applyOwnerChange earns no reputation: owners editing their own cafe is not
+// a community contribution.
+func (h *Handler) applyOwnerChange(
+	ctx context.Context,
+	userID string,
+	entityType string,
+	actionType string,
+	cafeID string,
+	payload any,
+) (moderationSubmissionResponse, error) {
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+
+	tx, err := h.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
+
+	row := tx.QueryRow(
+		ctx,
+		`insert into moderation_submissions (author_user_id, entity_type, action_type, target_id, payload, status, decided_at)
+		 values ($1::uuid, $2, $3, $4::uuid, $5::jsonb, $6, now())
+		 returning id::text, author_user_id::text, entity_type, action_type, target_id::text, payload, status,
+		           moderator_id::text, moderator_comment, created_at, updated_at, decided_at`,
+		userID,
+		entityType,
+		actionType,
+		cafeID,
+		payloadJSON,
+		statusApprove,
+	)
+	submission, err := scanSubmissionRow(row, "")
+	if err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+
+	if err := cafeaudit.Tag(ctx, tx, cafeaudit.Change{
+		Source:    cafeaudit.SourceOwner,
+		ActorID:   userID,
+		Reference: "submission:" + submission.ID,
+	}); err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+	if err := h.applySubmission(ctx, tx, submission, userID); err != nil {
+		return moderationSubmissionResponse{}, ownerChangeError{err: err}
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`insert into moderation_events (submission_id, actor_user_id, event_type)
+		 values ($1::uuid, $2::uuid, $3)`,
+		submission.ID,
+		userID,
+		eventTypeOwnerApplied,
+	); err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return moderationSubmissionResponse{}, err
+	}
+
+	h.enrichSubmission(&submission)
+	return submission, nil
+}
+
+func respondCafeChangeError(c *gin.Context, err error) {
+	var changeErr ownerChangeError
+	if errors.As(err, &changeErr) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", changeErr.Error(), nil)
+		return
+	}
+	httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось создать заявку.", nil)
+}
+
+// ListOwnedCafes returns the cafes the current user is a verified owner of.
+func (h *Handler) ListOwnedCafes(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+
+	rows, err := h.pool.Query(
+		ctx,
+		`select c.id::text, c.name, coalesce(c.address, ''), c.status, co.created_at
+		   from cafe_owners co
+		   join cafes c on c.id = co.cafe_id
+		  where co.user_id = $1::uuid
+		    and c.status <> 'deleted'
+		  order by co.created_at desc`,
+		userID,
+	)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить кофейни.", nil)
+		return
+	}
+	defer rows.Close()
+
+	out := make([]ownedCafeResponse, 0, 4)
+	for rows.Next() {
+		var item ownedCafeResponse
+		if err := rows.Scan(&item.CafeID, &item.Name, &item.Address, &item.Status, &item.OwnerSince); err != nil {
+			httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить кофейни.", nil)
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить кофейни.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+func (h *Handler) AdminListCafeOwners(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+
+	rows, err := h.pool.Query(
+		ctx,
+		`select co.user_id::text,
+		        coalesce(u.display_name, u.email_normalized, u.id::text),
+		        co.claim_submission_id::text,
+		        co.approved_by::text,
+		        co.created_at
+		   from cafe_owners co
+		   join users u on u.id = co.user_id
+		  where co.cafe_id = $1::uuid
+		  order by co.created_at asc`,
+		cafeID,
+	)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить владельцев.", nil)
+		return
+	}
+	defer rows.Close()
+
+	out := make([]cafeOwnerResponse, 0, 2)
+	for rows.Next() {
+		var item cafeOwnerResponse
+		if err := rows.Scan(&item.UserID, &item.UserLabel, &item.ClaimSubmissionID, &item.ApprovedBy, &item.CreatedAt); err != nil {
+			httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить владельцев.", nil)
+			return
+		}
+		out = append(out, item)
+	}
+	if err := rows.Err(); err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить владельцев.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": out})
+}
+
+// AdminRevokeCafeOwner removes an owner. An account left without cafes goes
+// back from barista to the regular user role.
+func (h *Handler) AdminRevokeCafeOwner(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+	userID := strings.TrimSpace(c.Param("userID"))
+	if !validation.IsValidUUID(userID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id пользователя.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 6*time.Second)
+	defer cancel()
+
+	tx, err := h.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	defer func() {
+		_ = tx.Rollback(context.Background())
+	}()
+
+	result, err := tx.Exec(
+		ctx,
+		`delete from cafe_owners where cafe_id = $1::uuid and user_id = $2::uuid`,
+		cafeID,
+		userID,
+	)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	if result.RowsAffected() == 0 {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Владелец не найден.", nil)
+		return
+	}
+	if _, err := tx.Exec(
+		ctx,
+		`update users
+		    set role = 'user', updated_at = now()
+		  where id = $1::uuid
+		    and role = $2
+		    and not exists (select 1 from cafe_owners where user_id = $1::uuid)`,
+		userID,
+		roleVerifiedOwner,
+	); err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
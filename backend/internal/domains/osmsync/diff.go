@@ -0,0 +1,115 @@
+package osmsync
+
+import (
+	"math"
+	"strings"
+
+	"backend/internal/shared/geo"
+)
+
+// minGuardedRemovals is the number of removals always accepted regardless
+// of the ratio guard, so small areas can still lose a cafe or two.
+const minGuardedRemovals = 5
+
+// ComputeDiff compares fetched POIs with stored links. Elements without a
+// link are created, linked elements whose name, address or position changed
+// are updated, links missing from the fetch are removed and removed links
+// that show up again are restored.
+//
+// When removals exceed maxRemovedRatio of the live links (and
+// minGuardedRemovals), the fetch is assumed to be incomplete and removals are
+// dropped from the diff; a ratio >= 1 disables the guard.
+func ComputeDiff(pois []POI, links []Link, maxRemovedRatio float64) Diff {
+	byKey := make(map[elementKey]Link, len(links))
+	live := 0
+	for _, link := range links {
+		byKey[elementKey{osmType: link.OSMType, osmID: link.OSMID}] = link
+		if link.RemovedAt == nil {
+			live++
+		}
+	}
+
+	var diff Diff
+	seen := make(map[elementKey]struct{}, len(pois))
+	for _, poi := range pois {
+		key := elementKey{osmType: poi.OSMType, osmID: poi.OSMID}
+		seen[key] = struct{}{}
+
+		link, ok := byKey[key]
+		if !ok {
+			diff.Changes = append(diff.Changes, poiChange(ChangeCreated, poi, nil))
+			continue
+		}
+		previous := link
+		if link.RemovedAt != nil {
+			diff.Changes = append(diff.Changes, poiChange(ChangeRestored, poi, &previous))
+			continue
+		}
+
+		renamed := !sameText(poi.Name, link.Name) || !sameText(poi.Address, link.Address)
+		distance := geo.HaversineM(link.Latitude, link.Longitude, poi.Latitude, poi.Longitude)
+		moved := distance > movedThresholdM
+		if !renamed && !moved {
+			diff.Unchanged++
+			continue
+		}
+		change := poiChange(ChangeUpdated, poi, &previous)
+		change.Renamed = renamed
+		change.Moved = moved
+		change.DistanceM = math.Round(distance*10) / 10
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	var removed []Change
+	for _, link := range links {
+		if link.RemovedAt != nil {
+			continue
+		}
+		if _, ok := seen[elementKey{osmType: link.OSMType, osmID: link.OSMID}]; ok {
+			continue
+		}
+		previous := link
+		removed = append(removed, Change{
+			Kind:      ChangeRemoved,
+			OSMType:   link.OSMType,
+			OSMID:     link.OSMID,
+			CafeID:    link.CafeID,
+			Name:      link.Name,
+			Address:   link.Address,
+			Latitude:  link.Latitude,
+			Longitude: link.Longitude,
+			previous:  &previous,
+		})
+	}
+	if len(removed) > minGuardedRemovals && maxRemovedRatio < 1 &&
+		float64(len(removed)) > maxRemovedRatio*float64(live) {
+		diff.RemovalsSkipped = true
+	} else {
+		diff.Changes = append(diff.Changes, removed...)
+	}
+	return diff
+}
+
+func poiChange(kind string, poi POI, previous *Link) Change {
+	change := Change{
+		Kind:      kind,
+		OSMType:   poi.OSMType,
+		OSMID:     poi.OSMID,
+		Name:      poi.Name,
+		Address:   poi.Address,
+		Latitude:  poi.Latitude,
+		Longitude: poi.Longitude,
+		Amenities: poi.Amenities,
+		previous:  previous,
+	}
+	if previous != nil {
+		change.CafeID = previous.CafeID
+	}
+	return change
+}
+
+// sameText compares names and addresses ignoring case and whitespace runs,
+// which OSM edits often touch without a real change.
+func sameText(a, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
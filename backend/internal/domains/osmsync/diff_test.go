@@ -0,0 +1,108 @@
+package osmsync
+
+import (
+	"testing"
+	"time"
+)
+
+// linksFor stores pois the way a completed sync leaves them: linked to
+// active cafes, snapshot equal to the element.
+func linksFor(pois []POI) []Link {
+	links := make([]Link, 0, len(pois))
+	for _, poi := range pois {
+		cafeID := "cafe-" + poi.Ref()
+		links = append(links, Link{
+			OSMType:    poi.OSMType,
+			OSMID:      poi.OSMID,
+			CafeID:     &cafeID,
+			CafeStatus: "active",
+			Name:       poi.Name,
+			Address:    poi.Address,
+			Latitude:   poi.Latitude,
+			Longitude:  poi.Longitude,
+		})
+	}
+	return links
+}
+
+func TestComputeDiffRecordedResponses(t *testing.T) {
+	t.Parallel()
+
+	links := linksFor(mustFetch(t, "testdata/overpass_initial.json"))
+	diff := ComputeDiff(mustFetch(t, "testdata/overpass_changed.json"), links, 1)
+
+	if diff.Unchanged != 0 || diff.RemovalsSkipped {
+		t.Fatalf("unexpected diff summary: %+v", diff)
+	}
+	byRef := make(map[string]Change, len(diff.Changes))
+	for _, change := range diff.Changes {
+		byRef[osmRef(change.OSMType, change.OSMID)] = change
+	}
+	if len(byRef) != 4 {
+		t.Fatalf("expected 4 changes, got %+v", diff.Changes)
+	}
+
+	moved := byRef["node/1001"]
+	if moved.Kind != ChangeUpdated || !moved.Moved || moved.Renamed || moved.DistanceM < 90 || moved.DistanceM > 110 {
+		t.Fatalf("expected node/1001 to move ~100 m: %+v", moved)
+	}
+	renamed := byRef["way/2002"]
+	if renamed.Kind != ChangeUpdated || !renamed.Renamed || renamed.Moved {
+		t.Fatalf("expected way/2002 to be renamed only: %+v", renamed)
+	}
+	payload := detailsPayload(renamed)
+	if payload.Name == nil || *payload.Name != "Coffee House" || payload.Address != nil || payload.Latitude != nil {
+		t.Fatalf("rename payload must carry only the name: %+v", payload)
+	}
+	if payload.Previous.Name != "Кофе Хаус" {
+		t.Fatalf("rename payload must carry the previous name: %+v", payload.Previous)
+	}
+	if removed := byRef["node/1004"]; removed.Kind != ChangeRemoved || removed.CafeID == nil {
+		t.Fatalf("expected node/1004 to be removed: %+v", removed)
+	}
+	if created := byRef["node/1006"]; created.Kind != ChangeCreated || created.CafeID != nil {
+		t.Fatalf("expected node/1006 to be created: %+v", created)
+	}
+}
+
+func TestComputeDiffIgnoresNoiseAndRestores(t *testing.T) {
+	t.Parallel()
+
+	removedAt := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	links := []Link{
+		{OSMType: "node", OSMID: 1, Name: "Зерно", Address: "Невский проспект, 10", Latitude: 59.9343, Longitude: 30.3351},
+		{OSMType: "node", OSMID: 2, Name: "Бриошь", Address: "Садовая, 3", Latitude: 59.93, Longitude: 30.32, RemovedAt: &removedAt},
+	}
+	pois := []POI{
+		// Case, whitespace and a 10 m shift are not worth a submission.
+		{OSMType: "node", OSMID: 1, Name: "зерно ", Address: "Невский  проспект, 10", Latitude: 59.9344, Longitude: 30.3352},
+		{OSMType: "node", OSMID: 2, Name: "Бриошь", Address: "Садовая, 3", Latitude: 59.93, Longitude: 30.32},
+	}
+
+	diff := ComputeDiff(pois, links, 0.2)
+	if diff.Unchanged != 1 || len(diff.Changes) != 1 || diff.Changes[0].Kind != ChangeRestored {
+		t.Fatalf("unexpected diff: %+v", diff)
+	}
+}
+
+func TestComputeDiffGuardsMassRemoval(t *testing.T) {
+	t.Parallel()
+
+	pois := make([]POI, 0, 20)
+	for i := int64(1); i <= 20; i++ {
+		pois = append(pois, POI{OSMType: "node", OSMID: i, Name: "Кафе", Address: "Улица, 1", Latitude: 59.9, Longitude: 30.3})
+	}
+	links := linksFor(pois)
+
+	// Losing 8 of 20 cafes at once looks like a truncated response.
+	diff := ComputeDiff(pois[:12], links, 0.2)
+	if !diff.RemovalsSkipped || len(diff.Changes) != 0 {
+		t.Fatalf("expected removals to be skipped: %+v", diff)
+	}
+
+	// A few removals always go through.
+	diff = ComputeDiff(pois[:16], links, 0.2)
+	if diff.RemovalsSkipped || len(diff.Changes) != 4 {
+		t.Fatalf("expected 4 removals: %+v", diff)
+	}
+}
@@ -0,0 +1,93 @@
+package osmsync
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/shared/httpx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func NewDefaultHandler(pool *pgxpool.Pool, cfg config.OSMSyncConfig) *Handler {
+	repository := NewRepository(pool)
+	service := NewService(repository, cfg)
+	return NewHandler(service)
+}
+
+func (h *Handler) Service() *Service {
+	return h.service
+}
+
+// AdminRun syncs with Overpass right away. With dry_run=true nothing is
+// stored except the run record and the response lists the changes a real
+// run would make.
+func (h *Handler) AdminRun(c *gin.Context) {
+	dryRun := false
+	if rawDryRun := strings.TrimSpace(c.Query("dry_run")); rawDryRun != "" {
+		value, err := strconv.ParseBool(rawDryRun)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "dry_run должен быть true/false.", nil)
+			return
+		}
+		dryRun = value
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.service.cfg.Timeout+time.Minute)
+	defer cancel()
+
+	result, err := h.service.Run(ctx, h.service.OverpassSource(), dryRun)
+	if err != nil {
+		switch {
+		case errors.Is(err, errSyncLocked):
+			httpx.RespondError(c, http.StatusConflict, "conflict", "Синхронизация с OpenStreetMap уже выполняется.", nil)
+		case errors.Is(err, errFetchFailed):
+			slog.Warn("osm sync fetch failed", "error", err)
+			httpx.RespondError(c, http.StatusBadGateway, "upstream_error", "Не удалось получить данные из OpenStreetMap.", nil)
+		default:
+			slog.Error("osm sync failed", "error", err)
+			httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *Handler) AdminListRuns(c *gin.Context) {
+	limit := runListDefaultLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		value, err := strconv.Atoi(rawLimit)
+		if err != nil || value <= 0 || value > runListMaxLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть в диапазоне от 1 до 100.", nil)
+			return
+		}
+		limit = value
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	items, err := h.service.ListRuns(ctx, limit)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
@@ -0,0 +1,242 @@
+package osmsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/shared/validation"
+)
+
+// Source yields the current set of cafes in the synced area.
+type Source interface {
+	Name() string
+	Fetch(ctx context.Context) ([]POI, error)
+}
+
+// BuildOverpassQuery selects cafes and coffee shops with a name and an
+// address inside an Overpass area (3600000000 + OSM relation id).
+func BuildOverpassQuery(areaID int64, timeout time.Duration) string {
+	seconds := int(timeout / time.Second)
+	if seconds <= 0 {
+		seconds = 180
+	}
+	return fmt.Sprintf(`[out:json][timeout:%d];
+area(%d)->.a;
+(
+  nwr["amenity"="cafe"]["name"]["addr:street"](area.a);
+  nwr["amenity"="cafe"]["name"]["addr:full"](area.a);
+  nwr["shop"="coffee"]["name"]["addr:street"](area.a);
+  nwr["shop"="coffee"]["name"]["addr:full"](area.a);
+);
+out tags center;
+`, seconds, areaID)
+}
+
+// OverpassSource queries a live Overpass API instance. When Record is set,
+// the raw response is copied to it for replay through FileSource.
+type OverpassSource struct {
+	Endpoint  string
+	Query     string
+	UserAgent string
+	Client    *http.Client
+	Record    io.Writer
+}
+
+func (s *OverpassSource) Name() string {
+	return SourceOverpass
+}
+
+func (s *OverpassSource) Fetch(ctx context.Context) ([]POI, error) {
+	form := url.Values{"data": {s.Query}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	if s.UserAgent != "" {
+		req.Header.Set("User-Agent", s.UserAgent)
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 2000))
+		return nil, fmt.Errorf("overpass http %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var body io.Reader = resp.Body
+	if s.Record != nil {
+		body = io.TeeReader(resp.Body, s.Record)
+	}
+	return ParseOverpassResponse(body)
+}
+
+// FileSource replays a recorded Overpass JSON response.
+type FileSource struct {
+	Path string
+}
+
+func (s FileSource) Name() string {
+	return SourceFile
+}
+
+func (s FileSource) Fetch(context.Context) ([]POI, error) {
+	file, err := os.Open(s.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return ParseOverpassResponse(file)
+}
+
+type overpassResponse struct {
+	Remark   string            `json:"remark"`
+	Elements []overpassElement `json:"elements"`
+}
+
+type overpassElement struct {
+	Type   string            `json:"type"`
+	ID     int64             `json:"id"`
+	Lat    *float64          `json:"lat,omitempty"`
+	Lon    *float64          `json:"lon,omitempty"`
+	Center *overpassCenter   `json:"center,omitempty"`
+	Tags   map[string]string `json:"tags,omitempty"`
+}
+
+type overpassCenter struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// ParseOverpassResponse turns an Overpass JSON response into POIs, sorted by
+// element. Elements without a name, address or position are dropped.
+// Overpass reports timeouts and memory exhaustion in "remark" next to a
+// truncated element list; such a response is an error, otherwise every
+// missing element would look removed.
+func ParseOverpassResponse(r io.Reader) ([]POI, error) {
+	var resp overpassResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("decode overpass response: %w", err)
+	}
+	if remark := strings.TrimSpace(resp.Remark); remark != "" && strings.Contains(strings.ToLower(remark), "error") {
+		return nil, errors.New("overpass returned a partial result: " + remark)
+	}
+
+	seen := make(map[elementKey]struct{}, len(resp.Elements))
+	out := make([]POI, 0, len(resp.Elements))
+	for _, el := range resp.Elements {
+		poi, ok := elementPOI(el)
+		if !ok {
+			continue
+		}
+		key := elementKey{osmType: poi.OSMType, osmID: poi.OSMID}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, poi)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].OSMType != out[j].OSMType {
+			return out[i].OSMType < out[j].OSMType
+		}
+		return out[i].OSMID < out[j].OSMID
+	})
+	return out, nil
+}
+
+func elementPOI(el overpassElement) (POI, bool) {
+	switch el.Type {
+	case "node", "way", "relation":
+	default:
+		return POI{}, false
+	}
+	if el.ID <= 0 || el.Tags == nil {
+		return POI{}, false
+	}
+	name := strings.TrimSpace(el.Tags["name"])
+	address := buildAddress(el.Tags)
+	if name == "" || address == "" {
+		return POI{}, false
+	}
+	lat, lng, ok := elementLatLng(el)
+	if !ok {
+		return POI{}, false
+	}
+	return POI{
+		OSMType:   el.Type,
+		OSMID:     el.ID,
+		Name:      name,
+		Address:   address,
+		Latitude:  lat,
+		Longitude: lng,
+		Amenities: tagAmenities(el.Tags),
+	}, true
+}
+
+func elementLatLng(el overpassElement) (float64, float64, bool) {
+	var lat, lng float64
+	switch {
+	case el.Lat != nil && el.Lon != nil:
+		lat, lng = *el.Lat, *el.Lon
+	case el.Center != nil:
+		lat, lng = el.Center.Lat, el.Center.Lon
+	default:
+		return 0, 0, false
+	}
+	if !validation.IsFinite(lat) || !validation.IsFinite(lng) || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		return 0, 0, false
+	}
+	return lat, lng, true
+}
+
+// buildAddress prefers addr:full, otherwise "street, housenumber".
+func buildAddress(tags map[string]string) string {
+	if full := strings.TrimSpace(tags["addr:full"]); full != "" {
+		return full
+	}
+	street := strings.TrimSpace(tags["addr:street"])
+	house := strings.TrimSpace(tags["addr:housenumber"])
+	if street == "" {
+		return ""
+	}
+	if house == "" {
+		return street
+	}
+	return street + ", " + house
+}
+
+// tagAmenities maps the few OSM tags that have a counterpart among our
+// amenities; the rest is left to visitors.
+func tagAmenities(tags map[string]string) []string {
+	var out []string
+	switch strings.ToLower(strings.TrimSpace(tags["internet_access"])) {
+	case "wlan", "wifi", "yes":
+		out = append(out, "wifi")
+	}
+	if strings.EqualFold(strings.TrimSpace(tags["toilets"]), "yes") {
+		out = append(out, "toilet")
+	}
+	return out
+}
+
+func osmRef(osmType string, osmID int64) string {
+	return osmType + "/" + strconv.FormatInt(osmID, 10)
+}
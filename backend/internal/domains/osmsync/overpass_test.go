@@ -0,0 +1,50 @@
+package osmsync
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func mustFetch(t *testing.T, path string) []POI {
+	t.Helper()
+	pois, err := FileSource{Path: path}.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("fetch %s: %v", path, err)
+	}
+	return pois
+}
+
+func TestParseOverpassResponse(t *testing.T) {
+	t.Parallel()
+
+	pois := mustFetch(t, "testdata/overpass_initial.json")
+	refs := make([]string, 0, len(pois))
+	for _, poi := range pois {
+		refs = append(refs, poi.Ref())
+	}
+	if strings.Join(refs, ",") != "node/1001,node/1004,way/2002" {
+		t.Fatalf("unexpected elements: %v", refs)
+	}
+
+	node := pois[0]
+	if node.Address != "Невский проспект, 10" || strings.Join(node.Amenities, ",") != "wifi" {
+		t.Fatalf("unexpected node: %+v", node)
+	}
+	way := pois[2]
+	if way.Latitude != 59.9401 || way.Longitude != 30.3480 {
+		t.Fatalf("way must take its center: %+v", way)
+	}
+	if way.Address != "Литейный проспект, 5" || strings.Join(way.Amenities, ",") != "toilet" {
+		t.Fatalf("unexpected way: %+v", way)
+	}
+}
+
+func TestParseOverpassResponseRejectsPartialResult(t *testing.T) {
+	t.Parallel()
+
+	_, err := FileSource{Path: "testdata/overpass_timeout.json"}.Fetch(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected timeout remark to fail the fetch, got %v", err)
+	}
+}
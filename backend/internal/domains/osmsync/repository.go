@@ -0,0 +1,383 @@
+package osmsync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"backend/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var errSyncLocked = errors.New("osm sync is already running")
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// ApplySync diffs pois against the stored links and records the outcome in
+// one transaction: links are created or refreshed, and every change that
+// touches a visible cafe becomes a pending moderation submission. A dry run
+// does the same work and rolls it back, so the preview matches what a real
+// run would do. Concurrent runs are serialized by an advisory lock; the
+// loser gets errSyncLocked.
+func (r *Repository) ApplySync(ctx context.Context, pois []POI, maxRemovedRatio float64, dryRun bool) (Diff, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Diff{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var locked bool
+	if err := tx.QueryRow(ctx, `select pg_try_advisory_xact_lock($1::bigint)`, int64(syncLockKey)).Scan(&locked); err != nil {
+		return Diff{}, err
+	}
+	if !locked {
+		return Diff{}, errSyncLocked
+	}
+
+	links, err := loadLinks(ctx, tx)
+	if err != nil {
+		return Diff{}, err
+	}
+	diff := ComputeDiff(pois, links, maxRemovedRatio)
+
+	for i := range diff.Changes {
+		if err := applyChange(ctx, tx, &diff.Changes[i]); err != nil {
+			return Diff{}, fmt.Errorf("%s %s: %w", diff.Changes[i].Kind, osmRef(diff.Changes[i].OSMType, diff.Changes[i].OSMID), err)
+		}
+	}
+	if err := touchLinks(ctx, tx, pois); err != nil {
+		return Diff{}, err
+	}
+
+	if dryRun {
+		return diff, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return Diff{}, err
+	}
+	return diff, nil
+}
+
+func loadLinks(ctx context.Context, tx pgx.Tx) ([]Link, error) {
+	rows, err := tx.Query(
+		ctx,
+		`select l.osm_type, l.osm_id, l.cafe_id::text, coalesce(c.status, ''),
+		        l.name, l.address, l.lat, l.lng, l.removed_at
+		   from public.osm_cafe_links l
+		   left join public.cafes c on c.id = l.cafe_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Link
+	for rows.Next() {
+		var link Link
+		if err := rows.Scan(
+			&link.OSMType,
+			&link.OSMID,
+			&link.CafeID,
+			&link.CafeStatus,
+			&link.Name,
+			&link.Address,
+			&link.Latitude,
+			&link.Longitude,
+			&link.RemovedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, link)
+	}
+	return out, rows.Err()
+}
+
+func applyChange(ctx context.Context, tx pgx.Tx, change *Change) error {
+	switch change.Kind {
+	case ChangeCreated:
+		return applyCreated(ctx, tx, change)
+	case ChangeUpdated:
+		if err := updateLinkSnapshot(ctx, tx, *change); err != nil {
+			return err
+		}
+		if change.CafeID == nil || !acceptsChanges(change.previous.CafeStatus) {
+			return nil
+		}
+		return submit(ctx, tx, change, "cafe_details", detailsPayload(*change))
+	case ChangeRemoved:
+		if _, err := tx.Exec(
+			ctx,
+			`update public.osm_cafe_links
+			    set removed_at = now(), updated_at = now()
+			  where osm_type = $1 and osm_id = $2`,
+			change.OSMType,
+			change.OSMID,
+		); err != nil {
+			return err
+		}
+		if change.CafeID == nil || !acceptsChanges(change.previous.CafeStatus) {
+			return nil
+		}
+		return submit(ctx, tx, change, "cafe_closure", closurePayload{
+			Status:  model.CafeStatusPermanentlyClosed,
+			Comment: fmt.Sprintf("Точка удалена из OpenStreetMap (%s).", osmRef(change.OSMType, change.OSMID)),
+			OSMType: change.OSMType,
+			OSMID:   change.OSMID,
+		})
+	case ChangeRestored:
+		// A reappeared element only revives its link; if its cafe was closed
+		// meanwhile, reopening it is left to visitors and moderators.
+		return updateLinkSnapshot(ctx, tx, *change)
+	default:
+		return fmt.Errorf("unknown change kind %q", change.Kind)
+	}
+}
+
+// applyCreated links a new element to a nearby cafe with a similar name, or
+// proposes it as a new cafe. Cafes that already have an element are not
+// matched again, so a node and a building mapped for the same cafe end up as
+// one link and one create submission for moderators to sort out.
+func applyCreated(ctx context.Context, tx pgx.Tx, change *Change) error {
+	var cafeID string
+	err := tx.QueryRow(
+		ctx,
+		`select c.id::text
+		   from public.cafes c
+		  where c.status <> 'deleted'
+		    and c.geog is not null
+		    and ST_DWithin(c.geog, ST_SetSRID(ST_MakePoint($2::double precision, $1::double precision), 4326)::geography, $3::double precision)
+		    and similarity(lower(c.name), lower($4::text)) >= $5::real
+		    and not exists (select 1 from public.osm_cafe_links l where l.cafe_id = c.id)
+		  order by similarity(lower(c.name), lower($4::text)) desc,
+		           ST_Distance(c.geog, ST_SetSRID(ST_MakePoint($2::double precision, $1::double precision), 4326)::geography) asc
+		  limit 1`,
+		change.Latitude,
+		change.Longitude,
+		matchRadiusM,
+		change.Name,
+		matchMinNameSimilarity,
+	).Scan(&cafeID)
+	switch {
+	case err == nil:
+		change.Kind = ChangeMatched
+		change.CafeID = &cafeID
+	case errors.Is(err, pgx.ErrNoRows):
+	default:
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`insert into public.osm_cafe_links (osm_type, osm_id, cafe_id, name, address, lat, lng)
+		 values ($1, $2, $3::uuid, $4, $5, $6, $7)`,
+		change.OSMType,
+		change.OSMID,
+		change.CafeID,
+		change.Name,
+		change.Address,
+		change.Latitude,
+		change.Longitude,
+	); err != nil {
+		return err
+	}
+	if change.Kind == ChangeMatched {
+		return nil
+	}
+	amenities := change.Amenities
+	if amenities == nil {
+		amenities = []string{}
+	}
+	return submit(ctx, tx, change, "cafe", cafeCreatePayload{
+		Name:      change.Name,
+		Address:   change.Address,
+		Latitude:  change.Latitude,
+		Longitude: change.Longitude,
+		Amenities: amenities,
+		OSMType:   change.OSMType,
+		OSMID:     change.OSMID,
+	})
+}
+
+func updateLinkSnapshot(ctx context.Context, tx pgx.Tx, change Change) error {
+	_, err := tx.Exec(
+		ctx,
+		`update public.osm_cafe_links
+		    set name = $3, address = $4, lat = $5, lng = $6, removed_at = null, updated_at = now()
+		  where osm_type = $1 and osm_id = $2`,
+		change.OSMType,
+		change.OSMID,
+		change.Name,
+		change.Address,
+		change.Latitude,
+		change.Longitude,
+	)
+	return err
+}
+
+func touchLinks(ctx context.Context, tx pgx.Tx, pois []POI) error {
+	if len(pois) == 0 {
+		return nil
+	}
+	types := make([]string, len(pois))
+	ids := make([]int64, len(pois))
+	for i, poi := range pois {
+		types[i] = poi.OSMType
+		ids[i] = poi.OSMID
+	}
+	_, err := tx.Exec(
+		ctx,
+		`update public.osm_cafe_links l
+		    set last_seen_at = now()
+		   from unnest($1::text[], $2::bigint[]) as s(osm_type, osm_id)
+		  where l.osm_type = s.osm_type and l.osm_id = s.osm_id`,
+		types,
+		ids,
+	)
+	return err
+}
+
+// acceptsChanges reports whether OSM edits to a cafe in this status are worth
+// a moderator's time: deleted and permanently closed cafes are settled.
+func acceptsChanges(status string) bool {
+	return status == model.CafeStatusActive || status == model.CafeStatusTemporarilyClosed
+}
+
+func submit(ctx context.Context, tx pgx.Tx, change *Change, entityType string, payload any) error {
+	actionType := "update"
+	if entityType == "cafe" {
+		actionType = "create"
+	}
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	var submissionID string
+	if err := tx.QueryRow(
+		ctx,
+		`insert into public.moderation_submissions (author_user_id, entity_type, action_type, target_id, payload)
+		 values ($1::uuid, $2, $3, $4::uuid, $5::jsonb)
+		 returning id::text`,
+		SystemUserID,
+		entityType,
+		actionType,
+		change.CafeID,
+		raw,
+	).Scan(&submissionID); err != nil {
+		return err
+	}
+	change.SubmissionID = &submissionID
+	return nil
+}
+
+// LinkCafe attaches the cafe created from an approved OSM submission to its
+// element.
+func LinkCafe(ctx context.Context, tx pgx.Tx, osmType string, osmID int64, cafeID string) error {
+	_, err := tx.Exec(
+		ctx,
+		`update public.osm_cafe_links
+		    set cafe_id = $3::uuid, updated_at = now()
+		  where osm_type = $1 and osm_id = $2 and cafe_id is null`,
+		osmType,
+		osmID,
+		cafeID,
+	)
+	return err
+}
+
+const syncRunColumns = `id::text, source, dry_run, status, fetched_count, created_count, matched_count,
+  renamed_count, moved_count, removed_count, restored_count, unchanged_count, submissions_count,
+  removals_skipped, coalesce(error, ''), started_at, finished_at`
+
+func scanSyncRun(row pgx.Row) (SyncRun, error) {
+	var (
+		run        SyncRun
+		finishedAt time.Time
+	)
+	if err := row.Scan(
+		&run.ID,
+		&run.Source,
+		&run.DryRun,
+		&run.Status,
+		&run.Fetched,
+		&run.Created,
+		&run.Matched,
+		&run.Renamed,
+		&run.Moved,
+		&run.Removed,
+		&run.Restored,
+		&run.Unchanged,
+		&run.Submissions,
+		&run.RemovalsSkipped,
+		&run.Error,
+		&run.StartedAt,
+		&finishedAt,
+	); err != nil {
+		return SyncRun{}, err
+	}
+	run.FinishedAt = &finishedAt
+	return run, nil
+}
+
+func (r *Repository) InsertRun(ctx context.Context, run SyncRun) (SyncRun, error) {
+	return scanSyncRun(r.pool.QueryRow(
+		ctx,
+		`insert into public.osm_sync_runs (
+		   source, dry_run, status, fetched_count, created_count, matched_count, renamed_count,
+		   moved_count, removed_count, restored_count, unchanged_count, submissions_count,
+		   removals_skipped, error, started_at
+		 ) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, nullif($14, ''), $15)
+		 returning `+syncRunColumns,
+		run.Source,
+		run.DryRun,
+		run.Status,
+		run.Fetched,
+		run.Created,
+		run.Matched,
+		run.Renamed,
+		run.Moved,
+		run.Removed,
+		run.Restored,
+		run.Unchanged,
+		run.Submissions,
+		run.RemovalsSkipped,
+		run.Error,
+		run.StartedAt,
+	))
+}
+
+func (r *Repository) ListRuns(ctx context.Context, limit int) ([]SyncRun, error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`select `+syncRunColumns+`
+		   from public.osm_sync_runs
+		  order by started_at desc, id desc
+		  limit $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]SyncRun, 0, limit)
+	for rows.Next() {
+		run, err := scanSyncRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, run)
+	}
+	return out, rows.Err()
+}
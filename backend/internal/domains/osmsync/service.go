@@ -0,0 +1,172 @@
+package osmsync
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"backend/internal/config"
+)
+
+var errFetchFailed = errors.New("osm fetch failed")
+
+type repository interface {
+	ApplySync(ctx context.Context, pois []POI, maxRemovedRatio float64, dryRun bool) (Diff, error)
+	InsertRun(ctx context.Context, run SyncRun) (SyncRun, error)
+	ListRuns(ctx context.Context, limit int) ([]SyncRun, error)
+}
+
+type Service struct {
+	repository repository
+	cfg        config.OSMSyncConfig
+}
+
+func NewService(repository repository, cfg config.OSMSyncConfig) *Service {
+	return &Service{repository: repository, cfg: cfg}
+}
+
+// OverpassSource is the live source for the configured area.
+func (s *Service) OverpassSource() *OverpassSource {
+	return &OverpassSource{
+		Endpoint:  s.cfg.OverpassURL,
+		Query:     BuildOverpassQuery(s.cfg.AreaID, s.cfg.Timeout),
+		UserAgent: s.cfg.UserAgent,
+		// Overpass may spend the whole query timeout before it answers.
+		Client: &http.Client{Timeout: s.cfg.Timeout + 30*time.Second},
+	}
+}
+
+// Run fetches the current cafes from source, applies the diff and records
+// the run. Failed runs are recorded too, except when another instance holds
+// the sync lock.
+func (s *Service) Run(ctx context.Context, source Source, dryRun bool) (SyncResult, error) {
+	run := SyncRun{
+		Source:    source.Name(),
+		DryRun:    dryRun,
+		StartedAt: time.Now().UTC(),
+	}
+
+	pois, err := source.Fetch(ctx)
+	if err != nil {
+		s.recordFailure(ctx, run, err)
+		return SyncResult{}, errors.Join(errFetchFailed, err)
+	}
+	run.Fetched = len(pois)
+
+	diff, err := s.repository.ApplySync(ctx, pois, s.cfg.MaxRemovedRatio, dryRun)
+	if err != nil {
+		if !errors.Is(err, errSyncLocked) {
+			s.recordFailure(ctx, run, err)
+		}
+		return SyncResult{}, err
+	}
+
+	run.Status = RunStatusCompleted
+	run.Unchanged = diff.Unchanged
+	run.RemovalsSkipped = diff.RemovalsSkipped
+	for _, change := range diff.Changes {
+		switch change.Kind {
+		case ChangeCreated:
+			run.Created++
+		case ChangeMatched:
+			run.Matched++
+		case ChangeUpdated:
+			if change.Renamed {
+				run.Renamed++
+			}
+			if change.Moved {
+				run.Moved++
+			}
+		case ChangeRemoved:
+			run.Removed++
+		case ChangeRestored:
+			run.Restored++
+		}
+		if change.SubmissionID != nil {
+			run.Submissions++
+		}
+	}
+
+	saved, err := s.repository.InsertRun(ctx, run)
+	if err != nil {
+		return SyncResult{}, err
+	}
+	result := SyncResult{Run: saved}
+	if dryRun {
+		result.Changes = diff.Changes
+		if len(result.Changes) > previewMaxChanges {
+			result.Changes = result.Changes[:previewMaxChanges]
+		}
+		for i := range result.Changes {
+			result.Changes[i].SubmissionID = nil
+		}
+	}
+	return result, nil
+}
+
+func (s *Service) recordFailure(ctx context.Context, run SyncRun, cause error) {
+	run.Status = RunStatusFailed
+	run.Error = cause.Error()
+	// The run context may be what failed; the record gets its own deadline.
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if _, err := s.repository.InsertRun(recordCtx, run); err != nil {
+		slog.Error("osm sync run record failed", "error", err)
+	}
+}
+
+func (s *Service) ListRuns(ctx context.Context, limit int) ([]SyncRun, error) {
+	if limit <= 0 || limit > runListMaxLimit {
+		limit = runListDefaultLimit
+	}
+	return s.repository.ListRuns(ctx, limit)
+}
+
+// StartWorker syncs the configured area with Overpass on every tick. Nothing
+// runs at startup: Overpass is a shared service and restarts should not
+// multiply requests to it.
+func (s *Service) StartWorker(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	logger := slog.Default().With("worker_name", "osm_sync")
+	logger.Info("worker started", "interval", interval, "area_id", s.cfg.AreaID)
+	defer logger.Info("worker stopped")
+
+	source := s.OverpassSource()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout+time.Minute)
+			result, err := s.Run(runCtx, source, false)
+			cancel()
+			if errors.Is(err, errSyncLocked) {
+				logger.Info("sync skipped, another instance holds lock", "lock_key", syncLockKey)
+				continue
+			}
+			if err != nil {
+				logger.Error("sync failed", "error", err)
+				continue
+			}
+			run := result.Run
+			logger.Info("sync completed",
+				"fetched", run.Fetched,
+				"created", run.Created,
+				"matched", run.Matched,
+				"renamed", run.Renamed,
+				"moved", run.Moved,
+				"removed", run.Removed,
+				"restored", run.Restored,
+				"submissions", run.Submissions,
+				"removals_skipped", run.RemovalsSkipped,
+			)
+		}
+	}
+}
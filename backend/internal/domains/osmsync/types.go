@@ -0,0 +1,202 @@
+package osmsync
+
+import "time"
+
+type DomainType string
+
+const DomainName DomainType = "osmsync"
+
+const (
+	// SystemUserID authors the submissions created by the sync. The user is
+	// created by migration 000045 and has no credentials.
+	SystemUserID = "00000000-0000-4000-8000-000000000051"
+
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+
+	SourceOverpass = "overpass"
+	SourceFile     = "file"
+
+	syncLockKey = 740053
+
+	// movedThresholdM is how far an element must move before a relocation is
+	// proposed; smaller shifts are mapping noise (node vs building centroid).
+	movedThresholdM = 25.0
+	// New elements are linked to an existing cafe instead of proposed as a
+	// new one when a cafe with a similar name is this close.
+	matchRadiusM           = 60.0
+	matchMinNameSimilarity = 0.4
+
+	runListDefaultLimit = 20
+	runListMaxLimit     = 100
+	// previewMaxChanges bounds the change list returned by a dry run.
+	previewMaxChanges = 200
+)
+
+// POI is a cafe as currently mapped in OpenStreetMap.
+type POI struct {
+	OSMType   string   `json:"osm_type"`
+	OSMID     int64    `json:"osm_id"`
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Amenities []string `json:"amenities,omitempty"`
+}
+
+// Ref is the "node/123" form used in logs and submission comments.
+func (p POI) Ref() string {
+	return osmRef(p.OSMType, p.OSMID)
+}
+
+// Link is the stored state of an OSM element: the cafe it belongs to (nil
+// while its create submission waits for moderation or after a rejection)
+// and a snapshot of the element as of the last sync that changed it.
+type Link struct {
+	OSMType    string
+	OSMID      int64
+	CafeID     *string
+	CafeStatus string
+	Name       string
+	Address    string
+	Latitude   float64
+	Longitude  float64
+	RemovedAt  *time.Time
+}
+
+func (l Link) Ref() string {
+	return osmRef(l.OSMType, l.OSMID)
+}
+
+type elementKey struct {
+	osmType string
+	osmID   int64
+}
+
+const (
+	ChangeCreated  = "created"
+	ChangeMatched  = "matched"
+	ChangeUpdated  = "updated"
+	ChangeRemoved  = "removed"
+	ChangeRestored = "restored"
+)
+
+// Change is one difference between OpenStreetMap and the stored links.
+type Change struct {
+	Kind         string   `json:"kind"`
+	OSMType      string   `json:"osm_type"`
+	OSMID        int64    `json:"osm_id"`
+	CafeID       *string  `json:"cafe_id,omitempty"`
+	Name         string   `json:"name"`
+	Address      string   `json:"address"`
+	Latitude     float64  `json:"latitude"`
+	Longitude    float64  `json:"longitude"`
+	Renamed      bool     `json:"renamed,omitempty"`
+	Moved        bool     `json:"moved,omitempty"`
+	DistanceM    float64  `json:"distance_m,omitempty"`
+	SubmissionID *string  `json:"submission_id,omitempty"`
+	Amenities    []string `json:"-"`
+	previous     *Link
+}
+
+// Diff is the outcome of comparing fetched POIs with the stored links.
+type Diff struct {
+	Changes         []Change
+	Unchanged       int
+	RemovalsSkipped bool
+}
+
+type SyncRun struct {
+	ID              string     `json:"id"`
+	Source          string     `json:"source"`
+	DryRun          bool       `json:"dry_run"`
+	Status          string     `json:"status"`
+	Fetched         int        `json:"fetched"`
+	Created         int        `json:"created"`
+	Matched         int        `json:"matched"`
+	Renamed         int        `json:"renamed"`
+	Moved           int        `json:"moved"`
+	Removed         int        `json:"removed"`
+	Restored        int        `json:"restored"`
+	Unchanged       int        `json:"unchanged"`
+	Submissions     int        `json:"submissions"`
+	RemovalsSkipped bool       `json:"removals_skipped"`
+	Error           string     `json:"error,omitempty"`
+	StartedAt       time.Time  `json:"started_at"`
+	FinishedAt      *time.Time `json:"finished_at,omitempty"`
+}
+
+type SyncResult struct {
+	Run     SyncRun  `json:"run"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Submission payloads. cafeCreatePayload and closurePayload extend the
+// shapes the moderation handler already applies with the element reference;
+// cafe_details carries only the fields that changed in OSM plus their
+// previous OSM values for the moderator.
+type cafeCreatePayload struct {
+	Name      string   `json:"name"`
+	Address   string   `json:"address"`
+	Latitude  float64  `json:"latitude"`
+	Longitude float64  `json:"longitude"`
+	Amenities []string `json:"amenities"`
+	OSMType   string   `json:"osm_type"`
+	OSMID     int64    `json:"osm_id"`
+}
+
+type closurePayload struct {
+	Status  string `json:"status"`
+	Comment string `json:"comment"`
+	OSMType string `json:"osm_type"`
+	OSMID   int64  `json:"osm_id"`
+}
+
+type cafeDetailsPayload struct {
+	Name      *string          `json:"name,omitempty"`
+	Address   *string          `json:"address,omitempty"`
+	Latitude  *float64         `json:"latitude,omitempty"`
+	Longitude *float64         `json:"longitude,omitempty"`
+	DistanceM float64          `json:"distance_m,omitempty"`
+	Previous  cafeDetailsState `json:"osm_previous"`
+	OSMType   string           `json:"osm_type"`
+	OSMID     int64            `json:"osm_id"`
+}
+
+type cafeDetailsState struct {
+	Name      string  `json:"name"`
+	Address   string  `json:"address"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+func detailsPayload(change Change) cafeDetailsPayload {
+	payload := cafeDetailsPayload{
+		OSMType: change.OSMType,
+		OSMID:   change.OSMID,
+	}
+	if change.previous != nil {
+		payload.Previous = cafeDetailsState{
+			Name:      change.previous.Name,
+			Address:   change.previous.Address,
+			Latitude:  change.previous.Latitude,
+			Longitude: change.previous.Longitude,
+		}
+	}
+	if change.Renamed {
+		name, address := change.Name, change.Address
+		if change.previous == nil || !sameText(name, change.previous.Name) {
+			payload.Name = &name
+		}
+		if change.previous == nil || !sameText(address, change.previous.Address) {
+			payload.Address = &address
+		}
+	}
+	if change.Moved {
+		lat, lng := change.Latitude, change.Longitude
+		payload.Latitude = &lat
+		payload.Longitude = &lng
+		payload.DistanceM = change.DistanceM
+	}
+	return payload
+}
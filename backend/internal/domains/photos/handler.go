@@ -828,11 +828,12 @@ func (h *Handler) Delete(c *gin.Context) {
 	c.JSON(http.StatusOK, cafePhotoListResponse{Photos: photos})
 }
 
+// EnsureCafeExists returns pgx.ErrNoRows for unknown and soft-deleted cafes.
 func EnsureCafeExists(ctx context.Context, pool *pgxpool.Pool, cafeID string) error {
 	var exists bool
 	if err := pool.QueryRow(
 		ctx,
-		`select exists(select 1 from cafes where id = $1::uuid)`,
+		`select exists(select 1 from cafes where id = $1::uuid and status <> 'deleted')`,
 		cafeID,
 	).Scan(&exists); err != nil {
 		return err
@@ -973,13 +974,19 @@ func BuildPhotoURL(mediaCfg config.MediaConfig, objectKey string) string {
 	if strings.HasPrefix(key, "http://") || strings.HasPrefix(key, "https://") {
 		return key
 	}
+	return PhotoURLPrefix(mediaCfg) + key
+}
+
+// PhotoURLPrefix returns the public URL prefix that BuildPhotoURL puts in
+// front of relative object keys ("" when S3 is not configured).
+func PhotoURLPrefix(mediaCfg config.MediaConfig) string {
 	if strings.TrimSpace(mediaCfg.S3PublicBaseURL) != "" {
-		return strings.TrimRight(mediaCfg.S3PublicBaseURL, "/") + "/" + key
+		return strings.TrimRight(mediaCfg.S3PublicBaseURL, "/") + "/"
 	}
 
 	endpoint := strings.TrimSpace(mediaCfg.S3Endpoint)
 	if endpoint == "" || strings.TrimSpace(mediaCfg.S3Bucket) == "" {
-		return key
+		return ""
 	}
 	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
 		endpoint = "https://" + endpoint
@@ -987,11 +994,11 @@ func BuildPhotoURL(mediaCfg config.MediaConfig, objectKey string) string {
 	endpoint = strings.TrimRight(endpoint, "/")
 
 	if mediaCfg.S3UsePathStyle {
-		return endpoint + "/" + mediaCfg.S3Bucket + "/" + key
+		return endpoint + "/" + mediaCfg.S3Bucket + "/"
 	}
 	schemeHost := strings.TrimPrefix(strings.TrimPrefix(endpoint, "https://"), "http://")
 	if strings.HasPrefix(endpoint, "https://") {
-		return "https://" + mediaCfg.S3Bucket + "." + schemeHost + "/" + key
+		return "https://" + mediaCfg.S3Bucket + "." + schemeHost + "/"
 	}
-	return "http://" + mediaCfg.S3Bucket + "." + schemeHost + "/" + key
+	return "http://" + mediaCfg.S3Bucket + "." + schemeHost + "/"
 }
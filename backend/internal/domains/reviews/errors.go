@@ -18,4 +18,8 @@ var (
 	ErrCheckInTooEarly       = errors.New("check-in dwell is too short")
 	ErrCheckInCooldown       = errors.New("check-in cooldown is active")
 	ErrCheckInSuspicious     = errors.New("check-in looks suspicious")
+	ErrCafeClosed            = errors.New("cafe is closed")
+	ErrReplyExists           = errors.New("review already has a reply")
+	ErrCommentWindowClosed   = errors.New("comment edit window has closed")
+	ErrCityNotFound          = errors.New("city not found")
 )
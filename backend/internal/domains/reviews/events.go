@@ -4,16 +4,30 @@ const (
 	EventReviewCreated               = "review.created"
 	EventReviewUpdated               = "review.updated"
 	EventHelpfulAdded                = "vote.helpful_added"
+	EventHelpfulRetracted            = "vote.helpful_retracted"
+	EventNotHelpfulAdded             = "vote.not_helpful_added"
+	EventNotHelpfulRetracted         = "vote.not_helpful_retracted"
 	EventVisitVerified               = "visit.verified"
 	EventAbuseConfirmed              = "abuse.confirmed"
 	EventReviewPhotoProcessRequested = "review.photo.process_requested"
+	// EventCafeMerged is written by the cafes admin merge; the aggregate is
+	// the surviving cafe.
+	EventCafeMerged = "cafe.merged"
+	// EventReviewReplyCreated is emitted when a cafe owner replies to a
+	// review, so the review author can be notified.
+	EventReviewReplyCreated = "review.reply_created"
+	// EventReviewCommentCreated is emitted for every new comment in a review
+	// thread, with the review and parent comment authors to notify.
+	EventReviewCommentCreated = "review.comment_created"
 )
 
 const (
-	IdempotencyScopeReviewPublish = "review.publish"
-	IdempotencyScopeReviewCreate  = "review.create"
-	IdempotencyScopeReviewUpdate  = "review.update"
-	IdempotencyScopeHelpfulVote   = "vote.helpful"
-	IdempotencyScopeCheckInStart  = "checkin.start"
-	IdempotencyScopeCheckIn       = "checkin.verify"
+	IdempotencyScopeReviewPublish  = "review.publish"
+	IdempotencyScopeReviewCreate   = "review.create"
+	IdempotencyScopeReviewUpdate   = "review.update"
+	IdempotencyScopeHelpfulVote    = "vote.helpful"
+	IdempotencyScopeNotHelpfulVote = "vote.not_helpful"
+	IdempotencyScopeHelpfulRetract = "vote.retract"
+	IdempotencyScopeCheckInStart   = "checkin.start"
+	IdempotencyScopeCheckIn        = "checkin.verify"
 )
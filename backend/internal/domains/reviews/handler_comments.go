@@ -0,0 +1,261 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/pagination"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+const reviewCommentCursorVersion = 1
+
+type reviewCommentCursorPayload struct {
+	Version   int    `json:"v"`
+	ReviewID  string `json:"r"`
+	CreatedAt string `json:"t"`
+	ID        string `json:"id"`
+}
+
+func (h *Handler) ListComments(c *gin.Context) {
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+	limit, err := parseReviewCommentsLimit(c.Query("limit"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+	after, err := parseReviewCommentCursor(c.Query("cursor"), reviewID)
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	page, err := h.service.ListReviewComments(ctx, reviewID, after, limit)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	nextCursor := ""
+	if page.HasMore && page.Last != nil {
+		nextCursor, err = encodeReviewCommentCursor(*page.Last, reviewID)
+		if err != nil {
+			h.respondDomainError(c, err)
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"review_id":   reviewID,
+		"limit":       limit,
+		"has_more":    page.HasMore,
+		"next_cursor": nextCursor,
+		"comments":    page.Comments,
+	})
+}
+
+func (h *Handler) CreateComment(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+
+	var req ReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	parentID := strings.TrimSpace(req.ParentID)
+	if parentID != "" && !validation.IsValidUUID(parentID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный parent_id комментария.", nil)
+		return
+	}
+	body, validationErr := normalizeReviewCommentBody(req.Body)
+	if validationErr != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", validationErr.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := h.service.CreateReviewComment(ctx, userID, reviewID, parentID, body)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *Handler) UpdateComment(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	commentID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(commentID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id комментария.", nil)
+		return
+	}
+
+	var req ReviewCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	body, validationErr := normalizeReviewCommentBody(req.Body)
+	if validationErr != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", validationErr.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := h.service.UpdateReviewComment(ctx, userID, commentID, body)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) DeleteComment(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+	userRole, _ := auth.UserRoleFromContext(c)
+
+	commentID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(commentID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id комментария.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	asModerator := userRole == "admin" || userRole == "moderator"
+	response, err := h.service.DeleteReviewComment(ctx, userID, commentID, asModerator)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ReportCommentAbuse(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	commentID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(commentID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id комментария.", nil)
+		return
+	}
+
+	var req ReportAbuseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReportCommentAbuse(ctx, userID, commentID, req)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func normalizeReviewCommentBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	switch length := utfRuneLen(body); {
+	case length < minReviewCommentLength:
+		return body, errInvalid("Текст комментария должен быть не короче 2 символов.")
+	case length > maxReviewCommentLength:
+		return body, errInvalid("Текст комментария должен быть не длиннее 2000 символов.")
+	}
+	return body, nil
+}
+
+func parseReviewCommentsLimit(raw string) (int, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return defaultReviewCommentsLimit, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, errInvalid("limit должен быть целым числом.")
+	}
+	if size <= 0 {
+		return 0, errInvalid("limit должен быть больше 0.")
+	}
+	if size > maxReviewCommentsLimit {
+		return 0, errInvalid("limit не может быть больше 50.")
+	}
+	return size, nil
+}
+
+// parseReviewCommentCursor accepts only signed cursors; they are pinned to
+// the review they were issued for.
+func parseReviewCommentCursor(raw string, reviewID string) (*reviewCommentKeyset, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return nil, nil
+	}
+	var payload reviewCommentCursorPayload
+	if err := pagination.Default().Decode(value, &payload); err != nil {
+		if errors.Is(err, pagination.ErrCursorSignature) {
+			return nil, errInvalid("cursor устарел или повреждён, загрузите список заново.")
+		}
+		return nil, errInvalid("cursor имеет некорректный формат.")
+	}
+	if payload.Version != reviewCommentCursorVersion || payload.ReviewID != reviewID {
+		return nil, errInvalid("cursor устарел или повреждён, загрузите список заново.")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil || !validation.IsValidUUID(payload.ID) {
+		return nil, errInvalid("cursor имеет некорректный формат.")
+	}
+	return &reviewCommentKeyset{CreatedAt: createdAt, ID: payload.ID}, nil
+}
+
+func encodeReviewCommentCursor(keyset reviewCommentKeyset, reviewID string) (string, error) {
+	return pagination.Default().Encode(reviewCommentCursorPayload{
+		Version:   reviewCommentCursorVersion,
+		ReviewID:  reviewID,
+		CreatedAt: keyset.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        keyset.ID,
+	})
+}
@@ -0,0 +1,84 @@
+package reviews
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeReviewCommentBody(t *testing.T) {
+	if got, err := normalizeReviewCommentBody("  Согласен, раф там отличный  "); err != nil || got != "Согласен, раф там отличный" {
+		t.Fatalf("expected trimmed body, got %q (%v)", got, err)
+	}
+	if _, err := normalizeReviewCommentBody(" ! "); err == nil {
+		t.Fatalf("expected too short comment to be rejected")
+	}
+	if _, err := normalizeReviewCommentBody(strings.Repeat("я", maxReviewCommentLength+1)); err == nil {
+		t.Fatalf("expected too long comment to be rejected")
+	}
+}
+
+func TestReviewCommentCursorRoundTrip(t *testing.T) {
+	reviewID := "7f0c1d8e-2a4b-4c6d-8e9f-0a1b2c3d4e5f"
+	keyset := reviewCommentKeyset{
+		CreatedAt: time.Date(2026, 5, 1, 12, 0, 0, 123456000, time.UTC),
+		ID:        "1b2c3d4e-5f60-4718-8293-a4b5c6d7e8f9",
+	}
+	token, err := encodeReviewCommentCursor(keyset, reviewID)
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	after, err := parseReviewCommentCursor(token, reviewID)
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if after == nil || !after.CreatedAt.Equal(keyset.CreatedAt) || after.ID != keyset.ID {
+		t.Fatalf("unexpected keyset %+v", after)
+	}
+	if _, err := parseReviewCommentCursor(token, keyset.ID); err == nil {
+		t.Fatalf("expected cursor of another review to be rejected")
+	}
+	if after, err := parseReviewCommentCursor("", reviewID); err != nil || after != nil {
+		t.Fatalf("expected empty cursor to start from the first page")
+	}
+}
+
+func TestReviewCommentResponse(t *testing.T) {
+	createdAt := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	comment := reviewCommentState{
+		ID:             "c1",
+		ReviewID:       "r1",
+		ParentID:       "c0",
+		AuthorUserID:   "u1",
+		AuthorName:     "Аня",
+		Body:           "Спасибо!",
+		Status:         "published",
+		CreatedAt:      createdAt,
+		IsReviewAuthor: true,
+		IsModerator:    true,
+	}
+	response := comment.response()
+	badges, _ := response["badges"].([]string)
+	if strings.Join(badges, ",") != "author,moderator" {
+		t.Fatalf("unexpected badges: %v", badges)
+	}
+	if response["parent_id"] != "c0" || response["edited"] != false {
+		t.Fatalf("unexpected response: %v", response)
+	}
+
+	comment.Status = "removed"
+	removed := comment.response()
+	if removed["body"] != "" || removed["author_user_id"] != nil || removed["removed"] != true {
+		t.Fatalf("removed comment should hide its content: %v", removed)
+	}
+
+	if !reviewCommentEditable(createdAt, createdAt.Add(reviewCommentEditWindow)) {
+		t.Fatalf("expected edit to be allowed at the window boundary")
+	}
+	if reviewCommentEditable(createdAt, createdAt.Add(reviewCommentEditWindow+time.Second)) {
+		t.Fatalf("expected edit window to close")
+	}
+	if !reviewCommentDeletable(createdAt, createdAt.Add(time.Hour)) || reviewCommentDeletable(createdAt, createdAt.Add(25*time.Hour)) {
+		t.Fatalf("unexpected delete window")
+	}
+}
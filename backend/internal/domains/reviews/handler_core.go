@@ -66,6 +66,14 @@ func (h *Handler) respondDomainError(c *gin.Context, err error) {
 		httpx.RespondError(c, http.StatusTooManyRequests, "rate_limited", "Перед check-in в другой кофейне подождите 5 минут.", nil)
 	case errors.Is(err, ErrCheckInSuspicious):
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Подозрительная активность check-in. Попробуйте позже.", nil)
+	case errors.Is(err, ErrCafeClosed):
+		httpx.RespondError(c, http.StatusConflict, "cafe_closed", "Кофейня закрыта, действие недоступно.", nil)
+	case errors.Is(err, ErrReplyExists):
+		httpx.RespondError(c, http.StatusConflict, "already_exists", "У отзыва уже есть ответ кофейни. Используйте редактирование.", nil)
+	case errors.Is(err, ErrCommentWindowClosed):
+		httpx.RespondError(c, http.StatusConflict, "window_closed", "Время на изменение комментария истекло.", nil)
+	case errors.Is(err, ErrCityNotFound):
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
 	case errors.Is(err, ErrIdempotencyConflict):
 		httpx.RespondError(c, http.StatusConflict, "idempotency_conflict", "Idempotency-Key уже использован с другим payload.", nil)
 	case errors.Is(err, ErrIdempotencyInProgress):
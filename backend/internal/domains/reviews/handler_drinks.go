@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
 
 	"github.com/gin-gonic/gin"
 )
@@ -48,3 +49,63 @@ func (h *Handler) ListDrinks(c *gin.Context) {
 		"drinks": drinks,
 	})
 }
+
+func (h *Handler) GetCafeDrinkRankings(c *gin.Context) {
+	cafeID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(cafeID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id кофейни.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.ListCafeDrinkRankings(ctx, cafeID)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) GetDrinkLeaderboard(c *gin.Context) {
+	drinkID := normalizeDrinkToken(c.Param("id"))
+	if drinkID == "" {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id напитка.", nil)
+		return
+	}
+
+	city, err := validation.ParseCitySlug(c.Query("city"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return
+	}
+
+	limit := defaultDrinkLeaderboardLimit
+	if rawLimit := strings.TrimSpace(c.Query("limit")); rawLimit != "" {
+		parsedLimit, err := strconv.Atoi(rawLimit)
+		if err != nil {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть целым числом.", nil)
+			return
+		}
+		if parsedLimit <= 0 {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit должен быть больше 0.", nil)
+			return
+		}
+		if parsedLimit > maxDrinkLeaderboardLimit {
+			httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "limit не может быть больше 100.", nil)
+			return
+		}
+		limit = parsedLimit
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	response, err := h.service.DrinkLeaderboard(ctx, drinkID, city, limit)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
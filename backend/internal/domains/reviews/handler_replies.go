@@ -0,0 +1,128 @@
+package reviews
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/auth"
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) CreateReply(c *gin.Context) {
+	h.writeReply(c, h.service.CreateReviewReply, http.StatusCreated)
+}
+
+func (h *Handler) UpdateReply(c *gin.Context) {
+	h.writeReply(c, h.service.UpdateReviewReply, http.StatusOK)
+}
+
+func (h *Handler) writeReply(
+	c *gin.Context,
+	write func(ctx context.Context, userID, reviewID, body string) (map[string]interface{}, error),
+	status int,
+) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+
+	var req ReviewReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+	body, validationErr := normalizeReviewReplyBody(req.Body)
+	if validationErr != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", validationErr.Error(), nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := write(ctx, userID, reviewID, body)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(status, response)
+}
+
+func (h *Handler) DeleteReply(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+	userRole, _ := auth.UserRoleFromContext(c)
+
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	asModerator := userRole == "admin" || userRole == "moderator"
+	response, err := h.service.DeleteReviewReply(ctx, userID, reviewID, asModerator)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) ReportReplyAbuse(c *gin.Context) {
+	userID, ok := auth.UserIDFromContext(c)
+	if !ok || strings.TrimSpace(userID) == "" {
+		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
+		return
+	}
+
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+
+	var req ReportAbuseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный JSON в запросе.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := h.service.ReportReplyAbuse(ctx, userID, reviewID, req)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func normalizeReviewReplyBody(raw string) (string, error) {
+	body := strings.TrimSpace(raw)
+	switch length := utfRuneLen(body); {
+	case length < minReviewReplyLength:
+		return body, errInvalid("Текст ответа должен быть не короче 2 символов.")
+	case length > maxReviewReplyLength:
+		return body, errInvalid("Текст ответа должен быть не длиннее 2000 символов.")
+	}
+	return body, nil
+}
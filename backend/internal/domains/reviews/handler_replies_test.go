@@ -0,0 +1,62 @@
+package reviews
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeReviewReplyBody(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		want      string
+		expectErr bool
+	}{
+		{name: "trimmed", raw: "  Спасибо за отзыв!  ", want: "Спасибо за отзыв!"},
+		{name: "min length in runes", raw: "Ок", want: "Ок"},
+		{name: "empty rejected", raw: "   ", expectErr: true},
+		{name: "too short rejected", raw: "!", expectErr: true},
+		{name: "max length accepted", raw: strings.Repeat("я", maxReviewReplyLength), want: strings.Repeat("я", maxReviewReplyLength)},
+		{name: "too long rejected", raw: strings.Repeat("я", maxReviewReplyLength+1), expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeReviewReplyBody(tt.raw)
+			if tt.expectErr {
+				if err == nil {
+					t.Fatalf("expected error for raw=%q", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("expected body=%q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestDecodeReviewReplyJSON(t *testing.T) {
+	if decodeReviewReplyJSON(nil) != nil {
+		t.Fatalf("expected nil reply for empty column")
+	}
+
+	raw := []byte(`{"id":"r1","author_user_id":"u1","author_name":"Владелец","body":"Спасибо!","confirmed_reports":0,` +
+		`"created_at":"2026-01-02T10:00:00+00:00","updated_at":"2026-01-02T13:30:00.5+03:00"}`)
+	reply := decodeReviewReplyJSON(raw)
+	if reply == nil {
+		t.Fatalf("expected reply to be decoded")
+	}
+	if reply["body"] != "Спасибо!" || reply["author_name"] != "Владелец" {
+		t.Fatalf("unexpected reply: %+v", reply)
+	}
+	if reply["created_at"] != "2026-01-02T10:00:00Z" || reply["updated_at"] != "2026-01-02T10:30:00Z" {
+		t.Fatalf("expected UTC timestamps, got %v / %v", reply["created_at"], reply["updated_at"])
+	}
+	if reply["edited"] != true {
+		t.Fatalf("expected reply to be marked edited")
+	}
+}
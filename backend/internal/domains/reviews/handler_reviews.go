@@ -4,12 +4,15 @@ import (
 	"context"
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
 	"backend/internal/auth"
+	"backend/internal/model"
 	"backend/internal/shared/httpx"
 	"backend/internal/shared/validation"
 
@@ -18,6 +21,7 @@ import (
 
 const (
 	maxReviewPositions    = 8
+	maxPositionNoteLength = 280
 	maxTasteTags          = 10
 	maxReviewPhotos       = 8
 	defaultReviewPageSize = 20
@@ -129,22 +133,22 @@ func (h *Handler) ListCafeReviews(c *gin.Context) {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
 		return
 	}
-	offset, err := parseReviewCursorOffset(c.Query("cursor"), sortBy)
+	positionFilter := normalizeReviewPositionFilter(c.Query("position"))
+	cursor, err := parseReviewCursor(c.Query("cursor"), sortBy, positionFilter)
 	if err != nil {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
 		return
 	}
-	positionFilter := normalizeReviewPositionFilter(c.Query("position"))
 
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
 	defer cancel()
 
-	reviewsList, positionOptions, hasMore, nextOffset, err := h.service.ListCafeReviews(
+	page, err := h.service.ListCafeReviewsPage(
 		ctx,
 		cafeID,
 		sortBy,
 		positionFilter,
-		offset,
+		cursor,
 		limit,
 	)
 	if err != nil {
@@ -152,8 +156,12 @@ func (h *Handler) ListCafeReviews(c *gin.Context) {
 		return
 	}
 	nextCursor := ""
-	if hasMore {
-		nextCursor = encodeReviewCursor(nextOffset, sortBy)
+	if page.HasMore && page.Last != nil {
+		nextCursor, err = encodeReviewKeysetCursor(*page.Last, sortBy, positionFilter)
+		if err != nil {
+			h.respondDomainError(c, err)
+			return
+		}
 	}
 
 	response := map[string]interface{}{
@@ -162,10 +170,10 @@ func (h *Handler) ListCafeReviews(c *gin.Context) {
 		"limit":            limit,
 		"cursor":           strings.TrimSpace(c.Query("cursor")),
 		"position":         positionFilter,
-		"has_more":         hasMore,
+		"has_more":         page.HasMore,
 		"next_cursor":      nextCursor,
-		"position_options": positionOptions,
-		"reviews":          reviewsList,
+		"position_options": page.PositionOptions,
+		"reviews":          page.Reviews,
 	}
 	h.service.appendVersionMetadata(response)
 	c.JSON(http.StatusOK, response)
@@ -226,6 +234,8 @@ func parseReviewCursorOffset(raw string, sortBy string) (int, error) {
 	return cursor.Offset, nil
 }
 
+// encodeReviewCursor builds a legacy offset cursor. New pages are served with
+// signed keyset cursors, see encodeReviewKeysetCursor.
 func encodeReviewCursor(offset int, sortBy string) string {
 	if offset < 0 {
 		offset = 0
@@ -262,6 +272,9 @@ func normalizeAndValidateCreateRequest(req PublishReviewRequest) (PublishReviewR
 	if len(normalized.Positions) > maxReviewPositions {
 		return normalized, errInvalid("positions не может быть больше 8.")
 	}
+	if err := validateReviewPositionScoresInput(normalized.Positions); err != nil {
+		return normalized, err
+	}
 	normalized.DrinkID = normalized.Positions[0].DrinkID
 	normalized.Drink = normalized.Positions[0].Drink
 	if utfRuneLen(normalized.Summary) < minReviewSummaryLength {
@@ -273,6 +286,9 @@ func normalizeAndValidateCreateRequest(req PublishReviewRequest) (PublishReviewR
 	if len(normalized.Photos) > maxReviewPhotos {
 		return normalized, errInvalid("photos не может быть больше 8.")
 	}
+	if err := validateReviewAspectsInput(normalized.Aspects); err != nil {
+		return normalized, err
+	}
 	for _, photoURL := range normalized.Photos {
 		if !validPhotoURL(photoURL) {
 			return normalized, errInvalid("Каждое значение в photos должно быть корректным URL.")
@@ -308,6 +324,9 @@ func normalizeAndValidateUpdateRequest(req UpdateReviewRequest) (UpdateReviewReq
 		if len(normalized) > maxReviewPositions {
 			return req, errInvalid("positions не может быть больше 8.")
 		}
+		if err := validateReviewPositionScoresInput(normalized); err != nil {
+			return req, err
+		}
 		req.Positions = &normalized
 	}
 	if req.Summary != nil {
@@ -339,6 +358,14 @@ func normalizeAndValidateUpdateRequest(req UpdateReviewRequest) (UpdateReviewReq
 		}
 		req.Photos = &normalized
 	}
+	if req.Aspects != nil {
+		hasAnyField = true
+		normalized := normalizeReviewAspects(*req.Aspects)
+		if err := validateReviewAspectsInput(normalized); err != nil {
+			return req, err
+		}
+		req.Aspects = &normalized
+	}
 	if !hasAnyField {
 		return req, errInvalid("Нужно передать хотя бы одно поле для обновления.")
 	}
@@ -365,6 +392,35 @@ func normalizeAndValidateUpdateRequest(req UpdateReviewRequest) (UpdateReviewReq
 	return req, nil
 }
 
+func validateReviewPositionScoresInput(positions []ReviewPositionDTO) error {
+	for _, item := range positions {
+		if item.Score != nil && (*item.Score < 1 || *item.Score > 5) {
+			return errInvalid("Оценка напитка в positions должна быть в диапазоне от 1 до 5.")
+		}
+		if utfRuneLen(item.Note) > maxPositionNoteLength {
+			return errInvalid("Заметка к напитку не может быть длиннее 280 символов.")
+		}
+	}
+	return nil
+}
+
+func validateReviewAspectsInput(values map[string]int) error {
+	aspects := make([]string, 0, len(values))
+	for aspect := range values {
+		aspects = append(aspects, aspect)
+	}
+	sort.Strings(aspects)
+	for _, aspect := range aspects {
+		if !model.IsReviewAspect(aspect) {
+			return errInvalid(fmt.Sprintf("Неизвестный аспект %q. Допустимые: %s.", aspect, strings.Join(model.ReviewAspects, ", ")))
+		}
+		if score := values[aspect]; score < 1 || score > 5 {
+			return errInvalid("Оценки в aspects должны быть в диапазоне от 1 до 5.")
+		}
+	}
+	return nil
+}
+
 func valueOrEmpty(value *string) string {
 	if value == nil {
 		return ""
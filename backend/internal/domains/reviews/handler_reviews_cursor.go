@@ -0,0 +1,114 @@
+package reviews
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/shared/pagination"
+)
+
+const reviewCursorVersion = 1
+
+// reviewKeyset is the sort key of the last review on a page. Which fields
+// matter depends on the sort mode, see reviewKeysetClause.
+type reviewKeyset struct {
+	CreatedAt    time.Time
+	ID           string
+	HelpfulScore float64
+	HelpfulVotes int64
+	Verified     bool
+}
+
+// reviewListCursor is either a keyset position (signed cursors) or a plain
+// offset (legacy unsigned cursors, still accepted for older clients).
+type reviewListCursor struct {
+	Offset int
+	After  *reviewKeyset
+}
+
+type reviewCursorPayload struct {
+	Version      int     `json:"v"`
+	Sort         string  `json:"s"`
+	Position     string  `json:"p,omitempty"`
+	CreatedAt    string  `json:"t"`
+	ID           string  `json:"id"`
+	HelpfulScore float64 `json:"hs,omitempty"`
+	HelpfulVotes int64   `json:"hv,omitempty"`
+	Verified     bool    `json:"vf,omitempty"`
+}
+
+func parseReviewCursor(raw string, sortBy string, positionFilter string) (reviewListCursor, error) {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return reviewListCursor{}, nil
+	}
+	if !pagination.IsSigned(value) {
+		offset, err := parseReviewCursorOffset(value, sortBy)
+		if err != nil {
+			return reviewListCursor{}, err
+		}
+		return reviewListCursor{Offset: offset}, nil
+	}
+
+	var payload reviewCursorPayload
+	if err := pagination.Default().Decode(value, &payload); err != nil {
+		if errors.Is(err, pagination.ErrCursorSignature) {
+			return reviewListCursor{}, errInvalid("cursor устарел или повреждён, загрузите список заново.")
+		}
+		return reviewListCursor{}, errInvalid("cursor имеет некорректный формат.")
+	}
+	if payload.Version != reviewCursorVersion {
+		return reviewListCursor{}, errInvalid("cursor устарел или повреждён, загрузите список заново.")
+	}
+	if payload.Sort != sortBy {
+		return reviewListCursor{}, errInvalid("cursor не соответствует выбранной сортировке.")
+	}
+	if payload.Position != positionFilter {
+		return reviewListCursor{}, errInvalid("cursor не соответствует выбранной позиции.")
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, payload.CreatedAt)
+	if err != nil || strings.TrimSpace(payload.ID) == "" {
+		return reviewListCursor{}, errInvalid("cursor имеет некорректный формат.")
+	}
+	return reviewListCursor{
+		After: &reviewKeyset{
+			CreatedAt:    createdAt,
+			ID:           payload.ID,
+			HelpfulScore: payload.HelpfulScore,
+			HelpfulVotes: payload.HelpfulVotes,
+			Verified:     payload.Verified,
+		},
+	}, nil
+}
+
+func encodeReviewKeysetCursor(keyset reviewKeyset, sortBy string, positionFilter string) (string, error) {
+	payload := reviewCursorPayload{
+		Version:   reviewCursorVersion,
+		Sort:      sortBy,
+		Position:  positionFilter,
+		CreatedAt: keyset.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ID:        keyset.ID,
+	}
+	if sortBy == "helpful" || sortBy == "verified" {
+		payload.HelpfulScore = keyset.HelpfulScore
+		payload.HelpfulVotes = keyset.HelpfulVotes
+	}
+	if sortBy == "verified" {
+		payload.Verified = keyset.Verified
+	}
+	return pagination.Default().Encode(payload)
+}
+
+// args returns the bind values for reviewKeysetClause[sortBy], which starts
+// numbering at $5 right after the base list query parameters.
+func (k reviewKeyset) args(sortBy string) []interface{} {
+	switch sortBy {
+	case "helpful":
+		return []interface{}{k.HelpfulScore, k.HelpfulVotes, k.CreatedAt, k.ID}
+	case "verified":
+		return []interface{}{k.Verified, k.HelpfulScore, k.HelpfulVotes, k.CreatedAt, k.ID}
+	default:
+		return []interface{}{k.CreatedAt, k.ID}
+	}
+}
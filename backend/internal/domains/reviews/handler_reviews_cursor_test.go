@@ -0,0 +1,78 @@
+package reviews
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReviewKeysetCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2025, 3, 14, 9, 26, 53, 589793000, time.UTC)
+	keyset := reviewKeyset{
+		CreatedAt:    createdAt,
+		ID:           "7f0c1d8e-2a4b-4c6d-8e9f-0a1b2c3d4e5f",
+		HelpfulScore: 2.375,
+		HelpfulVotes: 3,
+		Verified:     true,
+	}
+
+	for _, sortBy := range []string{"new", "helpful", "verified"} {
+		token, err := encodeReviewKeysetCursor(keyset, sortBy, "латте")
+		if err != nil {
+			t.Fatalf("%s: unexpected encode error: %v", sortBy, err)
+		}
+		cursor, err := parseReviewCursor(token, sortBy, "латте")
+		if err != nil {
+			t.Fatalf("%s: unexpected parse error: %v", sortBy, err)
+		}
+		if cursor.After == nil || cursor.Offset != 0 {
+			t.Fatalf("%s: expected keyset cursor, got %+v", sortBy, cursor)
+		}
+		if !cursor.After.CreatedAt.Equal(createdAt) || cursor.After.ID != keyset.ID {
+			t.Fatalf("%s: unexpected keyset %+v", sortBy, cursor.After)
+		}
+		if got := len(cursor.After.args(sortBy)); got != strings.Count(reviewKeysetClause[sortBy], "$") {
+			t.Fatalf("%s: keyset args=%d do not match clause placeholders", sortBy, got)
+		}
+	}
+
+	token, _ := encodeReviewKeysetCursor(keyset, "verified", "")
+	cursor, _ := parseReviewCursor(token, "verified", "")
+	if cursor.After.HelpfulScore != 2.375 || cursor.After.HelpfulVotes != 3 || !cursor.After.Verified {
+		t.Fatalf("verified keyset lost sort keys: %+v", cursor.After)
+	}
+}
+
+func TestParseReviewCursorAcceptsLegacyOffset(t *testing.T) {
+	cursor, err := parseReviewCursor(encodeReviewCursor(40, "new"), "new", "")
+	if err != nil {
+		t.Fatalf("unexpected parse error: %v", err)
+	}
+	if cursor.After != nil || cursor.Offset != 40 {
+		t.Fatalf("expected offset cursor, got %+v", cursor)
+	}
+}
+
+func TestParseReviewCursorRejectsTampering(t *testing.T) {
+	token, err := encodeReviewKeysetCursor(reviewKeyset{
+		CreatedAt: time.Now(),
+		ID:        "7f0c1d8e-2a4b-4c6d-8e9f-0a1b2c3d4e5f",
+	}, "new", "")
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	body, signature, _ := strings.Cut(token, ".")
+	forged := body[:len(body)-2] + "AA." + signature
+	if _, err := parseReviewCursor(forged, "new", ""); err == nil {
+		t.Fatalf("expected forged cursor to be rejected")
+	}
+
+	_, err = parseReviewCursor(token, "helpful", "")
+	if err == nil || !strings.Contains(err.Error(), "cursor не соответствует выбранной сортировке") {
+		t.Fatalf("expected sort mismatch error, got %v", err)
+	}
+	_, err = parseReviewCursor(token, "new", "раф")
+	if err == nil || !strings.Contains(err.Error(), "cursor не соответствует выбранной позиции") {
+		t.Fatalf("expected position mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,31 @@
+package reviews
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/internal/shared/httpx"
+	"backend/internal/shared/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) ListRevisions(c *gin.Context) {
+	reviewID := strings.TrimSpace(c.Param("id"))
+	if !validation.IsValidUUID(reviewID) {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректный id отзыва.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
+	defer cancel()
+
+	response, err := h.service.ListReviewRevisions(ctx, reviewID)
+	if err != nil {
+		h.respondDomainError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
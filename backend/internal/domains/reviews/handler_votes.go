@@ -14,6 +14,29 @@ import (
 )
 
 func (h *Handler) AddHelpful(c *gin.Context) {
+	h.writeHelpfulVote(c, h.service.AddHelpfulVote)
+}
+
+func (h *Handler) AddNotHelpful(c *gin.Context) {
+	h.writeHelpfulVote(c, h.service.AddNotHelpfulVote)
+}
+
+func (h *Handler) RetractHelpful(c *gin.Context) {
+	h.writeHelpfulVote(c, func(ctx context.Context, userID, reviewID, idempotencyKey string) (idempotentResult, error) {
+		return h.service.RetractHelpfulVote(ctx, userID, reviewID, idempotencyKey, helpfulVoteKindHelpful)
+	})
+}
+
+func (h *Handler) RetractNotHelpful(c *gin.Context) {
+	h.writeHelpfulVote(c, func(ctx context.Context, userID, reviewID, idempotencyKey string) (idempotentResult, error) {
+		return h.service.RetractHelpfulVote(ctx, userID, reviewID, idempotencyKey, helpfulVoteKindNotHelpful)
+	})
+}
+
+func (h *Handler) writeHelpfulVote(
+	c *gin.Context,
+	write func(ctx context.Context, userID, reviewID, idempotencyKey string) (idempotentResult, error),
+) {
 	userID, ok := auth.UserIDFromContext(c)
 	if !ok || strings.TrimSpace(userID) == "" {
 		httpx.RespondError(c, http.StatusUnauthorized, "unauthorized", "Необходимо войти в аккаунт.", nil)
@@ -35,7 +58,7 @@ func (h *Handler) AddHelpful(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(c.Request.Context(), 8*time.Second)
 	defer cancel()
 
-	result, err := h.service.AddHelpfulVote(ctx, userID, reviewID, idempotencyKey)
+	result, err := write(ctx, userID, reviewID, idempotencyKey)
 	if err != nil {
 		h.respondDomainError(c, err)
 		return
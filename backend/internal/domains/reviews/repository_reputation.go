@@ -12,6 +12,15 @@ const sqlInsertReputationEvent = `insert into reputation_events (user_id, event_
  values ($1::uuid, $2, $3, $4, $5, $6::jsonb)
  on conflict (user_id, event_type, source_type, source_id) do nothing`
 
+// sqlInsertReputationReversal negates an earlier event and backdates the
+// reversal to it, so daily caps and decay treat both as the same day.
+// Without the original event nothing is inserted.
+const sqlInsertReputationReversal = `insert into reputation_events (user_id, event_type, points, source_type, source_id, metadata, created_at)
+select user_id, $2, -points, source_type, source_id, jsonb_build_object('reverses_event_id', id), created_at
+  from reputation_events
+ where user_id = $1::uuid and event_type = $3 and source_type = $4 and source_id = $5
+ on conflict (user_id, event_type, source_type, source_id) do nothing`
+
 type reputationEventExecer interface {
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
 }
@@ -59,6 +68,23 @@ func (r *Repository) AddReputationEventTx(
 	)
 }
 
+// ReverseReputationEvent records reversalType against the originalType
+// event of the same source.
+func (r *Repository) ReverseReputationEvent(
+	ctx context.Context,
+	userID string,
+	reversalType string,
+	originalType string,
+	sourceType string,
+	sourceID string,
+) error {
+	if userID == "" {
+		return nil
+	}
+	_, err := r.pool.Exec(ctx, sqlInsertReputationReversal, userID, reversalType, originalType, sourceType, sourceID)
+	return err
+}
+
 func addReputationEventQuery(
 	ctx context.Context,
 	q reputationEventExecer,
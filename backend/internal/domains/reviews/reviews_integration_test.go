@@ -108,6 +108,7 @@ func newIntegrationRouterWithMedia(pool *pgxpool.Pool, s3 *media.Service, mediaC
 	router.POST("/api/reviews/:id/visit/verify", handler.VerifyVisit)
 	router.GET("/api/cafes/:id/reviews", handler.ListCafeReviews)
 	router.GET("/api/cafes/:id/rating", handler.GetCafeRating)
+	router.GET("/api/drinks/:id/leaderboard", handler.GetDrinkLeaderboard)
 	moderationReviews := router.Group("/api/reviews")
 	moderationReviews.Use(testRequireRoles("admin", "moderator"))
 	moderationReviews.DELETE("/:id", handler.DeleteReview)
@@ -1151,6 +1152,44 @@ func TestAdminMapUnknownDrinkFormatEndpoint(t *testing.T) {
 	}
 }
 
+func TestDrinkLeaderboardUnknownCityNotFound(t *testing.T) {
+	pool := integrationTestPool(t)
+	router := newIntegrationRouter(pool)
+
+	drinkID := fmt.Sprintf("it-leaderboard-%d", time.Now().UnixNano())
+	mustExec(
+		t,
+		pool,
+		`insert into drinks (id, name, aliases, description, category, popularity_rank, is_active, created_at, updated_at)
+		 values ($1, $2, '{}'::text[], '', 'test', 100, true, now(), now())`,
+		drinkID,
+		"it leaderboard drink "+drinkID,
+	)
+	t.Cleanup(func() {
+		mustExec(t, pool, `delete from drinks where id = $1`, drinkID)
+	})
+
+	rec := performJSONRequest(
+		t,
+		router,
+		http.MethodGet,
+		"/api/drinks/"+drinkID+"/leaderboard?city=no-such-city",
+		nil,
+		nil,
+	)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for unknown city, got %d, body=%s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(rec.Body.String(), "Город не найден.") {
+		t.Fatalf("expected city not found message, got %s", rec.Body.String())
+	}
+
+	rec = performJSONRequest(t, router, http.MethodGet, "/api/drinks/"+drinkID+"/leaderboard", nil, nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 without city, got %d, body=%s", rec.Code, rec.Body.String())
+	}
+}
+
 func TestDeleteReviewByModeratorOnly(t *testing.T) {
 	pool := integrationTestPool(t)
 	router := newIntegrationRouter(pool)
@@ -16,12 +16,12 @@ const (
 
 	sqlInsertAbuseReport = `insert into abuse_reports (review_id, reporter_user_id, reason, details)
  values ($1::uuid, $2::uuid, $3, $4)
- on conflict (review_id, reporter_user_id) do nothing
+ on conflict (review_id, reporter_user_id) where reply_id is null and comment_id is null do nothing
  returning id::text, status`
 
 	sqlSelectAbuseReportByReviewAndReporter = `select id::text, status
    from abuse_reports
-  where review_id = $1::uuid and reporter_user_id = $2::uuid`
+  where review_id = $1::uuid and reporter_user_id = $2::uuid and reply_id is null and comment_id is null`
 
 	sqlConfirmAbuseReport = `update abuse_reports
     set status = 'confirmed',
@@ -29,21 +29,25 @@ const (
         confirmed_at = coalesce(confirmed_at, now()),
         updated_at = now()
   where id = $1::uuid and status <> 'confirmed'
-  returning review_id::text, status`
+  returning review_id::text, reply_id::text, comment_id::text, status`
 
-	sqlSelectAbuseReportByID = `select review_id::text, status
+	sqlSelectAbuseReportByID = `select review_id::text, reply_id::text, comment_id::text, status
    from abuse_reports
   where id = $1::uuid`
 
 	sqlSelectReviewCafeByReviewID = `select cafe_id::text from reviews where id = $1::uuid`
 )
 
-func (s *Service) ReportAbuse(ctx context.Context, userID string, reviewID string, req ReportAbuseRequest) (map[string]interface{}, error) {
+func normalizeAbuseReport(req ReportAbuseRequest) (string, string) {
 	reason := strings.TrimSpace(req.Reason)
-	details := strings.TrimSpace(req.Details)
 	if reason == "" {
 		reason = "other"
 	}
+	return reason, strings.TrimSpace(req.Details)
+}
+
+func (s *Service) ReportAbuse(ctx context.Context, userID string, reviewID string, req ReportAbuseRequest) (map[string]interface{}, error) {
+	reason, details := normalizeAbuseReport(req)
 
 	var reviewAuthorID string
 	err := s.repository.Pool().QueryRow(
@@ -97,9 +101,11 @@ func (s *Service) ConfirmAbuseReport(ctx context.Context, moderatorUserID string
 	}()
 
 	var (
-		reviewID string
-		status   string
-		cafeID   string
+		reviewID  string
+		replyID   *string
+		commentID *string
+		status    string
+		cafeID    string
 	)
 
 	err = tx.QueryRow(
@@ -107,7 +113,7 @@ func (s *Service) ConfirmAbuseReport(ctx context.Context, moderatorUserID string
 		sqlConfirmAbuseReport,
 		reportID,
 		moderatorUserID,
-	).Scan(&reviewID, &status)
+	).Scan(&reviewID, &replyID, &commentID, &status)
 
 	newlyConfirmed := true
 	if errors.Is(err, pgx.ErrNoRows) {
@@ -116,7 +122,7 @@ func (s *Service) ConfirmAbuseReport(ctx context.Context, moderatorUserID string
 			ctx,
 			sqlSelectAbuseReportByID,
 			reportID,
-		).Scan(&reviewID, &status)
+		).Scan(&reviewID, &replyID, &commentID, &status)
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrNotFound
 		}
@@ -141,6 +147,12 @@ func (s *Service) ConfirmAbuseReport(ctx context.Context, moderatorUserID string
 			"review_id":       reviewID,
 			"cafe_id":         cafeID,
 		}
+		if replyID != nil {
+			payload["reply_id"] = *replyID
+		}
+		if commentID != nil {
+			payload["comment_id"] = *commentID
+		}
 		dedupeKey := fmt.Sprintf("abuse-confirmed:%s", reportID)
 		if err := s.repository.EnqueueEventTx(ctx, tx, EventAbuseConfirmed, cafeID, dedupeKey, payload); err != nil {
 			return nil, err
@@ -11,6 +11,9 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/model"
+	"backend/internal/shared/geo"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -27,7 +30,8 @@ const (
 const (
 	sqlSelectCafeCoordinates = `select lat::float8, lng::float8
 from cafes
-where id = $1::uuid`
+where id = $1::uuid
+  and status <> 'deleted'`
 
 	sqlSelectLatestCheckInByUser = `select
 	id::text,
@@ -161,12 +165,19 @@ func (s *Service) StartCheckIn(
 	scope := IdempotencyScopeCheckInStart + ":" + userID
 
 	return s.repository.RunIdempotent(ctx, scope, idempotencyKey, hash, func(tx pgx.Tx) (int, map[string]interface{}, error) {
+		cafeStatus, err := s.lookupCafeStatusTx(ctx, tx, cafeID)
+		if err != nil {
+			return 0, nil, err
+		}
+		if cafeStatus != model.CafeStatusActive {
+			return 0, nil, ErrCafeClosed
+		}
 		cafeLat, cafeLng, err := s.lookupCafeCoordinatesTx(ctx, tx, cafeID)
 		if err != nil {
 			return 0, nil, err
 		}
 
-		distanceMeters := haversineMeters(req.Lat, req.Lng, cafeLat, cafeLng)
+		distanceMeters := geo.HaversineM(req.Lat, req.Lng, cafeLat, cafeLng)
 		if !isAdminBypass && distanceMeters > checkInRadiusMeters {
 			return 0, nil, ErrCheckInTooFar
 		}
@@ -234,6 +245,17 @@ func (s *Service) StartCheckIn(
 	})
 }
 
+func (s *Service) lookupCafeStatusTx(ctx context.Context, tx pgx.Tx, cafeID string) (string, error) {
+	var status string
+	if err := tx.QueryRow(ctx, sqlSelectCafeStatus, cafeID).Scan(&status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	return status, nil
+}
+
 func (s *Service) lookupCafeCoordinatesTx(ctx context.Context, tx pgx.Tx, cafeID string) (float64, float64, error) {
 	var lat, lng float64
 	if err := tx.QueryRow(ctx, sqlSelectCafeCoordinates, cafeID).Scan(&lat, &lng); err != nil {
@@ -284,7 +306,7 @@ func isImpossibleTravel(latA, lngA, latB, lngB float64, from, to time.Time) bool
 	if elapsedSec <= 0 {
 		return false
 	}
-	distanceMeters := haversineMeters(latA, lngA, latB, lngB)
+	distanceMeters := geo.HaversineM(latA, lngA, latB, lngB)
 	speedKmh := (distanceMeters / elapsedSec) * 3.6
 	// If implied speed is unrealistically high, treat as fake location hop.
 	return speedKmh > checkInImpossibleSpeedKmh
@@ -319,17 +341,6 @@ func normalizeIPPrefix(value string) string {
 	return prefix.Masked().String()
 }
 
-func haversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
-	const earthRadius = 6371000.0
-	rad := func(v float64) float64 { return v * math.Pi / 180.0 }
-	dLat := rad(lat2 - lat1)
-	dLng := rad(lng2 - lng1)
-	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
-		math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
-	c := 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
-	return earthRadius * c
-}
-
 func isAdminRole(role string) bool {
 	return strings.EqualFold(strings.TrimSpace(role), "admin")
 }
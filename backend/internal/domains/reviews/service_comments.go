@@ -0,0 +1,545 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	minReviewCommentLength = 2
+	maxReviewCommentLength = 2000
+
+	defaultReviewCommentsLimit = 20
+	maxReviewCommentsLimit     = 50
+	// maxThreadReplies caps the replies embedded per thread; replies_count
+	// still reports the full number.
+	maxThreadReplies = 50
+
+	// The author may fix a typo shortly after posting and take a comment back
+	// within a day. Moderators remove comments at any time.
+	reviewCommentEditWindow   = 15 * time.Minute
+	reviewCommentDeleteWindow = 24 * time.Hour
+
+	reviewCommentBadgeAuthor    = "author"
+	reviewCommentBadgeOwner     = "owner"
+	reviewCommentBadgeModerator = "moderator"
+
+	sqlSelectReviewForComment = `select r.cafe_id::text, r.user_id::text
+   from reviews r
+  where r.id = $1::uuid and r.status = 'published'
+  for share of r`
+
+	sqlSelectParentComment = `select coalesce(root_id, id)::text, author_user_id::text
+   from review_comments
+  where id = $1::uuid and review_id = $2::uuid and status = 'published'`
+
+	sqlInsertReviewComment = `insert into review_comments (review_id, parent_id, root_id, author_user_id, body)
+ values ($1::uuid, $2::uuid, $3::uuid, $4::uuid, $5)
+ returning id::text`
+
+	// sqlSelectCommentForChange locks the comment; comments of removed
+	// reviews are read-only.
+	sqlSelectCommentForChange = `select c.author_user_id::text, c.created_at
+   from review_comments c
+   join reviews r on r.id = c.review_id and r.status = 'published'
+  where c.id = $1::uuid and c.status = 'published'
+  for update of c`
+
+	sqlUpdateReviewComment = `update review_comments
+    set body = $2,
+        edited_at = now(),
+        updated_at = now()
+  where id = $1::uuid`
+
+	sqlRemoveReviewComment = `update review_comments
+    set status = 'removed',
+        removed_by = $2::uuid,
+        updated_at = now()
+  where id = $1::uuid and status = 'published'`
+
+	sqlSelectCommentForAbuse = `select c.review_id::text, c.author_user_id::text
+   from review_comments c
+   join reviews r on r.id = c.review_id and r.status = 'published'
+  where c.id = $1::uuid and c.status = 'published'`
+
+	sqlInsertCommentAbuseReport = `insert into abuse_reports (review_id, comment_id, reporter_user_id, reason, details)
+ values ($1::uuid, $2::uuid, $3::uuid, $4, $5)
+ on conflict (comment_id, reporter_user_id) where comment_id is not null do nothing
+ returning id::text, status`
+
+	sqlSelectAbuseReportByCommentAndReporter = `select id::text, status
+   from abuse_reports
+  where comment_id = $1::uuid and reporter_user_id = $2::uuid`
+
+	sqlSelectReviewPublished = `select exists(
+	select 1 from reviews where id = $1::uuid and status = 'published'
+)`
+
+	// sqlReviewCommentColumns is shared by every comment read; the badge
+	// flags are resolved against the commented review and its cafe.
+	sqlReviewCommentColumns = `c.id::text,
+	c.review_id::text,
+	coalesce(c.parent_id::text, ''),
+	coalesce(c.root_id::text, ''),
+	c.author_user_id::text,
+	coalesce(nullif(trim(u.display_name), ''), 'Участник'),
+	c.body,
+	c.status,
+	c.created_at,
+	c.edited_at,
+	c.author_user_id = r.user_id,
+	exists(
+		select 1
+		  from cafe_owners co
+		 where co.cafe_id = r.cafe_id and co.user_id = c.author_user_id
+	),
+	coalesce(u.role in ('admin', 'moderator'), false)`
+
+	sqlReviewCommentJoins = `
+join reviews r on r.id = c.review_id
+join users u on u.id = c.author_user_id`
+
+	sqlSelectReviewComment = `select ` + sqlReviewCommentColumns + `, 0
+from review_comments c` + sqlReviewCommentJoins + `
+where c.id = $1::uuid`
+
+	// Removed threads stay listed while they have live replies.
+	sqlListReviewCommentThreads = `select ` + sqlReviewCommentColumns + `, 0
+from review_comments c` + sqlReviewCommentJoins + `
+where c.review_id = $1::uuid
+  and c.parent_id is null
+  and (
+	c.status = 'published'
+	or exists (
+		select 1
+		  from review_comments rc
+		 where rc.root_id = c.id and rc.status = 'published'
+	)
+  )
+  and ($2::timestamptz is null or (c.created_at, c.id) > ($2::timestamptz, $3::uuid))
+order by c.created_at asc, c.id asc
+limit $4`
+
+	sqlListReviewCommentReplies = `select ` + sqlReviewCommentColumns + `, c.thread_replies
+from (
+	select
+		rc.*,
+		row_number() over (partition by rc.root_id order by rc.created_at asc, rc.id asc) as thread_position,
+		count(*) over (partition by rc.root_id)::int as thread_replies
+	  from review_comments rc
+	 where rc.root_id = any($1::uuid[])
+) c` + sqlReviewCommentJoins + `
+where c.thread_position <= $2
+order by c.created_at asc, c.id asc`
+)
+
+// reviewCommentKeyset is the position of the last thread on a page.
+type reviewCommentKeyset struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type reviewCommentPage struct {
+	Comments []map[string]interface{}
+	HasMore  bool
+	Last     *reviewCommentKeyset
+}
+
+type reviewCommentState struct {
+	ID             string
+	ReviewID       string
+	ParentID       string
+	RootID         string
+	AuthorUserID   string
+	AuthorName     string
+	Body           string
+	Status         string
+	CreatedAt      time.Time
+	EditedAt       *time.Time
+	IsReviewAuthor bool
+	IsCafeOwner    bool
+	IsModerator    bool
+	ThreadReplies  int
+}
+
+func scanReviewComment(row pgx.Row) (reviewCommentState, error) {
+	var comment reviewCommentState
+	err := row.Scan(
+		&comment.ID,
+		&comment.ReviewID,
+		&comment.ParentID,
+		&comment.RootID,
+		&comment.AuthorUserID,
+		&comment.AuthorName,
+		&comment.Body,
+		&comment.Status,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+		&comment.IsReviewAuthor,
+		&comment.IsCafeOwner,
+		&comment.IsModerator,
+		&comment.ThreadReplies,
+	)
+	return comment, err
+}
+
+func reviewCommentBadges(isReviewAuthor, isCafeOwner, isModerator bool) []string {
+	badges := make([]string, 0, 3)
+	if isReviewAuthor {
+		badges = append(badges, reviewCommentBadgeAuthor)
+	}
+	if isCafeOwner {
+		badges = append(badges, reviewCommentBadgeOwner)
+	}
+	if isModerator {
+		badges = append(badges, reviewCommentBadgeModerator)
+	}
+	return badges
+}
+
+func reviewCommentEditable(createdAt, now time.Time) bool {
+	return now.Sub(createdAt) <= reviewCommentEditWindow
+}
+
+func reviewCommentDeletable(createdAt, now time.Time) bool {
+	return now.Sub(createdAt) <= reviewCommentDeleteWindow
+}
+
+// response hides the text and author of removed comments; they only keep
+// their place in the thread.
+func (c reviewCommentState) response() map[string]interface{} {
+	response := map[string]interface{}{
+		"id":         c.ID,
+		"review_id":  c.ReviewID,
+		"removed":    c.Status != "published",
+		"edited":     c.EditedAt != nil,
+		"created_at": c.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if c.ParentID != "" {
+		response["parent_id"] = c.ParentID
+	}
+	if c.Status != "published" {
+		response["body"] = ""
+		response["badges"] = []string{}
+		return response
+	}
+	response["author_user_id"] = c.AuthorUserID
+	response["author_name"] = c.AuthorName
+	response["body"] = c.Body
+	response["badges"] = reviewCommentBadges(c.IsReviewAuthor, c.IsCafeOwner, c.IsModerator)
+	response["editable_until"] = c.CreatedAt.Add(reviewCommentEditWindow).UTC().Format(time.RFC3339)
+	response["deletable_until"] = c.CreatedAt.Add(reviewCommentDeleteWindow).UTC().Format(time.RFC3339)
+	return response
+}
+
+func (s *Service) allowReviewComment(userID string) bool {
+	if s.commentLimiter == nil {
+		return true
+	}
+	return s.commentLimiter.Allow(strings.TrimSpace(userID))
+}
+
+// ListReviewComments returns a page of threads, oldest first, each with its
+// replies in posting order.
+func (s *Service) ListReviewComments(
+	ctx context.Context,
+	reviewID string,
+	after *reviewCommentKeyset,
+	limit int,
+) (reviewCommentPage, error) {
+	var published bool
+	if err := s.repository.Pool().QueryRow(ctx, sqlSelectReviewPublished, reviewID).Scan(&published); err != nil {
+		return reviewCommentPage{}, err
+	}
+	if !published {
+		return reviewCommentPage{}, ErrNotFound
+	}
+
+	threads, err := s.listCommentThreads(ctx, reviewID, after, limit+1)
+	if err != nil {
+		return reviewCommentPage{}, err
+	}
+
+	page := reviewCommentPage{Comments: make([]map[string]interface{}, 0, limit)}
+	if len(threads) > limit {
+		page.HasMore = true
+		threads = threads[:limit]
+	}
+	if len(threads) == 0 {
+		return page, nil
+	}
+
+	rootIDs := make([]string, 0, len(threads))
+	for _, thread := range threads {
+		rootIDs = append(rootIDs, thread.ID)
+	}
+	replies, err := s.listThreadReplies(ctx, rootIDs)
+	if err != nil {
+		return reviewCommentPage{}, err
+	}
+
+	for _, thread := range threads {
+		item := thread.response()
+		threadReplies := replies[thread.ID]
+		items := make([]map[string]interface{}, 0, len(threadReplies))
+		repliesCount := 0
+		for _, reply := range threadReplies {
+			items = append(items, reply.response())
+			repliesCount = reply.ThreadReplies
+		}
+		item["replies"] = items
+		item["replies_count"] = repliesCount
+		page.Comments = append(page.Comments, item)
+	}
+	last := threads[len(threads)-1]
+	page.Last = &reviewCommentKeyset{CreatedAt: last.CreatedAt, ID: last.ID}
+	return page, nil
+}
+
+func (s *Service) listCommentThreads(
+	ctx context.Context,
+	reviewID string,
+	after *reviewCommentKeyset,
+	limit int,
+) ([]reviewCommentState, error) {
+	var (
+		afterCreatedAt *time.Time
+		afterID        *string
+	)
+	if after != nil {
+		afterCreatedAt = &after.CreatedAt
+		afterID = &after.ID
+	}
+	rows, err := s.repository.Pool().Query(ctx, sqlListReviewCommentThreads, reviewID, afterCreatedAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	threads := make([]reviewCommentState, 0, limit)
+	for rows.Next() {
+		thread, err := scanReviewComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, thread)
+	}
+	return threads, rows.Err()
+}
+
+func (s *Service) listThreadReplies(ctx context.Context, rootIDs []string) (map[string][]reviewCommentState, error) {
+	rows, err := s.repository.Pool().Query(ctx, sqlListReviewCommentReplies, rootIDs, maxThreadReplies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	replies := make(map[string][]reviewCommentState, len(rootIDs))
+	for rows.Next() {
+		reply, err := scanReviewComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		replies[reply.RootID] = append(replies[reply.RootID], reply)
+	}
+	return replies, rows.Err()
+}
+
+// CreateReviewComment posts a comment on a published review, or a reply to
+// another comment when parentID is set.
+func (s *Service) CreateReviewComment(ctx context.Context, userID, reviewID, parentID, body string) (map[string]interface{}, error) {
+	if !s.allowReviewComment(userID) {
+		return nil, ErrRateLimited
+	}
+	if looksLikeSpamSummary(body) {
+		return nil, ErrSpamDetected
+	}
+
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	var cafeID, reviewAuthorID string
+	err = tx.QueryRow(ctx, sqlSelectReviewForComment, reviewID).Scan(&cafeID, &reviewAuthorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var parent, root *string
+	parentAuthorID := ""
+	if parentID != "" {
+		var rootID string
+		err = tx.QueryRow(ctx, sqlSelectParentComment, parentID, reviewID).Scan(&rootID, &parentAuthorID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		parent, root = &parentID, &rootID
+	}
+
+	var commentID string
+	if err := tx.QueryRow(ctx, sqlInsertReviewComment, reviewID, parent, root, userID, body).Scan(&commentID); err != nil {
+		return nil, err
+	}
+	comment, err := scanReviewComment(tx.QueryRow(ctx, sqlSelectReviewComment, commentID))
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"comment_id":        commentID,
+		"review_id":         reviewID,
+		"cafe_id":           cafeID,
+		"review_author_id":  reviewAuthorID,
+		"comment_author_id": userID,
+	}
+	if parentID != "" {
+		payload["parent_id"] = parentID
+		payload["parent_author_id"] = parentAuthorID
+	}
+	if err := s.repository.EnqueueEventTx(ctx, tx, EventReviewCommentCreated, cafeID, "review-comment-created:"+commentID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return comment.response(), nil
+}
+
+// UpdateReviewComment lets the author edit a comment within
+// reviewCommentEditWindow.
+func (s *Service) UpdateReviewComment(ctx context.Context, userID, commentID, body string) (map[string]interface{}, error) {
+	if looksLikeSpamSummary(body) {
+		return nil, ErrSpamDetected
+	}
+
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	authorID, createdAt, err := lockReviewCommentTx(ctx, tx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if authorID != userID {
+		return nil, ErrForbidden
+	}
+	if !reviewCommentEditable(createdAt, time.Now()) {
+		return nil, ErrCommentWindowClosed
+	}
+
+	if _, err := tx.Exec(ctx, sqlUpdateReviewComment, commentID, body); err != nil {
+		return nil, err
+	}
+	comment, err := scanReviewComment(tx.QueryRow(ctx, sqlSelectReviewComment, commentID))
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return comment.response(), nil
+}
+
+// DeleteReviewComment removes a comment. Authors may do it within
+// reviewCommentDeleteWindow, moderators at any time. Replies stay in place.
+func (s *Service) DeleteReviewComment(ctx context.Context, userID, commentID string, asModerator bool) (map[string]interface{}, error) {
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	authorID, createdAt, err := lockReviewCommentTx(ctx, tx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if !asModerator {
+		if authorID != userID {
+			return nil, ErrForbidden
+		}
+		if !reviewCommentDeletable(createdAt, time.Now()) {
+			return nil, ErrCommentWindowClosed
+		}
+	}
+
+	if _, err := tx.Exec(ctx, sqlRemoveReviewComment, commentID, userID); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"comment_id": commentID, "removed": true}, nil
+}
+
+// ReportCommentAbuse files an abuse report against a comment; it is
+// confirmed through the same moderator endpoint as review reports.
+func (s *Service) ReportCommentAbuse(ctx context.Context, userID, commentID string, req ReportAbuseRequest) (map[string]interface{}, error) {
+	reason, details := normalizeAbuseReport(req)
+
+	var reviewID, commentAuthorID string
+	err := s.repository.Pool().QueryRow(ctx, sqlSelectCommentForAbuse, commentID).Scan(&reviewID, &commentAuthorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if commentAuthorID == userID {
+		return nil, ErrForbidden
+	}
+
+	var reportID, status string
+	err = s.repository.Pool().QueryRow(
+		ctx,
+		sqlInsertCommentAbuseReport,
+		reviewID,
+		commentID,
+		userID,
+		reason,
+		details,
+	).Scan(&reportID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = s.repository.Pool().QueryRow(
+			ctx,
+			sqlSelectAbuseReportByCommentAndReporter,
+			commentID,
+			userID,
+		).Scan(&reportID, &status)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"report_id": reportID, "comment_id": commentID, "status": status}, nil
+}
+
+func lockReviewCommentTx(ctx context.Context, tx pgx.Tx, commentID string) (string, time.Time, error) {
+	var (
+		authorID  string
+		createdAt time.Time
+	)
+	err := tx.QueryRow(ctx, sqlSelectCommentForChange, commentID).Scan(&authorID, &createdAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", time.Time{}, ErrNotFound
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return authorID, createdAt, nil
+}
@@ -12,22 +12,24 @@ import (
 )
 
 type Service struct {
-	repository    *Repository
-	createLimiter *auth.RateLimiter
-	updateLimiter *auth.RateLimiter
-	mediaService  *media.Service
-	mediaCfg      config.MediaConfig
-	versioning    formulaVersioning
-	aiSummaryCfg  aiSummaryConfig
+	repository     *Repository
+	createLimiter  *auth.RateLimiter
+	updateLimiter  *auth.RateLimiter
+	commentLimiter *auth.RateLimiter
+	mediaService   *media.Service
+	mediaCfg       config.MediaConfig
+	versioning     formulaVersioning
+	aiSummaryCfg   aiSummaryConfig
 }
 
 func NewService(repository *Repository) *Service {
 	return &Service{
-		repository:    repository,
-		createLimiter: auth.NewRateLimiter(6, 10*time.Minute),
-		updateLimiter: auth.NewRateLimiter(20, 10*time.Minute),
-		versioning:    loadFormulaVersioningFromEnv(),
-		aiSummaryCfg:  loadAISummaryConfigFromEnv(),
+		repository:     repository,
+		createLimiter:  auth.NewRateLimiter(6, 10*time.Minute),
+		updateLimiter:  auth.NewRateLimiter(20, 10*time.Minute),
+		commentLimiter: auth.NewRateLimiter(20, 10*time.Minute),
+		versioning:     loadFormulaVersioningFromEnv(),
+		aiSummaryCfg:   loadAISummaryConfigFromEnv(),
 	}
 }
 
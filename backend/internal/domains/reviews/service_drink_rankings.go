@@ -0,0 +1,293 @@
+package reviews
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// drinkScoreBayesianM is smaller than ratingBayesianM: drink scores are
+	// sparse, a handful of scores should already move a drink.
+	drinkScoreBayesianM = 5.0
+
+	defaultDrinkLeaderboardLimit = 20
+	maxDrinkLeaderboardLimit     = 100
+
+	sqlListCafeDrinkScores = `with scored as (
+	select
+		coalesce(nullif(rp.drink_id, ''), rp.drink_name) as drink_key,
+		coalesce(rp.drink_id, '') as drink_id,
+		coalesce(rp.drink_name, '') as drink_name,
+		rp.score,
+		rp.note,
+		r.created_at
+	  from review_positions rp
+	  join reviews r on r.id = rp.review_id and r.status = 'published'
+	 where r.cafe_id = $1::uuid and rp.score is not null
+),
+global as (
+	select
+		coalesce(nullif(rp.drink_id, ''), rp.drink_name) as drink_key,
+		avg(rp.score)::float8 as mean
+	  from review_positions rp
+	  join reviews r on r.id = rp.review_id and r.status = 'published'
+	 where rp.score is not null
+	   and coalesce(nullif(rp.drink_id, ''), rp.drink_name) in (select drink_key from scored)
+	 group by 1
+)
+select
+	s.drink_key,
+	max(s.drink_id),
+	coalesce(max(nullif(trim(d.name), '')), max(s.drink_name)),
+	sum(s.score)::float8,
+	count(*)::int,
+	max(g.mean),
+	-- the three latest notes
+	coalesce(
+		(array_agg(s.note order by s.created_at desc) filter (where s.note <> ''))[1:3],
+		'{}'::text[]
+	)
+from scored s
+join global g on g.drink_key = s.drink_key
+left join drinks d on d.id = s.drink_id
+group by s.drink_key`
+
+	sqlCityExists = `select exists(select 1 from cities where slug = $1)`
+
+	sqlListDrinkCafeScores = `select
+	c.id::text,
+	c.name,
+	c.address,
+	sum(rp.score)::float8,
+	count(*)::int
+from review_positions rp
+join reviews r on r.id = rp.review_id and r.status = 'published'
+join cafes c on c.id = r.cafe_id and c.status in ('active', 'temporarily_closed')
+where rp.drink_id = $1
+  and rp.score is not null
+  and ($2 = '' or c.city_id = (select id from cities where slug = $2))
+group by c.id, c.name, c.address`
+)
+
+// drinkScore aggregates the position scores of one drink, smoothed towards
+// PriorMean with drinkScoreBayesianM.
+type drinkScore struct {
+	ScoresSum   float64
+	ScoresCount int
+	PriorMean   float64
+}
+
+func (d drinkScore) mean() float64 {
+	if d.ScoresCount <= 0 {
+		return 0
+	}
+	return d.ScoresSum / float64(d.ScoresCount)
+}
+
+func (d drinkScore) rating() float64 {
+	return clamp(bayesianMean(d.mean(), float64(d.ScoresCount), d.PriorMean, drinkScoreBayesianM), 1, 5)
+}
+
+// ranksAbove orders by smoothed rating, then by the number of scores.
+func (d drinkScore) ranksAbove(other drinkScore) (bool, bool) {
+	left, right := d.rating(), other.rating()
+	if !almostEqual(left, right) {
+		return left > right, true
+	}
+	if d.ScoresCount != other.ScoresCount {
+		return d.ScoresCount > other.ScoresCount, true
+	}
+	return false, false
+}
+
+type cafeDrinkRanking struct {
+	DrinkID   string
+	DrinkName string
+	Notes     []string
+	Score     drinkScore
+}
+
+type drinkLeaderboardEntry struct {
+	CafeID   string
+	CafeName string
+	Address  string
+	Score    drinkScore
+}
+
+func rankCafeDrinks(items []cafeDrinkRanking) {
+	sort.SliceStable(items, func(i, j int) bool {
+		if above, decided := items[i].Score.ranksAbove(items[j].Score); decided {
+			return above
+		}
+		return items[i].DrinkName < items[j].DrinkName
+	})
+}
+
+// rankDrinkLeaderboard smooths every cafe towards the drink's mean over all
+// listed cafes and keeps the best limit entries.
+func rankDrinkLeaderboard(entries []drinkLeaderboardEntry, limit int) []drinkLeaderboardEntry {
+	var (
+		sum   float64
+		count int
+	)
+	for _, entry := range entries {
+		sum += entry.Score.ScoresSum
+		count += entry.Score.ScoresCount
+	}
+	prior := 0.0
+	if count > 0 {
+		prior = sum / float64(count)
+	}
+	for i := range entries {
+		entries[i].Score.PriorMean = prior
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if above, decided := entries[i].Score.ranksAbove(entries[j].Score); decided {
+			return above
+		}
+		return entries[i].CafeName < entries[j].CafeName
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+func drinkScoreResponse(score drinkScore) map[string]interface{} {
+	return map[string]interface{}{
+		"rating":       roundFloat(score.rating(), 2),
+		"scores_mean":  roundFloat(score.mean(), 2),
+		"scores_count": score.ScoresCount,
+	}
+}
+
+// ListCafeDrinkRankings ranks the drinks scored in reviews of a cafe, best
+// first ("best flat white here").
+func (s *Service) ListCafeDrinkRankings(ctx context.Context, cafeID string) (map[string]interface{}, error) {
+	var status string
+	err := s.repository.Pool().QueryRow(ctx, sqlSelectCafeStatus, cafeID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.repository.Pool().Query(ctx, sqlListCafeDrinkScores, cafeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]cafeDrinkRanking, 0, 16)
+	for rows.Next() {
+		var (
+			drinkKey string
+			item     cafeDrinkRanking
+		)
+		if err := rows.Scan(
+			&drinkKey,
+			&item.DrinkID,
+			&item.DrinkName,
+			&item.Score.ScoresSum,
+			&item.Score.ScoresCount,
+			&item.Score.PriorMean,
+			&item.Notes,
+		); err != nil {
+			return nil, err
+		}
+		item.DrinkID = normalizeDrinkToken(item.DrinkID)
+		if strings.TrimSpace(item.DrinkName) == "" {
+			item.DrinkName = drinkKey
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rankCafeDrinks(items)
+
+	drinks := make([]map[string]interface{}, 0, len(items))
+	for idx, item := range items {
+		entry := drinkScoreResponse(item.Score)
+		entry["rank"] = idx + 1
+		entry["drink_id"] = item.DrinkID
+		entry["drink_name"] = item.DrinkName
+		entry["notes"] = item.Notes
+		drinks = append(drinks, entry)
+	}
+	return map[string]interface{}{
+		"cafe_id": cafeID,
+		"drinks":  drinks,
+	}, nil
+}
+
+// DrinkLeaderboard ranks cafes by the scores of one catalog drink, within a
+// city when citySlug is set.
+func (s *Service) DrinkLeaderboard(ctx context.Context, drinkID, citySlug string, limit int) (map[string]interface{}, error) {
+	var drinkName string
+	err := s.repository.Pool().QueryRow(ctx, sqlSelectDrinkByID, drinkID).Scan(&drinkID, &drinkName)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if citySlug != "" {
+		var cityExists bool
+		if err := s.repository.Pool().QueryRow(ctx, sqlCityExists, citySlug).Scan(&cityExists); err != nil {
+			return nil, err
+		}
+		if !cityExists {
+			return nil, ErrCityNotFound
+		}
+	}
+
+	rows, err := s.repository.Pool().Query(ctx, sqlListDrinkCafeScores, drinkID, citySlug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]drinkLeaderboardEntry, 0, 32)
+	for rows.Next() {
+		var entry drinkLeaderboardEntry
+		if err := rows.Scan(
+			&entry.CafeID,
+			&entry.CafeName,
+			&entry.Address,
+			&entry.Score.ScoresSum,
+			&entry.Score.ScoresCount,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	ranked := rankDrinkLeaderboard(entries, limit)
+	items := make([]map[string]interface{}, 0, len(ranked))
+	for idx, entry := range ranked {
+		item := drinkScoreResponse(entry.Score)
+		item["rank"] = idx + 1
+		item["cafe_id"] = entry.CafeID
+		item["cafe_name"] = entry.CafeName
+		item["address"] = entry.Address
+		items = append(items, item)
+	}
+	return map[string]interface{}{
+		"drink": map[string]interface{}{
+			"id":   normalizeDrinkToken(drinkID),
+			"name": normalizeDrinkText(drinkName),
+		},
+		"city":  citySlug,
+		"items": items,
+	}, nil
+}
@@ -0,0 +1,63 @@
+package reviews
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRankDrinkLeaderboard(t *testing.T) {
+	entries := []drinkLeaderboardEntry{
+		{CafeID: "single-five", CafeName: "B", Score: drinkScore{ScoresSum: 5, ScoresCount: 1}},
+		{CafeID: "many-fours", CafeName: "A", Score: drinkScore{ScoresSum: 4.8 * 20, ScoresCount: 20}},
+		{CafeID: "weak", CafeName: "C", Score: drinkScore{ScoresSum: 6, ScoresCount: 3}},
+	}
+
+	ranked := rankDrinkLeaderboard(entries, 2)
+	if len(ranked) != 2 {
+		t.Fatalf("expected limit to apply, got %d entries", len(ranked))
+	}
+	if ranked[0].CafeID != "many-fours" || ranked[1].CafeID != "single-five" {
+		t.Fatalf("a single perfect score should not beat many strong ones: %s, %s", ranked[0].CafeID, ranked[1].CafeID)
+	}
+	wantPrior := (5 + 4.8*20 + 6) / 24
+	if !almostEqual(ranked[0].Score.PriorMean, wantPrior) {
+		t.Fatalf("expected prior %.4f, got %.4f", wantPrior, ranked[0].Score.PriorMean)
+	}
+	if got := drinkScoreResponse(ranked[1].Score); got["scores_mean"] != 5.0 || got["scores_count"] != 1 {
+		t.Fatalf("unexpected response: %v", got)
+	}
+}
+
+func TestRankCafeDrinksTieBreaks(t *testing.T) {
+	items := []cafeDrinkRanking{
+		{DrinkName: "раф", Score: drinkScore{ScoresSum: 8, ScoresCount: 2, PriorMean: 4}},
+		{DrinkName: "капучино", Score: drinkScore{ScoresSum: 8, ScoresCount: 2, PriorMean: 4}},
+		{DrinkName: "флэт уайт", Score: drinkScore{ScoresSum: 16, ScoresCount: 4, PriorMean: 4}},
+	}
+	rankCafeDrinks(items)
+	got := []string{items[0].DrinkName, items[1].DrinkName, items[2].DrinkName}
+	if strings.Join(got, ",") != "флэт уайт,капучино,раф" {
+		t.Fatalf("unexpected order: %v", got)
+	}
+}
+
+func TestValidateReviewPositionScoresInput(t *testing.T) {
+	five, zero := 5, 0
+	positions := normalizeReviewPositions([]ReviewPositionDTO{
+		{DrinkID: "flat-white", Score: &five, Note: "  плотная пенка  "},
+		{DrinkID: "flat-white", Score: &zero},
+	})
+	if len(positions) != 1 || positions[0].Score == nil || *positions[0].Score != 5 || positions[0].Note != "плотная пенка" {
+		t.Fatalf("expected the first duplicate with its trimmed note, got %+v", positions)
+	}
+	if err := validateReviewPositionScoresInput(positions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateReviewPositionScoresInput([]ReviewPositionDTO{{DrinkID: "latte", Score: &zero}}); err == nil {
+		t.Fatalf("expected score 0 to be rejected")
+	}
+	longNote := strings.Repeat("я", maxPositionNoteLength+1)
+	if err := validateReviewPositionScoresInput([]ReviewPositionDTO{{DrinkID: "latte", Note: longNote}}); err == nil {
+		t.Fatalf("expected a long note to be rejected")
+	}
+}
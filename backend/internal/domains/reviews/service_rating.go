@@ -31,19 +31,24 @@ const (
 	coalesce((
 		select count(*)
 		  from abuse_reports ar
-		 where ar.review_id = r.id and ar.status = 'confirmed'
+		 where ar.review_id = r.id and ar.reply_id is null and ar.comment_id is null and ar.status = 'confirmed'
 	), 0),
 	coalesce((
 		select count(*)
 		  from helpful_votes hv
-		 where hv.review_id = r.id
+		 where hv.review_id = r.id and hv.kind = 'helpful'
 	), 0)::int as helpful_votes,
 	coalesce((
 		select sum(hv.weight)
 		  from helpful_votes hv
-		 where hv.review_id = r.id
+		 where hv.review_id = r.id and hv.kind = 'helpful'
 	), 0)::float8 as helpful_score,
-	r.created_at
+	r.created_at,
+	coalesce((
+		select jsonb_object_agg(ara.aspect, ara.score)
+		  from review_aspect_ratings ara
+		 where ara.review_id = r.id
+	), '{}'::jsonb) as aspects
 from reviews r
 join users u on u.id = r.user_id
 left join review_attributes ra on ra.review_id = r.id
@@ -87,6 +92,7 @@ do update set
 	id,
 	event_type,
 	points::float8,
+	source_id,
 	created_at
 from reputation_events
 where user_id = $1::uuid
@@ -188,6 +194,7 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 		HelpfulVotes     int
 		HelpfulScore     float64
 		CreatedAt        time.Time
+		Aspects          map[string]int
 	}
 
 	reviews := make([]reviewInput, 0, 32)
@@ -202,7 +209,10 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 	defer rows.Close()
 
 	for rows.Next() {
-		var item reviewInput
+		var (
+			item       reviewInput
+			aspectsRaw []byte
+		)
 		if err := rows.Scan(
 			&item.ReviewID,
 			&item.AuthorUserID,
@@ -220,9 +230,11 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 			&item.HelpfulVotes,
 			&item.HelpfulScore,
 			&item.CreatedAt,
+			&aspectsRaw,
 		); err != nil {
 			return err
 		}
+		item.Aspects = decodeReviewAspectsJSON(aspectsRaw)
 		reviews = append(reviews, item)
 	}
 	if err := rows.Err(); err != nil {
@@ -280,6 +292,7 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 			"descriptive_tags":        []cafeSemanticTag{},
 			"descriptive_tags_source": "rules_v1",
 			"specific_tags":           []cafeSemanticTag{},
+			"aspect_ratings":          map[string]interface{}{},
 			"ai_summary":              aiSummaryPayload,
 			"rating_formula":          ratingFormulaVersion,
 			"quality_formula":         qualityFormulaVersion,
@@ -319,6 +332,7 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 	)
 	aiSignals := make([]aiReviewSignal, 0, len(reviews))
 	specificTagStats := map[string]*cafeSemanticTag{}
+	aspectStats := map[string]*aspectRatingStat{}
 
 	for _, item := range reviews {
 		authorRep := authorRepCache[item.AuthorUserID]
@@ -359,6 +373,8 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 			CreatedAt:     item.CreatedAt,
 		})
 
+		appendAspectRatingSignals(aspectStats, item.Aspects)
+
 		sumRatings += item.Rating
 		sumAuthorRepNorm += clamp(authorRep/ratingAuthorRepNormMax, 0, 1)
 
@@ -608,6 +624,14 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 	}
 	appendAISummaryBudgetPayload(aiSummaryPayload, aiBudgetDecision)
 	specificTags := buildTopSpecificCafeTags(specificTagStats, 8)
+	aspectRatings := map[string]interface{}{}
+	if len(aspectStats) > 0 {
+		globalAspectMeans, err := s.globalAspectMeans(ctx)
+		if err != nil {
+			return err
+		}
+		aspectRatings = buildAspectRatings(aspectStats, globalAspectMeans, globalMean)
+	}
 
 	// rating_v2 formula:
 	// 1) base = bayesian_mean(cafe_ratings, global_mean, m)
@@ -680,6 +704,7 @@ func (s *Service) recalculateCafeRatingSnapshotWithOptions(
 		"descriptive_tags":        descriptiveTags,
 		"descriptive_tags_source": descriptiveTagsSource,
 		"specific_tags":           specificTags,
+		"aspect_ratings":          aspectRatings,
 		"ai_summary":              aiSummaryPayload,
 	}
 	for key, value := range s.versioningSnapshot() {
@@ -790,6 +815,7 @@ func (s *Service) loadCafeRatingSnapshot(ctx context.Context, cafeID string) (ma
 		"best_review":            bestReview,
 		"descriptive_tags":       descriptiveTags,
 		"specific_tags":          specificTags,
+		"aspect_ratings":         aspectRatingsResponse(components["aspect_ratings"]),
 		"components":             components,
 		"computed_at":            computedAt.UTC().Format(time.RFC3339),
 	}
@@ -1062,15 +1088,17 @@ func (s *Service) lookupUserReputationScoreQuery(ctx context.Context, q queryer,
 			id        int64
 			eventType string
 			points    float64
+			sourceID  string
 			createdAt time.Time
 		)
-		if err := rows.Scan(&id, &eventType, &points, &createdAt); err != nil {
+		if err := rows.Scan(&id, &eventType, &points, &sourceID, &createdAt); err != nil {
 			return 0, err
 		}
 		events = append(events, reputation.ScoreEvent{
 			ID:        id,
 			EventType: eventType,
 			Points:    points,
+			SourceID:  sourceID,
 			CreatedAt: createdAt,
 		})
 	}
@@ -0,0 +1,111 @@
+package reviews
+
+import (
+	"context"
+
+	"backend/internal/model"
+)
+
+const sqlSelectGlobalAspectMeans = `select ara.aspect, avg(ara.score)::float8
+   from review_aspect_ratings ara
+   join reviews r on r.id = ara.review_id and r.status = 'published'
+  group by ara.aspect`
+
+type aspectRatingStat struct {
+	Sum   float64
+	Count int
+}
+
+func appendAspectRatingSignals(stats map[string]*aspectRatingStat, aspects map[string]int) {
+	for aspect, score := range aspects {
+		if !model.IsReviewAspect(aspect) || score < 1 || score > 5 {
+			continue
+		}
+		stat, ok := stats[aspect]
+		if !ok {
+			stat = &aspectRatingStat{}
+			stats[aspect] = stat
+		}
+		stat.Sum += float64(score)
+		stat.Count++
+	}
+}
+
+// buildAspectRatings smooths every scored aspect towards its global mean the
+// same way the overall rating is (bayesian_m), without the trust multiplier.
+// Aspects nobody scored are left out. fallbackMean stands in for aspects
+// without a global mean yet.
+func buildAspectRatings(
+	stats map[string]*aspectRatingStat,
+	globalMeans map[string]float64,
+	fallbackMean float64,
+) map[string]interface{} {
+	out := make(map[string]interface{}, len(stats))
+	for _, aspect := range model.ReviewAspects {
+		stat, ok := stats[aspect]
+		if !ok || stat.Count == 0 {
+			continue
+		}
+		globalMean, ok := globalMeans[aspect]
+		if !ok {
+			globalMean = fallbackMean
+		}
+		mean := stat.Sum / float64(stat.Count)
+		out[aspect] = map[string]interface{}{
+			"rating":        roundFloat(clamp(bayesianMean(mean, float64(stat.Count), globalMean, ratingBayesianM), 1, 5), 2),
+			"ratings_mean":  roundFloat(mean, 4),
+			"global_mean":   roundFloat(globalMean, 4),
+			"reviews_count": stat.Count,
+		}
+	}
+	return out
+}
+
+// aspectRatingsResponse flattens components.aspect_ratings into a list in
+// display order for GET /cafes/:id/rating.
+func aspectRatingsResponse(raw interface{}) []map[string]interface{} {
+	items := make([]map[string]interface{}, 0, len(model.ReviewAspects))
+	byAspect, ok := raw.(map[string]interface{})
+	if !ok {
+		return items
+	}
+	for _, aspect := range model.ReviewAspects {
+		entry, ok := byAspect[aspect].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		rating, ok := entry["rating"].(float64)
+		if !ok {
+			continue
+		}
+		reviewsCount, _ := entry["reviews_count"].(float64)
+		items = append(items, map[string]interface{}{
+			"aspect":        aspect,
+			"label":         model.ReviewAspectLabel(aspect),
+			"rating":        rating,
+			"reviews_count": int(reviewsCount),
+		})
+	}
+	return items
+}
+
+func (s *Service) globalAspectMeans(ctx context.Context) (map[string]float64, error) {
+	rows, err := s.repository.Pool().Query(ctx, sqlSelectGlobalAspectMeans)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	means := make(map[string]float64, len(model.ReviewAspects))
+	for rows.Next() {
+		var (
+			aspect string
+			mean   float64
+		)
+		if err := rows.Scan(&aspect, &mean); err != nil {
+			return nil, err
+		}
+		means[aspect] = clamp(mean, 1, 5)
+	}
+	return means, rows.Err()
+}
@@ -0,0 +1,63 @@
+package reviews
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestBuildAspectRatings(t *testing.T) {
+	stats := map[string]*aspectRatingStat{}
+	appendAspectRatingSignals(stats, map[string]int{"coffee": 5, "service": 2})
+	appendAspectRatingSignals(stats, map[string]int{"coffee": 5, "unknown": 4, "workspace": 9})
+
+	ratings := buildAspectRatings(stats, map[string]float64{"coffee": 4.0}, 3.5)
+	if len(ratings) != 2 {
+		t.Fatalf("expected only valid scored aspects, got %v", ratings)
+	}
+
+	coffee := ratings["coffee"].(map[string]interface{})
+	if coffee["reviews_count"] != 2 || coffee["ratings_mean"] != 5.0 {
+		t.Fatalf("unexpected coffee stats: %v", coffee)
+	}
+	if want := roundFloat(bayesianMean(5, 2, 4.0, ratingBayesianM), 2); coffee["rating"] != want {
+		t.Fatalf("expected coffee rating %v, got %v", want, coffee["rating"])
+	}
+	service := ratings["service"].(map[string]interface{})
+	if service["global_mean"] != 3.5 {
+		t.Fatalf("aspect without global mean should fall back, got %v", service["global_mean"])
+	}
+
+	raw, err := json.Marshal(ratings)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	items := aspectRatingsResponse(decoded)
+	if len(items) != 2 || items[0]["aspect"] != "coffee" || items[1]["aspect"] != "service" {
+		t.Fatalf("expected aspects in display order, got %v", items)
+	}
+	if items[0]["label"] != "Кофе" || items[0]["reviews_count"] != 2 {
+		t.Fatalf("unexpected response item: %v", items[0])
+	}
+	if got := aspectRatingsResponse(nil); len(got) != 0 {
+		t.Fatalf("expected empty list for snapshots without aspects, got %v", got)
+	}
+}
+
+func TestValidateReviewAspectsInput(t *testing.T) {
+	if err := validateReviewAspectsInput(normalizeReviewAspects(map[string]int{" Coffee ": 5, "service": 1})); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := validateReviewAspectsInput(map[string]int{"coffee": 6}); err == nil {
+		t.Fatalf("expected out of range score to be rejected")
+	}
+	if err := validateReviewAspectsInput(map[string]int{"parking": 3}); err == nil {
+		t.Fatalf("expected unknown aspect to be rejected")
+	}
+	if normalizeReviewAspects(map[string]int{" ": 3}) != nil {
+		t.Fatalf("expected blank keys to be dropped")
+	}
+}
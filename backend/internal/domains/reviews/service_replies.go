@@ -0,0 +1,280 @@
+package reviews
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	minReviewReplyLength = 2
+	maxReviewReplyLength = 2000
+
+	// sqlSelectReviewForReply locks the review so a reply never outlives a
+	// concurrent removal.
+	sqlSelectReviewForReply = `select
+	r.cafe_id::text,
+	r.user_id::text,
+	exists(
+		select 1
+		  from cafe_owners co
+		 where co.cafe_id = r.cafe_id and co.user_id = $2::uuid
+	)
+from reviews r
+where r.id = $1::uuid and r.status = 'published'
+for share of r`
+
+	sqlInsertReviewReply = `insert into review_replies (review_id, cafe_id, author_user_id, body)
+ values ($1::uuid, $2::uuid, $3::uuid, $4)
+ on conflict (review_id) do nothing
+ returning id::text, author_user_id::text, body, created_at, updated_at`
+
+	sqlUpdateReviewReply = `update review_replies
+    set body = $2,
+        updated_at = now()
+  where review_id = $1::uuid
+  returning id::text, author_user_id::text, body, created_at, updated_at`
+
+	sqlDeleteReviewReply = `delete from review_replies where review_id = $1::uuid`
+
+	sqlSelectReplyForAbuse = `select rr.id::text, rr.author_user_id::text
+   from review_replies rr
+   join reviews r on r.id = rr.review_id and r.status = 'published'
+  where rr.review_id = $1::uuid`
+
+	sqlInsertReplyAbuseReport = `insert into abuse_reports (review_id, reply_id, reporter_user_id, reason, details)
+ values ($1::uuid, $2::uuid, $3::uuid, $4, $5)
+ on conflict (reply_id, reporter_user_id) where reply_id is not null do nothing
+ returning id::text, status`
+
+	sqlSelectAbuseReportByReplyAndReporter = `select id::text, status
+   from abuse_reports
+  where reply_id = $1::uuid and reporter_user_id = $2::uuid`
+)
+
+// reviewReplyState is an owner reply as stored and as embedded in review
+// lists (see sqlListCafeReviewsBase).
+type reviewReplyState struct {
+	ID               string    `json:"id"`
+	AuthorUserID     string    `json:"author_user_id"`
+	AuthorName       string    `json:"author_name"`
+	Body             string    `json:"body"`
+	ConfirmedReports int       `json:"confirmed_reports"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+func (r reviewReplyState) response() map[string]interface{} {
+	response := map[string]interface{}{
+		"id":                r.ID,
+		"author_user_id":    r.AuthorUserID,
+		"body":              r.Body,
+		"confirmed_reports": r.ConfirmedReports,
+		"edited":            r.UpdatedAt.After(r.CreatedAt),
+		"created_at":        r.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at":        r.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+	if r.AuthorName != "" {
+		response["author_name"] = r.AuthorName
+	}
+	return response
+}
+
+// decodeReviewReplyJSON reads the owner_reply column; nil means no reply.
+func decodeReviewReplyJSON(raw []byte) map[string]interface{} {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	var reply reviewReplyState
+	if err := json.Unmarshal(raw, &reply); err != nil || reply.ID == "" {
+		return nil
+	}
+	return reply.response()
+}
+
+// CreateReviewReply publishes the cafe's reply to a review. Only a verified
+// owner of the reviewed cafe may reply, once per review.
+func (s *Service) CreateReviewReply(ctx context.Context, userID, reviewID, body string) (map[string]interface{}, error) {
+	if looksLikeSpamSummary(body) {
+		return nil, ErrSpamDetected
+	}
+
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	cafeID, reviewAuthorID, err := lockReviewForOwnerReply(ctx, tx, reviewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply reviewReplyState
+	err = tx.QueryRow(ctx, sqlInsertReviewReply, reviewID, cafeID, userID, body).Scan(
+		&reply.ID,
+		&reply.AuthorUserID,
+		&reply.Body,
+		&reply.CreatedAt,
+		&reply.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrReplyExists
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{
+		"reply_id":         reply.ID,
+		"review_id":        reviewID,
+		"cafe_id":          cafeID,
+		"review_author_id": reviewAuthorID,
+		"reply_author_id":  userID,
+	}
+	if err := s.repository.EnqueueEventTx(ctx, tx, EventReviewReplyCreated, cafeID, "review-reply-created:"+reply.ID, payload); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := reply.response()
+	response["review_id"] = reviewID
+	response["cafe_id"] = cafeID
+	return response, nil
+}
+
+// UpdateReviewReply edits the reply; any current owner of the cafe may do it.
+func (s *Service) UpdateReviewReply(ctx context.Context, userID, reviewID, body string) (map[string]interface{}, error) {
+	if looksLikeSpamSummary(body) {
+		return nil, ErrSpamDetected
+	}
+
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	cafeID, _, err := lockReviewForOwnerReply(ctx, tx, reviewID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply reviewReplyState
+	err = tx.QueryRow(ctx, sqlUpdateReviewReply, reviewID, body).Scan(
+		&reply.ID,
+		&reply.AuthorUserID,
+		&reply.Body,
+		&reply.CreatedAt,
+		&reply.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	response := reply.response()
+	response["review_id"] = reviewID
+	response["cafe_id"] = cafeID
+	return response, nil
+}
+
+// DeleteReviewReply removes the reply. Moderators may remove any reply,
+// everyone else only replies of a cafe they own.
+func (s *Service) DeleteReviewReply(ctx context.Context, userID, reviewID string, asModerator bool) (map[string]interface{}, error) {
+	tx, err := s.repository.Pool().BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(context.Background()) }()
+
+	if !asModerator {
+		if _, _, err := lockReviewForOwnerReply(ctx, tx, reviewID, userID); err != nil {
+			return nil, err
+		}
+	}
+	result, err := tx.Exec(ctx, sqlDeleteReviewReply, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"review_id": reviewID, "removed": true}, nil
+}
+
+// ReportReplyAbuse files an abuse report against the reply of a review; it
+// is confirmed through the same moderator endpoint as review reports.
+func (s *Service) ReportReplyAbuse(ctx context.Context, userID, reviewID string, req ReportAbuseRequest) (map[string]interface{}, error) {
+	reason, details := normalizeAbuseReport(req)
+
+	var replyID, replyAuthorID string
+	err := s.repository.Pool().QueryRow(ctx, sqlSelectReplyForAbuse, reviewID).Scan(&replyID, &replyAuthorID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if replyAuthorID == userID {
+		return nil, ErrForbidden
+	}
+
+	var reportID, status string
+	err = s.repository.Pool().QueryRow(
+		ctx,
+		sqlInsertReplyAbuseReport,
+		reviewID,
+		replyID,
+		userID,
+		reason,
+		details,
+	).Scan(&reportID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = s.repository.Pool().QueryRow(
+			ctx,
+			sqlSelectAbuseReportByReplyAndReporter,
+			replyID,
+			userID,
+		).Scan(&reportID, &status)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{"report_id": reportID, "reply_id": replyID, "status": status}, nil
+}
+
+func lockReviewForOwnerReply(ctx context.Context, tx pgx.Tx, reviewID, userID string) (string, string, error) {
+	var (
+		cafeID         string
+		reviewAuthorID string
+		isOwner        bool
+	)
+	err := tx.QueryRow(ctx, sqlSelectReviewForReply, reviewID, userID).Scan(&cafeID, &reviewAuthorID, &isOwner)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", "", ErrNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	if !isOwner {
+		return "", "", ErrForbidden
+	}
+	return cafeID, reviewAuthorID, nil
+}
@@ -110,6 +110,7 @@ func (s *Service) ListUserReputationEvents(
 			ID:        row.id,
 			EventType: row.eventType,
 			Points:    row.points,
+			SourceID:  row.sourceID,
 			CreatedAt: row.createdAt,
 		})
 	}
@@ -7,6 +7,8 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/model"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -41,16 +43,16 @@ func (s *Service) CreateReview(
 			return 0, nil, ErrRateLimited
 		}
 
-		var cafeExists bool
-		if err := tx.QueryRow(ctx, sqlCheckCafeExists, request.CafeID).Scan(&cafeExists); err != nil {
+		cafeStatus, err := s.lookupCafeStatusTx(ctx, tx, request.CafeID)
+		if err != nil {
 			return 0, nil, err
 		}
-		if !cafeExists {
-			return 0, nil, ErrNotFound
+		if cafeStatus == model.CafeStatusPermanentlyClosed {
+			return 0, nil, ErrCafeClosed
 		}
 
 		var existingReviewID, existingStatus string
-		err := tx.QueryRow(ctx, sqlSelectReviewByUserCafeForUpdate, userID, request.CafeID).Scan(&existingReviewID, &existingStatus)
+		err = tx.QueryRow(ctx, sqlSelectReviewByUserCafeForUpdate, userID, request.CafeID).Scan(&existingReviewID, &existingStatus)
 		switch {
 		case errors.Is(err, pgx.ErrNoRows):
 			// continue
@@ -111,6 +113,20 @@ func (s *Service) CreateReview(
 		); err != nil {
 			return 0, nil, err
 		}
+		if err := s.replaceReviewAspectsTx(ctx, tx, reviewID, request.Aspects); err != nil {
+			return 0, nil, err
+		}
+		revision, err := s.insertReviewRevisionTx(ctx, tx, reviewID, cafeReviewState{
+			Rating:    request.Rating,
+			Summary:   request.Summary,
+			TasteTags: request.TasteTags,
+			Photos:    request.Photos,
+			Positions: mapReviewPositionDTOToState(request.Positions),
+			Aspects:   request.Aspects,
+		}, updatedAt)
+		if err != nil {
+			return 0, nil, err
+		}
 
 		payload := map[string]interface{}{
 			"review_id": reviewID,
@@ -129,6 +145,7 @@ func (s *Service) CreateReview(
 			"cafe_id":    request.CafeID,
 			"event_type": EventReviewCreated,
 			"created":    true,
+			"revision":   revision,
 			"updated_at": updatedAt.UTC().Format(time.RFC3339),
 		}
 		s.appendVersionMetadata(response)
@@ -201,6 +218,9 @@ func (s *Service) UpdateReview(
 		if req.Photos != nil {
 			next.Photos = normalizePhotos(*req.Photos)
 		}
+		if req.Aspects != nil {
+			next.Aspects = normalizeReviewAspects(*req.Aspects)
+		}
 
 		if err := validateEffectiveReviewState(next, req.Summary != nil); err != nil {
 			return 0, nil, err
@@ -211,6 +231,8 @@ func (s *Service) UpdateReview(
 				rawPositions = append(rawPositions, ReviewPositionDTO{
 					DrinkID: item.DrinkID,
 					Drink:   item.DrinkName,
+					Score:   item.Score,
+					Note:    item.Note,
 				})
 			}
 			resolved, err := s.resolveReviewPositionsTx(ctx, tx, rawPositions, userID)
@@ -261,6 +283,15 @@ func (s *Service) UpdateReview(
 				return 0, nil, err
 			}
 		}
+		if req.Aspects != nil {
+			if err := s.replaceReviewAspectsTx(ctx, tx, state.ReviewID, next.Aspects); err != nil {
+				return 0, nil, err
+			}
+		}
+		revision, err := s.insertReviewRevisionTx(ctx, tx, state.ReviewID, next, updatedAt)
+		if err != nil {
+			return 0, nil, err
+		}
 
 		payload := map[string]interface{}{
 			"review_id": state.ReviewID,
@@ -277,6 +308,7 @@ func (s *Service) UpdateReview(
 			"cafe_id":    state.CafeID,
 			"event_type": EventReviewUpdated,
 			"created":    false,
+			"revision":   revision,
 			"updated_at": updatedAt.UTC().Format(time.RFC3339),
 		}
 		s.appendVersionMetadata(response)
@@ -8,6 +8,14 @@ import (
 	"backend/internal/reputation"
 )
 
+type reviewListPage struct {
+	Reviews         []map[string]interface{}
+	PositionOptions []map[string]interface{}
+	HasMore         bool
+	NextOffset      int
+	Last            *reviewKeyset
+}
+
 func (s *Service) ListCafeReviews(
 	ctx context.Context,
 	cafeID string,
@@ -16,29 +24,54 @@ func (s *Service) ListCafeReviews(
 	offset int,
 	limit int,
 ) ([]map[string]interface{}, []map[string]interface{}, bool, int, error) {
+	page, err := s.ListCafeReviewsPage(ctx, cafeID, sortBy, positionFilter, reviewListCursor{Offset: offset}, limit)
+	if err != nil {
+		return nil, nil, false, offset, err
+	}
+	return page.Reviews, page.PositionOptions, page.HasMore, page.NextOffset, nil
+}
+
+func (s *Service) ListCafeReviewsPage(
+	ctx context.Context,
+	cafeID string,
+	sortBy string,
+	positionFilter string,
+	cursor reviewListCursor,
+	limit int,
+) (reviewListPage, error) {
 	if limit <= 0 {
 		limit = defaultReviewListLimit
 	}
-	if offset < 0 {
+	offset := cursor.Offset
+	if offset < 0 || cursor.After != nil {
 		offset = 0
 	}
 
 	orderClause, ok := reviewSortOrderClause[sortBy]
 	if !ok {
-		orderClause = reviewSortOrderClause["new"]
+		sortBy = "new"
+		orderClause = reviewSortOrderClause[sortBy]
 	}
 
-	// Offset cursor stays deterministic because ORDER BY is explicit per sort mode.
-	query := sqlListCafeReviewsBase + "\n" + orderClause + "\noffset $2 limit $3"
+	// ORDER BY is explicit per sort mode, so both offset and keyset cursors
+	// stay deterministic; keyset cursors also survive inserts between pages.
 	fetchLimit := limit + 1
+	query := sqlListCafeReviewsBase
+	args := []interface{}{cafeID, offset, fetchLimit, positionFilter}
+	if cursor.After != nil {
+		query += reviewKeysetClause[sortBy] + "\n"
+		args = append(args, cursor.After.args(sortBy)...)
+	}
+	query += orderClause + "\noffset $2 limit $3"
 
-	rows, err := s.repository.Pool().Query(ctx, query, cafeID, offset, fetchLimit, positionFilter)
+	rows, err := s.repository.Pool().Query(ctx, query, args...)
 	if err != nil {
-		return nil, nil, false, offset, err
+		return reviewListPage{}, err
 	}
 	defer rows.Close()
 
 	result := make([]map[string]interface{}, 0, fetchLimit)
+	keysets := make([]reviewKeyset, 0, fetchLimit)
 	authorReputationCache := make(map[string]float64)
 	for rows.Next() {
 		var (
@@ -53,6 +86,11 @@ func (s *Service) ListCafeReviews(
 			confirmedReports int
 			reviewPhotos     []string
 			positionsRaw     []byte
+			ownerReplyRaw    []byte
+			aspectsRaw       []byte
+			commentsCount    int
+			revision         int
+			notHelpfulVotes  int
 		)
 
 		if err := rows.Scan(
@@ -75,15 +113,20 @@ func (s *Service) ListCafeReviews(
 			&item.UpdatedAt,
 			&reviewPhotos,
 			&positionsRaw,
+			&ownerReplyRaw,
+			&aspectsRaw,
+			&commentsCount,
+			&revision,
+			&notHelpfulVotes,
 		); err != nil {
-			return nil, nil, false, offset, err
+			return reviewListPage{}, err
 		}
 
 		cachedAuthorScore, ok := authorReputationCache[item.UserID]
 		if !ok {
 			score, err := s.lookupUserReputationScore(ctx, item.UserID)
 			if err != nil {
-				return nil, nil, false, offset, err
+				return reviewListPage{}, err
 			}
 			cachedAuthorScore = score
 			authorReputationCache[item.UserID] = score
@@ -121,37 +164,58 @@ func (s *Service) ListCafeReviews(
 			"drink_id":          item.DrinkID,
 			"drink_name":        item.DrinkName,
 			"positions":         mapReviewPositionsForResponse(item.Positions),
+			"aspects":           decodeReviewAspectsJSON(aspectsRaw),
 			"taste_tags":        tasteTags,
 			"specific_tags":     tasteTags,
 			"photos":            reviewPhotos,
 			"photo_count":       len(reviewPhotos),
 			"helpful_votes":     helpfulVotes,
 			"helpful_score":     roundFloat(helpfulScore, 3),
+			"not_helpful_votes": notHelpfulVotes,
 			"visit_confidence":  visitConfidence,
 			"visit_verified":    visitVerified,
 			"quality_score":     qualityScore,
 			"quality_formula":   qualityFormulaVersion,
 			"confirmed_reports": confirmedReports,
+			"owner_reply":       decodeReviewReplyJSON(ownerReplyRaw),
+			"comments_count":    commentsCount,
+			"revision":          revision,
+			"edited":            revision > 1,
 			"created_at":        item.CreatedAt.UTC().Format(time.RFC3339),
 			"updated_at":        item.UpdatedAt.UTC().Format(time.RFC3339),
 		})
+		keysets = append(keysets, reviewKeyset{
+			CreatedAt:    item.CreatedAt,
+			ID:           item.ReviewID,
+			HelpfulScore: helpfulScore,
+			HelpfulVotes: int64(helpfulVotes),
+			Verified:     visitVerified,
+		})
 	}
 	if err := rows.Err(); err != nil {
-		return nil, nil, false, offset, err
+		return reviewListPage{}, err
 	}
 
 	positionOptions, err := s.listCafeReviewPositionOptions(ctx, cafeID)
 	if err != nil {
-		return nil, nil, false, offset, err
+		return reviewListPage{}, err
 	}
 
 	hasMore := len(result) > limit
 	if hasMore {
 		result = result[:limit]
 	}
-	nextOffset := offset + len(result)
-
-	return result, positionOptions, hasMore, nextOffset, nil
+	page := reviewListPage{
+		Reviews:         result,
+		PositionOptions: positionOptions,
+		HasMore:         hasMore,
+		NextOffset:      offset + len(result),
+	}
+	if len(result) > 0 {
+		last := keysets[len(result)-1]
+		page.Last = &last
+	}
+	return page, nil
 }
 
 func decodeReviewPositionsJSON(raw []byte) []reviewPositionState {
@@ -169,6 +233,17 @@ func decodeReviewPositionsJSON(raw []byte) []reviewPositionState {
 	return parsed
 }
 
+func decodeReviewAspectsJSON(raw []byte) map[string]int {
+	aspects := map[string]int{}
+	if len(raw) == 0 {
+		return aspects
+	}
+	if err := json.Unmarshal(raw, &aspects); err != nil {
+		return map[string]int{}
+	}
+	return aspects
+}
+
 func mapReviewPositionsForResponse(positions []reviewPositionState) []map[string]interface{} {
 	result := make([]map[string]interface{}, 0, len(positions))
 	for idx, item := range positions {
@@ -180,6 +255,8 @@ func mapReviewPositionsForResponse(positions []reviewPositionState) []map[string
 			"position":   position,
 			"drink_id":   item.DrinkID,
 			"drink_name": item.DrinkName,
+			"score":      item.Score,
+			"note":       item.Note,
 		})
 	}
 	return result
@@ -5,6 +5,8 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/model"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -21,6 +23,9 @@ func validateCreateReviewRequest(req PublishReviewRequest) error {
 	if utfRuneLen(req.Summary) < minReviewSummaryLength {
 		return ErrConflict
 	}
+	if !validReviewAspects(req.Aspects) || !validReviewPositionScores(req.Positions) {
+		return ErrConflict
+	}
 	for _, photo := range req.Photos {
 		if !validPhotoURL(photo) {
 			return ErrConflict
@@ -39,6 +44,14 @@ func validateEffectiveReviewState(state cafeReviewState, enforceSummaryMin bool)
 	if enforceSummaryMin && utfRuneLen(state.Summary) < minReviewSummaryLength {
 		return ErrConflict
 	}
+	if !validReviewAspects(state.Aspects) {
+		return ErrConflict
+	}
+	for _, item := range state.Positions {
+		if !validPositionScore(item.Score, item.Note) {
+			return ErrConflict
+		}
+	}
 	for _, photo := range state.Photos {
 		if !validPhotoURL(photo) {
 			return ErrConflict
@@ -121,7 +134,7 @@ func (s *Service) loadReviewStateForUpdateTx(ctx context.Context, tx pgx.Tx, rev
 	positions := make([]reviewPositionState, 0, 4)
 	for positionRows.Next() {
 		var item reviewPositionState
-		if err := positionRows.Scan(&item.Position, &item.DrinkID, &item.DrinkName); err != nil {
+		if err := positionRows.Scan(&item.Position, &item.DrinkID, &item.DrinkName, &item.Score, &item.Note); err != nil {
 			return state, err
 		}
 		item.DrinkID = normalizeDrinkToken(item.DrinkID)
@@ -132,6 +145,30 @@ func (s *Service) loadReviewStateForUpdateTx(ctx context.Context, tx pgx.Tx, rev
 		return state, err
 	}
 	state.Positions = positions
+
+	aspectRows, err := tx.Query(ctx, sqlSelectReviewAspects, state.ReviewID)
+	if err != nil {
+		return state, err
+	}
+	defer aspectRows.Close()
+
+	for aspectRows.Next() {
+		var (
+			aspect string
+			score  int
+		)
+		if err := aspectRows.Scan(&aspect, &score); err != nil {
+			return state, err
+		}
+		if state.Aspects == nil {
+			state.Aspects = make(map[string]int, len(model.ReviewAspects))
+		}
+		state.Aspects[aspect] = score
+	}
+	if err := aspectRows.Err(); err != nil {
+		return state, err
+	}
+
 	if len(state.Positions) > 0 {
 		state.DrinkID = state.Positions[0].DrinkID
 		state.DrinkName = state.Positions[0].DrinkName
@@ -221,6 +258,8 @@ func (s *Service) replaceReviewPositionsTx(
 			idx+1,
 			drinkID,
 			drinkName,
+			item.Score,
+			strings.TrimSpace(item.Note),
 		); err != nil {
 			return err
 		}
@@ -228,6 +267,27 @@ func (s *Service) replaceReviewPositionsTx(
 	return nil
 }
 
+func (s *Service) replaceReviewAspectsTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	reviewID string,
+	aspects map[string]int,
+) error {
+	if _, err := tx.Exec(ctx, sqlDeleteReviewAspects, reviewID); err != nil {
+		return err
+	}
+	for _, aspect := range model.ReviewAspects {
+		score, ok := aspects[aspect]
+		if !ok {
+			continue
+		}
+		if _, err := tx.Exec(ctx, sqlInsertReviewAspect, reviewID, aspect, score); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) resolveReviewPositionsTx(
 	ctx context.Context,
 	tx pgx.Tx,
@@ -248,6 +308,8 @@ func (s *Service) resolveReviewPositionsTx(
 		resolved = append(resolved, ReviewPositionDTO{
 			DrinkID: choice.ID,
 			Drink:   choice.Name,
+			Score:   item.Score,
+			Note:    item.Note,
 		})
 	}
 
@@ -266,6 +328,8 @@ func mapReviewPositionDTOToState(input []ReviewPositionDTO) []reviewPositionStat
 			Position:  idx + 1,
 			DrinkID:   normalizeDrinkToken(item.DrinkID),
 			DrinkName: normalizeDrinkText(item.Drink),
+			Score:     item.Score,
+			Note:      strings.TrimSpace(item.Note),
 		})
 	}
 	return positions
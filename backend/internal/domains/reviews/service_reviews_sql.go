@@ -6,7 +6,7 @@ const (
 	minReviewSummaryLength = 12
 	defaultReviewListLimit = 20
 
-	sqlCheckCafeExists    = `select exists(select 1 from cafes where id = $1::uuid)`
+	sqlSelectCafeStatus   = `select status from cafes where id = $1::uuid and status <> 'deleted'`
 	sqlSelectDrinkByID    = `select id, name from drinks where id = $1 and is_active = true`
 	sqlSelectDrinkByAlias = `select id, name
    from drinks
@@ -101,17 +101,28 @@ do update set
 
 	sqlDeleteReviewPositions = `delete from review_positions where review_id = $1::uuid`
 
-	sqlInsertReviewPosition = `insert into review_positions (review_id, position, drink_id, drink_name)
- values ($1::uuid, $2, $3, $4)`
+	sqlInsertReviewPosition = `insert into review_positions (review_id, position, drink_id, drink_name, score, note)
+ values ($1::uuid, $2, $3, $4, $5, $6)`
 
 	sqlSelectReviewPositions = `select
 	position,
 	coalesce(drink_id, ''),
-	coalesce(drink_name, '')
+	coalesce(drink_name, ''),
+	score,
+	note
 from review_positions
 where review_id = $1::uuid
 order by position asc`
 
+	sqlDeleteReviewAspects = `delete from review_aspect_ratings where review_id = $1::uuid`
+
+	sqlInsertReviewAspect = `insert into review_aspect_ratings (review_id, aspect, score)
+ values ($1::uuid, $2, $3)`
+
+	sqlSelectReviewAspects = `select aspect, score
+   from review_aspect_ratings
+  where review_id = $1::uuid`
+
 	sqlExistsDuplicateSummary = `select exists(
 	select 1
 	  from review_attributes ra
@@ -141,7 +152,7 @@ const sqlListCafeReviewsBase = `select
 	coalesce((
 		select count(*)
 		  from abuse_reports ar
-		 where ar.review_id = r.id and ar.status = 'confirmed'
+		 where ar.review_id = r.id and ar.reply_id is null and ar.comment_id is null and ar.status = 'confirmed'
 	), 0) as confirmed_reports,
 	r.created_at,
 	r.updated_at,
@@ -156,23 +167,61 @@ const sqlListCafeReviewsBase = `select
 			jsonb_build_object(
 				'position', rp.position,
 				'drink_id', coalesce(rp.drink_id, ''),
-				'drink_name', coalesce(nullif(trim(drp.name), ''), coalesce(rp.drink_name, ''))
+				'drink_name', coalesce(nullif(trim(drp.name), ''), coalesce(rp.drink_name, '')),
+				'score', rp.score,
+				'note', rp.note
 			)
 			order by rp.position asc
 		)
 		  from review_positions rp
 		  left join drinks drp on drp.id = rp.drink_id
 		 where rp.review_id = r.id
-	), '[]'::jsonb) as positions
+	), '[]'::jsonb) as positions,
+	(
+		select jsonb_build_object(
+			'id', rr.id::text,
+			'author_user_id', rr.author_user_id::text,
+			'author_name', coalesce(nullif(trim(ru.display_name), ''), 'Владелец'),
+			'body', rr.body,
+			'confirmed_reports', (
+				select count(*)
+				  from abuse_reports ar
+				 where ar.reply_id = rr.id and ar.status = 'confirmed'
+			),
+			'created_at', rr.created_at,
+			'updated_at', rr.updated_at
+		)
+		  from review_replies rr
+		  join users ru on ru.id = rr.author_user_id
+		 where rr.review_id = r.id
+	) as owner_reply,
+	coalesce((
+		select jsonb_object_agg(ara.aspect, ara.score)
+		  from review_aspect_ratings ara
+		 where ara.review_id = r.id
+	), '{}'::jsonb) as aspects,
+	(
+		select count(*)::int
+		  from review_comments rc
+		 where rc.review_id = r.id and rc.status = 'published'
+	) as comments_count,
+	coalesce((
+		select max(rv.revision)
+		  from review_revisions rv
+		 where rv.review_id = r.id
+	), 1) as revision,
+	coalesce(hs.not_helpful_votes, 0) as not_helpful_votes
 from reviews r
+join cafes c on c.id = r.cafe_id and c.status <> 'deleted'
 join users u on u.id = r.user_id
 left join review_attributes ra on ra.review_id = r.id
 left join drinks d on d.id = ra.drink_id
 left join visit_verifications vv on vv.review_id = r.id
 left join lateral (
 	select
-		count(*) as helpful_votes,
-		coalesce(sum(hv.weight), 0) as helpful_score
+		count(*) filter (where hv.kind = 'helpful') as helpful_votes,
+		coalesce(sum(hv.weight) filter (where hv.kind = 'helpful'), 0) as helpful_score,
+		count(*) filter (where hv.kind = 'not_helpful') as not_helpful_votes
 	  from helpful_votes hv
 	 where hv.review_id = r.id
 ) hs on true
@@ -207,6 +256,21 @@ var reviewSortOrderClause = map[string]string{
 	"verified": "order by visit_verified desc, hs.helpful_score desc, hs.helpful_votes desc, r.created_at desc, r.id desc",
 }
 
+// reviewKeysetClause continues reviewSortOrderClause after the last row of the
+// previous page. Every column in those orderings is descending, so a single
+// row comparison is enough. Parameters start at $5, see reviewKeyset.args.
+var reviewKeysetClause = map[string]string{
+	"new":     "  and (r.created_at, r.id) < ($5::timestamptz, $6::uuid)",
+	"helpful": "  and (hs.helpful_score::float8, hs.helpful_votes, r.created_at, r.id) < ($5::float8, $6::bigint, $7::timestamptz, $8::uuid)",
+	"verified": `  and (
+	coalesce(vv.verified_at is not null and vv.confidence in ('low', 'medium', 'high'), false),
+	hs.helpful_score::float8,
+	hs.helpful_votes,
+	r.created_at,
+	r.id
+  ) < ($5::boolean, $6::float8, $7::bigint, $8::timestamptz, $9::uuid)`,
+}
+
 type cafeReviewState struct {
 	ReviewID   string
 	UserID     string
@@ -223,12 +287,15 @@ type cafeReviewState struct {
 	HasReview  bool
 	PhotoCount int
 	Positions  []reviewPositionState
+	Aspects    map[string]int
 }
 
 type reviewPositionState struct {
 	Position  int    `json:"position"`
 	DrinkID   string `json:"drink_id"`
 	DrinkName string `json:"drink_name"`
+	Score     *int   `json:"score"`
+	Note      string `json:"note"`
 }
 
 type resolvedDrink struct {
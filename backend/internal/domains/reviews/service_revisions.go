@@ -0,0 +1,173 @@
+package reviews
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// The review row is locked by the caller (insert or select for update),
+	// so max(revision) + 1 cannot race.
+	sqlInsertReviewRevision = `insert into review_revisions (
+	review_id, revision, rating, summary, positions, taste_tags, photos, aspects, created_at
+)
+select $1::uuid, coalesce(max(revision), 0) + 1, $2, $3, $4::jsonb, $5::text[], $6::text[], $7::jsonb, $8
+  from review_revisions
+ where review_id = $1::uuid
+returning revision`
+
+	sqlListReviewRevisions = `select
+	revision,
+	rating,
+	summary,
+	positions,
+	taste_tags,
+	photos,
+	aspects,
+	created_at
+from review_revisions
+where review_id = $1::uuid
+order by revision desc`
+)
+
+// reviewRevision is one immutable version of a review's content.
+type reviewRevision struct {
+	Revision  int
+	Rating    int
+	Summary   string
+	Positions []reviewPositionState
+	TasteTags []string
+	Photos    []string
+	Aspects   map[string]int
+	CreatedAt time.Time
+}
+
+func (r reviewRevision) response() map[string]interface{} {
+	tasteTags := r.TasteTags
+	if tasteTags == nil {
+		tasteTags = []string{}
+	}
+	photos := r.Photos
+	if photos == nil {
+		photos = []string{}
+	}
+	aspects := r.Aspects
+	if aspects == nil {
+		aspects = map[string]int{}
+	}
+	return map[string]interface{}{
+		"revision":   r.Revision,
+		"rating":     r.Rating,
+		"summary":    r.Summary,
+		"positions":  mapReviewPositionsForResponse(r.Positions),
+		"taste_tags": tasteTags,
+		"photos":     photos,
+		"aspects":    aspects,
+		"created_at": r.CreatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// insertReviewRevisionTx appends the given content as the next revision of
+// the review and returns its number.
+func (s *Service) insertReviewRevisionTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	reviewID string,
+	state cafeReviewState,
+	createdAt time.Time,
+) (int, error) {
+	positions := state.Positions
+	if positions == nil {
+		positions = []reviewPositionState{}
+	}
+	positionsRaw, err := json.Marshal(positions)
+	if err != nil {
+		return 0, err
+	}
+	aspects := state.Aspects
+	if aspects == nil {
+		aspects = map[string]int{}
+	}
+	aspectsRaw, err := json.Marshal(aspects)
+	if err != nil {
+		return 0, err
+	}
+	tasteTags := state.TasteTags
+	if tasteTags == nil {
+		tasteTags = []string{}
+	}
+	photos := state.Photos
+	if photos == nil {
+		photos = []string{}
+	}
+
+	var revision int
+	err = tx.QueryRow(
+		ctx,
+		sqlInsertReviewRevision,
+		reviewID,
+		state.Rating,
+		state.Summary,
+		string(positionsRaw),
+		tasteTags,
+		photos,
+		string(aspectsRaw),
+		createdAt,
+	).Scan(&revision)
+	return revision, err
+}
+
+// ListReviewRevisions returns the edit history of a published review, newest
+// revision first.
+func (s *Service) ListReviewRevisions(ctx context.Context, reviewID string) (map[string]interface{}, error) {
+	var published bool
+	if err := s.repository.Pool().QueryRow(ctx, sqlSelectReviewPublished, reviewID).Scan(&published); err != nil {
+		return nil, err
+	}
+	if !published {
+		return nil, ErrNotFound
+	}
+
+	rows, err := s.repository.Pool().Query(ctx, sqlListReviewRevisions, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := make([]map[string]interface{}, 0, 4)
+	for rows.Next() {
+		var (
+			revision     reviewRevision
+			positionsRaw []byte
+			aspectsRaw   []byte
+		)
+		if err := rows.Scan(
+			&revision.Revision,
+			&revision.Rating,
+			&revision.Summary,
+			&positionsRaw,
+			&revision.TasteTags,
+			&revision.Photos,
+			&aspectsRaw,
+			&revision.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		revision.Positions = decodeReviewPositionsJSON(positionsRaw)
+		revision.Aspects = decodeReviewAspectsJSON(aspectsRaw)
+		revisions = append(revisions, revision.response())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"review_id":       reviewID,
+		"revisions_count": len(revisions),
+		"edited":          len(revisions) > 1,
+		"revisions":       revisions,
+	}, nil
+}
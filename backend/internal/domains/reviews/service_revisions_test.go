@@ -0,0 +1,43 @@
+package reviews
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestReviewRevisionResponse(t *testing.T) {
+	score := 4
+	positions, err := json.Marshal([]reviewPositionState{
+		{Position: 1, DrinkID: "flat-white", DrinkName: "Флэт уайт", Score: &score, Note: "плотная пенка"},
+	})
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+
+	revision := reviewRevision{
+		Revision:  2,
+		Rating:    1,
+		Summary:   "Стало хуже после смены зерна.",
+		Positions: decodeReviewPositionsJSON(positions),
+		Aspects:   decodeReviewAspectsJSON([]byte(`{"coffee":2}`)),
+		CreatedAt: time.Date(2026, 6, 1, 15, 0, 0, 0, time.FixedZone("MSK", 3*60*60)),
+	}
+	response := revision.response()
+	if response["revision"] != 2 || response["rating"] != 1 {
+		t.Fatalf("unexpected response: %v", response)
+	}
+	if response["created_at"] != "2026-06-01T12:00:00Z" {
+		t.Fatalf("expected UTC timestamp, got %v", response["created_at"])
+	}
+	items := response["positions"].([]map[string]interface{})
+	if len(items) != 1 || items[0]["drink_id"] != "flat-white" || items[0]["note"] != "плотная пенка" {
+		t.Fatalf("unexpected positions: %v", items)
+	}
+	if tags, ok := response["taste_tags"].([]string); !ok || tags == nil {
+		t.Fatalf("expected empty taste tags list, got %v", response["taste_tags"])
+	}
+	if aspects := response["aspects"].(map[string]int); aspects["coffee"] != 2 {
+		t.Fatalf("unexpected aspects: %v", aspects)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"unicode"
+
+	"backend/internal/model"
 )
 
 func sanitizePublishReviewRequest(req PublishReviewRequest) PublishReviewRequest {
@@ -37,9 +39,54 @@ func sanitizePublishReviewRequest(req PublishReviewRequest) PublishReviewRequest
 	}
 	clean.TasteTags = normalizeTags(req.TasteTags)
 	clean.Photos = normalizePhotos(req.Photos)
+	clean.Aspects = normalizeReviewAspects(req.Aspects)
 	return clean
 }
 
+// normalizeReviewAspects lowercases aspect keys; nil means no aspect scores.
+func normalizeReviewAspects(values map[string]int) map[string]int {
+	if len(values) == 0 {
+		return nil
+	}
+	out := make(map[string]int, len(values))
+	for rawAspect, score := range values {
+		aspect := strings.ToLower(strings.TrimSpace(rawAspect))
+		if aspect == "" {
+			continue
+		}
+		out[aspect] = score
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func validReviewPositionScores(positions []ReviewPositionDTO) bool {
+	for _, item := range positions {
+		if !validPositionScore(item.Score, item.Note) {
+			return false
+		}
+	}
+	return true
+}
+
+func validPositionScore(score *int, note string) bool {
+	if score != nil && (*score < 1 || *score > 5) {
+		return false
+	}
+	return utfRuneLen(note) <= maxPositionNoteLength
+}
+
+func validReviewAspects(values map[string]int) bool {
+	for aspect, score := range values {
+		if !model.IsReviewAspect(aspect) || score < 1 || score > 5 {
+			return false
+		}
+	}
+	return true
+}
+
 func normalizeReviewPositions(values []ReviewPositionDTO) []ReviewPositionDTO {
 	seen := map[string]struct{}{}
 	out := make([]ReviewPositionDTO, 0, len(values))
@@ -60,6 +107,8 @@ func normalizeReviewPositions(values []ReviewPositionDTO) []ReviewPositionDTO {
 		out = append(out, ReviewPositionDTO{
 			DrinkID: drinkID,
 			Drink:   drinkName,
+			Score:   item.Score,
+			Note:    strings.TrimSpace(item.Note),
 		})
 		if len(out) >= maxReviewPositions {
 			break
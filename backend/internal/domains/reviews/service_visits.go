@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/shared/geo"
+
 	"github.com/jackc/pgx/v5"
 )
 
@@ -96,7 +98,7 @@ func (s *Service) verifyVisitByCheckIn(
 			lat = checkIn.StartLat
 			lng = checkIn.StartLng
 		}
-		verifyDistance := int(math.Round(haversineMeters(lat, lng, cafeLat, cafeLng)))
+		verifyDistance := int(math.Round(geo.HaversineM(lat, lng, cafeLat, cafeLng)))
 		if !isAdminBypass && float64(verifyDistance) > checkInRadiusMeters {
 			return 0, nil, ErrCheckInTooFar
 		}
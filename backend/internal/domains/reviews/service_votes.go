@@ -9,31 +9,74 @@ import (
 )
 
 const (
+	helpfulVoteKindHelpful    = "helpful"
+	helpfulVoteKindNotHelpful = "not_helpful"
+
 	sqlSelectPublishedReviewAuthorAndCafe = `select user_id::text, cafe_id::text
    from reviews
   where id = $1::uuid and status = 'published'`
 
-	sqlInsertHelpfulVote = `insert into helpful_votes (review_id, voter_user_id, weight)
- values ($1::uuid, $2::uuid, $3)
+	sqlInsertHelpfulVote = `insert into helpful_votes (review_id, voter_user_id, weight, kind)
+ values ($1::uuid, $2::uuid, $3, $4)
  on conflict (review_id, voter_user_id) do nothing
  returning id::text`
 
 	sqlSelectHelpfulVoteByReviewAndVoter = `select id::text, weight
    from helpful_votes
   where review_id = $1::uuid and voter_user_id = $2::uuid`
+
+	sqlDeleteHelpfulVote = `delete from helpful_votes
+  where review_id = $1::uuid and voter_user_id = $2::uuid and kind = $3
+  returning id::text, kind`
 )
 
+func helpfulVoteAddedEvent(kind string) string {
+	if kind == helpfulVoteKindNotHelpful {
+		return EventNotHelpfulAdded
+	}
+	return EventHelpfulAdded
+}
+
+func helpfulVoteRetractedEvent(kind string) string {
+	if kind == helpfulVoteKindNotHelpful {
+		return EventNotHelpfulRetracted
+	}
+	return EventHelpfulRetracted
+}
+
 func (s *Service) AddHelpfulVote(
 	ctx context.Context,
 	userID string,
 	reviewID string,
 	idempotencyKey string,
+) (idempotentResult, error) {
+	return s.castHelpfulVote(ctx, userID, reviewID, idempotencyKey, helpfulVoteKindHelpful, IdempotencyScopeHelpfulVote)
+}
+
+// AddNotHelpfulVote marks a review as not helpful. It replaces a helpful
+// vote of the same voter, retracting it first.
+func (s *Service) AddNotHelpfulVote(
+	ctx context.Context,
+	userID string,
+	reviewID string,
+	idempotencyKey string,
+) (idempotentResult, error) {
+	return s.castHelpfulVote(ctx, userID, reviewID, idempotencyKey, helpfulVoteKindNotHelpful, IdempotencyScopeNotHelpfulVote)
+}
+
+func (s *Service) castHelpfulVote(
+	ctx context.Context,
+	userID string,
+	reviewID string,
+	idempotencyKey string,
+	kind string,
+	scopePrefix string,
 ) (idempotentResult, error) {
 	hash := requestHash(struct {
 		UserID   string `json:"user_id"`
 		ReviewID string `json:"review_id"`
 	}{UserID: userID, ReviewID: reviewID})
-	scope := IdempotencyScopeHelpfulVote + ":" + userID
+	scope := scopePrefix + ":" + userID
 
 	return s.repository.RunIdempotent(ctx, scope, idempotencyKey, hash, func(tx pgx.Tx) (int, map[string]interface{}, error) {
 		var reviewAuthorID, cafeID string
@@ -59,6 +102,21 @@ func (s *Service) AddHelpfulVote(
 		}
 		weight := voteWeightFromReputation(voterRep)
 
+		// A vote of the other kind is retracted before the new one is cast.
+		var (
+			previousVoteID string
+			previousKind   string
+		)
+		err = tx.QueryRow(ctx, sqlDeleteHelpfulVote, reviewID, userID, oppositeHelpfulVoteKind(kind)).Scan(&previousVoteID, &previousKind)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, err
+		}
+		if previousVoteID != "" {
+			if err := s.enqueueHelpfulVoteRetractedTx(ctx, tx, scope, idempotencyKey, previousVoteID, previousKind, reviewID, cafeID, reviewAuthorID); err != nil {
+				return 0, nil, err
+			}
+		}
+
 		var voteID string
 		err = tx.QueryRow(
 			ctx,
@@ -66,6 +124,7 @@ func (s *Service) AddHelpfulVote(
 			reviewID,
 			userID,
 			weight,
+			kind,
 		).Scan(&voteID)
 
 		alreadyExists := false
@@ -85,13 +144,14 @@ func (s *Service) AddHelpfulVote(
 		}
 
 		if !alreadyExists {
+			eventType := helpfulVoteAddedEvent(kind)
 			payload := map[string]interface{}{
 				"vote_id":   voteID,
 				"review_id": reviewID,
 				"cafe_id":   cafeID,
 			}
-			dedupeKey := fmt.Sprintf("%s:%s:%s", scope, idempotencyKey, EventHelpfulAdded)
-			if err := s.repository.EnqueueEventTx(ctx, tx, EventHelpfulAdded, cafeID, dedupeKey, payload); err != nil {
+			dedupeKey := fmt.Sprintf("%s:%s:%s", scope, idempotencyKey, eventType)
+			if err := s.repository.EnqueueEventTx(ctx, tx, eventType, cafeID, dedupeKey, payload); err != nil {
 				return 0, nil, err
 			}
 		}
@@ -99,9 +159,100 @@ func (s *Service) AddHelpfulVote(
 		response := map[string]interface{}{
 			"vote_id":        voteID,
 			"review_id":      reviewID,
+			"kind":           kind,
 			"weight":         weight,
 			"already_exists": alreadyExists,
 		}
+		if previousVoteID != "" {
+			response["replaced_vote_id"] = previousVoteID
+		}
 		return 200, response, nil
 	})
 }
+
+// RetractHelpfulVote takes back the voter's vote of the given kind. Nothing
+// to retract is not an error, so retries stay harmless.
+func (s *Service) RetractHelpfulVote(
+	ctx context.Context,
+	userID string,
+	reviewID string,
+	idempotencyKey string,
+	kind string,
+) (idempotentResult, error) {
+	hash := requestHash(struct {
+		UserID   string `json:"user_id"`
+		ReviewID string `json:"review_id"`
+		Kind     string `json:"kind"`
+	}{UserID: userID, ReviewID: reviewID, Kind: kind})
+	scope := IdempotencyScopeHelpfulRetract + ":" + userID
+
+	return s.repository.RunIdempotent(ctx, scope, idempotencyKey, hash, func(tx pgx.Tx) (int, map[string]interface{}, error) {
+		var reviewAuthorID, cafeID string
+		err := tx.QueryRow(
+			ctx,
+			sqlSelectPublishedReviewAuthorAndCafe,
+			reviewID,
+		).Scan(&reviewAuthorID, &cafeID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil, ErrNotFound
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		var voteID, votedKind string
+		err = tx.QueryRow(ctx, sqlDeleteHelpfulVote, reviewID, userID, kind).Scan(&voteID, &votedKind)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 200, map[string]interface{}{
+				"review_id": reviewID,
+				"kind":      kind,
+				"retracted": false,
+			}, nil
+		}
+		if err != nil {
+			return 0, nil, err
+		}
+
+		if err := s.enqueueHelpfulVoteRetractedTx(ctx, tx, scope, idempotencyKey, voteID, votedKind, reviewID, cafeID, reviewAuthorID); err != nil {
+			return 0, nil, err
+		}
+		return 200, map[string]interface{}{
+			"vote_id":   voteID,
+			"review_id": reviewID,
+			"kind":      votedKind,
+			"retracted": true,
+		}, nil
+	})
+}
+
+// enqueueHelpfulVoteRetractedTx carries the review author in the payload:
+// the vote row is gone by the time the reputation consumer runs.
+func (s *Service) enqueueHelpfulVoteRetractedTx(
+	ctx context.Context,
+	tx pgx.Tx,
+	scope string,
+	idempotencyKey string,
+	voteID string,
+	kind string,
+	reviewID string,
+	cafeID string,
+	reviewAuthorID string,
+) error {
+	eventType := helpfulVoteRetractedEvent(kind)
+	payload := map[string]interface{}{
+		"vote_id":          voteID,
+		"review_id":        reviewID,
+		"cafe_id":          cafeID,
+		"review_author_id": reviewAuthorID,
+		"kind":             kind,
+	}
+	dedupeKey := fmt.Sprintf("%s:%s:%s:%s", scope, idempotencyKey, eventType, voteID)
+	return s.repository.EnqueueEventTx(ctx, tx, eventType, cafeID, dedupeKey, payload)
+}
+
+func oppositeHelpfulVoteKind(kind string) string {
+	if kind == helpfulVoteKindNotHelpful {
+		return helpfulVoteKindHelpful
+	}
+	return helpfulVoteKindNotHelpful
+}
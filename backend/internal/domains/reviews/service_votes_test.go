@@ -0,0 +1,29 @@
+package reviews
+
+import "testing"
+
+func TestHelpfulVoteEvents(t *testing.T) {
+	if helpfulVoteAddedEvent(helpfulVoteKindHelpful) != EventHelpfulAdded ||
+		helpfulVoteAddedEvent(helpfulVoteKindNotHelpful) != EventNotHelpfulAdded {
+		t.Fatalf("unexpected added events")
+	}
+	if helpfulVoteRetractedEvent(helpfulVoteKindHelpful) != EventHelpfulRetracted ||
+		helpfulVoteRetractedEvent(helpfulVoteKindNotHelpful) != EventNotHelpfulRetracted {
+		t.Fatalf("unexpected retracted events")
+	}
+	if oppositeHelpfulVoteKind(helpfulVoteKindHelpful) != helpfulVoteKindNotHelpful {
+		t.Fatalf("a helpful vote should replace a not-helpful one")
+	}
+
+	// Retracting a helpful vote changes the rating inputs, not-helpful votes
+	// only move reputation.
+	if got := inboxConsumersForEvent(EventHelpfulRetracted); len(got) != 2 {
+		t.Fatalf("expected rating and reputation consumers, got %v", got)
+	}
+	for _, eventType := range []string{EventNotHelpfulAdded, EventNotHelpfulRetracted} {
+		got := inboxConsumersForEvent(eventType)
+		if len(got) != 1 || got[0] != inboxConsumerReputation {
+			t.Fatalf("%s: expected reputation consumer only, got %v", eventType, got)
+		}
+	}
+}
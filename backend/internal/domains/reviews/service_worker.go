@@ -17,7 +17,7 @@ const (
 	inboxConsumerReputation  = "reputation.projector.v1"
 	inboxConsumerReviewPhoto = "review.photo.pipeline.v1"
 
-	sqlSelectHelpfulVoteAuthorAndWeight = `select r.user_id::text, hv.weight::float8
+	sqlSelectHelpfulVoteAuthorAndWeight = `select r.user_id::text, hv.weight::float8, hv.kind
    from helpful_votes hv
    join reviews r on r.id = hv.review_id
   where hv.id = $1::uuid`
@@ -27,9 +27,12 @@ const (
    join reviews r on r.id = vv.review_id
   where vv.id = $1::uuid`
 
-	sqlSelectAbuseReportAuthor = `select r.user_id::text
+	// A confirmed report on an owner reply or a comment penalizes its author.
+	sqlSelectAbuseReportAuthor = `select coalesce(rc.author_user_id, rr.author_user_id, r.user_id)::text
    from abuse_reports ar
    join reviews r on r.id = ar.review_id
+   left join review_replies rr on rr.id = ar.reply_id
+   left join review_comments rc on rc.id = ar.comment_id
  where ar.id = $1::uuid and ar.status = 'confirmed'`
 )
 
@@ -148,8 +151,8 @@ func (s *Service) handleInboxEvent(ctx context.Context, evt domainInboxEvent) er
 
 func (s *Service) applyReputationProjection(ctx context.Context, evt domainInboxEvent) error {
 	switch evt.EventType {
-	case EventHelpfulAdded:
-		return s.applyHelpfulReputation(ctx, evt.Payload)
+	case EventHelpfulAdded, EventNotHelpfulAdded, EventHelpfulRetracted, EventNotHelpfulRetracted:
+		return s.applyHelpfulReputation(ctx, evt.EventType, evt.Payload)
 	case EventVisitVerified:
 		return s.applyVisitReputation(ctx, evt.Payload)
 	case EventAbuseConfirmed:
@@ -161,10 +164,13 @@ func (s *Service) applyReputationProjection(ctx context.Context, evt domainInbox
 
 func inboxConsumersForEvent(eventType string) []string {
 	switch eventType {
-	case EventReviewCreated, EventReviewUpdated:
+	case EventReviewCreated, EventReviewUpdated, EventCafeMerged:
 		return []string{inboxConsumerCafeRating}
-	case EventHelpfulAdded, EventVisitVerified, EventAbuseConfirmed:
+	case EventHelpfulAdded, EventHelpfulRetracted, EventVisitVerified, EventAbuseConfirmed:
 		return []string{inboxConsumerCafeRating, inboxConsumerReputation}
+	case EventNotHelpfulAdded, EventNotHelpfulRetracted:
+		// Not-helpful votes do not feed the cafe rating.
+		return []string{inboxConsumerReputation}
 	case EventReviewPhotoProcessRequested:
 		return []string{inboxConsumerReviewPhoto}
 	default:
@@ -172,22 +178,45 @@ func inboxConsumersForEvent(eventType string) []string {
 	}
 }
 
-func (s *Service) applyHelpfulReputation(ctx context.Context, payload map[string]interface{}) error {
+func (s *Service) applyHelpfulReputation(ctx context.Context, eventType string, payload map[string]interface{}) error {
 	voteID := payloadString(payload, "vote_id")
 	if voteID == "" {
 		return nil
 	}
 
+	switch eventType {
+	case EventHelpfulRetracted:
+		return s.repository.ReverseReputationEvent(
+			ctx,
+			payloadString(payload, "review_author_id"),
+			reputation.EventHelpfulRetracted,
+			reputation.EventHelpfulReceived,
+			reputation.SourceHelpfulVote,
+			voteID,
+		)
+	case EventNotHelpfulRetracted:
+		return s.repository.ReverseReputationEvent(
+			ctx,
+			payloadString(payload, "review_author_id"),
+			reputation.EventNotHelpfulRetracted,
+			reputation.EventNotHelpfulReceived,
+			reputation.SourceHelpfulVote,
+			voteID,
+		)
+	}
+
 	var (
 		reviewAuthorID string
 		weight         float64
+		kind           string
 	)
 
+	// A vote retracted before this runs is gone; there is nothing to award.
 	err := s.repository.Pool().QueryRow(
 		ctx,
 		sqlSelectHelpfulVoteAuthorAndWeight,
 		voteID,
-	).Scan(&reviewAuthorID, &weight)
+	).Scan(&reviewAuthorID, &weight, &kind)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil
 	}
@@ -195,6 +224,24 @@ func (s *Service) applyHelpfulReputation(ctx context.Context, payload map[string
 		return err
 	}
 
+	if kind == helpfulVoteKindNotHelpful {
+		// Each not-helpful vote costs 1 point scaled by voter weight; the
+		// daily loss is capped in reputation.EvaluateScoreEvents.
+		points := int(math.Round(reputation.PointsNotHelpfulBase * weight))
+		if points > -1 {
+			points = -1
+		}
+		return s.repository.AddReputationEvent(
+			ctx,
+			reviewAuthorID,
+			reputation.EventNotHelpfulReceived,
+			points,
+			reputation.SourceHelpfulVote,
+			voteID,
+			map[string]interface{}{"weight": weight},
+		)
+	}
+
 	// Reputation v1: each helpful vote gives +2 points scaled by voter weight.
 	points := int(math.Round(reputation.PointsHelpfulBase * weight))
 	if points < 1 {
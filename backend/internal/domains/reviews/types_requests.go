@@ -9,6 +9,8 @@ type PublishReviewRequest struct {
 	TasteTags []string            `json:"taste_tags"`
 	Summary   string              `json:"summary"`
 	Photos    []string            `json:"photos"`
+	// Aspects holds optional 1..5 scores keyed by model.ReviewAspects.
+	Aspects map[string]int `json:"aspects,omitempty"`
 }
 
 type UpdateReviewRequest struct {
@@ -19,11 +21,16 @@ type UpdateReviewRequest struct {
 	TasteTags *[]string            `json:"taste_tags"`
 	Summary   *string              `json:"summary"`
 	Photos    *[]string            `json:"photos"`
+	// Aspects replaces the aspect scores; an empty object clears them.
+	Aspects *map[string]int `json:"aspects"`
 }
 
 type ReviewPositionDTO struct {
 	DrinkID string `json:"drink_id"`
 	Drink   string `json:"drink"`
+	// Score is an optional 1..5 score of this drink; Note a short comment.
+	Score *int   `json:"score,omitempty"`
+	Note  string `json:"note,omitempty"`
 }
 
 type PresignReviewPhotoRequest struct {
@@ -78,6 +85,15 @@ type ReportAbuseRequest struct {
 	Details string `json:"details"`
 }
 
+type ReviewReplyRequest struct {
+	Body string `json:"body"`
+}
+
+type ReviewCommentRequest struct {
+	Body     string `json:"body"`
+	ParentID string `json:"parent_id,omitempty"`
+}
+
 type DeleteReviewRequest struct {
 	Reason  string `json:"reason"`
 	Details string `json:"details"`
@@ -2,6 +2,7 @@ package tags
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -48,6 +49,10 @@ func (h *Handler) GetDiscoveryDescriptive(c *gin.Context) {
 	defer cancel()
 
 	result, err := h.service.GetDiscoveryDescriptiveTags(ctx, scope, userID, limit)
+	if errors.Is(err, ErrCityNotFound) {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+		return
+	}
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить теги для главной.", nil)
 		return
@@ -67,6 +72,10 @@ func (h *Handler) GetDescriptiveOptions(c *gin.Context) {
 	defer cancel()
 
 	result, err := h.service.ListDescriptiveTagOptions(ctx, scope, search, limit)
+	if errors.Is(err, ErrCityNotFound) {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+		return
+	}
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось загрузить варианты тегов.", nil)
 		return
@@ -125,6 +134,10 @@ func (h *Handler) PutMyDescriptivePreferences(c *gin.Context) {
 		scope,
 		req.Tags,
 	)
+	if errors.Is(err, ErrCityNotFound) {
+		httpx.RespondError(c, http.StatusNotFound, "not_found", "Город не найден.", nil)
+		return
+	}
 	if err != nil {
 		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось сохранить любимые теги.", nil)
 		return
@@ -132,10 +145,21 @@ func (h *Handler) PutMyDescriptivePreferences(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// readGeoScope reads lat/lng/radius_m and city. The circle may be omitted
+// when a city is given.
 func readGeoScope(c *gin.Context) (GeoScope, bool) {
+	city, err := validation.ParseCitySlug(c.Query("city"))
+	if err != nil {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", err.Error(), nil)
+		return GeoScope{}, false
+	}
+
 	latStr := strings.TrimSpace(c.Query("lat"))
 	lngStr := strings.TrimSpace(c.Query("lng"))
 	radiusStr := strings.TrimSpace(c.Query("radius_m"))
+	if city != "" && latStr == "" && lngStr == "" && radiusStr == "" {
+		return GeoScope{City: city}, true
+	}
 	if latStr == "" || lngStr == "" || radiusStr == "" {
 		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Параметры lat, lng и radius_m обязательны.", nil)
 		return GeoScope{}, false
@@ -169,6 +193,7 @@ func readGeoScope(c *gin.Context) (GeoScope, bool) {
 		Latitude:  lat,
 		Longitude: lng,
 		RadiusM:   radiusM,
+		City:      city,
 	}, true
 }
 
@@ -36,7 +36,9 @@ func (r *Repository) ListPopularDescriptiveTags(
 	cross join center
 	cross join lateral jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
 	where c.geog is not null
+	  and c.status in ('active', 'temporarily_closed')
 	  and ($3::double precision <= 0 OR ST_DWithin(c.geog, center.p, $3::double precision))
+	  and ($5::text = '' OR c.city_id = (select id from public.cities where slug = $5::text))
 	  and trim(coalesce(tag->>'label', '')) <> ''
 )
 select
@@ -49,7 +51,7 @@ group by tag_key
 order by cafes_count desc, avg_weight desc, tag_key asc
 limit $4::int`
 
-	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, limit)
+	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, limit, scope.City)
 	if err != nil {
 		return nil, err
 	}
@@ -91,7 +93,9 @@ func (r *Repository) ListDescriptiveTagOptions(
 	cross join center
 	cross join lateral jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
 	where c.geog is not null
+	  and c.status in ('active', 'temporarily_closed')
 	  and ($3::double precision <= 0 OR ST_DWithin(c.geog, center.p, $3::double precision))
+	  and ($6::text = '' OR c.city_id = (select id from public.cities where slug = $6::text))
 	  and trim(coalesce(tag->>'label', '')) <> ''
 ), ranked as (
 	select
@@ -107,7 +111,7 @@ where ($4::text = '' or tag_key like '%' || lower($4::text) || '%')
 order by cafes_count desc, tag_label asc
 limit $5::int`
 
-	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, search, limit)
+	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, search, limit, scope.City)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +156,9 @@ func (r *Repository) ListExistingDescriptiveTagLabels(
 	cross join center
 	cross join lateral jsonb_array_elements(coalesce(crs.components->'descriptive_tags', '[]'::jsonb)) as tag
 	where c.geog is not null
+	  and c.status in ('active', 'temporarily_closed')
 	  and ($3::double precision <= 0 OR ST_DWithin(c.geog, center.p, $3::double precision))
+	  and ($5::text = '' OR c.city_id = (select id from public.cities where slug = $5::text))
 	  and trim(coalesce(tag->>'label', '')) <> ''
 )
 select
@@ -162,7 +168,7 @@ from tags
 where tag_key = any($4::text[])
 group by tag_key`
 
-	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, keys)
+	rows, err := r.pool.Query(ctx, query, scope.Latitude, scope.Longitude, scope.RadiusM, keys, scope.City)
 	if err != nil {
 		return nil, err
 	}
@@ -283,3 +289,13 @@ where user_id = $1::uuid
 
 	return tx.Commit(ctx)
 }
+
+func (r *Repository) CityExists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(
+		ctx,
+		`select exists(select 1 from public.cities where slug = $1)`,
+		slug,
+	).Scan(&exists)
+	return exists, err
+}
@@ -2,10 +2,15 @@ package tags
 
 import (
 	"context"
+	"errors"
 	"strings"
 )
 
+// ErrCityNotFound is returned for a scope whose city matches no city.
+var ErrCityNotFound = errors.New("city not found")
+
 type repository interface {
+	CityExists(ctx context.Context, slug string) (bool, error)
 	ListPopularDescriptiveTags(ctx context.Context, scope GeoScope, limit int) ([]popularTagRow, error)
 	ListDescriptiveTagOptions(ctx context.Context, scope GeoScope, search string, limit int) ([]string, error)
 	ListExistingDescriptiveTagLabels(ctx context.Context, scope GeoScope, keys []string) (map[string]string, error)
@@ -21,12 +26,31 @@ func NewService(repository repository) *Service {
 	return &Service{repository: repository}
 }
 
+// requireCity resolves the scope's city, so an unknown slug is a 404 rather
+// than an empty tag list.
+func (s *Service) requireCity(ctx context.Context, scope GeoScope) error {
+	if scope.City == "" {
+		return nil
+	}
+	exists, err := s.repository.CityExists(ctx, scope.City)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrCityNotFound
+	}
+	return nil
+}
+
 func (s *Service) GetDiscoveryDescriptiveTags(
 	ctx context.Context,
 	scope GeoScope,
 	_ *string,
 	limit int,
 ) (DiscoveryResponse, error) {
+	if err := s.requireCity(ctx, scope); err != nil {
+		return DiscoveryResponse{}, err
+	}
 	limit = normalizeLimit(limit, DefaultLimit, MaxLimit)
 
 	popular, err := s.repository.ListPopularDescriptiveTags(ctx, scope, limit)
@@ -57,6 +81,9 @@ func (s *Service) ListDescriptiveTagOptions(
 	search string,
 	limit int,
 ) (OptionsResponse, error) {
+	if err := s.requireCity(ctx, scope); err != nil {
+		return OptionsResponse{}, err
+	}
 	limit = normalizeLimit(limit, DefaultOptionsLimit, MaxOptionsLimit)
 	search = strings.TrimSpace(search)
 	options, err := s.repository.ListDescriptiveTagOptions(ctx, scope, search, limit)
@@ -86,6 +113,9 @@ func (s *Service) ReplaceUserDescriptivePreferences(
 	scope GeoScope,
 	tags []string,
 ) (PreferencesResponse, error) {
+	if err := s.requireCity(ctx, scope); err != nil {
+		return PreferencesResponse{}, err
+	}
 	normalized := normalizeTagLabels(tags, MaxPreferenceTags)
 	if len(normalized) == 0 {
 		if err := s.repository.ReplaceUserPreferences(ctx, userID, CategoryDescriptive, []string{}); err != nil {
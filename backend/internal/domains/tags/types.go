@@ -12,10 +12,13 @@ const (
 	MaxPreferenceTags = 12
 )
 
+// GeoScope limits tag statistics to a circle, a city (by slug) or both. A
+// zero RadiusM or an empty City does not restrict.
 type GeoScope struct {
 	Latitude  float64
 	Longitude float64
 	RadiusM   float64
+	City      string
 }
 
 type DiscoveryTag struct {
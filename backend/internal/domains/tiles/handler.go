@@ -0,0 +1,105 @@
+package tiles
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/shared/httpx"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const mvtContentType = "application/vnd.mapbox-vector-tile"
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func NewDefaultHandler(pool *pgxpool.Pool, mediaCfg config.MediaConfig) *Handler {
+	repository := NewRepository(pool)
+	service := NewService(repository, mediaCfg)
+	return NewHandler(service)
+}
+
+// GetCafesTile serves GET /api/tiles/cafes/:z/:x/:y where y carries the
+// ".mvt" suffix.
+func (h *Handler) GetCafesTile(c *gin.Context) {
+	z, x, y, ok := parseTileCoords(c.Param("z"), c.Param("x"), c.Param("y"))
+	if !ok {
+		httpx.RespondError(c, http.StatusBadRequest, "invalid_argument", "Некорректные координаты тайла.", nil)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	etag, err := h.service.CafesETag(ctx)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Внутренняя ошибка сервера.", nil)
+		return
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "public, max-age=60")
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	tile, err := h.service.CafesTile(ctx, z, x, y)
+	if err != nil {
+		httpx.RespondError(c, http.StatusInternalServerError, "internal", "Не удалось построить тайл.", nil)
+		return
+	}
+	if len(tile) == 0 {
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.Data(http.StatusOK, mvtContentType, tile)
+}
+
+func parseTileCoords(rawZ, rawX, rawY string) (int, int, int, bool) {
+	rawY = strings.TrimSuffix(strings.TrimSpace(rawY), ".mvt")
+	z, err := strconv.Atoi(strings.TrimSpace(rawZ))
+	if err != nil || z < 0 || z > MaxZoom {
+		return 0, 0, 0, false
+	}
+	x, err := strconv.Atoi(strings.TrimSpace(rawX))
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	y, err := strconv.Atoi(rawY)
+	if err != nil {
+		return 0, 0, 0, false
+	}
+	size := 1 << z
+	if x < 0 || x >= size || y < 0 || y >= size {
+		return 0, 0, 0, false
+	}
+	return z, x, y, true
+}
+
+func etagMatches(header, etag string) bool {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return false
+	}
+	if header == "*" {
+		return true
+	}
+	normalized := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == normalized {
+			return true
+		}
+	}
+	return false
+}
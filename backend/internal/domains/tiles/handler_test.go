@@ -0,0 +1,42 @@
+package tiles
+
+import "testing"
+
+func TestParseTileCoords(t *testing.T) {
+	t.Parallel()
+
+	z, x, y, ok := parseTileCoords("12", "2475", "1282.mvt")
+	if !ok || z != 12 || x != 2475 || y != 1282 {
+		t.Fatalf("unexpected parse result: %d/%d/%d ok=%v", z, x, y, ok)
+	}
+
+	invalid := [][3]string{
+		{"-1", "0", "0.mvt"},
+		{"23", "0", "0.mvt"},
+		{"2", "4", "0.mvt"},
+		{"2", "0", "abc.mvt"},
+	}
+	for _, coords := range invalid {
+		if _, _, _, ok := parseTileCoords(coords[0], coords[1], coords[2]); ok {
+			t.Fatalf("expected %v to be rejected", coords)
+		}
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	t.Parallel()
+
+	etag := `W/"cafes-v42"`
+	if !etagMatches(`"cafes-v41", W/"cafes-v42"`, etag) {
+		t.Fatal("expected weak etag to match")
+	}
+	if !etagMatches(`"cafes-v42"`, etag) {
+		t.Fatal("expected strong form to match weakly")
+	}
+	if etagMatches(`"cafes-v41"`, etag) {
+		t.Fatal("expected stale etag not to match")
+	}
+	if etagMatches("", etag) {
+		t.Fatal("expected empty header not to match")
+	}
+}
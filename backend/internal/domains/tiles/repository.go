@@ -0,0 +1,72 @@
+package tiles
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Repository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRepository(pool *pgxpool.Pool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// CafesVersion returns the sum of the counters bumped by triggers whenever
+// a cafe, its rating snapshot or its photos change.
+func (r *Repository) CafesVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := r.pool.QueryRow(
+		ctx,
+		`select coalesce(sum(version), 0)::bigint from public.cafe_tiles_version_slots`,
+	).Scan(&version)
+	return version, err
+}
+
+func (r *Repository) CafesTile(ctx context.Context, z, x, y int, photoURLPrefix string) ([]byte, error) {
+	const query = `with bounds as (
+  select ST_TileEnvelope($1::int, $2::int, $3::int) as geom
+),
+covers as (
+  select distinct on (cafe_id)
+    cafe_id,
+    object_key
+  from public.cafe_photos
+  where kind = 'cafe'
+  order by cafe_id asc, is_cover desc, position asc, created_at asc
+),
+features as (
+  select
+    ST_AsMVTGeom(ST_Transform(c.geog::geometry, 3857), bounds.geom, $5::int, $6::int, true) as geom,
+    c.id::text as id,
+    c.name,
+    coalesce(crs.rating, 0)::double precision as rating,
+    coalesce(crs.reviews_count, 0) as reviews_count,
+    array_to_string(coalesce(c.amenities, '{}'::text[]), ',') as amenities,
+    c.status,
+    case
+      when cv.object_key is null then null
+      when cv.object_key ~ '^https?://' then cv.object_key
+      else $4::text || ltrim(cv.object_key, '/')
+    end as cover_photo_url
+  from public.cafes c
+  cross join bounds
+  left join public.cafe_rating_snapshots crs on crs.cafe_id = c.id
+  left join covers cv on cv.cafe_id = c.id
+  where c.geog is not null
+    and c.status in ('active', 'temporarily_closed')
+    and c.geog && ST_Transform(bounds.geom, 4326)::geography
+)
+select coalesce(ST_AsMVT(features.*, 'cafes', $5::int, 'geom'), ''::bytea)
+from features
+where geom is not null`
+
+	var tile []byte
+	err := r.pool.QueryRow(ctx, query, z, x, y, photoURLPrefix, tileExtent, tileBuffer).Scan(&tile)
+	if err != nil {
+		return nil, err
+	}
+	return tile, nil
+}
@@ -0,0 +1,43 @@
+package tiles
+
+import (
+	"context"
+	"fmt"
+
+	"backend/internal/config"
+	"backend/internal/domains/photos"
+)
+
+const (
+	MaxZoom = 22
+
+	tileExtent = 4096
+	tileBuffer = 64
+)
+
+type Service struct {
+	repository *Repository
+	mediaCfg   config.MediaConfig
+}
+
+func NewService(repository *Repository, mediaCfg config.MediaConfig) *Service {
+	return &Service{
+		repository: repository,
+		mediaCfg:   mediaCfg,
+	}
+}
+
+// CafesETag identifies the current content of every cafe tile. Tiles share
+// one version so a single cheap lookup answers conditional requests without
+// rendering the tile.
+func (s *Service) CafesETag(ctx context.Context) (string, error) {
+	version, err := s.repository.CafesVersion(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`W/"cafes-v%d"`, version), nil
+}
+
+func (s *Service) CafesTile(ctx context.Context, z, x, y int) ([]byte, error) {
+	return s.repository.CafesTile(ctx, z, x, y, photos.PhotoURLPrefix(s.mediaCfg))
+}
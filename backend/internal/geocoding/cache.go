@@ -0,0 +1,93 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// cachePurgeInterval is how often a write also drops expired entries.
+const cachePurgeInterval = time.Hour
+
+// Cache stores answers by kind and normalized key. Misses are cached too
+// (Found: false), with their own TTL.
+type Cache interface {
+	Get(ctx context.Context, kind, key string) (Result, bool, error)
+	Set(ctx context.Context, kind, key string, result Result, ttl time.Duration) error
+}
+
+// PostgresCache keeps answers in geocode_cache, so they are shared between
+// instances and survive restarts.
+type PostgresCache struct {
+	pool       *pgxpool.Pool
+	lastPurge  atomic.Int64
+	purgeEvery time.Duration
+}
+
+func NewPostgresCache(pool *pgxpool.Pool) *PostgresCache {
+	return &PostgresCache{pool: pool, purgeEvery: cachePurgeInterval}
+}
+
+func (c *PostgresCache) Get(ctx context.Context, kind, key string) (Result, bool, error) {
+	var raw []byte
+	err := c.pool.QueryRow(
+		ctx,
+		`select result
+		   from public.geocode_cache
+		  where kind = $1 and query_key = $2 and expires_at > now()`,
+		kind,
+		key,
+	).Scan(&raw)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Result{}, false, nil
+	}
+	if err != nil {
+		return Result{}, false, err
+	}
+	var result Result
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return Result{}, false, err
+	}
+	return result, true, nil
+}
+
+func (c *PostgresCache) Set(ctx context.Context, kind, key string, result Result, ttl time.Duration) error {
+	result.Cached = false
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	if _, err := c.pool.Exec(
+		ctx,
+		`insert into public.geocode_cache (kind, query_key, result, expires_at)
+		 values ($1, $2, $3::jsonb, now() + make_interval(secs => $4::float8))
+		 on conflict (kind, query_key) do update
+		    set result = excluded.result,
+		        expires_at = excluded.expires_at,
+		        created_at = now()`,
+		kind,
+		key,
+		raw,
+		ttl.Seconds(),
+	); err != nil {
+		return err
+	}
+	c.purgeExpired(ctx)
+	return nil
+}
+
+// purgeExpired deletes expired entries at most once per purgeEvery per
+// instance; a failure is left for the next write.
+func (c *PostgresCache) purgeExpired(ctx context.Context) {
+	now := time.Now().UnixNano()
+	last := c.lastPurge.Load()
+	if now-last < int64(c.purgeEvery) || !c.lastPurge.CompareAndSwap(last, now) {
+		return
+	}
+	_, _ = c.pool.Exec(ctx, `delete from public.geocode_cache where expires_at <= now()`)
+}
@@ -0,0 +1,106 @@
+package geocoding
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+
+	"backend/internal/config"
+)
+
+// fakeReverseRadiusM is how far from a place a reverse lookup still finds it.
+const fakeReverseRadiusM = 100.0
+
+func init() {
+	// GEOCODING_PROVIDERS=fake runs without network access; it knows no
+	// places, so every lookup is a miss.
+	Register("fake", func(config.GeocodingConfig, *http.Client) (Geocoder, bool) {
+		return NewFake(), true
+	})
+}
+
+type FakePlace struct {
+	Address   string
+	Latitude  float64
+	Longitude float64
+}
+
+// Fake is an in-memory provider for tests: Geocode matches an address
+// ignoring case and whitespace, Reverse returns the nearest place within
+// 100 m. Err, when set, is returned by every call.
+type Fake struct {
+	mu     sync.Mutex
+	places []FakePlace
+	calls  int
+	Err    error
+}
+
+func NewFake(places ...FakePlace) *Fake {
+	return &Fake{places: places}
+}
+
+func (f *Fake) Name() string {
+	return "fake"
+}
+
+// Calls is the number of lookups that reached the provider.
+func (f *Fake) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func (f *Fake) Geocode(_ context.Context, query string) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.Err != nil {
+		return Result{}, f.Err
+	}
+	key := normalizeQuery(query)
+	for _, place := range f.places {
+		if normalizeQuery(place.Address) == key {
+			return place.result(), nil
+		}
+	}
+	return Result{}, nil
+}
+
+func (f *Fake) Reverse(_ context.Context, lat, lng float64) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.Err != nil {
+		return Result{}, f.Err
+	}
+	best, bestDistance := -1, fakeReverseRadiusM
+	for i, place := range f.places {
+		if distance := approxDistanceM(lat, lng, place.Latitude, place.Longitude); distance <= bestDistance {
+			best, bestDistance = i, distance
+		}
+	}
+	if best < 0 {
+		return Result{}, nil
+	}
+	return f.places[best].result(), nil
+}
+
+func (p FakePlace) result() Result {
+	return Result{
+		Found:       true,
+		Latitude:    p.Latitude,
+		Longitude:   p.Longitude,
+		Address:     p.Address,
+		DisplayName: p.Address,
+	}
+}
+
+// approxDistanceM is an equirectangular approximation, exact enough for the
+// short distances Fake compares.
+func approxDistanceM(lat1, lng1, lat2, lng2 float64) float64 {
+	const metresPerDegree = 111320.0
+	dLat := lat2 - lat1
+	dLng := (lng2 - lng1) * math.Cos((lat1+lat2)/2*math.Pi/180)
+	return math.Hypot(dLat, dLng) * metresPerDegree
+}
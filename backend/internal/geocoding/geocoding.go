@@ -0,0 +1,213 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/config"
+)
+
+const (
+	KindForward = "forward"
+	KindReverse = "reverse"
+)
+
+var (
+	ErrRateLimited = errors.New("geocoding provider rate limit exceeded")
+	ErrNoProviders = errors.New("no geocoding provider is configured")
+)
+
+// Result is a geocoding answer. Address is the short street address
+// ("Невский проспект, 10"); DisplayName is the provider's full label.
+type Result struct {
+	Found       bool    `json:"found"`
+	Latitude    float64 `json:"latitude,omitempty"`
+	Longitude   float64 `json:"longitude,omitempty"`
+	Address     string  `json:"address,omitempty"`
+	DisplayName string  `json:"display_name,omitempty"`
+	Provider    string  `json:"provider,omitempty"`
+	Cached      bool    `json:"cached,omitempty"`
+}
+
+// Geocoder is one geocoding backend. A miss is Result{Found: false} with a
+// nil error; errors mean the provider could not answer.
+type Geocoder interface {
+	Name() string
+	Geocode(ctx context.Context, query string) (Result, error)
+	Reverse(ctx context.Context, lat, lng float64) (Result, error)
+}
+
+// Factory builds a registered provider from config. ok is false when the
+// provider is not configured (e.g. no API key) and should be skipped.
+type Factory func(cfg config.GeocodingConfig, client *http.Client) (provider Geocoder, ok bool)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register makes a provider available to GEOCODING_PROVIDERS. It panics on a
+// duplicate name, like database/sql drivers.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	name = strings.ToLower(strings.TrimSpace(name))
+	if _, dup := registry[name]; dup {
+		panic("geocoding: provider registered twice: " + name)
+	}
+	registry[name] = factory
+}
+
+// Providers lists registered provider names.
+func Providers() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type Options struct {
+	CacheTTL         time.Duration
+	NegativeCacheTTL time.Duration
+	// RateLimits are requests per second by provider name.
+	RateLimits map[string]float64
+}
+
+// Service asks providers in order and caches the answers.
+type Service struct {
+	providers []Geocoder
+	cache     Cache
+	opts      Options
+}
+
+// New builds the provider chain from cfg.Providers. cache may be nil.
+func New(cfg config.GeocodingConfig, cache Cache) (*Service, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+	providers := make([]Geocoder, 0, len(cfg.Providers))
+	for _, name := range cfg.Providers {
+		registryMu.RLock()
+		factory, ok := registry[name]
+		registryMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unknown geocoding provider %q (registered: %s)", name, strings.Join(Providers(), ", "))
+		}
+		if provider, enabled := factory(cfg, client); enabled {
+			providers = append(providers, provider)
+		}
+	}
+	if len(providers) == 0 {
+		return nil, ErrNoProviders
+	}
+	return NewService(cache, Options{
+		CacheTTL:         cfg.CacheTTL,
+		NegativeCacheTTL: cfg.NegativeCacheTTL,
+		RateLimits:       cfg.RateLimits,
+	}, providers...), nil
+}
+
+// NewService wires explicit providers; tests use it with Fake.
+func NewService(cache Cache, opts Options, providers ...Geocoder) *Service {
+	limited := make([]Geocoder, 0, len(providers))
+	for _, provider := range providers {
+		if rps := opts.RateLimits[provider.Name()]; rps > 0 {
+			provider = newRateLimited(provider, rps)
+		}
+		limited = append(limited, provider)
+	}
+	return &Service{providers: limited, cache: cache, opts: opts}
+}
+
+// Geocode resolves an address to coordinates.
+func (s *Service) Geocode(ctx context.Context, query string) (Result, error) {
+	key := normalizeQuery(query)
+	if key == "" {
+		return Result{}, nil
+	}
+	return s.resolve(ctx, KindForward, key, func(provider Geocoder) (Result, error) {
+		return provider.Geocode(ctx, strings.TrimSpace(query))
+	})
+}
+
+// Reverse resolves coordinates to an address.
+func (s *Service) Reverse(ctx context.Context, lat, lng float64) (Result, error) {
+	return s.resolve(ctx, KindReverse, reverseKey(lat, lng), func(provider Geocoder) (Result, error) {
+		return provider.Reverse(ctx, lat, lng)
+	})
+}
+
+// resolve returns the first hit of the chain. A miss is reported (and
+// cached) only when every provider answered; if some failed, the miss may be
+// theirs and the errors are returned when nobody answered at all.
+func (s *Service) resolve(ctx context.Context, kind, key string, ask func(Geocoder) (Result, error)) (Result, error) {
+	if s.cache != nil {
+		cached, ok, err := s.cache.Get(ctx, kind, key)
+		if err != nil {
+			slog.Warn("geocoding cache read failed", "kind", kind, "error", err)
+		} else if ok {
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+
+	var (
+		errs     []error
+		answered bool
+	)
+	for _, provider := range s.providers {
+		result, err := ask(provider)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+			continue
+		}
+		answered = true
+		if result.Found {
+			result.Provider = provider.Name()
+			s.store(ctx, kind, key, result, s.opts.CacheTTL)
+			return result, nil
+		}
+	}
+	if !answered {
+		return Result{}, errors.Join(errs...)
+	}
+	if len(errs) == 0 {
+		s.store(ctx, kind, key, Result{}, s.opts.NegativeCacheTTL)
+	}
+	return Result{}, nil
+}
+
+func (s *Service) store(ctx context.Context, kind, key string, result Result, ttl time.Duration) {
+	if s.cache == nil || ttl <= 0 {
+		return
+	}
+	if err := s.cache.Set(ctx, kind, key, result, ttl); err != nil {
+		slog.Warn("geocoding cache write failed", "kind", kind, "error", err)
+	}
+}
+
+// normalizeQuery folds case and whitespace so trivially different spellings
+// share a cache entry.
+func normalizeQuery(query string) string {
+	return strings.ToLower(strings.Join(strings.Fields(query), " "))
+}
+
+// reverseKey rounds to 5 decimals (about a metre), well below the precision
+// of a street address.
+func reverseKey(lat, lng float64) string {
+	round := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*1e5)/1e5, 'f', 5, 64)
+	}
+	return round(lat) + "," + round(lng)
+}
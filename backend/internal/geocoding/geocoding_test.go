@@ -0,0 +1,179 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+)
+
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]Result
+	ttls    map[string]time.Duration
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{entries: map[string]Result{}, ttls: map[string]time.Duration{}}
+}
+
+func (c *memoryCache) Get(_ context.Context, kind, key string) (Result, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	result, ok := c.entries[kind+"|"+key]
+	return result, ok, nil
+}
+
+func (c *memoryCache) Set(_ context.Context, kind, key string, result Result, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[kind+"|"+key] = result
+	c.ttls[kind+"|"+key] = ttl
+	return nil
+}
+
+// namedFake lets a test put two fakes in one chain.
+type namedFake struct {
+	*Fake
+	name string
+}
+
+func (f namedFake) Name() string {
+	return f.name
+}
+
+var nevsky = FakePlace{Address: "Невский проспект, 28", Latitude: 59.935739, Longitude: 30.325907}
+
+func testOptions() Options {
+	return Options{CacheTTL: time.Hour, NegativeCacheTTL: time.Minute}
+}
+
+func TestServiceFallsThroughChain(t *testing.T) {
+	t.Parallel()
+
+	failing := NewFake()
+	failing.Err = errors.New("boom")
+	empty := NewFake()
+	hit := NewFake(nevsky)
+	service := NewService(nil, testOptions(),
+		namedFake{failing, "first"},
+		namedFake{empty, "second"},
+		namedFake{hit, "third"},
+	)
+
+	result, err := service.Geocode(context.Background(), "  невский ПРОСПЕКТ,   28 ")
+	if err != nil {
+		t.Fatalf("Geocode: %v", err)
+	}
+	if !result.Found || result.Provider != "third" || result.Address != nevsky.Address {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if failing.Calls() != 1 || empty.Calls() != 1 || hit.Calls() != 1 {
+		t.Fatalf("calls: %d %d %d", failing.Calls(), empty.Calls(), hit.Calls())
+	}
+}
+
+func TestServiceCachesHitsAndMisses(t *testing.T) {
+	t.Parallel()
+
+	cache := newMemoryCache()
+	provider := NewFake(nevsky)
+	service := NewService(cache, testOptions(), provider)
+	ctx := context.Background()
+
+	first, err := service.Reverse(ctx, 59.93574, 30.32591)
+	if err != nil || !first.Found || first.Cached {
+		t.Fatalf("first reverse: %+v, %v", first, err)
+	}
+	second, err := service.Reverse(ctx, 59.935741, 30.325911)
+	if err != nil || !second.Found || !second.Cached || second.Address != nevsky.Address {
+		t.Fatalf("second reverse: %+v, %v", second, err)
+	}
+	if provider.Calls() != 1 {
+		t.Fatalf("expected the second lookup to be served from cache, calls=%d", provider.Calls())
+	}
+
+	miss, err := service.Geocode(ctx, "Нигде, 0")
+	if err != nil || miss.Found {
+		t.Fatalf("miss: %+v, %v", miss, err)
+	}
+	if ttl := cache.ttls[KindForward+"|нигде, 0"]; ttl != time.Minute {
+		t.Fatalf("miss cached with ttl %s, want the negative ttl", ttl)
+	}
+	if _, err := service.Geocode(ctx, "нигде,  0"); err != nil || provider.Calls() != 2 {
+		t.Fatalf("cached miss reached the provider: calls=%d err=%v", provider.Calls(), err)
+	}
+}
+
+func TestServiceDoesNotCacheMissWhenProviderFailed(t *testing.T) {
+	t.Parallel()
+
+	cache := newMemoryCache()
+	failing := NewFake()
+	failing.Err = errors.New("timeout")
+	service := NewService(cache, testOptions(), namedFake{failing, "flaky"}, namedFake{NewFake(), "empty"})
+
+	result, err := service.Geocode(context.Background(), "Нигде, 0")
+	if err != nil || result.Found {
+		t.Fatalf("expected a plain miss, got %+v, %v", result, err)
+	}
+	if len(cache.entries) != 0 {
+		t.Fatalf("miss was cached although a provider failed: %+v", cache.entries)
+	}
+}
+
+func TestServiceReturnsErrorWhenNobodyAnswered(t *testing.T) {
+	t.Parallel()
+
+	failing := NewFake()
+	failing.Err = errors.New("timeout")
+	service := NewService(newMemoryCache(), testOptions(), failing)
+
+	_, err := service.Reverse(context.Background(), 1, 1)
+	if err == nil || !strings.Contains(err.Error(), "fake: timeout") {
+		t.Fatalf("expected provider error, got %v", err)
+	}
+}
+
+func TestFakeReverseNearestWithinRadius(t *testing.T) {
+	t.Parallel()
+
+	near := FakePlace{Address: "Невский проспект, 30", Latitude: 59.93560, Longitude: 30.32700}
+	fake := NewFake(nevsky, near)
+
+	result, err := fake.Reverse(context.Background(), 59.93562, 30.32690)
+	if err != nil || result.Address != near.Address {
+		t.Fatalf("expected nearest place, got %+v, %v", result, err)
+	}
+	result, err = fake.Reverse(context.Background(), 59.94, 30.33)
+	if err != nil || result.Found {
+		t.Fatalf("expected a miss outside the radius, got %+v, %v", result, err)
+	}
+}
+
+func TestCacheKeys(t *testing.T) {
+	t.Parallel()
+
+	if got := normalizeQuery("  Москва,\tТверская   7 "); got != "москва, тверская 7" {
+		t.Fatalf("normalizeQuery = %q", got)
+	}
+	if got := reverseKey(55.7575849, 37.6136721); got != "55.75758,37.61367" {
+		t.Fatalf("reverseKey = %q", got)
+	}
+}
+
+func TestNewRejectsUnknownProvider(t *testing.T) {
+	t.Parallel()
+
+	_, err := New(config.GeocodingConfig{Providers: []string{"fake", "missing"}}, nil)
+	if err == nil || !strings.Contains(err.Error(), `"missing"`) {
+		t.Fatalf("expected unknown provider error, got %v", err)
+	}
+	if _, err := New(config.GeocodingConfig{Providers: []string{"yandex"}}, nil); !errors.Is(err, ErrNoProviders) {
+		t.Fatalf("yandex without a key should leave no providers, got %v", err)
+	}
+}
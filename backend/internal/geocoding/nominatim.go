@@ -0,0 +1,139 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+)
+
+const nominatimBaseURL = "https://nominatim.openstreetmap.org"
+
+func init() {
+	Register("nominatim", func(cfg config.GeocodingConfig, client *http.Client) (Geocoder, bool) {
+		baseURL := strings.TrimRight(strings.TrimSpace(cfg.NominatimBaseURL), "/")
+		if baseURL == "" {
+			baseURL = nominatimBaseURL
+		}
+		return &nominatimGeocoder{
+			baseURL:   baseURL,
+			userAgent: strings.TrimSpace(cfg.UserAgent),
+			client:    client,
+		}, true
+	})
+}
+
+type nominatimGeocoder struct {
+	baseURL   string
+	userAgent string
+	client    *http.Client
+}
+
+type nominatimPlace struct {
+	Lat         string `json:"lat"`
+	Lon         string `json:"lon"`
+	DisplayName string `json:"display_name"`
+	Error       string `json:"error"`
+	Address     struct {
+		Road        string `json:"road"`
+		Pedestrian  string `json:"pedestrian"`
+		HouseNumber string `json:"house_number"`
+	} `json:"address"`
+}
+
+func (g *nominatimGeocoder) Name() string {
+	return "nominatim"
+}
+
+func (g *nominatimGeocoder) Geocode(ctx context.Context, query string) (Result, error) {
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("format", "jsonv2")
+	params.Set("limit", "1")
+	params.Set("addressdetails", "1")
+	params.Set("accept-language", "ru")
+
+	var places []nominatimPlace
+	if err := g.get(ctx, "/search", params, &places); err != nil {
+		return Result{}, err
+	}
+	if len(places) == 0 {
+		return Result{}, nil
+	}
+	return places[0].result()
+}
+
+func (g *nominatimGeocoder) Reverse(ctx context.Context, lat, lng float64) (Result, error) {
+	params := url.Values{}
+	params.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Set("lon", strconv.FormatFloat(lng, 'f', -1, 64))
+	params.Set("format", "jsonv2")
+	params.Set("zoom", "18")
+	params.Set("addressdetails", "1")
+	params.Set("accept-language", "ru")
+
+	var place nominatimPlace
+	if err := g.get(ctx, "/reverse", params, &place); err != nil {
+		return Result{}, err
+	}
+	// Nominatim answers 200 {"error":"Unable to geocode"} for empty spots.
+	if place.Error != "" {
+		return Result{}, nil
+	}
+	return place.result()
+}
+
+func (g *nominatimGeocoder) get(ctx context.Context, path string, params url.Values, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("nominatim status=%d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func (p nominatimPlace) result() (Result, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(p.Lat), 64)
+	if err != nil {
+		return Result{}, err
+	}
+	lng, err := strconv.ParseFloat(strings.TrimSpace(p.Lon), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	street := strings.TrimSpace(p.Address.Road)
+	if street == "" {
+		street = strings.TrimSpace(p.Address.Pedestrian)
+	}
+	address := street
+	if house := strings.TrimSpace(p.Address.HouseNumber); street != "" && house != "" {
+		address = street + ", " + house
+	}
+
+	return Result{
+		Found:       true,
+		Latitude:    lat,
+		Longitude:   lng,
+		Address:     address,
+		DisplayName: strings.TrimSpace(p.DisplayName),
+	}, nil
+}
@@ -0,0 +1,84 @@
+package geocoding
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestYandexReverseParsesHouse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("geocode"); got != "30.325907,59.935739" {
+			t.Errorf("geocode param = %q, want lng,lat", got)
+		}
+		if r.URL.Query().Get("kind") != "house" || r.URL.Query().Get("apikey") != "key" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		_, _ = w.Write([]byte(`{"response":{"GeoObjectCollection":{"featureMember":[{"GeoObject":{
+			"name":"Невский проспект, 28",
+			"Point":{"pos":"30.325907 59.935739"},
+			"metaDataProperty":{"GeocoderMetaData":{"text":"Россия, Санкт-Петербург, Невский проспект, 28"}}
+		}}]}}}`))
+	}))
+	defer server.Close()
+
+	geocoder := &yandexGeocoder{baseURL: server.URL, apiKey: "key", client: server.Client()}
+	result, err := geocoder.Reverse(context.Background(), 59.935739, 30.325907)
+	if err != nil {
+		t.Fatalf("Reverse: %v", err)
+	}
+	if !result.Found || result.Address != "Невский проспект, 28" || result.Latitude != 59.935739 {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+}
+
+func TestNominatimReverse(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/reverse" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("lat") == "0" {
+			_, _ = w.Write([]byte(`{"error":"Unable to geocode"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{
+			"lat":"59.9357","lon":"30.3259",
+			"display_name":"28, Невский проспект, Санкт-Петербург, Россия",
+			"address":{"road":"Невский проспект","house_number":"28"}
+		}`))
+	}))
+	defer server.Close()
+
+	geocoder := &nominatimGeocoder{baseURL: server.URL, client: server.Client()}
+	result, err := geocoder.Reverse(context.Background(), 59.9357, 30.3259)
+	if err != nil {
+		t.Fatalf("Reverse: %v", err)
+	}
+	if !result.Found || result.Address != "Невский проспект, 28" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	miss, err := geocoder.Reverse(context.Background(), 0, 0)
+	if err != nil || miss.Found {
+		t.Fatalf("expected a miss, got %+v, %v", miss, err)
+	}
+}
+
+func TestNominatimStatusIsError(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	geocoder := &nominatimGeocoder{baseURL: server.URL, client: server.Client()}
+	if _, err := geocoder.Geocode(context.Background(), "Невский 28"); err == nil {
+		t.Fatal("expected an error for HTTP 429")
+	}
+}
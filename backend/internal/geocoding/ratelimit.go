@@ -0,0 +1,76 @@
+package geocoding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxRateLimitWait bounds how long a call without a deadline queues for its
+// slot; past it the provider is skipped rather than stalling the request.
+const maxRateLimitWait = 2 * time.Second
+
+// rateLimited spaces calls to a provider evenly at rps. Calls queue for the
+// next free slot; a call whose slot falls after its deadline fails with
+// ErrRateLimited without taking the slot, so the chain moves on.
+// The limit is per process: N backend instances together send up to N*rps,
+// so the configured rps must be the provider quota divided by the instance
+// count.
+type rateLimited struct {
+	Geocoder
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimited(provider Geocoder, rps float64) *rateLimited {
+	return &rateLimited{Geocoder: provider, interval: time.Duration(float64(time.Second) / rps)}
+}
+
+func (r *rateLimited) Geocode(ctx context.Context, query string) (Result, error) {
+	if err := r.wait(ctx); err != nil {
+		return Result{}, err
+	}
+	return r.Geocoder.Geocode(ctx, query)
+}
+
+func (r *rateLimited) Reverse(ctx context.Context, lat, lng float64) (Result, error) {
+	if err := r.wait(ctx); err != nil {
+		return Result{}, err
+	}
+	return r.Geocoder.Reverse(ctx, lat, lng)
+}
+
+func (r *rateLimited) wait(ctx context.Context) error {
+	now := time.Now()
+	deadline, ok := ctx.Deadline()
+	if !ok || deadline.After(now.Add(maxRateLimitWait)) {
+		deadline = now.Add(maxRateLimitWait)
+	}
+
+	r.mu.Lock()
+	slot := r.next
+	if slot.Before(now) {
+		slot = now
+	}
+	if slot.After(deadline) {
+		r.mu.Unlock()
+		return ErrRateLimited
+	}
+	r.next = slot.Add(r.interval)
+	r.mu.Unlock()
+
+	delay := slot.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRateLimitedSkipsWhenSlotIsPastDeadline(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFake(nevsky)
+	limited := newRateLimited(provider, 0.5) // one call per 2s
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	if _, err := limited.Geocode(ctx, nevsky.Address); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	started := time.Now()
+	if _, err := limited.Geocode(ctx, nevsky.Address); !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("expected ErrRateLimited, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 100*time.Millisecond {
+		t.Fatalf("rate limited call should fail fast, took %s", elapsed)
+	}
+	if provider.Calls() != 1 {
+		t.Fatalf("provider calls = %d, want 1", provider.Calls())
+	}
+}
+
+func TestRateLimitedSpacesCalls(t *testing.T) {
+	t.Parallel()
+
+	provider := NewFake(nevsky)
+	limited := newRateLimited(provider, 20) // 50ms apart
+
+	started := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := limited.Reverse(context.Background(), nevsky.Latitude, nevsky.Longitude); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Fatalf("three calls at 20 rps finished in %s", elapsed)
+	}
+}
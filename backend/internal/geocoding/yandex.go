@@ -0,0 +1,125 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"backend/internal/config"
+)
+
+const yandexBaseURL = "https://geocode-maps.yandex.ru/1.x/"
+
+func init() {
+	Register("yandex", func(cfg config.GeocodingConfig, client *http.Client) (Geocoder, bool) {
+		apiKey := strings.TrimSpace(cfg.YandexAPIKey)
+		if apiKey == "" {
+			return nil, false
+		}
+		return &yandexGeocoder{
+			baseURL:   yandexBaseURL,
+			apiKey:    apiKey,
+			userAgent: strings.TrimSpace(cfg.UserAgent),
+			client:    client,
+		}, true
+	})
+}
+
+type yandexGeocoder struct {
+	baseURL   string
+	apiKey    string
+	userAgent string
+	client    *http.Client
+}
+
+func (g *yandexGeocoder) Name() string {
+	return "yandex"
+}
+
+func (g *yandexGeocoder) Geocode(ctx context.Context, query string) (Result, error) {
+	return g.lookup(ctx, url.Values{"geocode": {query}})
+}
+
+// Reverse takes the nearest house; Yandex expects "lng,lat".
+func (g *yandexGeocoder) Reverse(ctx context.Context, lat, lng float64) (Result, error) {
+	point := strconv.FormatFloat(lng, 'f', -1, 64) + "," + strconv.FormatFloat(lat, 'f', -1, 64)
+	return g.lookup(ctx, url.Values{"geocode": {point}, "kind": {"house"}})
+}
+
+func (g *yandexGeocoder) lookup(ctx context.Context, params url.Values) (Result, error) {
+	params.Set("apikey", g.apiKey)
+	params.Set("format", "json")
+	params.Set("results", "1")
+	params.Set("lang", "ru_RU")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return Result{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if g.userAgent != "" {
+		req.Header.Set("User-Agent", g.userAgent)
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return Result{}, fmt.Errorf("yandex geocoder status=%d", resp.StatusCode)
+	}
+
+	var payload struct {
+		Response struct {
+			GeoObjectCollection struct {
+				FeatureMember []struct {
+					GeoObject struct {
+						Name  string `json:"name"`
+						Point struct {
+							Pos string `json:"pos"`
+						} `json:"Point"`
+						MetaDataProperty struct {
+							GeocoderMetaData struct {
+								Text string `json:"text"`
+							} `json:"GeocoderMetaData"`
+						} `json:"metaDataProperty"`
+					} `json:"GeoObject"`
+				} `json:"featureMember"`
+			} `json:"GeoObjectCollection"`
+		} `json:"response"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return Result{}, err
+	}
+	if len(payload.Response.GeoObjectCollection.FeatureMember) == 0 {
+		return Result{}, nil
+	}
+
+	item := payload.Response.GeoObjectCollection.FeatureMember[0].GeoObject
+	coords := strings.Fields(strings.TrimSpace(item.Point.Pos))
+	if len(coords) != 2 {
+		return Result{}, nil
+	}
+	lng, err := strconv.ParseFloat(coords[0], 64)
+	if err != nil {
+		return Result{}, err
+	}
+	lat, err := strconv.ParseFloat(coords[1], 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return Result{
+		Found:       true,
+		Latitude:    lat,
+		Longitude:   lng,
+		Address:     strings.TrimSpace(item.Name),
+		DisplayName: strings.TrimSpace(item.MetaDataProperty.GeocoderMetaData.Text),
+	}, nil
+}
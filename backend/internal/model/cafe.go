@@ -1,5 +1,15 @@
 package model
 
+// Cafe lifecycle statuses (cafes.status). Temporarily closed cafes stay on
+// the map; permanently closed ones are reachable by link and search only;
+// deleted cafes are hidden everywhere except the admin panel.
+const (
+	CafeStatusActive            = "active"
+	CafeStatusTemporarilyClosed = "temporarily_closed"
+	CafeStatusPermanentlyClosed = "permanently_closed"
+	CafeStatusDeleted           = "deleted"
+)
+
 type Cafe struct {
 	ID        string   `json:"id"`
 	Name      string   `json:"name"`
@@ -20,6 +30,10 @@ type CafeResponse struct {
 	Amenities      []string            `json:"amenities"`
 	DistanceM      float64             `json:"distance_m"`
 	IsFavorite     bool                `json:"is_favorite"`
+	OpeningHours   *OpeningHours       `json:"opening_hours,omitempty"`
+	IsOpen         *bool               `json:"is_open,omitempty"`
+	Status         string              `json:"status,omitempty"`
+	ReopensOn      *string             `json:"reopens_on,omitempty"`
 	CoverPhotoURL  *string             `json:"cover_photo_url,omitempty"`
 	Photos         []CafePhotoResponse `json:"photos,omitempty"`
 }
@@ -0,0 +1,23 @@
+package model
+
+// OpeningHours describes a weekly schedule in the cafe's local timezone.
+// Weekly is keyed by mon..sun; an interval whose close is earlier than its
+// open runs past midnight. Exceptions override the weekly schedule for a
+// single date (holidays, short days).
+type OpeningHours struct {
+	Timezone   string                       `json:"timezone,omitempty"`
+	Weekly     map[string][]OpeningInterval `json:"weekly"`
+	Exceptions []OpeningHoursException      `json:"exceptions,omitempty"`
+}
+
+type OpeningInterval struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+type OpeningHoursException struct {
+	Date      string            `json:"date"`
+	Closed    bool              `json:"closed,omitempty"`
+	Intervals []OpeningInterval `json:"intervals,omitempty"`
+	Note      string            `json:"note,omitempty"`
+}
@@ -0,0 +1,38 @@
+package model
+
+// Review aspects (review_aspect_ratings.aspect) scored separately from the
+// overall review rating.
+const (
+	ReviewAspectCoffee     = "coffee"
+	ReviewAspectService    = "service"
+	ReviewAspectAtmosphere = "atmosphere"
+	ReviewAspectWorkspace  = "workspace"
+)
+
+// ReviewAspects lists the aspects in display order.
+var ReviewAspects = []string{
+	ReviewAspectCoffee,
+	ReviewAspectService,
+	ReviewAspectAtmosphere,
+	ReviewAspectWorkspace,
+}
+
+var reviewAspectLabels = map[string]string{
+	ReviewAspectCoffee:     "Кофе",
+	ReviewAspectService:    "Сервис",
+	ReviewAspectAtmosphere: "Атмосфера",
+	ReviewAspectWorkspace:  "Работа с ноутбуком",
+}
+
+func IsReviewAspect(aspect string) bool {
+	_, ok := reviewAspectLabels[aspect]
+	return ok
+}
+
+// ReviewAspectLabel is the Russian display name of an aspect.
+func ReviewAspectLabel(aspect string) string {
+	if label, ok := reviewAspectLabels[aspect]; ok {
+		return label
+	}
+	return aspect
+}
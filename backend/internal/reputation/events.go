@@ -2,6 +2,9 @@ package reputation
 
 const (
 	EventHelpfulReceived      = "helpful_received"
+	EventHelpfulRetracted     = "helpful_retracted"
+	EventNotHelpfulReceived   = "not_helpful_received"
+	EventNotHelpfulRetracted  = "not_helpful_retracted"
 	EventVisitVerified        = "visit_verified"
 	EventAbuseConfirmed       = "abuse_confirmed"
 	EventDataUpdateApproved   = "data_update_approved"
@@ -19,6 +22,7 @@ const (
 
 const (
 	PointsHelpfulBase          = 2.0
+	PointsNotHelpfulBase       = -1.0
 	PointsVisitLow             = 3
 	PointsVisitMedium          = 6
 	PointsVisitHigh            = 8
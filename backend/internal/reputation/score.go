@@ -7,24 +7,30 @@ import (
 )
 
 const (
-	FormulaVersion          = "reputation_v1_1"
+	FormulaVersion          = "reputation_v1_2"
 	ScoreMin                = 0.0
 	ScoreMax                = 1000.0
 	DailyCapHelpfulReceived = 20.0
 	DailyCapVisitVerified   = 24.0
-	decayFreshDays          = 90
-	decayWarmDays           = 180
-	decayAgedDays           = 365
-	decayFreshMultiplier    = 1.0
-	decayWarmMultiplier     = 0.7
-	decayAgedMultiplier     = 0.4
-	decayLegacyMultiplier   = 0.2
+	// DailyCapNotHelpfulReceived bounds the daily loss from "not helpful"
+	// votes so a brigade cannot sink an author in one day.
+	DailyCapNotHelpfulReceived = 6.0
+	decayFreshDays             = 90
+	decayWarmDays              = 180
+	decayAgedDays              = 365
+	decayFreshMultiplier       = 1.0
+	decayWarmMultiplier        = 0.7
+	decayAgedMultiplier        = 0.4
+	decayLegacyMultiplier      = 0.2
 )
 
+// ScoreEvent is one reputation_events row. SourceID links a retraction to
+// the vote event it reverses.
 type ScoreEvent struct {
 	ID        int64
 	EventType string
 	Points    float64
+	SourceID  string
 	CreatedAt time.Time
 }
 
@@ -77,13 +83,12 @@ func EvaluateScoreEvents(events []ScoreEvent, now time.Time) ([]EventContributio
 		return left.Before(right)
 	})
 
-	helpfulDailyEarned := make(map[string]float64)
-	visitDailyEarned := make(map[string]float64)
+	caps := newDailyCaps()
 	contributions := make([]EventContribution, 0, len(ordered))
 	total := 0.0
 
 	for _, event := range ordered {
-		applied := applyDailyCap(event, helpfulDailyEarned, visitDailyEarned)
+		applied := caps.apply(event)
 		multiplier := decayMultiplier(event.CreatedAt, normalizedNow)
 		effective := applied * multiplier
 
@@ -99,27 +104,71 @@ func EvaluateScoreEvents(events []ScoreEvent, now time.Time) ([]EventContributio
 	return contributions, clamp(total, ScoreMin, ScoreMax)
 }
 
-func applyDailyCap(event ScoreEvent, helpfulDailyEarned map[string]float64, visitDailyEarned map[string]float64) float64 {
-	points := event.Points
-	if points <= 0 {
-		return points
+// dailyCaps tracks, per UTC day, how many points capped event types have
+// applied so far, and what each vote event applied so its retraction can
+// take back exactly that.
+type dailyCaps struct {
+	helpfulEarned  map[string]float64
+	visitEarned    map[string]float64
+	notHelpfulLost map[string]float64
+	votes          map[string]float64
+}
+
+func newDailyCaps() *dailyCaps {
+	return &dailyCaps{
+		helpfulEarned:  make(map[string]float64),
+		visitEarned:    make(map[string]float64),
+		notHelpfulLost: make(map[string]float64),
+		votes:          make(map[string]float64),
 	}
+}
 
+// apply returns the points an event contributes after daily caps.
+// Retractions are stored with the created_at of the vote event they reverse,
+// so they land on the same day and free its cap for later events.
+func (d *dailyCaps) apply(event ScoreEvent) float64 {
+	points := event.Points
 	dayKey := event.CreatedAt.UTC().Format("2006-01-02")
 	normalizedType := strings.ToLower(strings.TrimSpace(event.EventType))
 
 	switch normalizedType {
 	case EventHelpfulReceived:
-		earned := helpfulDailyEarned[dayKey]
-		remaining := maxFloat(0, DailyCapHelpfulReceived-earned)
-		applied := minFloat(points, remaining)
-		helpfulDailyEarned[dayKey] = earned + applied
+		if points <= 0 {
+			return points
+		}
+		earned := d.helpfulEarned[dayKey]
+		applied := minFloat(points, maxFloat(0, DailyCapHelpfulReceived-earned))
+		d.helpfulEarned[dayKey] = earned + applied
+		d.votes[EventHelpfulReceived+":"+event.SourceID] = applied
+		return applied
+	case EventHelpfulRetracted:
+		key := EventHelpfulReceived + ":" + event.SourceID
+		applied := d.votes[key]
+		delete(d.votes, key)
+		d.helpfulEarned[dayKey] -= applied
+		return -applied
+	case EventNotHelpfulReceived:
+		if points >= 0 {
+			return 0
+		}
+		lost := d.notHelpfulLost[dayKey]
+		applied := minFloat(-points, maxFloat(0, DailyCapNotHelpfulReceived-lost))
+		d.notHelpfulLost[dayKey] = lost + applied
+		d.votes[EventNotHelpfulReceived+":"+event.SourceID] = applied
+		return -applied
+	case EventNotHelpfulRetracted:
+		key := EventNotHelpfulReceived + ":" + event.SourceID
+		applied := d.votes[key]
+		delete(d.votes, key)
+		d.notHelpfulLost[dayKey] -= applied
 		return applied
 	case EventVisitVerified:
-		earned := visitDailyEarned[dayKey]
-		remaining := maxFloat(0, DailyCapVisitVerified-earned)
-		applied := minFloat(points, remaining)
-		visitDailyEarned[dayKey] = earned + applied
+		if points <= 0 {
+			return points
+		}
+		earned := d.visitEarned[dayKey]
+		applied := minFloat(points, maxFloat(0, DailyCapVisitVerified-earned))
+		d.visitEarned[dayKey] = earned + applied
 		return applied
 	default:
 		return points
@@ -24,6 +24,35 @@ func TestComputeScoreAppliesDailyCaps(t *testing.T) {
 	}
 }
 
+func TestComputeScoreRetractionsRespectDailyCaps(t *testing.T) {
+	now := time.Date(2026, 2, 21, 12, 0, 0, 0, time.UTC)
+	day := now.Add(-2 * time.Hour)
+	events := []ScoreEvent{
+		{ID: 1, EventType: EventHelpfulReceived, Points: 12, SourceID: "v1", CreatedAt: day},
+		{ID: 2, EventType: EventHelpfulReceived, Points: 12, SourceID: "v2", CreatedAt: day.Add(time.Minute)},
+		// v2 only applied 8 points because of the cap; its retraction takes
+		// back exactly that, not the stored 12.
+		{ID: 3, EventType: EventHelpfulRetracted, Points: -12, SourceID: "v2", CreatedAt: day.Add(time.Minute)},
+		{ID: 4, EventType: EventHelpfulReceived, Points: 6, SourceID: "v3", CreatedAt: day.Add(2 * time.Minute)},
+		// A retraction of an unknown vote changes nothing.
+		{ID: 5, EventType: EventHelpfulRetracted, Points: -2, SourceID: "missing", CreatedAt: day},
+	}
+	if score, want := ComputeScore(events, now), 18.0; math.Abs(score-want) > 0.0001 {
+		t.Fatalf("unexpected score: got=%v want=%v", score, want)
+	}
+
+	events = []ScoreEvent{
+		{ID: 1, EventType: EventHelpfulReceived, Points: 20, SourceID: "h1", CreatedAt: day},
+		{ID: 2, EventType: EventNotHelpfulReceived, Points: -4, SourceID: "n1", CreatedAt: day},
+		{ID: 3, EventType: EventNotHelpfulReceived, Points: -4, SourceID: "n2", CreatedAt: day.Add(time.Minute)},
+		{ID: 4, EventType: EventNotHelpfulRetracted, Points: 4, SourceID: "n2", CreatedAt: day.Add(time.Minute)},
+	}
+	// losses are capped at 6 per day: n1 -4, n2 -2 given back on retraction
+	if score, want := ComputeScore(events, now), 16.0; math.Abs(score-want) > 0.0001 {
+		t.Fatalf("unexpected score: got=%v want=%v", score, want)
+	}
+}
+
 func TestComputeScoreAppliesDecayByAge(t *testing.T) {
 	now := time.Date(2026, 2, 21, 12, 0, 0, 0, time.UTC)
 	events := []ScoreEvent{
@@ -0,0 +1,48 @@
+// Package cafeaudit attributes writes to public.cafes. The cafes_revision_log
+// trigger (migration 000042) reads the transaction-local settings set by Tag
+// and records them in cafe_revisions.
+package cafeaudit
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	SourceAdmin      = "admin"
+	SourceImport     = "import"
+	SourceModeration = "moderation"
+	SourceOwner      = "owner"
+	SourceRevert     = "revert"
+	SourceMerge      = "merge"
+	SourceSystem     = "system"
+)
+
+// Change describes who changes a cafe and through which path. Reference is a
+// free-form pointer to the origin, e.g. a moderation submission id.
+type Change struct {
+	Source    string
+	ActorID   string
+	Reference string
+}
+
+// Tag marks every cafe write made later in tx with change. The settings are
+// local to tx and vanish on commit or rollback.
+func Tag(ctx context.Context, tx pgx.Tx, change Change) error {
+	source := strings.TrimSpace(change.Source)
+	if source == "" {
+		source = SourceSystem
+	}
+	_, err := tx.Exec(
+		ctx,
+		`select set_config('app.cafe_change_source', $1, true),
+		        set_config('app.cafe_change_actor', $2, true),
+		        set_config('app.cafe_change_reference', $3, true)`,
+		source,
+		strings.TrimSpace(change.ActorID),
+		strings.TrimSpace(change.Reference),
+	)
+	return err
+}
@@ -0,0 +1,31 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineM(t *testing.T) {
+	cases := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+		tolerance              float64
+	}{
+		{name: "same point", lat1: 55.75, lng1: 37.62, lat2: 55.75, lng2: 37.62, want: 0, tolerance: 1e-9},
+		{name: "one degree of latitude", lat1: 0, lng1: 0, lat2: 1, lng2: 0, want: 111195, tolerance: 1},
+		{name: "moscow to saint petersburg", lat1: 55.7558, lng1: 37.6173, lat2: 59.9343, lng2: 30.3351, want: 634000, tolerance: 2000},
+		{name: "antipodes", lat1: 0, lng1: 0, lat2: 0, lng2: 180, want: math.Pi * 6371000, tolerance: 1},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := HaversineM(tc.lat1, tc.lng1, tc.lat2, tc.lng2)
+			if math.Abs(got-tc.want) > tc.tolerance {
+				t.Fatalf("HaversineM() = %.2f, want %.2f ± %.2f", got, tc.want, tc.tolerance)
+			}
+			if back := HaversineM(tc.lat2, tc.lng2, tc.lat1, tc.lng1); math.Abs(back-got) > 1e-6 {
+				t.Fatalf("HaversineM() is not symmetric: %.6f vs %.6f", got, back)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package pagination
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestSignerRoundTrip(t *testing.T) {
+	signer := NewSigner("test-secret")
+	token, err := signer.Encode(map[string]int{"offset": 20})
+	if err != nil {
+		t.Fatalf("unexpected encode error: %v", err)
+	}
+	if !IsSigned(token) {
+		t.Fatalf("expected signed token, got %q", token)
+	}
+
+	var decoded map[string]int
+	if err := signer.Decode(token, &decoded); err != nil {
+		t.Fatalf("unexpected decode error: %v", err)
+	}
+	if decoded["offset"] != 20 {
+		t.Fatalf("expected offset=20, got %v", decoded)
+	}
+}
+
+func TestSignerRejectsForeignAndTamperedTokens(t *testing.T) {
+	signer := NewSigner("test-secret")
+	token, _ := signer.Encode(map[string]int{"offset": 20})
+
+	var decoded map[string]int
+	if err := NewSigner("other-secret").Decode(token, &decoded); !errors.Is(err, ErrCursorSignature) {
+		t.Fatalf("expected signature error for foreign key, got %v", err)
+	}
+
+	_, signature, _ := strings.Cut(token, ".")
+	otherToken, _ := signer.Encode(map[string]int{"offset": 0})
+	otherBody, _, _ := strings.Cut(otherToken, ".")
+	forged := otherBody + "." + signature
+	if err := signer.Decode(forged, &decoded); !errors.Is(err, ErrCursorSignature) {
+		t.Fatalf("expected signature error for tampered body, got %v", err)
+	}
+
+	if err := signer.Decode("no-dot", &decoded); !errors.Is(err, ErrMalformedCursor) {
+		t.Fatalf("expected malformed error, got %v", err)
+	}
+}
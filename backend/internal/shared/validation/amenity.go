@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+var amenityKeyPattern = regexp.MustCompile(`^[a-z0-9]+(?:[_-][a-z0-9]+)*$`)
+
+const (
+	maxAmenityKeyLen     = 40
+	maxAmenityAliasRunes = 60
+)
+
+// builtinAmenities is the catalog used until the amenities table has been
+// loaded, e.g. in tests and CLI tools that never call SetAmenityCatalog.
+var builtinAmenities = []string{"wifi", "power", "quiet", "toilet", "laptop"}
+
+// AmenityCatalog is the set of amenity keys the API accepts. Active keys are
+// valid everywhere; deprecated keys stay valid in filters, since cafes may
+// still carry them, but are dropped from new cafe data. Aliases (old names of
+// renamed amenities and free-form synonyms) resolve to their canonical key.
+type AmenityCatalog struct {
+	active     map[string]struct{}
+	deprecated map[string]struct{}
+	aliases    map[string]string
+}
+
+func NewAmenityCatalog(active, deprecated []string, aliases map[string]string) *AmenityCatalog {
+	catalog := &AmenityCatalog{
+		active:     make(map[string]struct{}, len(active)),
+		deprecated: make(map[string]struct{}, len(deprecated)),
+		aliases:    make(map[string]string, len(aliases)),
+	}
+	for _, key := range active {
+		catalog.active[cleanAmenity(key)] = struct{}{}
+	}
+	for _, key := range deprecated {
+		catalog.deprecated[cleanAmenity(key)] = struct{}{}
+	}
+	for alias, key := range aliases {
+		catalog.aliases[cleanAmenity(alias)] = cleanAmenity(key)
+	}
+	return catalog
+}
+
+// Resolve maps raw to its canonical key. ok is false for unknown values;
+// deprecated reports whether the key is deprecated.
+func (c *AmenityCatalog) Resolve(raw string) (key string, deprecated bool, ok bool) {
+	key = cleanAmenity(raw)
+	if key == "" {
+		return "", false, false
+	}
+	if target, isAlias := c.aliases[key]; isAlias {
+		key = target
+	}
+	if _, isActive := c.active[key]; isActive {
+		return key, false, true
+	}
+	if _, isDeprecated := c.deprecated[key]; isDeprecated {
+		return key, true, true
+	}
+	return "", false, false
+}
+
+var amenityCatalog atomic.Pointer[AmenityCatalog]
+
+// SetAmenityCatalog installs the process-wide amenity catalog used by
+// NormalizeAmenity and ParseAmenities.
+func SetAmenityCatalog(catalog *AmenityCatalog) {
+	if catalog != nil {
+		amenityCatalog.Store(catalog)
+	}
+}
+
+func CurrentAmenityCatalog() *AmenityCatalog {
+	if catalog := amenityCatalog.Load(); catalog != nil {
+		return catalog
+	}
+	amenityCatalog.CompareAndSwap(nil, NewAmenityCatalog(builtinAmenities, nil, nil))
+	return amenityCatalog.Load()
+}
+
+// ParseAmenities parses a comma-separated amenity filter. Deprecated keys are
+// kept so cafes that still carry them can be found.
+func ParseAmenities(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	catalog := CurrentAmenityCatalog()
+	parts := strings.Split(raw, ",")
+	amenities := make([]string, 0, len(parts))
+	seen := make(map[string]struct{}, len(parts))
+	for _, part := range parts {
+		amenity, _, ok := catalog.Resolve(part)
+		if !ok {
+			continue
+		}
+		if _, already := seen[amenity]; already {
+			continue
+		}
+		seen[amenity] = struct{}{}
+		amenities = append(amenities, amenity)
+	}
+
+	return amenities
+}
+
+// NormalizeAmenity validates an amenity written to cafe data: aliases resolve
+// to their key, deprecated and unknown amenities are rejected.
+func NormalizeAmenity(raw string) (string, bool) {
+	amenity, deprecated, ok := CurrentAmenityCatalog().Resolve(raw)
+	if !ok || deprecated {
+		return "", false
+	}
+	return amenity, true
+}
+
+// ParseAmenityKey validates a canonical amenity key such as "alt_milk" or
+// "laptop-friendly".
+func ParseAmenityKey(raw string) (string, bool) {
+	key := cleanAmenity(raw)
+	if len(key) > maxAmenityKeyLen || !amenityKeyPattern.MatchString(key) {
+		return "", false
+	}
+	return key, true
+}
+
+// ParseAmenityAlias validates an alias. Aliases may be free text in any
+// language but cannot contain commas, which separate amenities in filters.
+func ParseAmenityAlias(raw string) (string, bool) {
+	alias := cleanAmenity(raw)
+	if alias == "" || strings.Contains(alias, ",") || utf8.RuneCountInString(alias) > maxAmenityAliasRunes {
+		return "", false
+	}
+	return alias, true
+}
+
+func cleanAmenity(raw string) string {
+	return strings.Join(strings.Fields(strings.ToLower(raw)), " ")
+}
@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAmenityCatalogResolve(t *testing.T) {
+	t.Parallel()
+
+	catalog := NewAmenityCatalog(
+		[]string{"wifi", "alt_milk"},
+		[]string{"laptop"},
+		map[string]string{"plant milk": "alt_milk", "notebook": "laptop"},
+	)
+	cases := []struct {
+		raw        string
+		key        string
+		deprecated bool
+		ok         bool
+	}{
+		{" WiFi ", "wifi", false, true},
+		{"Plant  Milk", "alt_milk", false, true},
+		{"laptop", "laptop", true, true},
+		{"notebook", "laptop", true, true},
+		{"power", "", false, false},
+		{"", "", false, false},
+	}
+	for _, tc := range cases {
+		key, deprecated, ok := catalog.Resolve(tc.raw)
+		if key != tc.key || deprecated != tc.deprecated || ok != tc.ok {
+			t.Fatalf("Resolve(%q) = %q, %v, %v; want %q, %v, %v", tc.raw, key, deprecated, ok, tc.key, tc.deprecated, tc.ok)
+		}
+	}
+}
+
+func TestNormalizeAmenityUsesInstalledCatalog(t *testing.T) {
+	previous := CurrentAmenityCatalog()
+	t.Cleanup(func() { SetAmenityCatalog(previous) })
+
+	if _, ok := NormalizeAmenity("alt_milk"); ok {
+		t.Fatalf("alt_milk must be unknown to the built-in catalog")
+	}
+
+	SetAmenityCatalog(NewAmenityCatalog(
+		[]string{"wifi", "alt_milk"},
+		[]string{"laptop"},
+		map[string]string{"oat": "alt_milk"},
+	))
+	if got, ok := NormalizeAmenity("oat"); !ok || got != "alt_milk" {
+		t.Fatalf("NormalizeAmenity(oat) = %q, %v", got, ok)
+	}
+	if _, ok := NormalizeAmenity("laptop"); ok {
+		t.Fatalf("deprecated amenities must not be accepted in new cafe data")
+	}
+	if got := ParseAmenities("oat, laptop,alt_milk,bogus"); !reflect.DeepEqual(got, []string{"alt_milk", "laptop"}) {
+		t.Fatalf("ParseAmenities = %v", got)
+	}
+}
+
+func TestParseAmenityKeyAndAlias(t *testing.T) {
+	t.Parallel()
+
+	for raw, want := range map[string]string{"Alt_Milk": "alt_milk", "laptop-friendly": "laptop-friendly"} {
+		if got, ok := ParseAmenityKey(raw); !ok || got != want {
+			t.Fatalf("ParseAmenityKey(%q) = %q, %v", raw, got, ok)
+		}
+	}
+	for _, raw := range []string{"", "alt milk", "-wifi", "wifi_", "кофе", "a__b"} {
+		if _, ok := ParseAmenityKey(raw); ok {
+			t.Fatalf("ParseAmenityKey(%q) should fail", raw)
+		}
+	}
+	if got, ok := ParseAmenityAlias("  Растительное   молоко "); !ok || got != "растительное молоко" {
+		t.Fatalf("ParseAmenityAlias = %q, %v", got, ok)
+	}
+	if _, ok := ParseAmenityAlias("oat, soy"); ok {
+		t.Fatalf("aliases with commas must be rejected")
+	}
+}
@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// MaxCafeReopenHorizon bounds how far ahead a temporary closure may be
+// scheduled; longer closures should be marked permanent.
+const MaxCafeReopenHorizon = 366 * 24 * time.Hour
+
+// ParseReopenDate parses the YYYY-MM-DD reopen date of a temporarily closed
+// cafe. The date must be after today and within MaxCafeReopenHorizon.
+func ParseReopenDate(raw string, now time.Time) (time.Time, error) {
+	reopensOn, err := time.Parse(time.DateOnly, strings.TrimSpace(raw))
+	if err != nil {
+		return time.Time{}, errors.New("reopens_on должен быть в формате YYYY-MM-DD.")
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !reopensOn.After(today) {
+		return time.Time{}, errors.New("reopens_on должен быть в будущем.")
+	}
+	if reopensOn.Sub(today) > MaxCafeReopenHorizon {
+		return time.Time{}, errors.New("reopens_on не может быть дальше чем через год.")
+	}
+	return reopensOn, nil
+}
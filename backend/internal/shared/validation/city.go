@@ -0,0 +1,24 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const maxCitySlugLength = 64
+
+var citySlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ParseCitySlug normalizes the ?city= parameter. An empty value means no
+// city scope.
+func ParseCitySlug(raw string) (string, error) {
+	slug := strings.ToLower(strings.TrimSpace(raw))
+	if slug == "" {
+		return "", nil
+	}
+	if len(slug) > maxCitySlugLength || !citySlugPattern.MatchString(slug) {
+		return "", fmt.Errorf("city должен быть slug города, например saint-petersburg.")
+	}
+	return slug, nil
+}
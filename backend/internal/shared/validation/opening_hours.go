@@ -0,0 +1,203 @@
+package validation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"backend/internal/config"
+	"backend/internal/model"
+)
+
+const (
+	MaxOpeningIntervalsPerDay = 4
+	MaxOpeningHoursExceptions = 60
+)
+
+var openingHoursDayAliases = map[string]string{
+	"mon": "mon", "monday": "mon",
+	"tue": "tue", "tuesday": "tue",
+	"wed": "wed", "wednesday": "wed",
+	"thu": "thu", "thursday": "thu",
+	"fri": "fri", "friday": "fri",
+	"sat": "sat", "saturday": "sat",
+	"sun": "sun", "sunday": "sun",
+}
+
+// NormalizeOpeningHours validates a schedule and returns its canonical form:
+// lowercase short day keys, HH:MM times, sorted intervals and exceptions.
+// An empty timezone falls back to config.DefaultTimezone.
+func NormalizeOpeningHours(raw model.OpeningHours) (model.OpeningHours, error) {
+	timezone := strings.TrimSpace(raw.Timezone)
+	if timezone == "" {
+		timezone = config.DefaultTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return model.OpeningHours{}, fmt.Errorf("Неизвестный часовой пояс: %s", timezone)
+	}
+
+	weekly := make(map[string][]model.OpeningInterval, len(raw.Weekly))
+	for rawDay, rawIntervals := range raw.Weekly {
+		day, ok := openingHoursDayAliases[strings.ToLower(strings.TrimSpace(rawDay))]
+		if !ok {
+			return model.OpeningHours{}, fmt.Errorf("Неизвестный день недели: %s", rawDay)
+		}
+		if _, exists := weekly[day]; exists {
+			return model.OpeningHours{}, fmt.Errorf("День недели указан несколько раз: %s", day)
+		}
+		intervals, err := normalizeOpeningIntervals(rawIntervals)
+		if err != nil {
+			return model.OpeningHours{}, fmt.Errorf("%s: %w", day, err)
+		}
+		weekly[day] = intervals
+	}
+
+	if len(raw.Exceptions) > MaxOpeningHoursExceptions {
+		return model.OpeningHours{}, fmt.Errorf("Слишком много исключений в расписании, максимум %d.", MaxOpeningHoursExceptions)
+	}
+	exceptions := make([]model.OpeningHoursException, 0, len(raw.Exceptions))
+	seenDates := make(map[string]struct{}, len(raw.Exceptions))
+	for _, item := range raw.Exceptions {
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(item.Date))
+		if err != nil {
+			return model.OpeningHours{}, fmt.Errorf("Дата исключения должна быть в формате YYYY-MM-DD.")
+		}
+		key := date.Format("2006-01-02")
+		if _, exists := seenDates[key]; exists {
+			return model.OpeningHours{}, fmt.Errorf("Дата исключения указана несколько раз: %s", key)
+		}
+		seenDates[key] = struct{}{}
+
+		exception := model.OpeningHoursException{
+			Date:   key,
+			Closed: item.Closed,
+			Note:   strings.TrimSpace(item.Note),
+		}
+		if len([]rune(exception.Note)) > 200 {
+			return model.OpeningHours{}, fmt.Errorf("Комментарий к исключению слишком длинный.")
+		}
+		if item.Closed && len(item.Intervals) > 0 {
+			return model.OpeningHours{}, fmt.Errorf("%s: нельзя одновременно указать closed и интервалы.", key)
+		}
+		if !item.Closed {
+			if len(item.Intervals) == 0 {
+				return model.OpeningHours{}, fmt.Errorf("%s: укажите интервалы или closed.", key)
+			}
+			intervals, err := normalizeOpeningIntervals(item.Intervals)
+			if err != nil {
+				return model.OpeningHours{}, fmt.Errorf("%s: %w", key, err)
+			}
+			exception.Intervals = intervals
+		}
+		exceptions = append(exceptions, exception)
+	}
+	sort.Slice(exceptions, func(i, j int) bool {
+		return exceptions[i].Date < exceptions[j].Date
+	})
+	if len(exceptions) == 0 {
+		exceptions = nil
+	}
+
+	return model.OpeningHours{
+		Timezone:   timezone,
+		Weekly:     weekly,
+		Exceptions: exceptions,
+	}, nil
+}
+
+func normalizeOpeningIntervals(raw []model.OpeningInterval) ([]model.OpeningInterval, error) {
+	if len(raw) > MaxOpeningIntervalsPerDay {
+		return nil, fmt.Errorf("не больше %d интервалов в день", MaxOpeningIntervalsPerDay)
+	}
+	type span struct {
+		open  int
+		close int
+	}
+	spans := make([]span, 0, len(raw))
+	out := make([]model.OpeningInterval, 0, len(raw))
+	for _, item := range raw {
+		open, ok := parseClockMinutes(item.Open, false)
+		if !ok {
+			return nil, fmt.Errorf("время открытия должно быть в формате HH:MM")
+		}
+		closeAt, ok := parseClockMinutes(item.Close, true)
+		if !ok {
+			return nil, fmt.Errorf("время закрытия должно быть в формате HH:MM")
+		}
+		if open == closeAt {
+			return nil, fmt.Errorf("время открытия и закрытия не должны совпадать")
+		}
+		end := closeAt
+		if closeAt < open {
+			// Past midnight: the tail belongs to the next day.
+			end = 24 * 60
+		}
+		spans = append(spans, span{open: open, close: end})
+		out = append(out, model.OpeningInterval{
+			Open:  formatClockMinutes(open),
+			Close: formatClockMinutes(closeAt),
+		})
+	}
+
+	order := make([]int, len(out))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return spans[order[i]].open < spans[order[j]].open
+	})
+	sorted := make([]model.OpeningInterval, 0, len(out))
+	for i, idx := range order {
+		if i > 0 && spans[order[i-1]].close > spans[idx].open {
+			return nil, fmt.Errorf("интервалы не должны пересекаться")
+		}
+		sorted = append(sorted, out[idx])
+	}
+	return sorted, nil
+}
+
+func parseClockMinutes(raw string, allowEndOfDay bool) (int, bool) {
+	value := strings.TrimSpace(raw)
+	if allowEndOfDay && value == "24:00" {
+		return 24 * 60, true
+	}
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
+func formatClockMinutes(value int) string {
+	return fmt.Sprintf("%02d:%02d", value/60, value%60)
+}
+
+// EncodeOpeningHours splits normalized hours into the jsonb column value and
+// the cafe timezone. A schedule with neither weekly days nor exceptions
+// clears the hours (stored as NULL, meaning "unknown").
+func EncodeOpeningHours(hours *model.OpeningHours) ([]byte, string, error) {
+	if hours == nil {
+		return nil, config.DefaultTimezone, nil
+	}
+	timezone := strings.TrimSpace(hours.Timezone)
+	if timezone == "" {
+		timezone = config.DefaultTimezone
+	}
+	if len(hours.Weekly) == 0 && len(hours.Exceptions) == 0 {
+		return nil, timezone, nil
+	}
+
+	stored := *hours
+	stored.Timezone = ""
+	if stored.Weekly == nil {
+		stored.Weekly = map[string][]model.OpeningInterval{}
+	}
+	raw, err := json.Marshal(stored)
+	if err != nil {
+		return nil, "", err
+	}
+	return raw, timezone, nil
+}
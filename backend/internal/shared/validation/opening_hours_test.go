@@ -0,0 +1,22 @@
+package validation
+
+import (
+	"testing"
+
+	"backend/internal/model"
+)
+
+func TestEncodeOpeningHours_EmptyClearsSchedule(t *testing.T) {
+	t.Parallel()
+
+	raw, timezone, err := EncodeOpeningHours(&model.OpeningHours{Timezone: "Asia/Yekaterinburg"})
+	if err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	if raw != nil {
+		t.Fatalf("expected NULL hours, got %s", raw)
+	}
+	if timezone != "Asia/Yekaterinburg" {
+		t.Fatalf("unexpected timezone %q", timezone)
+	}
+}
@@ -0,0 +1,35 @@
+package validation
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"backend/internal/model"
+)
+
+// ParseReviewAspect normalizes the ?aspect= parameter. An empty value means
+// the overall rating.
+func ParseReviewAspect(raw string) (string, error) {
+	aspect := strings.ToLower(strings.TrimSpace(raw))
+	if aspect == "" {
+		return "", nil
+	}
+	if !model.IsReviewAspect(aspect) {
+		return "", errors.New("aspect должен быть одним из: " + strings.Join(model.ReviewAspects, ", ") + ".")
+	}
+	return aspect, nil
+}
+
+// ParseMinAspectRating parses ?min_aspect_rating=; zero means no filter.
+func ParseMinAspectRating(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil || value < 1 || value > 5 {
+		return 0, errors.New("min_aspect_rating должен быть числом от 1 до 5.")
+	}
+	return value, nil
+}
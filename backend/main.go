@@ -21,19 +21,25 @@ import (
 
 	"backend/internal/auth"
 	"backend/internal/config"
+	"backend/internal/domains/amenities"
 	"backend/internal/domains/cafes"
+	"backend/internal/domains/cities"
 	"backend/internal/domains/favorites"
 	"backend/internal/domains/feedback"
 	"backend/internal/domains/metrics"
 	"backend/internal/domains/moderation"
+	"backend/internal/domains/osmsync"
 	"backend/internal/domains/photos"
 	"backend/internal/domains/reviews"
 	"backend/internal/domains/tags"
 	"backend/internal/domains/taste"
+	"backend/internal/domains/tiles"
+	"backend/internal/geocoding"
 	"backend/internal/logging"
 	"backend/internal/mailer"
 	"backend/internal/media"
 	"backend/internal/shared/httpx"
+	"backend/internal/shared/pagination"
 	dbmigrations "backend/migrations"
 )
 
@@ -123,6 +129,7 @@ func main() {
 		"bot_username", strings.TrimSpace(cfg.Auth.TelegramBotUsername),
 		"token_set", strings.TrimSpace(cfg.Auth.TelegramBotToken) != "",
 	)
+	pagination.Configure(cfg.Pagination.CursorSecret)
 
 	dbURL1 := os.Getenv("DATABASE_URL")
 	dbURL2 := os.Getenv("DATABASE_URL_2")
@@ -247,6 +254,7 @@ func main() {
 	photosHandler := photos.NewHandler(pool, mediaService, cfg.Media)
 	moderationHandler := moderation.NewHandler(pool, mediaService, cfg.Media)
 	reviewsHandler := reviews.NewDefaultHandler(pool, mediaService, cfg.Media)
+	cafesHandler.Service().SetReviewsReader(reviewsHandler.Service())
 	tagsHandler := tags.NewDefaultHandler(pool)
 	tasteHandler, err := taste.NewDefaultHandler(pool, taste.TasteMapEnabledFromEnv())
 	if err != nil {
@@ -255,12 +263,34 @@ func main() {
 		os.Exit(1)
 	}
 	metricsHandler := metrics.NewDefaultHandler(pool)
+	tilesHandler := tiles.NewDefaultHandler(pool, cfg.Media)
+	osmSyncHandler := osmsync.NewDefaultHandler(pool, cfg.OSMSync)
+	citiesHandler := cities.NewDefaultHandler(pool)
+	amenitiesHandler := amenities.NewDefaultHandler(pool)
+	geocoder, err := geocoding.New(cfg.Geocoding, geocoding.NewPostgresCache(pool))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "FATAL: geocoding init failed: %v\n", err)
+		slog.Error("geocoding init failed", "error", err)
+		os.Exit(1)
+	}
+	cafesHandler.Service().SetGeocoder(geocoder)
+	moderationHandler.SetGeocoder(geocoder)
 
 	wg.Add(4)
 	go func() { defer wg.Done(); reviewsHandler.Service().StartEventWorker(workerCtx, 2*time.Second) }()
 	go func() { defer wg.Done(); reviewsHandler.Service().StartInboxWorker(workerCtx, 2*time.Second) }()
 	go func() { defer wg.Done(); reviewsHandler.Service().StartPhotoCleanupWorker(workerCtx, 15*time.Minute) }()
 	go func() { defer wg.Done(); reviewsHandler.Service().StartRatingRebuildWorker(workerCtx, 15*time.Minute) }()
+	wg.Add(1)
+	go func() { defer wg.Done(); amenitiesHandler.Service().StartCatalogRefresh(workerCtx, time.Minute) }()
+	wg.Add(3)
+	go func() { defer wg.Done(); cafesHandler.Service().StartDuplicateScanWorker(workerCtx, 6*time.Hour) }()
+	go func() { defer wg.Done(); cafesHandler.Service().StartReopenWorker(workerCtx, time.Hour) }()
+	go func() { defer wg.Done(); cafesHandler.Service().StartImportWorker(workerCtx, 5*time.Second) }()
+	if cfg.OSMSync.Enabled {
+		wg.Add(1)
+		go func() { defer wg.Done(); osmSyncHandler.Service().StartWorker(workerCtx, cfg.OSMSync.Interval) }()
+	}
 	if taste.TasteInferenceEnabledFromEnv() {
 		if tasteService := tasteHandler.Service(); tasteService != nil {
 			wg.Add(2)
@@ -279,8 +309,28 @@ func main() {
 
 	api := r.Group("/api")
 	api.GET("/geocode", cafesHandler.GeocodeLookup)
+	api.GET("/geocode/reverse", cafesHandler.ReverseGeocode)
 	api.GET("/drinks", reviewsHandler.ListDrinks)
+	api.GET("/drinks/:id/leaderboard", reviewsHandler.GetDrinkLeaderboard)
+	api.GET("/cities", citiesHandler.List)
+	api.GET("/cities/locate", citiesHandler.Locate)
+	api.GET("/cities/:slug", citiesHandler.Get)
+	api.PUT("/admin/cities/:slug", auth.RequireRole(pool, "admin"), citiesHandler.AdminUpsert)
+	api.DELETE("/admin/cities/:slug", auth.RequireRole(pool, "admin"), citiesHandler.AdminDelete)
+	api.GET("/amenities", amenitiesHandler.List)
+	api.POST("/admin/amenities", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminCreate)
+	api.PATCH("/admin/amenities/:key", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminUpdate)
+	api.DELETE("/admin/amenities/:key", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminDelete)
+	api.POST("/admin/amenities/:key/rename", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminRename)
+	api.POST("/admin/amenities/:key/aliases", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminAddAlias)
+	api.DELETE("/admin/amenities/:key/aliases/:alias", auth.RequireRole(pool, "admin"), amenitiesHandler.AdminDeleteAlias)
 	api.GET("/cafes", auth.OptionalAuth(pool), cafesHandler.List)
+	api.GET("/cafes/search", auth.OptionalAuth(pool), cafesHandler.Search)
+	api.GET("/cafes/viewport", auth.OptionalAuth(pool), cafesHandler.Viewport)
+	api.POST("/cafes/route", auth.OptionalAuth(pool), cafesHandler.Route)
+	api.GET("/cafes/:id", auth.OptionalAuth(pool), cafesHandler.GetByID)
+	api.GET("/cafes/:id/similar", auth.OptionalAuth(pool), cafesHandler.Similar)
+	api.GET("/tiles/cafes/:z/:x/:y", tilesHandler.GetCafesTile)
 	api.POST("/cafes/:id/favorite", auth.RequireAuth(pool), favoritesHandler.Add)
 	api.DELETE("/cafes/:id/favorite", auth.RequireAuth(pool), favoritesHandler.Remove)
 	api.PATCH("/cafes/:id/description", auth.RequireRole(pool, "admin", "moderator"), cafesHandler.UpdateDescription)
@@ -291,13 +341,27 @@ func main() {
 	api.POST("/reviews/photos/confirm", auth.RequireAuth(pool), reviewsHandler.ConfirmPhoto)
 	api.GET("/reviews/photos/:id/status", auth.RequireAuth(pool), reviewsHandler.GetPhotoStatus)
 	api.POST("/reviews/:id/helpful", auth.RequireAuth(pool), reviewsHandler.AddHelpful)
+	api.DELETE("/reviews/:id/helpful", auth.RequireAuth(pool), reviewsHandler.RetractHelpful)
+	api.POST("/reviews/:id/not-helpful", auth.RequireAuth(pool), reviewsHandler.AddNotHelpful)
+	api.DELETE("/reviews/:id/not-helpful", auth.RequireAuth(pool), reviewsHandler.RetractNotHelpful)
 	api.POST("/cafes/:id/check-in/start", auth.RequireAuth(pool), reviewsHandler.StartCheckIn)
 	api.POST("/reviews/:id/visit/verify", auth.RequireAuth(pool), reviewsHandler.VerifyVisit)
 	api.POST("/reviews/:id/abuse", auth.RequireAuth(pool), reviewsHandler.ReportAbuse)
+	api.POST("/reviews/:id/reply", auth.RequireAuth(pool), reviewsHandler.CreateReply)
+	api.PATCH("/reviews/:id/reply", auth.RequireAuth(pool), reviewsHandler.UpdateReply)
+	api.DELETE("/reviews/:id/reply", auth.RequireAuth(pool), reviewsHandler.DeleteReply)
+	api.POST("/reviews/:id/reply/abuse", auth.RequireAuth(pool), reviewsHandler.ReportReplyAbuse)
+	api.GET("/reviews/:id/revisions", reviewsHandler.ListRevisions)
+	api.GET("/reviews/:id/comments", reviewsHandler.ListComments)
+	api.POST("/reviews/:id/comments", auth.RequireAuth(pool), reviewsHandler.CreateComment)
+	api.PATCH("/reviews/comments/:id", auth.RequireAuth(pool), reviewsHandler.UpdateComment)
+	api.DELETE("/reviews/comments/:id", auth.RequireAuth(pool), reviewsHandler.DeleteComment)
+	api.POST("/reviews/comments/:id/abuse", auth.RequireAuth(pool), reviewsHandler.ReportCommentAbuse)
 	api.POST("/abuse-reports/:id/confirm", auth.RequireRole(pool, "admin", "moderator"), reviewsHandler.ConfirmAbuse)
 	api.POST("/metrics/events", auth.OptionalAuth(pool), metricsHandler.IngestEvents)
 	api.GET("/cafes/:id/rating", reviewsHandler.GetCafeRating)
 	api.GET("/cafes/:id/reviews", reviewsHandler.ListCafeReviews)
+	api.GET("/cafes/:id/drinks/rankings", reviewsHandler.GetCafeDrinkRankings)
 	api.GET("/tags/descriptive/discovery", auth.OptionalAuth(pool), tagsHandler.GetDiscoveryDescriptive)
 	api.GET("/tags/descriptive/options", tagsHandler.GetDescriptiveOptions)
 	api.GET("/tags/descriptive/preferences", auth.RequireAuth(pool), tagsHandler.GetMyDescriptivePreferences)
@@ -333,9 +397,27 @@ func main() {
 	adminCafesGroup := api.Group("/admin/cafes")
 	adminCafesGroup.Use(auth.RequireRole(pool, "admin", "moderator"))
 	adminCafesGroup.GET("/search", cafesHandler.AdminSearch)
+	adminCafesGroup.GET("/duplicates", cafesHandler.AdminListDuplicates)
+	adminCafesGroup.POST("/duplicates/scan", cafesHandler.AdminScanDuplicates)
+	adminCafesGroup.POST("/duplicates/:id/dismiss", cafesHandler.AdminDismissDuplicate)
+	adminCafesGroup.GET("/export", auth.RequireRole(pool, "admin"), cafesHandler.AdminExport)
+	adminCafesGroup.GET("/osm-sync/runs", osmSyncHandler.AdminListRuns)
+	adminCafesGroup.POST("/osm-sync", auth.RequireRole(pool, "admin"), osmSyncHandler.AdminRun)
+	adminCafesGroup.GET("/import-jobs", cafesHandler.AdminListImportJobs)
+	adminCafesGroup.POST("/import-jobs", auth.RequireRole(pool, "admin"), cafesHandler.AdminCreateImportJob)
+	adminCafesGroup.GET("/import-jobs/:id", cafesHandler.AdminGetImportJob)
+	adminCafesGroup.GET("/import-jobs/:id/issues", cafesHandler.AdminImportJobIssues)
+	adminCafesGroup.POST("/import-jobs/:id/cancel", auth.RequireRole(pool, "admin"), cafesHandler.AdminCancelImportJob)
 	adminCafesGroup.GET("/:id", cafesHandler.AdminGetByID)
 	adminCafesGroup.PATCH("/:id", auth.RequireRole(pool, "admin"), cafesHandler.AdminUpdateByID)
 	adminCafesGroup.DELETE("/:id", auth.RequireRole(pool, "admin"), cafesHandler.AdminDeleteByID)
+	adminCafesGroup.PATCH("/:id/status", auth.RequireRole(pool, "admin"), cafesHandler.AdminSetStatus)
+	adminCafesGroup.POST("/:id/restore", auth.RequireRole(pool, "admin"), cafesHandler.AdminRestore)
+	adminCafesGroup.POST("/:id/merge", auth.RequireRole(pool, "admin"), cafesHandler.AdminMerge)
+	adminCafesGroup.GET("/:id/revisions", cafesHandler.AdminListRevisions)
+	adminCafesGroup.GET("/:id/owners", moderationHandler.AdminListCafeOwners)
+	adminCafesGroup.DELETE("/:id/owners/:userID", auth.RequireRole(pool, "admin"), moderationHandler.AdminRevokeCafeOwner)
+	adminCafesGroup.POST("/:id/revisions/:revision/revert", auth.RequireRole(pool, "admin"), cafesHandler.AdminRevertRevision)
 	adminCafesGroup.GET("/:id/rating-diagnostics", reviewsHandler.GetCafeRatingDiagnostics)
 	adminCafesGroup.POST("/:id/rating-ai-summarize", reviewsHandler.TriggerCafeAISummary)
 	api.POST("/admin/cafes/import-json", auth.RequireRole(pool, "admin"), cafesHandler.ImportJSON)
@@ -369,8 +451,11 @@ func main() {
 	submissionsGroup.POST("/photos/presign", moderationHandler.PresignPhoto)
 	submissionsGroup.POST("/cafes", moderationHandler.SubmitCafeCreate)
 	submissionsGroup.POST("/cafes/:id/description", moderationHandler.SubmitCafeDescription)
+	submissionsGroup.POST("/cafes/:id/hours", moderationHandler.SubmitCafeHours)
+	submissionsGroup.POST("/cafes/:id/closure", moderationHandler.SubmitCafeClosure)
 	submissionsGroup.POST("/cafes/:id/photos", moderationHandler.SubmitCafePhotos)
 	submissionsGroup.POST("/cafes/:id/menu-photos", moderationHandler.SubmitMenuPhotos)
+	submissionsGroup.POST("/cafes/:id/claim", moderationHandler.SubmitCafeClaim)
 	submissionsGroup.GET("/mine", moderationHandler.ListMine)
 
 	moderationGroup := api.Group("/moderation")
@@ -481,6 +566,7 @@ func main() {
 	accountGroup.POST("/profile/avatar/presign", auth.RequireAuth(pool), authHandler.ProfileAvatarPresign)
 	accountGroup.POST("/profile/avatar/confirm", auth.RequireAuth(pool), authHandler.ProfileAvatarConfirm)
 	accountGroup.GET("/favorites", auth.RequireAuth(pool), favoritesHandler.List)
+	accountGroup.GET("/cafes", auth.RequireAuth(pool), moderationHandler.ListOwnedCafes)
 	accountGroup.POST("/feedback", auth.RequireAuth(pool), feedbackHandler.Create)
 	api.GET("/admin/feedback", auth.RequireRole(pool, "admin"), feedbackHandler.ListAdmin)
 	accountGroup.GET("/email/change/confirm", authHandler.EmailChangeConfirm)
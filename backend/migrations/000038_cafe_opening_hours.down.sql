@@ -0,0 +1,19 @@
+DELETE FROM public.moderation_submissions
+WHERE entity_type = 'cafe_hours';
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_description', 'cafe_photo', 'menu_photo', 'review')
+);
+
+DROP FUNCTION IF EXISTS public.cafe_is_open_at(JSONB, TEXT, TIMESTAMPTZ);
+DROP FUNCTION IF EXISTS public.cafe_hours_intervals_for_date(JSONB, DATE);
+
+ALTER TABLE public.cafes
+DROP COLUMN IF EXISTS timezone;
+
+ALTER TABLE public.cafes
+DROP COLUMN IF EXISTS opening_hours;
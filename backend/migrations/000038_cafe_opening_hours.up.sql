@@ -0,0 +1,84 @@
+ALTER TABLE public.cafes
+ADD COLUMN IF NOT EXISTS opening_hours JSONB;
+
+ALTER TABLE public.cafes
+ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'Europe/Moscow';
+
+CREATE OR REPLACE FUNCTION public.cafe_hours_intervals_for_date(hours JSONB, day DATE)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT COALESCE(
+        (
+            SELECT CASE
+                WHEN COALESCE((ex->>'closed')::boolean, false) THEN '[]'::jsonb
+                ELSE COALESCE(ex->'intervals', '[]'::jsonb)
+            END
+            FROM jsonb_array_elements(COALESCE(hours->'exceptions', '[]'::jsonb)) AS ex
+            WHERE ex->>'date' = to_char(day, 'YYYY-MM-DD')
+            LIMIT 1
+        ),
+        hours->'weekly'->((ARRAY['sun', 'mon', 'tue', 'wed', 'thu', 'fri', 'sat'])[EXTRACT(DOW FROM day)::int + 1]),
+        '[]'::jsonb
+    );
+$$;
+
+-- Returns NULL when hours are unknown, so callers can tell "closed" from "no data".
+-- Intervals with close < open run past midnight into the next day.
+CREATE OR REPLACE FUNCTION public.cafe_is_open_at(hours JSONB, tz TEXT, at_ts TIMESTAMPTZ)
+RETURNS BOOLEAN
+LANGUAGE plpgsql
+STABLE
+AS $$
+DECLARE
+    local_ts TIMESTAMP;
+    local_day DATE;
+    local_time TIME;
+    item JSONB;
+    open_time TIME;
+    close_time TIME;
+BEGIN
+    IF hours IS NULL OR jsonb_typeof(hours->'weekly') IS DISTINCT FROM 'object' THEN
+        RETURN NULL;
+    END IF;
+
+    local_ts := at_ts AT TIME ZONE COALESCE(NULLIF(tz, ''), 'Europe/Moscow');
+    local_day := local_ts::date;
+    local_time := local_ts::time;
+
+    FOR item IN
+        SELECT value FROM jsonb_array_elements(public.cafe_hours_intervals_for_date(hours, local_day))
+    LOOP
+        open_time := (item->>'open')::time;
+        close_time := (item->>'close')::time;
+        IF close_time > open_time THEN
+            IF local_time >= open_time AND local_time < close_time THEN
+                RETURN true;
+            END IF;
+        ELSIF local_time >= open_time THEN
+            RETURN true;
+        END IF;
+    END LOOP;
+
+    FOR item IN
+        SELECT value FROM jsonb_array_elements(public.cafe_hours_intervals_for_date(hours, local_day - 1))
+    LOOP
+        open_time := (item->>'open')::time;
+        close_time := (item->>'close')::time;
+        IF close_time < open_time AND local_time < close_time THEN
+            RETURN true;
+        END IF;
+    END LOOP;
+
+    RETURN false;
+END;
+$$;
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_description', 'cafe_hours', 'cafe_photo', 'menu_photo', 'review')
+);
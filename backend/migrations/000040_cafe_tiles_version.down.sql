@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS cafe_photos_tiles_version_trg ON public.cafe_photos;
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_upd_trg ON public.cafe_rating_snapshots;
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_ins_del_trg ON public.cafe_rating_snapshots;
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+DROP TRIGGER IF EXISTS cafes_tiles_version_ins_del_trg ON public.cafes;
+
+DROP FUNCTION IF EXISTS public.bump_cafe_tiles_version();
+DROP TABLE IF EXISTS public.cafe_tiles_state;
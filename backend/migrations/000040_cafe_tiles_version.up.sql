@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS public.cafe_tiles_state (
+    id SMALLINT PRIMARY KEY DEFAULT 1,
+    version BIGINT NOT NULL DEFAULT 1,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT cafe_tiles_state_singleton_chk CHECK (id = 1)
+);
+
+INSERT INTO public.cafe_tiles_state (id)
+VALUES (1)
+ON CONFLICT (id) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION public.bump_cafe_tiles_version()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    UPDATE public.cafe_tiles_state
+       SET version = version + 1,
+           updated_at = now()
+     WHERE id = 1;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_ins_del_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafes
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_ins_del_trg ON public.cafe_rating_snapshots;
+CREATE TRIGGER cafe_rating_snapshots_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafe_rating_snapshots
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_upd_trg ON public.cafe_rating_snapshots;
+CREATE TRIGGER cafe_rating_snapshots_tiles_version_upd_trg
+AFTER UPDATE ON public.cafe_rating_snapshots
+FOR EACH ROW
+WHEN (
+    OLD.rating IS DISTINCT FROM NEW.rating
+    OR OLD.reviews_count IS DISTINCT FROM NEW.reviews_count
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_photos_tiles_version_trg ON public.cafe_photos;
+CREATE TRIGGER cafe_photos_tiles_version_trg
+AFTER INSERT OR UPDATE OR DELETE ON public.cafe_photos
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
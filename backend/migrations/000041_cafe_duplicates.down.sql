@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.cafe_merges;
+DROP TABLE IF EXISTS public.cafe_duplicate_candidates;
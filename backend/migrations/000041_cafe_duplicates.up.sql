@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS public.cafe_duplicate_candidates (
+    id BIGSERIAL PRIMARY KEY,
+    cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    duplicate_cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    distance_m DOUBLE PRECISION NOT NULL,
+    name_similarity REAL NOT NULL,
+    score REAL NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    resolved_by UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT cafe_duplicate_candidates_pair_uniq UNIQUE (cafe_id, duplicate_cafe_id),
+    CONSTRAINT cafe_duplicate_candidates_order_chk CHECK (cafe_id < duplicate_cafe_id),
+    CONSTRAINT cafe_duplicate_candidates_status_chk CHECK (status IN ('open', 'dismissed'))
+);
+
+CREATE INDEX IF NOT EXISTS cafe_duplicate_candidates_status_score_idx
+    ON public.cafe_duplicate_candidates (status, score DESC, id DESC);
+
+CREATE INDEX IF NOT EXISTS cafe_duplicate_candidates_duplicate_idx
+    ON public.cafe_duplicate_candidates (duplicate_cafe_id);
+
+-- The source cafe row is gone after a merge, so source_cafe_id has no FK and
+-- source_snapshot keeps what it looked like.
+CREATE TABLE IF NOT EXISTS public.cafe_merges (
+    id BIGSERIAL PRIMARY KEY,
+    source_cafe_id UUID NOT NULL,
+    target_cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    merged_by UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    source_snapshot JSONB NOT NULL DEFAULT '{}'::jsonb,
+    moved JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS cafe_merges_target_created_idx
+    ON public.cafe_merges (target_cafe_id, created_at DESC);
+
+CREATE INDEX IF NOT EXISTS cafe_merges_source_idx
+    ON public.cafe_merges (source_cafe_id);
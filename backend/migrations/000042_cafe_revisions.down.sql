@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS cafes_revision_log_trg ON public.cafes;
+DROP TRIGGER IF EXISTS cafe_revisions_append_only_trg ON public.cafe_revisions;
+
+DROP FUNCTION IF EXISTS public.forbid_cafe_revision_update();
+DROP FUNCTION IF EXISTS public.log_cafe_revision();
+DROP TABLE IF EXISTS public.cafe_revisions;
+DROP FUNCTION IF EXISTS public.cafe_revision_state(public.cafes);
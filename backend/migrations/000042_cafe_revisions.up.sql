@@ -0,0 +1,110 @@
+CREATE TABLE IF NOT EXISTS public.cafe_revisions (
+    id BIGSERIAL PRIMARY KEY,
+    cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    source TEXT NOT NULL,
+    actor_user_id UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    reference TEXT NULL,
+    changed_fields TEXT[] NOT NULL DEFAULT '{}'::text[],
+    diff JSONB NOT NULL DEFAULT '{}'::jsonb,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT cafe_revisions_cafe_revision_uniq UNIQUE (cafe_id, revision),
+    CONSTRAINT cafe_revisions_source_chk CHECK (
+        source IN ('baseline', 'admin', 'import', 'moderation', 'revert', 'merge', 'system')
+    )
+);
+
+-- Fields tracked by the revision log. Keys are the API field names so diffs
+-- can be shown as-is.
+CREATE OR REPLACE FUNCTION public.cafe_revision_state(c public.cafes)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'name', c.name,
+        'address', c.address,
+        'description', c.description,
+        'latitude', c.lat,
+        'longitude', c.lng,
+        'amenities', to_jsonb(COALESCE(c.amenities, '{}'::text[])),
+        'opening_hours', c.opening_hours,
+        'timezone', c.timezone
+    );
+$$;
+
+-- Writers tag their transaction with set_config('app.cafe_change_*', ..., true);
+-- untagged writes (seed scripts, manual SQL) are logged as 'system'.
+CREATE OR REPLACE FUNCTION public.log_cafe_revision()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+DECLARE
+    new_state JSONB := public.cafe_revision_state(NEW);
+    old_state JSONB := '{}'::jsonb;
+    changed TEXT[];
+    change_diff JSONB;
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        old_state := public.cafe_revision_state(OLD);
+    END IF;
+
+    SELECT
+        COALESCE(array_agg(n.key ORDER BY n.key), '{}'::text[]),
+        COALESCE(
+            jsonb_object_agg(n.key, jsonb_build_object('from', old_state->n.key, 'to', n.value)),
+            '{}'::jsonb
+        )
+      INTO changed, change_diff
+      FROM jsonb_each(new_state) AS n
+     WHERE TG_OP = 'INSERT' OR (old_state->n.key) IS DISTINCT FROM n.value;
+
+    IF TG_OP = 'UPDATE' AND cardinality(changed) = 0 THEN
+        RETURN NULL;
+    END IF;
+
+    INSERT INTO public.cafe_revisions (
+        cafe_id, revision, source, actor_user_id, reference, changed_fields, diff, snapshot
+    )
+    VALUES (
+        NEW.id,
+        COALESCE((SELECT max(revision) FROM public.cafe_revisions WHERE cafe_id = NEW.id), 0) + 1,
+        COALESCE(NULLIF(current_setting('app.cafe_change_source', true), ''), 'system'),
+        NULLIF(current_setting('app.cafe_change_actor', true), '')::uuid,
+        NULLIF(current_setting('app.cafe_change_reference', true), ''),
+        changed,
+        change_diff,
+        new_state
+    );
+    RETURN NULL;
+END;
+$$;
+
+CREATE OR REPLACE FUNCTION public.forbid_cafe_revision_update()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'cafe_revisions is append-only';
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafe_revisions_append_only_trg ON public.cafe_revisions;
+CREATE TRIGGER cafe_revisions_append_only_trg
+BEFORE UPDATE ON public.cafe_revisions
+FOR EACH ROW
+EXECUTE FUNCTION public.forbid_cafe_revision_update();
+
+DROP TRIGGER IF EXISTS cafes_revision_log_trg ON public.cafes;
+CREATE TRIGGER cafes_revision_log_trg
+AFTER INSERT OR UPDATE ON public.cafes
+FOR EACH ROW
+EXECUTE FUNCTION public.log_cafe_revision();
+
+-- Existing cafes start with a baseline so they can be reverted to the state
+-- they had before the log existed.
+INSERT INTO public.cafe_revisions (cafe_id, revision, source, changed_fields, diff, snapshot, created_at)
+SELECT c.id, 1, 'baseline', '{}'::text[], '{}'::jsonb, public.cafe_revision_state(c), c.created_at
+  FROM public.cafes c
+ON CONFLICT (cafe_id, revision) DO NOTHING;
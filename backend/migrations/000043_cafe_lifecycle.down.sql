@@ -0,0 +1,60 @@
+DELETE FROM public.moderation_submissions
+WHERE entity_type = 'cafe_closure';
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_description', 'cafe_hours', 'cafe_photo', 'menu_photo', 'review')
+);
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+CREATE OR REPLACE FUNCTION public.cafe_revision_state(c public.cafes)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'name', c.name,
+        'address', c.address,
+        'description', c.description,
+        'latitude', c.lat,
+        'longitude', c.lng,
+        'amenities', to_jsonb(COALESCE(c.amenities, '{}'::text[])),
+        'opening_hours', c.opening_hours,
+        'timezone', c.timezone
+    );
+$$;
+
+-- Soft-deleted cafes did not exist before this migration. Deleting them
+-- would cascade into their reviews, photos and revisions, so they come
+-- back as regular cafes instead.
+UPDATE public.cafes
+SET status = 'active',
+    reopens_on = NULL
+WHERE status = 'deleted';
+
+DROP INDEX IF EXISTS public.cafes_status_idx;
+
+ALTER TABLE public.cafes
+DROP CONSTRAINT IF EXISTS cafes_reopens_on_chk;
+
+ALTER TABLE public.cafes
+DROP CONSTRAINT IF EXISTS cafes_status_chk;
+
+ALTER TABLE public.cafes
+DROP COLUMN IF EXISTS status_changed_at,
+DROP COLUMN IF EXISTS status_reason,
+DROP COLUMN IF EXISTS reopens_on,
+DROP COLUMN IF EXISTS status;
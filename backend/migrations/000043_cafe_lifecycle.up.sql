@@ -0,0 +1,68 @@
+ALTER TABLE public.cafes
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'active',
+ADD COLUMN IF NOT EXISTS reopens_on DATE NULL,
+ADD COLUMN IF NOT EXISTS status_reason TEXT NULL,
+ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ NULL;
+
+ALTER TABLE public.cafes
+DROP CONSTRAINT IF EXISTS cafes_status_chk;
+
+ALTER TABLE public.cafes
+ADD CONSTRAINT cafes_status_chk CHECK (
+    status IN ('active', 'temporarily_closed', 'permanently_closed', 'deleted')
+);
+
+ALTER TABLE public.cafes
+DROP CONSTRAINT IF EXISTS cafes_reopens_on_chk;
+
+ALTER TABLE public.cafes
+ADD CONSTRAINT cafes_reopens_on_chk CHECK (
+    reopens_on IS NULL OR status = 'temporarily_closed'
+);
+
+CREATE INDEX IF NOT EXISTS cafes_status_idx
+    ON public.cafes (status)
+    WHERE status <> 'active';
+
+-- Status is part of the tracked state so closures show up in the revision
+-- timeline and can be reverted like any other field.
+CREATE OR REPLACE FUNCTION public.cafe_revision_state(c public.cafes)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'name', c.name,
+        'address', c.address,
+        'description', c.description,
+        'latitude', c.lat,
+        'longitude', c.lng,
+        'amenities', to_jsonb(COALESCE(c.amenities, '{}'::text[])),
+        'opening_hours', c.opening_hours,
+        'timezone', c.timezone,
+        'status', c.status,
+        'reopens_on', c.reopens_on
+    );
+$$;
+
+-- Tiles only render visible cafes and carry the status, so a status change
+-- invalidates them like a rename or a move does.
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+    OR OLD.status IS DISTINCT FROM NEW.status
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_description', 'cafe_hours', 'cafe_closure', 'cafe_photo', 'menu_photo', 'review')
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.cafe_import_job_items;
+DROP TABLE IF EXISTS public.cafe_import_jobs;
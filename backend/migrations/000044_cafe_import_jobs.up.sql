@@ -0,0 +1,56 @@
+CREATE TABLE IF NOT EXISTS public.cafe_import_jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    format TEXT NOT NULL,
+    mode TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    source_name TEXT NULL,
+    status TEXT NOT NULL DEFAULT 'queued',
+    total INTEGER NOT NULL,
+    -- processed is the index of the last applied item; a resumed job
+    -- continues right after it.
+    processed INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    updated_count INTEGER NOT NULL DEFAULT 0,
+    skipped_count INTEGER NOT NULL DEFAULT 0,
+    invalid_count INTEGER NOT NULL DEFAULT 0,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    error TEXT NULL,
+    created_by UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    started_at TIMESTAMPTZ NULL,
+    finished_at TIMESTAMPTZ NULL,
+    heartbeat_at TIMESTAMPTZ NULL,
+    CONSTRAINT cafe_import_jobs_format_chk CHECK (format IN ('json', 'csv', 'geojson')),
+    CONSTRAINT cafe_import_jobs_mode_chk CHECK (mode IN ('skip_existing', 'upsert')),
+    CONSTRAINT cafe_import_jobs_status_chk CHECK (
+        status IN ('queued', 'running', 'completed', 'failed', 'cancelled')
+    ),
+    CONSTRAINT cafe_import_jobs_progress_chk CHECK (processed >= 0 AND processed <= total)
+);
+
+CREATE INDEX IF NOT EXISTS cafe_import_jobs_pending_idx
+    ON public.cafe_import_jobs (created_at)
+    WHERE status IN ('queued', 'running');
+
+CREATE INDEX IF NOT EXISTS cafe_import_jobs_created_idx
+    ON public.cafe_import_jobs (created_at DESC);
+
+-- One row per source record. payload is the record in the
+-- adminCafeImportItem shape, parse_issues what the format parser rejected;
+-- status, cafe_id, message and issues are filled when the item is applied.
+CREATE TABLE IF NOT EXISTS public.cafe_import_job_items (
+    job_id UUID NOT NULL REFERENCES public.cafe_import_jobs(id) ON DELETE CASCADE,
+    item_index INTEGER NOT NULL,
+    payload JSONB NOT NULL,
+    parse_issues JSONB NULL,
+    status TEXT NULL,
+    cafe_id UUID NULL,
+    message TEXT NULL,
+    issues JSONB NULL,
+    PRIMARY KEY (job_id, item_index)
+);
+
+CREATE INDEX IF NOT EXISTS cafe_import_job_items_issues_idx
+    ON public.cafe_import_job_items (job_id, item_index)
+    WHERE status IN ('invalid', 'failed');
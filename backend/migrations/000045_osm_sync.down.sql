@@ -0,0 +1,17 @@
+DELETE FROM public.moderation_submissions
+WHERE entity_type = 'cafe_details';
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_description', 'cafe_hours', 'cafe_closure', 'cafe_photo', 'menu_photo', 'review')
+);
+
+DROP TABLE IF EXISTS public.osm_sync_runs;
+DROP TABLE IF EXISTS public.osm_cafe_links;
+
+-- Removing the user also removes its submissions (ON DELETE CASCADE).
+DELETE FROM public.users
+WHERE id = '00000000-0000-4000-8000-000000000051';
@@ -0,0 +1,62 @@
+-- Submissions proposed by the OpenStreetMap sync are authored by this
+-- account. It has no email or credentials, so nobody can sign in as it.
+INSERT INTO public.users (id, email_normalized, display_name, role)
+VALUES ('00000000-0000-4000-8000-000000000051', NULL, 'OpenStreetMap', 'user')
+ON CONFLICT (id) DO NOTHING;
+
+-- One row per OSM element ever seen. name/address/lat/lng are the element as
+-- of the last sync that acted on it, so the next sync only proposes what
+-- changed in OSM since then. cafe_id stays NULL while the create submission
+-- waits for moderation (or after it was rejected).
+CREATE TABLE IF NOT EXISTS public.osm_cafe_links (
+    osm_type TEXT NOT NULL,
+    osm_id BIGINT NOT NULL,
+    cafe_id UUID NULL REFERENCES public.cafes(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    address TEXT NOT NULL,
+    lat DOUBLE PRECISION NOT NULL,
+    lng DOUBLE PRECISION NOT NULL,
+    removed_at TIMESTAMPTZ NULL,
+    first_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (osm_type, osm_id),
+    CONSTRAINT osm_cafe_links_type_chk CHECK (osm_type IN ('node', 'way', 'relation'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS osm_cafe_links_cafe_uidx
+    ON public.osm_cafe_links (cafe_id)
+    WHERE cafe_id IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS public.osm_sync_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    source TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    status TEXT NOT NULL,
+    fetched_count INTEGER NOT NULL DEFAULT 0,
+    created_count INTEGER NOT NULL DEFAULT 0,
+    matched_count INTEGER NOT NULL DEFAULT 0,
+    renamed_count INTEGER NOT NULL DEFAULT 0,
+    moved_count INTEGER NOT NULL DEFAULT 0,
+    removed_count INTEGER NOT NULL DEFAULT 0,
+    restored_count INTEGER NOT NULL DEFAULT 0,
+    unchanged_count INTEGER NOT NULL DEFAULT 0,
+    submissions_count INTEGER NOT NULL DEFAULT 0,
+    removals_skipped BOOLEAN NOT NULL DEFAULT false,
+    error TEXT NULL,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT osm_sync_runs_status_chk CHECK (status IN ('completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS osm_sync_runs_started_idx
+    ON public.osm_sync_runs (started_at DESC);
+
+-- cafe_details proposes a new name, address or position for a cafe.
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_details', 'cafe_description', 'cafe_hours', 'cafe_closure', 'cafe_photo', 'menu_photo', 'review')
+);
@@ -0,0 +1,14 @@
+-- Geocoding answers by normalized query ("forward") or rounded coordinates
+-- ("reverse"). Misses are stored too, as {"found": false}, with a shorter TTL.
+CREATE TABLE IF NOT EXISTS public.geocode_cache (
+    kind TEXT NOT NULL,
+    query_key TEXT NOT NULL,
+    result JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (kind, query_key),
+    CONSTRAINT geocode_cache_kind_chk CHECK (kind IN ('forward', 'reverse'))
+);
+
+CREATE INDEX IF NOT EXISTS geocode_cache_expires_idx
+    ON public.geocode_cache (expires_at);
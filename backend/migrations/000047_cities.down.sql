@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS cities_reassign_cafes_trg ON public.cities;
+DROP TRIGGER IF EXISTS cafes_assign_city_trg ON public.cafes;
+DROP FUNCTION IF EXISTS public.reassign_cafe_cities();
+DROP FUNCTION IF EXISTS public.assign_cafe_city();
+DROP FUNCTION IF EXISTS public.city_for_point(geometry);
+
+DROP INDEX IF EXISTS public.cafes_city_idx;
+ALTER TABLE public.cafes DROP COLUMN IF EXISTS city_id;
+
+DROP TABLE IF EXISTS public.cities;
@@ -0,0 +1,85 @@
+-- Cities scope the public API (?city=<slug>). A cafe belongs to the smallest
+-- city whose boundary covers it; the assignment is kept up to date by
+-- triggers on both tables, so writers never set city_id themselves.
+CREATE TABLE IF NOT EXISTS public.cities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    slug TEXT NOT NULL,
+    name TEXT NOT NULL,
+    timezone TEXT NOT NULL DEFAULT 'Europe/Moscow',
+    center_lat DOUBLE PRECISION NOT NULL,
+    center_lng DOUBLE PRECISION NOT NULL,
+    default_zoom SMALLINT NOT NULL DEFAULT 12,
+    boundary geometry(MultiPolygon, 4326) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT cities_slug_uniq UNIQUE (slug),
+    CONSTRAINT cities_slug_chk CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    CONSTRAINT cities_center_lat_chk CHECK (center_lat BETWEEN -90 AND 90),
+    CONSTRAINT cities_center_lng_chk CHECK (center_lng BETWEEN -180 AND 180),
+    CONSTRAINT cities_default_zoom_chk CHECK (default_zoom BETWEEN 0 AND 22)
+);
+
+CREATE INDEX IF NOT EXISTS cities_boundary_gix ON public.cities USING GIST (boundary);
+
+ALTER TABLE public.cafes
+    ADD COLUMN IF NOT EXISTS city_id UUID NULL REFERENCES public.cities(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS cafes_city_idx ON public.cafes (city_id) WHERE city_id IS NOT NULL;
+
+CREATE OR REPLACE FUNCTION public.city_for_point(p geometry)
+RETURNS UUID
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT id
+      FROM public.cities
+     WHERE ST_Covers(boundary, p)
+     ORDER BY ST_Area(boundary) ASC, slug ASC
+     LIMIT 1;
+$$;
+
+CREATE OR REPLACE FUNCTION public.assign_cafe_city()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.city_id := public.city_for_point(
+        COALESCE(NEW.geog::geometry, ST_SetSRID(ST_MakePoint(NEW.lng, NEW.lat), 4326))
+    );
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafes_assign_city_trg ON public.cafes;
+CREATE TRIGGER cafes_assign_city_trg
+BEFORE INSERT OR UPDATE OF lat, lng, geog ON public.cafes
+FOR EACH ROW
+EXECUTE FUNCTION public.assign_cafe_city();
+
+-- A city boundary change can move cafes in or out of any city, so every
+-- cafe is re-evaluated. Cities change rarely; cafes_assign_city_trg does
+-- not fire because only city_id is written.
+CREATE OR REPLACE FUNCTION public.reassign_cafe_cities()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    UPDATE public.cafes c
+       SET city_id = a.city_id
+      FROM (
+        SELECT id, public.city_for_point(
+            COALESCE(geog::geometry, ST_SetSRID(ST_MakePoint(lng, lat), 4326))
+        ) AS city_id
+          FROM public.cafes
+      ) a
+     WHERE a.id = c.id
+       AND c.city_id IS DISTINCT FROM a.city_id;
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cities_reassign_cafes_trg ON public.cities;
+CREATE TRIGGER cities_reassign_cafes_trg
+AFTER INSERT OR DELETE OR UPDATE OF boundary ON public.cities
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.reassign_cafe_cities();
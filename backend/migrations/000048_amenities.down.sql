@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS public.amenity_aliases;
+DROP TABLE IF EXISTS public.amenities;
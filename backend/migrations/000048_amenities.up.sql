@@ -0,0 +1,39 @@
+-- Amenity taxonomy. cafes.amenities keeps plain keys; this table says which
+-- keys exist, how to show them and which old names (aliases) map onto them.
+-- Deprecated amenities stay on cafes and in filters but are not accepted in
+-- new cafe data.
+CREATE TABLE IF NOT EXISTS public.amenities (
+    key TEXT PRIMARY KEY,
+    category TEXT NOT NULL DEFAULT 'other',
+    labels JSONB NOT NULL DEFAULT '{}'::jsonb,
+    icon TEXT NULL,
+    position INT NOT NULL DEFAULT 0,
+    deprecated_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT amenities_key_chk CHECK (key ~ '^[a-z0-9]+([_-][a-z0-9]+)*$' AND length(key) <= 40),
+    CONSTRAINT amenities_category_chk CHECK (category ~ '^[a-z0-9]+([_-][a-z0-9]+)*$'),
+    CONSTRAINT amenities_labels_chk CHECK (jsonb_typeof(labels) = 'object')
+);
+
+CREATE INDEX IF NOT EXISTS amenities_position_idx ON public.amenities (category, position, key);
+
+-- Aliases resolve to a canonical key. Renames record the old key here so
+-- clients, pending submissions and the OSM sync mapping keep working.
+CREATE TABLE IF NOT EXISTS public.amenity_aliases (
+    alias TEXT PRIMARY KEY,
+    amenity_key TEXT NOT NULL REFERENCES public.amenities(key) ON UPDATE CASCADE ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT amenity_aliases_alias_chk CHECK (alias <> '' AND position(',' IN alias) = 0)
+);
+
+CREATE INDEX IF NOT EXISTS amenity_aliases_key_idx ON public.amenity_aliases (amenity_key);
+
+INSERT INTO public.amenities (key, category, labels, icon, position)
+VALUES
+    ('wifi', 'work', '{"ru": "Wi-Fi", "en": "Wi-Fi"}'::jsonb, 'wifi', 10),
+    ('power', 'work', '{"ru": "Розетки", "en": "Power outlets"}'::jsonb, 'plug', 20),
+    ('laptop', 'work', '{"ru": "Ноут", "en": "Laptop friendly"}'::jsonb, 'device-laptop', 30),
+    ('quiet', 'atmosphere', '{"ru": "Тихо", "en": "Quiet"}'::jsonb, 'volume-off', 10),
+    ('toilet', 'facilities', '{"ru": "Туалет", "en": "Restroom"}'::jsonb, 'toilet-paper', 10)
+ON CONFLICT (key) DO NOTHING;
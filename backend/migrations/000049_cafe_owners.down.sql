@@ -0,0 +1,26 @@
+ALTER TABLE public.cafe_revisions
+DROP CONSTRAINT IF EXISTS cafe_revisions_source_chk;
+
+-- cafe_revisions is append-only, so owner rows cannot be relabelled.
+DELETE FROM public.cafe_revisions
+WHERE source = 'owner';
+
+ALTER TABLE public.cafe_revisions
+ADD CONSTRAINT cafe_revisions_source_chk CHECK (
+    source IN ('baseline', 'admin', 'import', 'moderation', 'revert', 'merge', 'system')
+);
+
+DROP INDEX IF EXISTS public.moderation_submissions_pending_claim_uniq;
+
+DELETE FROM public.moderation_submissions
+WHERE entity_type = 'cafe_claim';
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_details', 'cafe_description', 'cafe_hours', 'cafe_closure', 'cafe_photo', 'menu_photo', 'review')
+);
+
+DROP TABLE IF EXISTS public.cafe_owners;
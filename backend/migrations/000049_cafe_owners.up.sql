@@ -0,0 +1,36 @@
+-- Verified cafe owners. A row is created when a moderator approves a
+-- cafe_claim submission; owners then edit hours, description and photos
+-- without waiting in the moderation queue.
+CREATE TABLE IF NOT EXISTS public.cafe_owners (
+    cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    claim_submission_id UUID NULL REFERENCES public.moderation_submissions(id) ON DELETE SET NULL,
+    approved_by UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (cafe_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS cafe_owners_user_idx
+    ON public.cafe_owners (user_id, created_at DESC);
+
+ALTER TABLE public.moderation_submissions
+DROP CONSTRAINT IF EXISTS moderation_submissions_entity_type_chk;
+
+ALTER TABLE public.moderation_submissions
+ADD CONSTRAINT moderation_submissions_entity_type_chk CHECK (
+    entity_type IN ('cafe', 'cafe_details', 'cafe_description', 'cafe_hours', 'cafe_closure', 'cafe_photo', 'menu_photo', 'cafe_claim', 'review')
+);
+
+-- One open claim per user and cafe.
+CREATE UNIQUE INDEX IF NOT EXISTS moderation_submissions_pending_claim_uniq
+    ON public.moderation_submissions (author_user_id, target_id)
+    WHERE entity_type = 'cafe_claim' AND status = 'pending';
+
+-- Owner edits are logged in the revision history under their own source.
+ALTER TABLE public.cafe_revisions
+DROP CONSTRAINT IF EXISTS cafe_revisions_source_chk;
+
+ALTER TABLE public.cafe_revisions
+ADD CONSTRAINT cafe_revisions_source_chk CHECK (
+    source IN ('baseline', 'admin', 'import', 'moderation', 'owner', 'revert', 'merge', 'system')
+);
@@ -0,0 +1,13 @@
+DELETE FROM public.abuse_reports
+WHERE reply_id IS NOT NULL;
+
+DROP INDEX IF EXISTS public.abuse_reports_reply_reporter_uniq;
+DROP INDEX IF EXISTS public.abuse_reports_review_reporter_uniq;
+
+ALTER TABLE public.abuse_reports
+    ADD CONSTRAINT abuse_reports_unique_reporter UNIQUE (review_id, reporter_user_id);
+
+ALTER TABLE public.abuse_reports
+    DROP COLUMN IF EXISTS reply_id;
+
+DROP TABLE IF EXISTS public.review_replies;
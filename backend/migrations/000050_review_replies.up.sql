@@ -0,0 +1,31 @@
+-- Public replies of verified cafe owners, at most one per review. Any current
+-- owner of the cafe may edit or delete it.
+CREATE TABLE IF NOT EXISTS public.review_replies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES public.reviews(id) ON DELETE CASCADE,
+    cafe_id UUID NOT NULL REFERENCES public.cafes(id) ON DELETE CASCADE,
+    author_user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT review_replies_review_uniq UNIQUE (review_id)
+);
+
+CREATE INDEX IF NOT EXISTS review_replies_cafe_idx
+    ON public.review_replies (cafe_id, created_at DESC);
+
+-- Replies are reported through abuse_reports as well; review_id still points
+-- at the replied review so cafe-level queries keep working.
+ALTER TABLE public.abuse_reports
+    ADD COLUMN IF NOT EXISTS reply_id UUID NULL REFERENCES public.review_replies(id) ON DELETE CASCADE;
+
+ALTER TABLE public.abuse_reports
+DROP CONSTRAINT IF EXISTS abuse_reports_unique_reporter;
+
+CREATE UNIQUE INDEX IF NOT EXISTS abuse_reports_review_reporter_uniq
+    ON public.abuse_reports (review_id, reporter_user_id)
+    WHERE reply_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS abuse_reports_reply_reporter_uniq
+    ON public.abuse_reports (reply_id, reporter_user_id)
+    WHERE reply_id IS NOT NULL;
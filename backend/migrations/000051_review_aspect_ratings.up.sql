@@ -0,0 +1,17 @@
+-- Optional per-aspect scores of a review. The overall reviews.rating stays
+-- the primary score; aspects are aggregated into
+-- cafe_rating_snapshots.components -> 'aspect_ratings'.
+CREATE TABLE IF NOT EXISTS public.review_aspect_ratings (
+    review_id UUID NOT NULL REFERENCES public.reviews(id) ON DELETE CASCADE,
+    aspect TEXT NOT NULL,
+    score SMALLINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (review_id, aspect),
+    CONSTRAINT review_aspect_ratings_aspect_chk CHECK (
+        aspect IN ('coffee', 'service', 'atmosphere', 'workspace')
+    ),
+    CONSTRAINT review_aspect_ratings_score_chk CHECK (score BETWEEN 1 AND 5)
+);
+
+CREATE INDEX IF NOT EXISTS review_aspect_ratings_aspect_idx
+    ON public.review_aspect_ratings (aspect);
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS public.review_positions_scored_drink_idx;
+
+ALTER TABLE public.review_positions
+    DROP CONSTRAINT IF EXISTS review_positions_score_chk;
+
+ALTER TABLE public.review_positions
+    DROP COLUMN IF EXISTS note,
+    DROP COLUMN IF EXISTS score;
@@ -0,0 +1,15 @@
+-- Optional per-drink score and note of a review position; they feed the
+-- per-cafe drink rankings and the city-wide drink leaderboards.
+ALTER TABLE public.review_positions
+    ADD COLUMN IF NOT EXISTS score SMALLINT NULL,
+    ADD COLUMN IF NOT EXISTS note TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE public.review_positions
+    DROP CONSTRAINT IF EXISTS review_positions_score_chk;
+
+ALTER TABLE public.review_positions
+    ADD CONSTRAINT review_positions_score_chk CHECK (score IS NULL OR score BETWEEN 1 AND 5);
+
+CREATE INDEX IF NOT EXISTS review_positions_scored_drink_idx
+    ON public.review_positions (drink_id)
+    WHERE score IS NOT NULL;
@@ -0,0 +1,14 @@
+DELETE FROM public.abuse_reports
+WHERE comment_id IS NOT NULL;
+
+DROP INDEX IF EXISTS public.abuse_reports_comment_reporter_uniq;
+DROP INDEX IF EXISTS public.abuse_reports_review_reporter_uniq;
+
+CREATE UNIQUE INDEX IF NOT EXISTS abuse_reports_review_reporter_uniq
+    ON public.abuse_reports (review_id, reporter_user_id)
+    WHERE reply_id IS NULL;
+
+ALTER TABLE public.abuse_reports
+    DROP COLUMN IF EXISTS comment_id;
+
+DROP TABLE IF EXISTS public.review_comments;
@@ -0,0 +1,39 @@
+-- Threaded comments on reviews. root_id is the top-level comment of the
+-- thread (NULL for top-level comments) so the replies of a page of threads
+-- load with one query. Removed comments keep their row to preserve the
+-- thread shape.
+CREATE TABLE IF NOT EXISTS public.review_comments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES public.reviews(id) ON DELETE CASCADE,
+    parent_id UUID NULL REFERENCES public.review_comments(id) ON DELETE CASCADE,
+    root_id UUID NULL REFERENCES public.review_comments(id) ON DELETE CASCADE,
+    author_user_id UUID NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'published',
+    removed_by UUID NULL REFERENCES public.users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    edited_at TIMESTAMPTZ NULL,
+    CONSTRAINT review_comments_status_chk CHECK (status IN ('published', 'removed'))
+);
+
+CREATE INDEX IF NOT EXISTS review_comments_threads_idx
+    ON public.review_comments (review_id, created_at, id)
+    WHERE parent_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS review_comments_root_idx
+    ON public.review_comments (root_id, created_at);
+
+-- Comment reports share abuse_reports with review and reply reports.
+ALTER TABLE public.abuse_reports
+    ADD COLUMN IF NOT EXISTS comment_id UUID NULL REFERENCES public.review_comments(id) ON DELETE CASCADE;
+
+DROP INDEX IF EXISTS public.abuse_reports_review_reporter_uniq;
+
+CREATE UNIQUE INDEX IF NOT EXISTS abuse_reports_review_reporter_uniq
+    ON public.abuse_reports (review_id, reporter_user_id)
+    WHERE reply_id IS NULL AND comment_id IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS abuse_reports_comment_reporter_uniq
+    ON public.abuse_reports (comment_id, reporter_user_id)
+    WHERE comment_id IS NOT NULL;
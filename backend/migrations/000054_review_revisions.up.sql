@@ -0,0 +1,54 @@
+-- Immutable history of review content. Every publish and edit appends a
+-- revision; reviews and their side tables keep holding the current one,
+-- which is all the rating snapshot reads.
+CREATE TABLE IF NOT EXISTS public.review_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES public.reviews(id) ON DELETE CASCADE,
+    revision INT NOT NULL,
+    rating SMALLINT NOT NULL,
+    summary TEXT NOT NULL,
+    positions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    taste_tags TEXT[] NOT NULL DEFAULT '{}'::text[],
+    photos TEXT[] NOT NULL DEFAULT '{}'::text[],
+    aspects JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT review_revisions_revision_chk CHECK (revision >= 1),
+    CONSTRAINT review_revisions_review_revision_uniq UNIQUE (review_id, revision)
+);
+
+-- Existing reviews start their history from the current content.
+INSERT INTO public.review_revisions (review_id, revision, rating, summary, positions, taste_tags, photos, aspects, created_at)
+SELECT
+    r.id,
+    1,
+    r.rating,
+    r.summary,
+    COALESCE((
+        SELECT jsonb_agg(
+            jsonb_build_object(
+                'position', rp.position,
+                'drink_id', COALESCE(rp.drink_id, ''),
+                'drink_name', COALESCE(rp.drink_name, ''),
+                'score', rp.score,
+                'note', rp.note
+            )
+            ORDER BY rp.position
+        )
+          FROM public.review_positions rp
+         WHERE rp.review_id = r.id
+    ), '[]'::jsonb),
+    COALESCE(ra.taste_tags, '{}'::text[]),
+    COALESCE((
+        SELECT array_agg(p.photo_url ORDER BY p.position)
+          FROM public.review_photos p
+         WHERE p.review_id = r.id
+    ), '{}'::text[]),
+    COALESCE((
+        SELECT jsonb_object_agg(ara.aspect, ara.score)
+          FROM public.review_aspect_ratings ara
+         WHERE ara.review_id = r.id
+    ), '{}'::jsonb),
+    r.updated_at
+FROM public.reviews r
+LEFT JOIN public.review_attributes ra ON ra.review_id = r.id
+ON CONFLICT (review_id, revision) DO NOTHING;
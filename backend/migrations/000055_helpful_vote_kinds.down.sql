@@ -0,0 +1,10 @@
+DELETE FROM public.helpful_votes
+WHERE kind <> 'helpful';
+
+DROP INDEX IF EXISTS public.helpful_votes_review_kind_idx;
+
+ALTER TABLE public.helpful_votes
+    DROP CONSTRAINT IF EXISTS helpful_votes_kind_chk;
+
+ALTER TABLE public.helpful_votes
+    DROP COLUMN IF EXISTS kind;
@@ -0,0 +1,14 @@
+-- A voter holds at most one vote per review, either "helpful" or
+-- "not_helpful". Retracting deletes the row; the reputation side keeps
+-- its history through retraction events.
+ALTER TABLE public.helpful_votes
+    ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'helpful';
+
+ALTER TABLE public.helpful_votes
+    DROP CONSTRAINT IF EXISTS helpful_votes_kind_chk;
+
+ALTER TABLE public.helpful_votes
+    ADD CONSTRAINT helpful_votes_kind_chk CHECK (kind IN ('helpful', 'not_helpful'));
+
+CREATE INDEX IF NOT EXISTS helpful_votes_review_kind_idx
+    ON public.helpful_votes (review_id, kind);
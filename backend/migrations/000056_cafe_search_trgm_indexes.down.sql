@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS public.cafes_address_trgm_idx;
+DROP INDEX IF EXISTS public.cafes_name_trgm_idx;
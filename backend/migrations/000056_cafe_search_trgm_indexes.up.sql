@@ -0,0 +1,7 @@
+-- Trigram indexes behind the public search prefilter, so a keystroke only
+-- scores cafes whose name or address shares trigrams with the query.
+CREATE INDEX IF NOT EXISTS cafes_name_trgm_idx
+    ON public.cafes USING gin (lower(name) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS cafes_address_trgm_idx
+    ON public.cafes USING gin (lower(address) gin_trgm_ops);
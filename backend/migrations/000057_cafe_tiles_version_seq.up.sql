@@ -0,0 +1,71 @@
+-- The tiles version lived in a singleton row, so every rating recompute,
+-- cafe edit and photo write queued on that row's lock until commit. A
+-- sequence hands out versions without row locks. nextval is not rolled
+-- back, so the bump runs from deferred constraint triggers right before
+-- commit rather than mid-transaction, keeping the window in which a reader
+-- sees the new version ahead of the data as short as possible.
+CREATE SEQUENCE IF NOT EXISTS public.cafe_tiles_version_seq;
+
+-- Continue past the last singleton version so no old ETag is reused.
+SELECT setval(
+    'public.cafe_tiles_version_seq',
+    COALESCE((SELECT version FROM public.cafe_tiles_state WHERE id = 1), 0) + 1,
+    true
+);
+
+CREATE OR REPLACE FUNCTION public.bump_cafe_tiles_version()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    PERFORM nextval('public.cafe_tiles_version_seq');
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_ins_del_trg ON public.cafes;
+CREATE CONSTRAINT TRIGGER cafes_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafes
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE CONSTRAINT TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+    OR OLD.status IS DISTINCT FROM NEW.status
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_ins_del_trg ON public.cafe_rating_snapshots;
+CREATE CONSTRAINT TRIGGER cafe_rating_snapshots_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafe_rating_snapshots
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_upd_trg ON public.cafe_rating_snapshots;
+CREATE CONSTRAINT TRIGGER cafe_rating_snapshots_tiles_version_upd_trg
+AFTER UPDATE ON public.cafe_rating_snapshots
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (
+    OLD.rating IS DISTINCT FROM NEW.rating
+    OR OLD.reviews_count IS DISTINCT FROM NEW.reviews_count
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_photos_tiles_version_trg ON public.cafe_photos;
+CREATE CONSTRAINT TRIGGER cafe_photos_tiles_version_trg
+AFTER INSERT OR UPDATE OR DELETE ON public.cafe_photos
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TABLE IF EXISTS public.cafe_tiles_state;
@@ -0,0 +1,8 @@
+CREATE OR REPLACE FUNCTION public.forbid_cafe_revision_update()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    RAISE EXCEPTION 'cafe_revisions is append-only';
+END;
+$$;
@@ -0,0 +1,15 @@
+-- The only update let through is the ON DELETE SET NULL of actor_user_id, so
+-- deleting a user keeps their revisions and drops the attribution.
+CREATE OR REPLACE FUNCTION public.forbid_cafe_revision_update()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF OLD.actor_user_id IS NOT NULL
+       AND NEW.actor_user_id IS NULL
+       AND (to_jsonb(NEW) - 'actor_user_id') = (to_jsonb(OLD) - 'actor_user_id') THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'cafe_revisions is append-only';
+END;
+$$;
@@ -0,0 +1,18 @@
+CREATE OR REPLACE FUNCTION public.cafe_revision_state(c public.cafes)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'name', c.name,
+        'address', c.address,
+        'description', c.description,
+        'latitude', c.lat,
+        'longitude', c.lng,
+        'amenities', to_jsonb(COALESCE(c.amenities, '{}'::text[])),
+        'opening_hours', c.opening_hours,
+        'timezone', c.timezone,
+        'status', c.status,
+        'reopens_on', c.reopens_on
+    );
+$$;
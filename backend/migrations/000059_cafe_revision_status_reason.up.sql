@@ -0,0 +1,21 @@
+-- The closure reason is tracked with the status, so reverting a closure
+-- brings back the reason that was shown alongside it.
+CREATE OR REPLACE FUNCTION public.cafe_revision_state(c public.cafes)
+RETURNS JSONB
+LANGUAGE sql
+STABLE
+AS $$
+    SELECT jsonb_build_object(
+        'name', c.name,
+        'address', c.address,
+        'description', c.description,
+        'latitude', c.lat,
+        'longitude', c.lng,
+        'amenities', to_jsonb(COALESCE(c.amenities, '{}'::text[])),
+        'opening_hours', c.opening_hours,
+        'timezone', c.timezone,
+        'status', c.status,
+        'reopens_on', c.reopens_on,
+        'status_reason', c.status_reason
+    );
+$$;
@@ -0,0 +1,64 @@
+CREATE SEQUENCE IF NOT EXISTS public.cafe_tiles_version_seq;
+
+SELECT setval(
+    'public.cafe_tiles_version_seq',
+    COALESCE((SELECT sum(version) FROM public.cafe_tiles_version_slots), 0) + 1,
+    true
+);
+
+CREATE OR REPLACE FUNCTION public.bump_cafe_tiles_version()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    PERFORM nextval('public.cafe_tiles_version_seq');
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_ins_del_trg ON public.cafes;
+CREATE CONSTRAINT TRIGGER cafes_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafes
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE CONSTRAINT TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+    OR OLD.status IS DISTINCT FROM NEW.status
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_ins_del_trg ON public.cafe_rating_snapshots;
+CREATE CONSTRAINT TRIGGER cafe_rating_snapshots_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafe_rating_snapshots
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_upd_trg ON public.cafe_rating_snapshots;
+CREATE CONSTRAINT TRIGGER cafe_rating_snapshots_tiles_version_upd_trg
+AFTER UPDATE ON public.cafe_rating_snapshots
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+WHEN (
+    OLD.rating IS DISTINCT FROM NEW.rating
+    OR OLD.reviews_count IS DISTINCT FROM NEW.reviews_count
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_photos_tiles_version_trg ON public.cafe_photos;
+CREATE CONSTRAINT TRIGGER cafe_photos_tiles_version_trg
+AFTER INSERT OR UPDATE OR DELETE ON public.cafe_photos
+DEFERRABLE INITIALLY DEFERRED
+FOR EACH ROW
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TABLE IF EXISTS public.cafe_tiles_version_slots;
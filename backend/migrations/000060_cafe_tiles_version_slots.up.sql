@@ -0,0 +1,75 @@
+-- A sequence bump is visible before the writer commits, so a tile rendered
+-- in between was cached under the new ETag with the old data. The version is
+-- now the sum of counter rows updated inside the writing transaction, which
+-- only becomes visible on commit. Writers are spread over 16 rows by backend
+-- pid, and each transaction bumps at most once, so concurrent rating
+-- recomputes and edits rarely wait on the same row.
+CREATE TABLE IF NOT EXISTS public.cafe_tiles_version_slots (
+    slot SMALLINT PRIMARY KEY,
+    version BIGINT NOT NULL DEFAULT 0,
+    CONSTRAINT cafe_tiles_version_slots_slot_chk CHECK (slot BETWEEN 0 AND 15)
+);
+
+-- Continue past the last sequence value so no old ETag is reused.
+INSERT INTO public.cafe_tiles_version_slots (slot, version)
+SELECT s, CASE WHEN s = 0 THEN (SELECT last_value FROM public.cafe_tiles_version_seq) + 1 ELSE 0 END
+FROM generate_series(0, 15) AS s
+ON CONFLICT (slot) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION public.bump_cafe_tiles_version()
+RETURNS TRIGGER
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    IF current_setting('app.cafe_tiles_bumped', true) = '1' THEN
+        RETURN NULL;
+    END IF;
+    UPDATE public.cafe_tiles_version_slots
+       SET version = version + 1
+     WHERE slot = pg_backend_pid() % 16;
+    PERFORM set_config('app.cafe_tiles_bumped', '1', true);
+    RETURN NULL;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_ins_del_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafes
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafes_tiles_version_upd_trg ON public.cafes;
+CREATE TRIGGER cafes_tiles_version_upd_trg
+AFTER UPDATE ON public.cafes
+FOR EACH ROW
+WHEN (
+    OLD.name IS DISTINCT FROM NEW.name
+    OR OLD.geog IS DISTINCT FROM NEW.geog
+    OR OLD.amenities IS DISTINCT FROM NEW.amenities
+    OR OLD.status IS DISTINCT FROM NEW.status
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_ins_del_trg ON public.cafe_rating_snapshots;
+CREATE TRIGGER cafe_rating_snapshots_tiles_version_ins_del_trg
+AFTER INSERT OR DELETE ON public.cafe_rating_snapshots
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_rating_snapshots_tiles_version_upd_trg ON public.cafe_rating_snapshots;
+CREATE TRIGGER cafe_rating_snapshots_tiles_version_upd_trg
+AFTER UPDATE ON public.cafe_rating_snapshots
+FOR EACH ROW
+WHEN (
+    OLD.rating IS DISTINCT FROM NEW.rating
+    OR OLD.reviews_count IS DISTINCT FROM NEW.reviews_count
+)
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP TRIGGER IF EXISTS cafe_photos_tiles_version_trg ON public.cafe_photos;
+CREATE TRIGGER cafe_photos_tiles_version_trg
+AFTER INSERT OR UPDATE OR DELETE ON public.cafe_photos
+FOR EACH STATEMENT
+EXECUTE FUNCTION public.bump_cafe_tiles_version();
+
+DROP SEQUENCE IF EXISTS public.cafe_tiles_version_seq;